@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"time"
+)
+
+// ListAuditEvents returns one page of audit events matching filter, newest
+// first. It needs WithAdminToken.
+func (c *Client) ListAuditEvents(ctx context.Context, filter AuditFilter, opts ...Option) ([]AuditEvent, error) {
+	var resp data[[]AuditEvent]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/audit/", query: filter.values()}, &resp, opts)
+	return resp.Data, err
+}
+
+// AuditEvents iterates over every audit event matching filter, newest first,
+// fetching filter.Limit events per request. Each page ends before the oldest
+// event of the one before it; events sharing that timestamp are fetched again
+// and skipped, so a page must be larger than any burst of events recorded at
+// the same instant. It needs WithAdminToken.
+func (c *Client) AuditEvents(ctx context.Context, filter AuditFilter, opts ...Option) iter.Seq2[AuditEvent, error] {
+	return func(yield func(AuditEvent, error) bool) {
+		seen := map[int64]bool{}
+		for {
+			page, err := c.ListAuditEvents(ctx, filter, opts...)
+			if err != nil {
+				yield(AuditEvent{}, err)
+				return
+			}
+
+			var (
+				fresh  int
+				oldest time.Time
+			)
+			for _, event := range page {
+				oldest = event.OccurredAt
+				if seen[event.ID] {
+					continue
+				}
+				fresh++
+				if !yield(event, nil) {
+					return
+				}
+			}
+
+			limit := filter.Limit
+			if limit <= 0 {
+				limit = 100
+			}
+			if len(page) < limit || fresh == 0 {
+				return
+			}
+
+			// Events at the boundary instant are kept so none are lost to the
+			// exclusive To.
+			clear(seen)
+			for _, event := range page {
+				if event.OccurredAt.Equal(oldest) {
+					seen[event.ID] = true
+				}
+			}
+			filter.To = oldest.Add(time.Microsecond)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Batch operations and entities.
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+
+	EntityCourse     = "course"
+	EntityPerson     = "person"
+	EntityEnrollment = "enrollment"
+)
+
+// BatchOperation is one operation of a Batch. Target is the course id or the
+// person's first name to update or delete. Body is a CourseInput, a
+// PersonInput or an EnrollmentInput. Strings of the form Ref returns may
+// stand in for any value of Target or Body.
+type BatchOperation struct {
+	Op     string `json:"op"`
+	Entity string `json:"entity"`
+	Target any    `json:"target,omitempty"`
+	Body   any    `json:"body,omitempty"`
+}
+
+// EnrollmentInput enrolls a person in a course, or withdraws them from it.
+// Role defaults to the person's default role and changes the role of a
+// person already enrolled.
+type EnrollmentInput struct {
+	FirstName string `json:"first_name"`
+	CourseID  int    `json:"course_id"`
+	Role      string `json:"role,omitempty"`
+}
+
+// BatchResult is the outcome of one batch operation. Data is the course or
+// person the operation returned, if any.
+type BatchResult struct {
+	Index            int             `json:"index"`
+	Status           int             `json:"status"`
+	Data             json.RawMessage `json:"data,omitempty"`
+	Error            string          `json:"error,omitempty"`
+	ValidationErrors []Problem       `json:"validation_errors,omitempty"`
+}
+
+// Decode decodes the result's data, e.g. into a Course or a Person.
+func (r BatchResult) Decode(out any) error {
+	if err := json.Unmarshal(r.Data, out); err != nil {
+		return fmt.Errorf("[in client.Decode] failed to decode result %d: %w", r.Index, err)
+	}
+	return nil
+}
+
+// Ref refers to the value at path, e.g. "id", in the result of the earlier
+// operation at index.
+func Ref(index int, path string) string {
+	return fmt.Sprintf("$ops[%d].%s", index, path)
+}
+
+// Batch runs ops in order in one transaction. If any operation fails nothing
+// is applied and the returned *Error lists every operation in BatchResults.
+func (c *Client) Batch(ctx context.Context, ops []BatchOperation, opts ...Option) ([]BatchResult, error) {
+	var resp data[[]BatchResult]
+	body := struct {
+		Operations []BatchOperation `json:"operations"`
+	}{ops}
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/batch", body: body}, &resp, opts)
+	return resp.Data, err
+}
@@ -0,0 +1,355 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client for a server answering with handler, which
+// retries quickly.
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c, err := New(server.URL, append([]Option{WithRetries(2, time.Millisecond, 10*time.Millisecond)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// respond writes a response; an empty body writes nothing.
+func respond(w http.ResponseWriter, status int, body string) {
+	if body != "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(status)
+	fmt.Fprint(w, body)
+}
+
+const coursesBody = `{"data": [{"id": 1, "name": "Math"}, {"id": 2, "name": "Logic"}]}`
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		wantCalls  int
+		wantStatus int
+	}{
+		{"success", []int{200}, 1, 0},
+		{"429 then success", []int{429, 200}, 2, 0},
+		{"503 twice then success", []int{503, 503, 200}, 3, 0},
+		{"retries exhausted", []int{503, 429, 503, 200}, 3, 503},
+		{"500 is not retried", []int{500, 200}, 1, 500},
+		{"404 is not retried", []int{404, 200}, 1, 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[calls]
+				calls++
+				if status == http.StatusOK {
+					respond(w, status, coursesBody)
+					return
+				}
+				respond(w, status, `{"error": "try again"}`)
+			})
+
+			courses, err := c.ListCourses(context.Background(), CourseFilter{})
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantStatus == 0 {
+				if err != nil || len(courses) != 2 {
+					t.Errorf("ListCourses() = %v, %v, want 2 courses", courses, err)
+				}
+				return
+			}
+			if !hasStatus(err, tt.wantStatus) {
+				t.Errorf("ListCourses() error = %v, want status %d", err, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRetryAfterIsCapped(t *testing.T) {
+	var calls int
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "3600")
+			respond(w, http.StatusServiceUnavailable, "")
+			return
+		}
+		respond(w, http.StatusOK, coursesBody)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.ListCourses(ctx, CourseFilter{}); err != nil {
+		t.Fatalf("ListCourses() = %v, want the retry to wait at most the maximum backoff", err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestRetryStopsWithContext(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusServiceUnavailable, "")
+	}, WithRetries(5, time.Hour, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.ListCourses(ctx, CourseFilter{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ListCourses() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{now.Add(time.Minute).Format(http.TimeFormat), time.Minute},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{"Retry-After": {tt.value}}}
+		if got := retryAfter(resp, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		want       *Error
+		validation bool
+	}{
+		{"not found", 404, `{"error": "No course with that ID"}`, &Error{StatusCode: 404, Message: "No course with that ID"}, false},
+		{"forbidden", 403, `{"error": "this route requires the X-Admin-Token header"}`,
+			&Error{StatusCode: 403, Message: "this route requires the X-Admin-Token header"}, false},
+		{"validation", 422, `{"error": "invalid course", "validation_errors": [{"name": "department_id", "description": "department 9 does not exist"}]}`,
+			&Error{StatusCode: 422, Message: "invalid course", ValidationErrors: []Problem{{Name: "department_id", Description: "department 9 does not exist"}}}, true},
+		{"batch", 409, `{"error": "batch rolled back", "results": [{"index": 0, "status": 201, "data": {"id": 1}}, {"index": 1, "status": 404, "error": "No course with that ID"}]}`,
+			&Error{StatusCode: 409, Message: "batch rolled back", BatchResults: []BatchResult{
+				{Index: 0, Status: 201, Data: json.RawMessage(`{"id": 1}`)},
+				{Index: 1, Status: 404, Error: "No course with that ID"},
+			}}, false},
+		{"not JSON", 502, "<html>Bad Gateway</html>", &Error{StatusCode: 502, Message: "Bad Gateway"}, false},
+		{"no body", 500, "", &Error{StatusCode: 500, Message: "Internal Server Error"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				respond(w, tt.status, tt.body)
+			})
+
+			_, err := c.GetCourse(context.Background(), 1)
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("GetCourse() error = %v, want an *Error", err)
+			}
+			if !reflect.DeepEqual(apiErr, tt.want) {
+				t.Errorf("error = %+v, want %+v", apiErr, tt.want)
+			}
+			if IsNotFound(err) != (tt.status == 404) || IsForbidden(err) != (tt.status == 403) || IsValidation(err) != tt.validation {
+				t.Errorf("IsNotFound, IsForbidden, IsValidation = %t, %t, %t for %v", IsNotFound(err), IsForbidden(err), IsValidation(err), err)
+			}
+		})
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	err := &Error{StatusCode: 422, Message: "invalid person", ValidationErrors: []Problem{{"email", "is already in use"}, {"phone", "has an invalid format"}}}
+	want := "api error 422: invalid person: email is already in use; phone has an invalid format"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestCoursesIterator(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusOK, coursesBody)
+	})
+
+	var names []string
+	for course, err := range c.Courses(context.Background(), CourseFilter{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, course.Name)
+	}
+	if want := []string{"Math", "Logic"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("courses = %v, want %v", names, want)
+	}
+
+	names = nil
+	for course := range c.Courses(context.Background(), CourseFilter{}) {
+		names = append(names, course.Name)
+		break
+	}
+	if want := []string{"Math"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("courses after break = %v, want %v", names, want)
+	}
+}
+
+func TestCoursesPages(t *testing.T) {
+	var queries []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		after, _ := strconv.Atoi(r.URL.Query().Get("after"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		page := []Course{}
+		for id := after + 1; id <= 5 && len(page) < limit; id++ {
+			page = append(page, Course{ID: id, Name: "Course " + strconv.Itoa(id)})
+		}
+		body, _ := json.Marshal(data[[]Course]{Data: page})
+		respond(w, http.StatusOK, string(body))
+	})
+
+	var ids []int
+	for course, err := range c.Courses(context.Background(), CourseFilter{Name: "course", Limit: 2}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, course.ID)
+	}
+	if want := []int{1, 2, 3, 4, 5}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+	want := []string{"limit=2&name=course", "after=2&limit=2&name=course", "after=4&limit=2&name=course"}
+	if !reflect.DeepEqual(queries, want) {
+		t.Errorf("queries = %q, want %q", queries, want)
+	}
+
+	// Without a limit, pages are defaultPageSize long.
+	queries = nil
+	for range c.Persons(context.Background(), PersonFilter{}) {
+	}
+	if want := []string{"limit=100"}; !reflect.DeepEqual(queries, want) {
+		t.Errorf("queries = %q, want %q", queries, want)
+	}
+}
+
+func TestIteratorYieldsError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusInternalServerError, `{"error": "Error getting courses"}`)
+	})
+
+	var errs []error
+	for _, err := range c.Courses(context.Background(), CourseFilter{}) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !hasStatus(errs[0], http.StatusInternalServerError) {
+		t.Errorf("errors = %v, want one 500", errs)
+	}
+}
+
+func TestAuditEventsPages(t *testing.T) {
+	base := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	// Newest first; events 4 and 3 were recorded at the same instant.
+	events := []AuditEvent{
+		{ID: 5, OccurredAt: base.Add(5 * time.Second)},
+		{ID: 4, OccurredAt: base.Add(3 * time.Second)},
+		{ID: 3, OccurredAt: base.Add(3 * time.Second)},
+		{ID: 2, OccurredAt: base.Add(2 * time.Second)},
+		{ID: 1, OccurredAt: base.Add(1 * time.Second)},
+	}
+	var pages int
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		page := []AuditEvent{}
+		for _, event := range events {
+			if to := r.URL.Query().Get("to"); to != "" {
+				before, err := time.Parse(time.RFC3339Nano, to)
+				if err != nil {
+					t.Errorf("invalid to %q", to)
+				}
+				if !event.OccurredAt.Before(before) {
+					continue
+				}
+			}
+			if len(page) < limit {
+				page = append(page, event)
+			}
+		}
+		body, _ := json.Marshal(data[[]AuditEvent]{Data: page})
+		respond(w, http.StatusOK, string(body))
+	})
+
+	var ids []int64
+	for event, err := range c.AuditEvents(context.Background(), AuditFilter{Limit: 3}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, event.ID)
+	}
+	if want := []int64{5, 4, 3, 2, 1}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+	if pages != 3 {
+		t.Errorf("pages = %d, want 3", pages)
+	}
+}
+
+func TestStreamEventsResumes(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		resumed []string
+	)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		resumed = append(resumed, r.URL.Query().Get("last_event_id"))
+		connection := len(resumed)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		if connection == 1 {
+			fmt.Fprint(w, "retry: 1\n\n: heartbeat\n\n")
+		}
+		position := 6 + connection
+		fmt.Fprintf(w, "id: %d\nevent: person.updated\ndata: {\"id\": %d, \"type\": \"person.updated\"}\n\n", position, 100+connection)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got []Event
+	for event, err := range c.StreamEvents(ctx, FromNow, nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, event)
+		if len(got) == 2 {
+			break
+		}
+	}
+
+	want := []Event{{ID: 101, Position: 7, Type: "person.updated"}, {ID: 102, Position: 8, Type: "person.updated"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"", "7"}; !reflect.DeepEqual(resumed, want) {
+		t.Errorf("last_event_id per connection = %q, want %q", resumed, want)
+	}
+}
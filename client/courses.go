@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ListCourses returns the courses matching filter, in the order of their ids,
+// or one page of them if filter.Limit is set.
+func (c *Client) ListCourses(ctx context.Context, filter CourseFilter, opts ...Option) ([]Course, error) {
+	var resp data[[]Course]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/course/", query: filter.values()}, &resp, opts)
+	return resp.Data, err
+}
+
+// Courses iterates over the courses matching filter, fetching filter.Limit
+// courses per request.
+func (c *Client) Courses(ctx context.Context, filter CourseFilter, opts ...Option) iter.Seq2[Course, error] {
+	return pages(filter.Limit, func(after, limit int) ([]Course, error) {
+		filter.After, filter.Limit = after, limit
+		return c.ListCourses(ctx, filter, opts...)
+	}, func(course Course) int { return course.ID })
+}
+
+// GetCourse returns the course with id.
+func (c *Client) GetCourse(ctx context.Context, id int, opts ...Option) (Course, error) {
+	return c.GetCourseAsOf(ctx, id, time.Time{}, opts...)
+}
+
+// GetCourseAsOf returns the course with id as it was at asOf.
+func (c *Client) GetCourseAsOf(ctx context.Context, id int, asOf time.Time, opts ...Option) (Course, error) {
+	query := url.Values{}
+	setTime(query, "as_of", asOf)
+	var resp data[Course]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/course/" + pathEscape(id), query: query}, &resp, opts)
+	return resp.Data, err
+}
+
+// CreateCourse creates a course.
+func (c *Client) CreateCourse(ctx context.Context, in CourseInput, opts ...Option) (Course, error) {
+	var resp data[Course]
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/course/", body: in}, &resp, opts)
+	return resp.Data, err
+}
+
+// UpdateCourse renames the course with id and sets its capacity.
+func (c *Client) UpdateCourse(ctx context.Context, id int, in CourseInput, opts ...Option) (Course, error) {
+	var resp data[Course]
+	err := c.do(ctx, request{method: http.MethodPut, path: "/api/course/" + pathEscape(id), body: in}, &resp, opts)
+	return resp.Data, err
+}
+
+// DeleteCourse soft deletes the course with id.
+func (c *Client) DeleteCourse(ctx context.Context, id int, opts ...Option) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/api/course/" + pathEscape(id)}, nil, opts)
+}
+
+// RestoreCourse undoes the soft delete of the course with id.
+func (c *Client) RestoreCourse(ctx context.Context, id int, opts ...Option) (Course, error) {
+	var resp data[Course]
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/course/" + pathEscape(id) + "/restore"}, &resp, opts)
+	return resp.Data, err
+}
+
+// ListWaitlist returns the persons waiting for a seat in the course with id,
+// next in line first.
+func (c *Client) ListWaitlist(ctx context.Context, id int, opts ...Option) ([]WaitlistEntry, error) {
+	var resp data[[]WaitlistEntry]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/course/" + pathEscape(id) + "/waitlist"}, &resp, opts)
+	return resp.Data, err
+}
+
+// JoinWaitlist puts the person with firstName on the waitlist of the full
+// course with id and returns their place in line. They are enrolled as a
+// student when their turn comes.
+func (c *Client) JoinWaitlist(ctx context.Context, id int, firstName string, opts ...Option) (WaitlistEntry, error) {
+	body := struct {
+		FirstName string `json:"first_name"`
+	}{firstName}
+	var resp data[WaitlistEntry]
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/course/" + pathEscape(id) + "/waitlist", body: body}, &resp, opts)
+	return resp.Data, err
+}
+
+// LeaveWaitlist takes the person with firstName off the waitlist of the
+// course with id.
+func (c *Client) LeaveWaitlist(ctx context.Context, id int, firstName string, opts ...Option) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/api/course/" + pathEscape(id) + "/waitlist/" + pathEscape(firstName)}, nil, opts)
+}
+
+// ListPrerequisites returns the prerequisites of the course with id.
+func (c *Client) ListPrerequisites(ctx context.Context, id int, opts ...Option) ([]Prerequisite, error) {
+	var resp data[[]Prerequisite]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/course/" + pathEscape(id) + "/prerequisites"}, &resp, opts)
+	return resp.Data, err
+}
+
+// AddPrerequisite makes the course with id require the course with
+// prerequisiteID and returns its prerequisites.
+func (c *Client) AddPrerequisite(ctx context.Context, id, prerequisiteID int, opts ...Option) ([]Prerequisite, error) {
+	body := struct {
+		PrerequisiteID int `json:"prerequisite_id"`
+	}{prerequisiteID}
+	var resp data[[]Prerequisite]
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/course/" + pathEscape(id) + "/prerequisites", body: body}, &resp, opts)
+	return resp.Data, err
+}
+
+// RemovePrerequisite stops the course with id from requiring the course with
+// prerequisiteID.
+func (c *Client) RemovePrerequisite(ctx context.Context, id, prerequisiteID int, opts ...Option) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/api/course/" + pathEscape(id) + "/prerequisites/" + pathEscape(prerequisiteID)}, nil, opts)
+}
+
+// SubscribeCourseCalendar returns where to subscribe to the calendar feed of
+// the course with id.
+func (c *Client) SubscribeCourseCalendar(ctx context.Context, id int, opts ...Option) (CalendarSubscription, error) {
+	var resp data[CalendarSubscription]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/course/" + pathEscape(id) + "/schedule/subscription"}, &resp, opts)
+	return resp.Data, err
+}
+
+// GetCourseCalendar streams the calendar feed of the course with id, given
+// its token, as "text/calendar". The caller closes the returned reader.
+func (c *Client) GetCourseCalendar(ctx context.Context, id int, token string, opts ...Option) (io.ReadCloser, error) {
+	return c.calendar(ctx, "/api/course/"+pathEscape(id)+"/schedule.ics", token, opts)
+}
+
+// calendar streams the calendar feed at path.
+func (c *Client) calendar(ctx context.Context, path, token string, opts []Option) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, request{method: http.MethodGet, path: path, query: url.Values{"token": {token}}, accept: "text/calendar"}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// defaultPageSize is the page size of the list iterators when the filter
+// sets no limit.
+const defaultPageSize = 100
+
+// pages iterates over a list fetched limit items at a time, or
+// defaultPageSize if limit is not positive. Each page starts after the id of
+// the last item of the one before it, and a short page is the last. An error
+// is yielded once and ends the iteration.
+func pages[T any](limit int, fetch func(after, limit int) ([]T, error), id func(T) int) iter.Seq2[T, error] {
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	return func(yield func(T, error) bool) {
+		after := 0
+		for {
+			items, err := fetch(after, limit)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(items) < limit {
+				return
+			}
+			after = id(items[len(items)-1])
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListDepartments returns every department in code order.
+func (c *Client) ListDepartments(ctx context.Context, opts ...Option) ([]Department, error) {
+	var resp data[[]Department]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/department/"}, &resp, opts)
+	return resp.Data, err
+}
+
+// GetDepartment returns the department with id.
+func (c *Client) GetDepartment(ctx context.Context, id int, opts ...Option) (Department, error) {
+	var resp data[Department]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/department/" + pathEscape(id)}, &resp, opts)
+	return resp.Data, err
+}
+
+// CreateDepartment creates a department.
+func (c *Client) CreateDepartment(ctx context.Context, in DepartmentInput, opts ...Option) (Department, error) {
+	var resp data[Department]
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/department/", body: in}, &resp, opts)
+	return resp.Data, err
+}
+
+// UpdateDepartment replaces the department with id. A new code changes the
+// catalog codes of its courses.
+func (c *Client) UpdateDepartment(ctx context.Context, id int, in DepartmentInput, opts ...Option) (Department, error) {
+	var resp data[Department]
+	err := c.do(ctx, request{method: http.MethodPut, path: "/api/department/" + pathEscape(id), body: in}, &resp, opts)
+	return resp.Data, err
+}
+
+// DeleteDepartment deletes the department with id, which may offer no
+// courses.
+func (c *Client) DeleteDepartment(ctx context.Context, id int, opts ...Option) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/api/department/" + pathEscape(id)}, nil, opts)
+}
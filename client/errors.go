@@ -0,0 +1,83 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Problem describes one invalid field of a request.
+type Problem struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Error is a response outside 2xx, decoded from the API's error body.
+type Error struct {
+	StatusCode int
+	// Message is the error reported by the API, or the status text if it
+	// reported none.
+	Message string
+	// ValidationErrors lists the invalid fields of a 400 or 422.
+	ValidationErrors []Problem
+	// BatchResults reports each operation of a rolled back Batch.
+	BatchResults []BatchResult
+}
+
+func (e *Error) Error() string {
+	if len(e.ValidationErrors) == 0 {
+		return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+	}
+	problems := make([]string, 0, len(e.ValidationErrors))
+	for _, p := range e.ValidationErrors {
+		problems = append(problems, p.Name+" "+p.Description)
+	}
+	return fmt.Sprintf("api error %d: %s: %s", e.StatusCode, e.Message, strings.Join(problems, "; "))
+}
+
+// IsNotFound reports whether err is a 404 from the API.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsValidation reports whether err rejected the request's fields, with a 400
+// or 422 listing validation errors.
+func IsValidation(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && len(apiErr.ValidationErrors) > 0
+}
+
+// IsForbidden reports whether err is a 403, e.g. for a missing admin token.
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// decodeError reads the error body of resp, which is
+// {"error": "...", "validation_errors": [...]} for API errors.
+func decodeError(resp *http.Response) *Error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+
+	var body struct {
+		Error            string        `json:"error"`
+		ValidationErrors []Problem     `json:"validation_errors"`
+		Results          []BatchResult `json:"results"`
+	}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if json.Unmarshal(raw, &body) == nil {
+		apiErr.Message = body.Error
+		apiErr.ValidationErrors = body.ValidationErrors
+		apiErr.BatchResults = body.Results
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FromNow starts StreamEvents with the next change instead of replaying.
+const FromNow int64 = -1
+
+// StreamEvents iterates over the changes to persons, courses and enrollments
+// after the event at position lastEventID, or from now if it is FromNow, optionally only those about
+// entities ("person", "course", "enrollment"). When the server closes the
+// stream it reconnects after the delay the server asked for and resumes after
+// the last event, so the iteration only ends when ctx is done, the loop
+// breaks, or a connection fails. It needs WithAdminToken.
+func (c *Client) StreamEvents(ctx context.Context, lastEventID int64, entities []string, opts ...Option) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		retry := 3 * time.Second
+		for {
+			query := url.Values{}
+			if len(entities) > 0 {
+				query.Set("entity", strings.Join(entities, ","))
+			}
+			if lastEventID >= 0 {
+				query.Set("last_event_id", strconv.FormatInt(lastEventID, 10))
+			}
+			resp, err := c.send(ctx, request{method: http.MethodGet, path: "/api/events", query: query, accept: "text/event-stream", stream: true}, opts)
+			if err != nil {
+				if ctx.Err() == nil {
+					yield(Event{}, err)
+				}
+				return
+			}
+
+			stop := false
+			err = readEvents(resp.Body, func(event Event, delay time.Duration) bool {
+				if delay > 0 {
+					retry = delay
+					return true
+				}
+				lastEventID = event.Position
+				stop = !yield(event, nil)
+				return !stop
+			})
+			resp.Body.Close()
+			if stop || ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				if !yield(Event{}, err) {
+					return
+				}
+			}
+
+			timer := time.NewTimer(retry)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// readEvents parses a Server-Sent Events stream, calling fn with each event,
+// or with the reconnection delay when the server sets one, until fn returns
+// false or the stream ends.
+func readEvents(r io.Reader, fn func(Event, time.Duration) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var (
+		payload  strings.Builder
+		position int64
+	)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if payload.Len() == 0 {
+				continue
+			}
+			var event Event
+			if err := json.Unmarshal([]byte(payload.String()), &event); err != nil {
+				return fmt.Errorf("[in client.readEvents] failed to decode event: %w", err)
+			}
+			event.Position = position
+			payload.Reset()
+			if !fn(event, 0) {
+				return nil
+			}
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			if payload.Len() > 0 {
+				payload.WriteByte('\n')
+			}
+			payload.WriteString(value)
+		case "id":
+			if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+				position = id
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms > 0 {
+				if !fn(Event{}, time.Duration(ms)*time.Millisecond) {
+					return nil
+				}
+			}
+		}
+		// event repeats what the data carries; comments are heartbeats.
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Export formats.
+const (
+	ExportCSV    = "csv"
+	ExportNDJSON = "ndjson"
+	ExportXLSX   = "xlsx"
+)
+
+// ExportPersons streams the persons matching filter, with their course
+// names, in format (CSV if empty). The caller closes the returned reader.
+func (c *Client) ExportPersons(ctx context.Context, format string, filter PersonFilter, opts ...Option) (io.ReadCloser, error) {
+	query := filter.values()
+	setString(query, "format", format)
+	return c.export(ctx, "/api/export/persons", query, opts)
+}
+
+// ExportCourses streams the courses matching filter in format (CSV if
+// empty). The caller closes the returned reader.
+func (c *Client) ExportCourses(ctx context.Context, format string, filter CourseFilter, opts ...Option) (io.ReadCloser, error) {
+	query := filter.values()
+	setString(query, "format", format)
+	return c.export(ctx, "/api/export/courses", query, opts)
+}
+
+// ExportEnrollments streams the person by course enrollment matrix for the
+// persons matching filter and the courses whose names contain courseName, in
+// format (CSV if empty). The caller closes the returned reader.
+func (c *Client) ExportEnrollments(ctx context.Context, format, courseName string, filter PersonFilter, opts ...Option) (io.ReadCloser, error) {
+	query := filter.values()
+	setString(query, "format", format)
+	setString(query, "course_name", courseName)
+	return c.export(ctx, "/api/export/enrollments", query, opts)
+}
+
+func (c *Client) export(ctx context.Context, path string, query url.Values, opts []Option) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, request{method: http.MethodGet, path: path, query: query, accept: "*/*"}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GraphQLRequest is a GraphQL operation.
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	// ReadOnly sends the request with GET, which the server only allows for
+	// queries.
+	ReadOnly bool `json:"-"`
+}
+
+// GraphQLError is one entry of the errors list of a GraphQL result.
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// GraphQLErrors is returned by GraphQL when the result lists errors. Any
+// partial data has still been decoded.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Message)
+	}
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+// GraphQL runs req and decodes the data of the result into out, which may be
+// nil.
+func (c *Client) GraphQL(ctx context.Context, req GraphQLRequest, out any, opts ...Option) error {
+	r := request{method: http.MethodPost, path: "/graphql", body: req, graphql: true}
+	if req.ReadOnly {
+		query := url.Values{}
+		query.Set("query", req.Query)
+		setString(query, "operationName", req.OperationName)
+		if len(req.Variables) > 0 {
+			variables, err := json.Marshal(req.Variables)
+			if err != nil {
+				return fmt.Errorf("[in client.GraphQL] failed to encode variables: %w", err)
+			}
+			query.Set("variables", string(variables))
+		}
+		r = request{method: http.MethodGet, path: "/graphql", query: query, graphql: true}
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors GraphQLErrors   `json:"errors"`
+		// Error and ValidationErrors are set when the request itself was
+		// malformed, e.g. without a query.
+		Error            string    `json:"error"`
+		ValidationErrors []Problem `json:"validation_errors"`
+	}
+	if err := c.do(ctx, r, &result, opts); err != nil {
+		return err
+	}
+	if result.Error != "" || len(result.ValidationErrors) > 0 {
+		message := result.Error
+		if message == "" {
+			message = http.StatusText(http.StatusBadRequest)
+		}
+		return &Error{StatusCode: http.StatusBadRequest, Message: message, ValidationErrors: result.ValidationErrors}
+	}
+	if out != nil && len(result.Data) > 0 && string(result.Data) != "null" {
+		if err := json.Unmarshal(result.Data, out); err != nil {
+			return fmt.Errorf("[in client.GraphQL] failed to decode data: %w", err)
+		}
+	}
+	if len(result.Errors) > 0 {
+		return result.Errors
+	}
+	return nil
+}
+
+// OpenAPI returns the API's OpenAPI document.
+func (c *Client) OpenAPI(ctx context.Context, opts ...Option) (json.RawMessage, error) {
+	var doc json.RawMessage
+	err := c.do(ctx, request{method: http.MethodGet, path: "/openapi.json"}, &doc, opts)
+	return doc, err
+}
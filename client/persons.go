@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ListPersons returns the persons matching filter, in the order of their ids,
+// or one page of them if filter.Limit is set.
+func (c *Client) ListPersons(ctx context.Context, filter PersonFilter, opts ...Option) ([]Person, error) {
+	var resp data[[]Person]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/person/", query: filter.values()}, &resp, opts)
+	return resp.Data, err
+}
+
+// Persons iterates over the persons matching filter, fetching filter.Limit
+// persons per request.
+func (c *Client) Persons(ctx context.Context, filter PersonFilter, opts ...Option) iter.Seq2[Person, error] {
+	return pages(filter.Limit, func(after, limit int) ([]Person, error) {
+		filter.After, filter.Limit = after, limit
+		return c.ListPersons(ctx, filter, opts...)
+	}, func(p Person) int { return p.ID })
+}
+
+// GetPerson returns the person with firstName.
+func (c *Client) GetPerson(ctx context.Context, firstName string, opts ...Option) (Person, error) {
+	return c.GetPersonAsOf(ctx, firstName, time.Time{}, opts...)
+}
+
+// GetPersonAsOf returns the person with firstName as they were at asOf.
+func (c *Client) GetPersonAsOf(ctx context.Context, firstName string, asOf time.Time, opts ...Option) (Person, error) {
+	query := url.Values{}
+	setTime(query, "as_of", asOf)
+	var resp data[Person]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/person/" + pathEscape(firstName), query: query}, &resp, opts)
+	return resp.Data, err
+}
+
+// CreatePerson creates a person enrolled in in.Courses.
+func (c *Client) CreatePerson(ctx context.Context, in PersonInput, opts ...Option) (Person, error) {
+	var resp data[Person]
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/person/", body: in}, &resp, opts)
+	return resp.Data, err
+}
+
+// UpdatePerson replaces the person with firstName, including their
+// enrollments.
+func (c *Client) UpdatePerson(ctx context.Context, firstName string, in PersonInput, opts ...Option) (Person, error) {
+	// The API returns the updated person without the data wrapper.
+	var person Person
+	err := c.do(ctx, request{method: http.MethodPut, path: "/api/person/" + pathEscape(firstName), body: in}, &person, opts)
+	return person, err
+}
+
+// CheckEligibility reports whether the person with firstName could be
+// enrolled in the course with courseID with role, or their default role if
+// it is empty, without enrolling them.
+func (c *Client) CheckEligibility(ctx context.Context, firstName string, courseID int, role string, opts ...Option) (Eligibility, error) {
+	body := struct {
+		CourseID int    `json:"course_id"`
+		Role     string `json:"role,omitempty"`
+	}{courseID, role}
+	var resp data[Eligibility]
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/person/" + pathEscape(firstName) + "/eligibility", body: body}, &resp, opts)
+	return resp.Data, err
+}
+
+// DeletePerson soft deletes the person with firstName.
+func (c *Client) DeletePerson(ctx context.Context, firstName string, opts ...Option) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/api/person/" + pathEscape(firstName)}, nil, opts)
+}
+
+// RestorePerson undoes the soft delete of the person with id.
+func (c *Client) RestorePerson(ctx context.Context, id int, opts ...Option) (Person, error) {
+	var resp data[Person]
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/person/" + pathEscape(id) + "/restore"}, &resp, opts)
+	return resp.Data, err
+}
+
+// GetTranscript returns the transcript of the person with id.
+func (c *Client) GetTranscript(ctx context.Context, id int, opts ...Option) (Transcript, error) {
+	var resp data[Transcript]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/person/" + pathEscape(id) + "/transcript"}, &resp, opts)
+	return resp.Data, err
+}
+
+// RenderTranscript streams the transcript of the person with id rendered for
+// people to read, as "text/plain" or "text/html". The caller closes the
+// returned reader.
+func (c *Client) RenderTranscript(ctx context.Context, id int, mediaType string, opts ...Option) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, request{method: http.MethodGet, path: "/api/person/" + pathEscape(id) + "/transcript", accept: mediaType}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// GetSchedule returns the week of the person with id in the term with
+// termID, or in the current term if termID is 0.
+func (c *Client) GetSchedule(ctx context.Context, id, termID int, opts ...Option) (Schedule, error) {
+	var resp data[Schedule]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/person/" + pathEscape(id) + "/schedule", query: scheduleQuery(termID)}, &resp, opts)
+	return resp.Data, err
+}
+
+// RenderSchedule streams the week of the person with id in the term with
+// termID, or in the current term if termID is 0, rendered for people to
+// read, as "text/plain" or "text/html". The caller closes the returned
+// reader.
+func (c *Client) RenderSchedule(ctx context.Context, id, termID int, mediaType string, opts ...Option) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, request{method: http.MethodGet, path: "/api/person/" + pathEscape(id) + "/schedule", query: scheduleQuery(termID), accept: mediaType}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// SubscribePersonCalendar returns where to subscribe to the calendar feed of
+// the person with id. Only an admin, set with WithAdminToken, may.
+func (c *Client) SubscribePersonCalendar(ctx context.Context, id int, opts ...Option) (CalendarSubscription, error) {
+	var resp data[CalendarSubscription]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/person/" + pathEscape(id) + "/schedule/subscription"}, &resp, opts)
+	return resp.Data, err
+}
+
+// GetPersonCalendar streams the calendar feed of the person with id, given
+// its token, as "text/calendar". The caller closes the returned reader.
+func (c *Client) GetPersonCalendar(ctx context.Context, id int, token string, opts ...Option) (io.ReadCloser, error) {
+	return c.calendar(ctx, "/api/person/"+pathEscape(id)+"/schedule.ics", token, opts)
+}
+
+func scheduleQuery(termID int) url.Values {
+	q := url.Values{}
+	setInt(q, "term", termID)
+	return q
+}
+
+// ListWaitlistPositions returns the places of the person with firstName on
+// the waitlists of the courses they are waiting for.
+func (c *Client) ListWaitlistPositions(ctx context.Context, firstName string, opts ...Option) ([]WaitlistEntry, error) {
+	var resp data[[]WaitlistEntry]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/person/" + pathEscape(firstName) + "/waitlist"}, &resp, opts)
+	return resp.Data, err
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListRooms returns every room in name order.
+func (c *Client) ListRooms(ctx context.Context, opts ...Option) ([]Room, error) {
+	var resp data[[]Room]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/room/"}, &resp, opts)
+	return resp.Data, err
+}
+
+// GetRoom returns the room with id.
+func (c *Client) GetRoom(ctx context.Context, id int, opts ...Option) (Room, error) {
+	var resp data[Room]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/room/" + pathEscape(id)}, &resp, opts)
+	return resp.Data, err
+}
+
+// CreateRoom creates a room.
+func (c *Client) CreateRoom(ctx context.Context, in RoomInput, opts ...Option) (Room, error) {
+	var resp data[Room]
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/room/", body: in}, &resp, opts)
+	return resp.Data, err
+}
+
+// UpdateRoom renames the room with id and sets its capacity, which cannot
+// be lowered below that of a section meeting in it.
+func (c *Client) UpdateRoom(ctx context.Context, id int, in RoomInput, opts ...Option) (Room, error) {
+	var resp data[Room]
+	err := c.do(ctx, request{method: http.MethodPut, path: "/api/room/" + pathEscape(id), body: in}, &resp, opts)
+	return resp.Data, err
+}
+
+// DeleteRoom deletes the room with id, which no section may meet in.
+func (c *Client) DeleteRoom(ctx context.Context, id int, opts ...Option) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/api/room/" + pathEscape(id)}, nil, opts)
+}
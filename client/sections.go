@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+)
+
+// ListSections returns the sections matching filter, by term, course and
+// number, or one page of them if filter.Limit is set.
+func (c *Client) ListSections(ctx context.Context, filter SectionFilter, opts ...Option) ([]Section, error) {
+	var resp data[[]Section]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/section/", query: filter.values()}, &resp, opts)
+	return resp.Data, err
+}
+
+// Sections iterates over the sections matching filter, fetching
+// filter.Limit sections per request.
+func (c *Client) Sections(ctx context.Context, filter SectionFilter, opts ...Option) iter.Seq2[Section, error] {
+	return pages(filter.Limit, func(after, limit int) ([]Section, error) {
+		filter.After, filter.Limit = after, limit
+		return c.ListSections(ctx, filter, opts...)
+	}, func(s Section) int { return s.ID })
+}
+
+// GetSection returns the section with id.
+func (c *Client) GetSection(ctx context.Context, id int, opts ...Option) (Section, error) {
+	var resp data[Section]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/section/" + pathEscape(id)}, &resp, opts)
+	return resp.Data, err
+}
+
+// CreateSection offers a course in a term.
+func (c *Client) CreateSection(ctx context.Context, in SectionInput, opts ...Option) (Section, error) {
+	var resp data[Section]
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/section/", body: in}, &resp, opts)
+	return resp.Data, err
+}
+
+// UpdateSection renumbers the section with id, assigns its instructor and
+// sets its capacity.
+func (c *Client) UpdateSection(ctx context.Context, id int, in SectionInput, opts ...Option) (Section, error) {
+	var resp data[Section]
+	err := c.do(ctx, request{method: http.MethodPut, path: "/api/section/" + pathEscape(id), body: in}, &resp, opts)
+	return resp.Data, err
+}
+
+// DeleteSection deletes the section with id, which nobody may be enrolled in.
+func (c *Client) DeleteSection(ctx context.Context, id int, opts ...Option) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/api/section/" + pathEscape(id)}, nil, opts)
+}
+
+// Enroll enrolls the person with firstName in the section with id with role,
+// or with the default role for their type if role is empty.
+func (c *Client) Enroll(ctx context.Context, id int, firstName, role string, opts ...Option) (Section, error) {
+	body := struct {
+		FirstName string `json:"first_name"`
+		Role      string `json:"role,omitempty"`
+	}{firstName, role}
+	var resp data[Section]
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/section/" + pathEscape(id) + "/enrollment", body: body}, &resp, opts)
+	return resp.Data, err
+}
+
+// Withdraw withdraws the person with firstName from the section with id.
+func (c *Client) Withdraw(ctx context.Context, id int, firstName string, opts ...Option) (Section, error) {
+	var resp data[Section]
+	err := c.do(ctx, request{method: http.MethodDelete, path: "/api/section/" + pathEscape(id) + "/enrollment/" + pathEscape(firstName)}, &resp, opts)
+	return resp.Data, err
+}
+
+// ListGrades returns the grades recorded in the section with id.
+func (c *Client) ListGrades(ctx context.Context, id int, opts ...Option) ([]Grade, error) {
+	var resp data[[]Grade]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/section/" + pathEscape(id) + "/grades"}, &resp, opts)
+	return resp.Data, err
+}
+
+// SubmitGrades records the grades of students of the section with id and
+// returns every grade of the section. It needs WithAdminToken, and the actor,
+// see WithActor, must be a professor teaching the section.
+func (c *Client) SubmitGrades(ctx context.Context, id int, grades []GradeInput, opts ...Option) ([]Grade, error) {
+	body := struct {
+		Grades []GradeInput `json:"grades"`
+	}{grades}
+	var resp data[[]Grade]
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/section/" + pathEscape(id) + "/grades", body: body}, &resp, opts)
+	return resp.Data, err
+}
+
+// AmendGrade changes the grade of the person with firstName in the section
+// with id. It needs WithAdminToken, and the actor, see WithActor, must be a
+// professor teaching the section.
+func (c *Client) AmendGrade(ctx context.Context, id int, firstName, grade string, opts ...Option) (Grade, error) {
+	body := struct {
+		Grade string `json:"grade"`
+	}{grade}
+	var resp data[Grade]
+	err := c.do(ctx, request{method: http.MethodPut, path: "/api/section/" + pathEscape(id) + "/grades/" + pathEscape(firstName), body: body}, &resp, opts)
+	return resp.Data, err
+}
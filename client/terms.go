@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListTerms returns every term in the order they start.
+func (c *Client) ListTerms(ctx context.Context, opts ...Option) ([]Term, error) {
+	var resp data[[]Term]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/term/"}, &resp, opts)
+	return resp.Data, err
+}
+
+// GetTerm returns the term with id.
+func (c *Client) GetTerm(ctx context.Context, id int, opts ...Option) (Term, error) {
+	var resp data[Term]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/term/" + pathEscape(id)}, &resp, opts)
+	return resp.Data, err
+}
+
+// CreateTerm creates a term.
+func (c *Client) CreateTerm(ctx context.Context, in TermInput, opts ...Option) (Term, error) {
+	var resp data[Term]
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/term/", body: in}, &resp, opts)
+	return resp.Data, err
+}
+
+// UpdateTerm renames the term with id and moves its dates.
+func (c *Client) UpdateTerm(ctx context.Context, id int, in TermInput, opts ...Option) (Term, error) {
+	var resp data[Term]
+	err := c.do(ctx, request{method: http.MethodPut, path: "/api/term/" + pathEscape(id), body: in}, &resp, opts)
+	return resp.Data, err
+}
+
+// DeleteTerm deletes the term with id, which must have no sections.
+func (c *Client) DeleteTerm(ctx context.Context, id int, opts ...Option) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/api/term/" + pathEscape(id)}, nil, opts)
+}
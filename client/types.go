@@ -0,0 +1,521 @@
+package client
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Person types.
+const (
+	PersonStudent   = "student"
+	PersonProfessor = "professor"
+)
+
+// Roles a person can hold in a course. Only professors can be instructors.
+const (
+	RoleInstructor        = "instructor"
+	RoleTeachingAssistant = "teaching_assistant"
+	RoleStudent           = "student"
+	RoleAuditor           = "auditor"
+)
+
+// Course is a course offered by the college with the ids of the persons
+// holding each role in it.
+type Course struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Capacity is the number of student seats, or nil if it is unlimited.
+	Capacity *int `json:"capacity,omitempty"`
+	Credits  int  `json:"credits"`
+	// DepartmentID is nil for courses outside a department, which have no
+	// CatalogNumber or Code. Code is the catalog code, e.g. CS-201.
+	DepartmentID       *int   `json:"department_id,omitempty"`
+	CatalogNumber      string `json:"catalog_number,omitempty"`
+	Code               string `json:"code,omitempty"`
+	Description        string `json:"description"`
+	Instructors        []int  `json:"instructors"`
+	TeachingAssistants []int  `json:"teaching_assistants"`
+	Students           []int  `json:"students"`
+	Auditors           []int  `json:"auditors"`
+	// DeletedAt is set on soft deleted courses, which are only listed with
+	// IncludeDeleted.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// CourseInput creates or replaces a course. A nil Capacity is unlimited and
+// nil Credits default to 3. CatalogNumber needs a DepartmentID.
+type CourseInput struct {
+	Name          string `json:"name"`
+	Capacity      *int   `json:"capacity,omitempty"`
+	Credits       *int   `json:"credits,omitempty"`
+	DepartmentID  *int   `json:"department_id,omitempty"`
+	CatalogNumber string `json:"catalog_number,omitempty"`
+	Description   string `json:"description,omitempty"`
+}
+
+// Eligibility is whether a person could be enrolled in a course, and the
+// problems that would keep them out if not.
+type Eligibility struct {
+	Allowed  bool      `json:"allowed"`
+	Problems []Problem `json:"problems"`
+}
+
+// WaitlistEntry is a person's place in line for a student seat in a full
+// section of a course, counting from 1.
+type WaitlistEntry struct {
+	CourseID  int       `json:"course_id"`
+	SectionID int       `json:"section_id"`
+	PersonID  int       `json:"person_id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Position  int       `json:"position"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+// Prerequisite is a course that must be completed before enrolling in
+// another, with its own prerequisites.
+type Prerequisite struct {
+	CourseID      int            `json:"course_id"`
+	Name          string         `json:"name"`
+	Prerequisites []Prerequisite `json:"prerequisites"`
+}
+
+// Person is a student or professor with the ids of their courses and their
+// role in each. DateOfBirth is formatted as 2006-01-02 and Age is computed
+// from it. Email, Phone and Address are empty when they are not known.
+type Person struct {
+	ID          int          `json:"id"`
+	FirstName   string       `json:"first_name"`
+	LastName    string       `json:"last_name"`
+	Type        string       `json:"type"`
+	DateOfBirth string       `json:"date_of_birth"`
+	Age         int          `json:"age"`
+	Email       string       `json:"email,omitempty"`
+	Phone       string       `json:"phone,omitempty"`
+	Address     string       `json:"address,omitempty"`
+	Courses     []int        `json:"courses"`
+	Enrollments []Enrollment `json:"enrollments"`
+	DeletedAt   *time.Time   `json:"deleted_at,omitempty"`
+}
+
+// Enrollment is a person's role in one of their courses, through a section
+// of it. SectionID is ignored in a PersonInput.
+type Enrollment struct {
+	CourseID  int    `json:"course_id"`
+	SectionID int    `json:"section_id,omitempty"`
+	Role      string `json:"role"`
+}
+
+// Term is an academic term. Dates are formatted as 2006-01-02.
+type Term struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	// Current is set for the term the course and person routes act on.
+	Current bool `json:"current"`
+}
+
+// TermInput creates or replaces a term. Dates are formatted as 2006-01-02.
+type TermInput struct {
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+// Section is an offering of a course in a term with the ids of the persons
+// holding each role in it.
+type Section struct {
+	ID       int `json:"id"`
+	CourseID int `json:"course_id"`
+	TermID   int `json:"term_id"`
+	Number   int `json:"number"`
+	// InstructorID is the professor of record, or nil if none is assigned.
+	InstructorID *int `json:"instructor_id,omitempty"`
+	// Capacity is the number of student seats, or nil if it is unlimited.
+	Capacity           *int  `json:"capacity,omitempty"`
+	Instructors        []int `json:"instructors"`
+	TeachingAssistants []int `json:"teaching_assistants"`
+	Students           []int `json:"students"`
+	Auditors           []int `json:"auditors"`
+	// Meetings is when and where the section meets each week.
+	Meetings []Meeting `json:"meetings"`
+}
+
+// Meeting is a weekly meeting of a section on each of Days, "mon" to "sun",
+// from StartTime to EndTime, formatted as 15:04. RoomID is nil until a room
+// is assigned.
+type Meeting struct {
+	Days      []string `json:"days"`
+	StartTime string   `json:"start_time"`
+	EndTime   string   `json:"end_time"`
+	RoomID    *int     `json:"room_id,omitempty"`
+}
+
+// Room is a place sections meet in; Capacity is the number of seats.
+type Room struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Capacity int    `json:"capacity"`
+}
+
+// Department offers courses. Its chair, if any, is a professor.
+type Department struct {
+	ID      int    `json:"id"`
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	ChairID *int   `json:"chair_id,omitempty"`
+}
+
+// DepartmentInput creates or replaces a department. Code is 2 to 6 capital
+// letters, e.g. CS.
+type DepartmentInput struct {
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	ChairID *int   `json:"chair_id,omitempty"`
+}
+
+// RoomInput creates or replaces a room.
+type RoomInput struct {
+	Name     string `json:"name"`
+	Capacity int    `json:"capacity"`
+}
+
+// Schedule is the week of a person in a term, Monday first.
+type Schedule struct {
+	PersonID  int           `json:"person_id"`
+	FirstName string        `json:"first_name"`
+	LastName  string        `json:"last_name"`
+	TermID    int           `json:"term_id"`
+	TermName  string        `json:"term_name"`
+	Days      []ScheduleDay `json:"days"`
+}
+
+// ScheduleDay is one weekday of a schedule, with its meetings in the order
+// they start.
+type ScheduleDay struct {
+	Day      string          `json:"day"`
+	Meetings []ScheduleEntry `json:"meetings"`
+}
+
+// ScheduleEntry is a meeting of a section on a schedule, with the person's
+// role in it. RoomID and RoomName are empty until a room is assigned.
+type ScheduleEntry struct {
+	StartTime     string `json:"start_time"`
+	EndTime       string `json:"end_time"`
+	SectionID     int    `json:"section_id"`
+	SectionNumber int    `json:"section_number"`
+	CourseID      int    `json:"course_id"`
+	CourseName    string `json:"course_name"`
+	Role          string `json:"role"`
+	RoomID        *int   `json:"room_id,omitempty"`
+	RoomName      string `json:"room_name,omitempty"`
+}
+
+// CalendarSubscription is where calendar apps subscribe to the iCalendar
+// feed of a person or course. Token is also part of URL.
+type CalendarSubscription struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+// SectionInput creates or replaces a section. A zero Number takes the next
+// free one and a nil Capacity the course's on creation; CourseID and TermID
+// cannot be changed. Meetings replace those of the section.
+type SectionInput struct {
+	CourseID     int       `json:"course_id"`
+	TermID       int       `json:"term_id"`
+	Number       int       `json:"number,omitempty"`
+	InstructorID *int      `json:"instructor_id,omitempty"`
+	Capacity     *int      `json:"capacity,omitempty"`
+	Meetings     []Meeting `json:"meetings,omitempty"`
+}
+
+// Grade is a student's final grade in a section: a letter of the server's
+// grade scale, P or NP for pass/fail, or I for incomplete.
+type Grade struct {
+	PersonID  int    `json:"person_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	SectionID int    `json:"section_id"`
+	CourseID  int    `json:"course_id"`
+	Grade     string `json:"grade"`
+	// Points is nil for pass/fail and incomplete grades.
+	Points *float64 `json:"points,omitempty"`
+	Passed bool     `json:"passed"`
+	// GradedBy is the professor who recorded or last amended the grade, or
+	// nil if they have been purged.
+	GradedBy *int      `json:"graded_by,omitempty"`
+	GradedAt time.Time `json:"graded_at"`
+}
+
+// GradeInput is the grade of the student with FirstName.
+type GradeInput struct {
+	FirstName string `json:"first_name"`
+	Grade     string `json:"grade"`
+}
+
+// Transcript is a person's courses as a student by term, with grades and
+// GPAs. GPAs are nil until a letter grade is recorded.
+type Transcript struct {
+	PersonID         int              `json:"person_id"`
+	FirstName        string           `json:"first_name"`
+	LastName         string           `json:"last_name"`
+	Terms            []TranscriptTerm `json:"terms"`
+	CreditsAttempted int              `json:"credits_attempted"`
+	CreditsEarned    int              `json:"credits_earned"`
+	GPA              *float64         `json:"gpa,omitempty"`
+}
+
+// TranscriptTerm is one term of a transcript. Dates are formatted as
+// 2006-01-02.
+type TranscriptTerm struct {
+	TermID           int                `json:"term_id"`
+	Name             string             `json:"name"`
+	StartDate        string             `json:"start_date"`
+	EndDate          string             `json:"end_date"`
+	Courses          []TranscriptCourse `json:"courses"`
+	CreditsAttempted int                `json:"credits_attempted"`
+	CreditsEarned    int                `json:"credits_earned"`
+	GPA              *float64           `json:"gpa,omitempty"`
+	CumulativeGPA    *float64           `json:"cumulative_gpa,omitempty"`
+}
+
+// TranscriptCourse is a course on a transcript. Grade is empty until one is
+// recorded.
+type TranscriptCourse struct {
+	CourseID  int      `json:"course_id"`
+	SectionID int      `json:"section_id"`
+	Name      string   `json:"name"`
+	Credits   int      `json:"credits"`
+	Grade     string   `json:"grade,omitempty"`
+	Points    *float64 `json:"points,omitempty"`
+}
+
+// SectionFilter narrows ListSections. Zero values are ignored.
+type SectionFilter struct {
+	CourseID int
+	TermID   int
+	// After and Limit select a page: at most Limit sections listed after the
+	// one with id After. Sections iterates over the pages.
+	After int
+	Limit int
+}
+
+func (f SectionFilter) values() url.Values {
+	q := url.Values{}
+	setInt(q, "course", f.CourseID)
+	setInt(q, "term", f.TermID)
+	setInt(q, "after", f.After)
+	setInt(q, "limit", f.Limit)
+	return q
+}
+
+// PersonInput creates or replaces a person and their enrollments. Courses get
+// the default role for the person's type, instructor for professors and
+// student for students; Enrollments take precedence for the same course.
+// DateOfBirth is formatted as 2006-01-02, and Phone, if set, in E.164 format,
+// e.g. +15555550100.
+type PersonInput struct {
+	FirstName   string       `json:"first_name"`
+	LastName    string       `json:"last_name"`
+	Type        string       `json:"type"`
+	DateOfBirth string       `json:"date_of_birth"`
+	Email       string       `json:"email,omitempty"`
+	Phone       string       `json:"phone,omitempty"`
+	Address     string       `json:"address,omitempty"`
+	Courses     []int        `json:"courses,omitempty"`
+	Enrollments []Enrollment `json:"enrollments,omitempty"`
+}
+
+// PersonFilter narrows ListPersons and the person exports. Zero values are
+// ignored.
+type PersonFilter struct {
+	FirstName string
+	LastName  string
+	Type      string
+	MinAge    int
+	MaxAge    int
+	CourseID  int
+	// IncludeDeleted also lists soft deleted persons; it needs WithAdminToken.
+	IncludeDeleted bool
+	// AsOf reads the persons as they were at that time.
+	AsOf time.Time
+	// After and Limit select a page: at most Limit persons with ids greater
+	// than After. Persons iterates over the pages.
+	After int
+	Limit int
+}
+
+func (f PersonFilter) values() url.Values {
+	q := url.Values{}
+	setString(q, "first_name", f.FirstName)
+	setString(q, "last_name", f.LastName)
+	setString(q, "type", f.Type)
+	setInt(q, "min_age", f.MinAge)
+	setInt(q, "max_age", f.MaxAge)
+	setInt(q, "course", f.CourseID)
+	setBool(q, "include_deleted", f.IncludeDeleted)
+	setTime(q, "as_of", f.AsOf)
+	setInt(q, "after", f.After)
+	setInt(q, "limit", f.Limit)
+	return q
+}
+
+// CourseFilter narrows ListCourses and the course export. Zero values are
+// ignored.
+type CourseFilter struct {
+	// Name is a case-insensitive substring of the course name.
+	Name string
+	// DepartmentID only lists courses of that department.
+	DepartmentID int
+	// MinCredits and MaxCredits bound the credits of the courses, inclusive.
+	MinCredits int
+	MaxCredits int
+	// IncludeDeleted also lists soft deleted courses; it needs WithAdminToken.
+	IncludeDeleted bool
+	// AsOf reads the courses as they were at that time.
+	AsOf time.Time
+	// After and Limit select a page: at most Limit courses with ids greater
+	// than After. Courses iterates over the pages.
+	After int
+	Limit int
+}
+
+func (f CourseFilter) values() url.Values {
+	q := url.Values{}
+	setString(q, "name", f.Name)
+	setInt(q, "department", f.DepartmentID)
+	setInt(q, "min_credits", f.MinCredits)
+	setInt(q, "max_credits", f.MaxCredits)
+	setBool(q, "include_deleted", f.IncludeDeleted)
+	setTime(q, "as_of", f.AsOf)
+	setInt(q, "after", f.After)
+	setInt(q, "limit", f.Limit)
+	return q
+}
+
+// AuditEvent records one change to a person, course, term or section.
+type AuditEvent struct {
+	ID         int64           `json:"id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Actor      string          `json:"actor"`
+	RequestID  string          `json:"request_id"`
+	Action     string          `json:"action"`
+	Entity     string          `json:"entity"`
+	EntityID   int             `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+}
+
+// AuditFilter narrows ListAuditEvents and AuditEvents. Zero values are
+// ignored; From is inclusive and To exclusive.
+type AuditFilter struct {
+	Entity   string
+	EntityID int
+	Actor    string
+	From     time.Time
+	To       time.Time
+	// Limit is the page size, 100 by default.
+	Limit int
+}
+
+func (f AuditFilter) values() url.Values {
+	q := url.Values{}
+	setString(q, "entity", f.Entity)
+	setInt(q, "entity_id", f.EntityID)
+	setString(q, "actor", f.Actor)
+	setTime(q, "from", f.From)
+	setTime(q, "to", f.To)
+	setInt(q, "limit", f.Limit)
+	return q
+}
+
+// Webhook is a webhook subscription.
+type Webhook struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	// Secret signs the deliveries. It is only returned by CreateWebhook.
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookInput registers or updates a webhook subscription. An empty Events
+// subscribes to every event type. Secret is only used on creation and is
+// generated if empty; Active defaults to true.
+type WebhookInput struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+	Secret string   `json:"secret,omitempty"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+// WebhookDelivery is one event sent, or to be sent, to a subscription.
+type WebhookDelivery struct {
+	ID             int64      `json:"id"`
+	EventID        int64      `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastStatusCode *int       `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// DeliveryFilter narrows ListWebhookDeliveries. Zero values are ignored.
+type DeliveryFilter struct {
+	// Status is pending, delivered or dead.
+	Status string
+	// Limit is the maximum number of deliveries, 100 by default.
+	Limit int
+}
+
+func (f DeliveryFilter) values() url.Values {
+	q := url.Values{}
+	setString(q, "status", f.Status)
+	setInt(q, "limit", f.Limit)
+	return q
+}
+
+// Event is a change streamed by StreamEvents, in the same form as a webhook
+// delivery body. Position is its place in the stream, which follows commit
+// order, to resume StreamEvents after; it is not part of the body.
+type Event struct {
+	ID         int64           `json:"id"`
+	Position   int64           `json:"-"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Actor      string          `json:"actor"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func setString(q url.Values, key, value string) {
+	if value != "" {
+		q.Set(key, value)
+	}
+}
+
+func setInt(q url.Values, key string, value int) {
+	if value != 0 {
+		q.Set(key, strconv.Itoa(value))
+	}
+}
+
+func setBool(q url.Values, key string, value bool) {
+	if value {
+		q.Set(key, "true")
+	}
+}
+
+func setTime(q url.Values, key string, value time.Time) {
+	if !value.IsZero() {
+		q.Set(key, value.Format(time.RFC3339Nano))
+	}
+}
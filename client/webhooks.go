@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// The webhook methods need WithAdminToken.
+
+// ListWebhooks returns the webhook subscriptions.
+func (c *Client) ListWebhooks(ctx context.Context, opts ...Option) ([]Webhook, error) {
+	var resp data[[]Webhook]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/webhook/"}, &resp, opts)
+	return resp.Data, err
+}
+
+// CreateWebhook registers a subscription. The result carries the signing
+// secret, which is not returned again.
+func (c *Client) CreateWebhook(ctx context.Context, in WebhookInput, opts ...Option) (Webhook, error) {
+	var resp data[Webhook]
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/webhook/", body: in}, &resp, opts)
+	return resp.Data, err
+}
+
+// GetWebhook returns the subscription with id.
+func (c *Client) GetWebhook(ctx context.Context, id int, opts ...Option) (Webhook, error) {
+	var resp data[Webhook]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/webhook/" + pathEscape(id)}, &resp, opts)
+	return resp.Data, err
+}
+
+// UpdateWebhook updates the subscription with id; its secret is kept.
+func (c *Client) UpdateWebhook(ctx context.Context, id int, in WebhookInput, opts ...Option) (Webhook, error) {
+	var resp data[Webhook]
+	err := c.do(ctx, request{method: http.MethodPut, path: "/api/webhook/" + pathEscape(id), body: in}, &resp, opts)
+	return resp.Data, err
+}
+
+// DeleteWebhook deletes the subscription with id and its deliveries.
+func (c *Client) DeleteWebhook(ctx context.Context, id int, opts ...Option) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/api/webhook/" + pathEscape(id)}, nil, opts)
+}
+
+// ListWebhookDeliveries returns deliveries to the subscription with id,
+// newest first.
+func (c *Client) ListWebhookDeliveries(ctx context.Context, id int, filter DeliveryFilter, opts ...Option) ([]WebhookDelivery, error) {
+	var resp data[[]WebhookDelivery]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/webhook/" + pathEscape(id) + "/deliveries", query: filter.values()}, &resp, opts)
+	return resp.Data, err
+}
+
+// ReplayWebhookDelivery sends a delivery again with a fresh retry budget.
+func (c *Client) ReplayWebhookDelivery(ctx context.Context, id int, deliveryID int64, opts ...Option) error {
+	path := "/api/webhook/" + pathEscape(id) + "/deliveries/" + pathEscape(deliveryID) + "/replay"
+	return c.do(ctx, request{method: http.MethodPost, path: path}, nil, opts)
+}
+
+// ReplayWebhookEvents sends every event since from to the subscription with
+// id again and returns how many deliveries were queued.
+func (c *Client) ReplayWebhookEvents(ctx context.Context, id int, from time.Time, opts ...Option) (int, error) {
+	query := url.Values{}
+	setTime(query, "from", from)
+	var resp struct {
+		Queued int `json:"queued"`
+	}
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/webhook/" + pathEscape(id) + "/replay", query: query}, &resp, opts)
+	return resp.Queued, err
+}
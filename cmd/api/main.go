@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,7 +13,12 @@ import (
 	"time"
 	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/config"
 	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/database"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/events"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/grpcserver"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/handlers"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/jobs"
 	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/routes"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/rules"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -42,16 +48,17 @@ func run(ctx context.Context) error {
 	})
 
 	// Set up DB connection
+	connectionString := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
+		cfg.DBHost,
+		cfg.DBUser,
+		cfg.DBPassword,
+		cfg.DBName,
+		cfg.DBPort,
+	)
 	db, err := database.New(
 		ctx,
-		fmt.Sprintf(
-			"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-			cfg.DBHost,
-			cfg.DBUser,
-			cfg.DBPassword,
-			cfg.DBName,
-			cfg.DBPort,
-		),
+		connectionString,
 		logger,
 		time.Duration(cfg.DBRetryDuration)*time.Second,
 	)
@@ -67,20 +74,46 @@ func run(ctx context.Context) error {
 
 	// Router setup
 	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
 	r.Use(httplog.RequestLogger(logger))
 	r.Use(middleware.Recoverer)
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins: []string{"*"},
 		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+		AllowedHeaders: []string{"Accept", "Content-Type", "X-Requested-With", handlers.ActorHeader, handlers.AdminTokenHeader, "Last-Event-ID"},
 		MaxAge:         300,
 	}))
+	r.Use(handlers.AdminContext(cfg.AdminToken))
 
 	// Instantiate service
-	svsCourse := services.NewCourseService(db)
-	svsPerson := services.NewPersonService(db)
+	eligibility, err := rules.Load(cfg.EnrollmentRulesFile)
+	if err != nil {
+		return fmt.Errorf("[in run]: %w", err)
+	}
+	svsCourse := services.NewCourseService(db, eligibility)
+	svsPerson := services.NewPersonService(db, eligibility)
+	svsTerm := services.NewTermService(db)
+	svsSection := services.NewSectionService(db, eligibility)
+	svsRoom := services.NewRoomService(db)
+	svsDepartment := services.NewDepartmentService(db)
+	svsAudit := services.NewAuditService(db)
+	svsWebhook := services.NewWebhookService(db)
+	svsBatch := services.NewBatchService(db, eligibility)
+	gradeScale := services.GradeScale(cfg.GradeScale)
+	if err = gradeScale.Check(); err != nil {
+		return fmt.Errorf("[in run]: %w", err)
+	}
+	svsGrade := services.NewGradeService(db, gradeScale)
+	svsCalendar := services.NewCalendarService(db, cfg.CalendarSecret)
+	hub, err := events.NewHub(ctx, logger, services.NewOutboxService(db))
+	if err != nil {
+		return fmt.Errorf("[in run]: %w", err)
+	}
 
 	// Register routes
-	routes.RegisterRoutes(r, logger, svsCourse, svsPerson)
+	if err = routes.RegisterRoutes(r, logger, svsCourse, svsPerson, svsTerm, svsSection, svsRoom, svsDepartment, svsGrade, svsCalendar, svsAudit, svsWebhook, svsBatch, hub); err != nil {
+		return fmt.Errorf("[in run]: %w", err)
+	}
 
 	// HTTP Server setup
 	srv := &http.Server{
@@ -92,9 +125,43 @@ func run(ctx context.Context) error {
 		Handler:           r,
 	}
 
+	// End event streams when shutdown starts, so it does not wait on them
+	srv.RegisterOnShutdown(hub.Close)
+
+	// gRPC server on its own port, sharing the services
+	grpcSrv := grpcserver.New(logger, svsCourse, svsPerson)
+	grpcLis, err := net.Listen("tcp", cfg.HTTPDomain+cfg.GRPCPort)
+	if err != nil {
+		return fmt.Errorf("[in run]: %w", err)
+	}
+	go func() {
+		logger.Info("gRPC server started", "addr", grpcLis.Addr().String())
+		if err := grpcSrv.Serve(grpcLis); err != nil {
+			logger.Error("gRPC server stopped", "error", err)
+		}
+	}()
+
 	// Graceful shutdown setup
 	serverCtx, serverStopCtx := context.WithCancel(context.Background())
 
+	// Relay outbox notifications to event streams
+	go hub.Run(serverCtx, connectionString)
+
+	// Purge soft deleted records past the retention period
+	go jobs.RunPurge(serverCtx, logger,
+		time.Duration(cfg.PurgeInterval)*time.Minute,
+		time.Duration(cfg.SoftDeleteRetention)*24*time.Hour,
+		svsCourse, svsPerson)
+
+	// Deliver outbox events to webhook subscriptions
+	go jobs.RunWebhooks(serverCtx, logger, svsWebhook, jobs.WebhookOptions{
+		Interval:    time.Duration(cfg.WebhookInterval) * time.Second,
+		Timeout:     time.Duration(cfg.WebhookTimeout) * time.Second,
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		BaseBackoff: 30 * time.Second,
+		MaxBackoff:  6 * time.Hour,
+	})
+
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
 	go func() {
@@ -113,13 +180,14 @@ func run(ctx context.Context) error {
 			}
 		}()
 
+		grpcSrv.Shutdown(shutdownCtx)
 		if err := srv.Shutdown(shutdownCtx); err != nil {
 			log.Fatalf("Error shutting down server. err: %v", err)
 		}
 		serverStopCtx()
 	}()
 
-	logger.Info("Server started at %s", srv.Addr)
+	logger.Info("Server started", "addr", srv.Addr)
 	err = srv.ListenAndServe()
 	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return err
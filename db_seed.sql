@@ -1,54 +1,402 @@
-DROP TABLE IF EXISTS person_course;
+DROP TABLE IF EXISTS webhook_delivery;
+DROP TABLE IF EXISTS webhook_subscription;
+DROP TABLE IF EXISTS outbox_event;
+DROP TABLE IF EXISTS audit_event;
+DROP TABLE IF EXISTS section_waitlist;
+DROP TABLE IF EXISTS grade;
+DROP TABLE IF EXISTS person_section_history;
+DROP TABLE IF EXISTS course_history;
+DROP TABLE IF EXISTS person_history;
+DROP TABLE IF EXISTS person_section;
+DROP TABLE IF EXISTS section_meeting;
+DROP TABLE IF EXISTS section;
+DROP TABLE IF EXISTS room;
+DROP TABLE IF EXISTS term;
+DROP TABLE IF EXISTS course_prerequisite;
 DROP TABLE IF EXISTS course;
+DROP TABLE IF EXISTS department;
 DROP TABLE IF EXISTS person;
 
--- person
+-- person; their age is computed from date_of_birth when read. email is
+-- optional but unique whatever its case, and phone is in E.164 format, e.g.
+-- +15555550100, or empty.
 CREATE TABLE person
 (
-    id         SERIAL PRIMARY KEY,
-    first_name TEXT                                          NOT NULL,
-    last_name  TEXT                                          NOT NULL,
-    type       TEXT CHECK (type IN ('professor', 'student')) NOT NULL,
-    age        INTEGER                                       NOT NULL
+    id            SERIAL PRIMARY KEY,
+    first_name    TEXT                                          NOT NULL,
+    last_name     TEXT                                          NOT NULL,
+    type          TEXT CHECK (type IN ('professor', 'student')) NOT NULL,
+    date_of_birth DATE                                          NOT NULL CHECK (date_of_birth > '1900-01-01'),
+    email         TEXT CHECK (email ~ '^[^@\s]+@[^@\s]+\.[^@\s]+$'),
+    phone         TEXT                                          NOT NULL DEFAULT '' CHECK (phone = '' OR phone ~ '^\+[1-9][0-9]{6,14}$'),
+    address       TEXT                                          NOT NULL DEFAULT '',
+    deleted_at    TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX person_email_idx ON person (lower(email));
+
+-- soft deleted rows are only read back by restore and the purge job
+CREATE INDEX person_deleted_at_idx ON person (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- person_history holds every version of each person row. A version is valid
+-- from valid_from until valid_to; the current version has no valid_to.
+CREATE TABLE person_history
+(
+    id         INTEGER     NOT NULL,
+    first_name TEXT        NOT NULL,
+    last_name  TEXT        NOT NULL,
+    type          TEXT        NOT NULL,
+    date_of_birth DATE        NOT NULL,
+    email         TEXT,
+    phone         TEXT        NOT NULL,
+    address       TEXT        NOT NULL,
+    deleted_at    TIMESTAMPTZ,
+    valid_from    TIMESTAMPTZ NOT NULL,
+    valid_to      TIMESTAMPTZ
+);
+
+CREATE INDEX person_history_id_idx ON person_history (id, valid_from);
+CREATE INDEX person_history_valid_idx ON person_history (valid_from, valid_to);
+
+CREATE OR REPLACE FUNCTION person_history_version() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE person_history SET valid_to = now() WHERE id = OLD.id AND valid_to IS NULL;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO person_history (id, first_name, last_name, type, date_of_birth, email, phone, address, deleted_at, valid_from)
+        VALUES (NEW.id, NEW.first_name, NEW.last_name, NEW.type, NEW.date_of_birth, NEW.email, NEW.phone, NEW.address,
+                NEW.deleted_at, now());
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER person_history_version
+    AFTER INSERT OR UPDATE OR DELETE
+    ON person
+    FOR EACH ROW
+EXECUTE FUNCTION person_history_version();
+
+INSERT INTO person (first_name, last_name, type, date_of_birth, email)
+VALUES ('Steve', 'Jobs', 'professor', '1955-02-24', 'steve.jobs@example.edu'),
+       ('Jeff', 'Bezos', 'professor', '1964-01-12', 'jeff.bezos@example.edu'),
+       ('Larry', 'Page', 'student', '1973-03-26', 'larry.page@example.edu'),
+       ('Bill', 'Gates', 'student', '1955-10-28', 'bill.gates@example.edu'),
+       ('Elon', 'Musk', 'student', '1971-06-28', 'elon.musk@example.edu');
+
+-- department offers courses. code prefixes the catalog codes of its courses,
+-- e.g. CS in CS-201. chair_id is a professor, or NULL once they are purged.
+CREATE TABLE department
+(
+    id       SERIAL PRIMARY KEY,
+    code     TEXT NOT NULL UNIQUE CHECK (code ~ '^[A-Z]{2,6}$'),
+    name     TEXT NOT NULL UNIQUE,
+    chair_id INTEGER REFERENCES person (id)
 );
 
-INSERT INTO person (first_name, last_name, type, age)
-VALUES ('Steve', 'Jobs', 'professor', 56),
-       ('Jeff', 'Bezos', 'professor', 60),
-       ('Larry', 'Page', 'student', 51),
-       ('Bill', 'Gates', 'student', 67),
-       ('Elon', 'Musk', 'student', 52);
+INSERT INTO department (code, name, chair_id)
+VALUES ('CS', 'Computer Science', 1),
+       ('DES', 'Design', 2);
 
--- course
+-- course; capacity is the number of students its sections seat unless they
+-- set their own, or NULL if it is unlimited. credits is what the course is
+-- worth on a transcript. catalog_number is only set for courses in a
+-- department, and makes their catalog code with the department's code.
 CREATE TABLE course
 (
-    id   SERIAL PRIMARY KEY,
-    name TEXT NOT NULL
+    id             SERIAL PRIMARY KEY,
+    name           TEXT    NOT NULL,
+    capacity       INTEGER CHECK (capacity > 0),
+    credits        INTEGER NOT NULL DEFAULT 3 CHECK (credits > 0),
+    department_id  INTEGER REFERENCES department (id),
+    catalog_number TEXT CHECK (catalog_number IS NULL OR department_id IS NOT NULL),
+    description    TEXT    NOT NULL DEFAULT '',
+    deleted_at     TIMESTAMPTZ,
+    UNIQUE (department_id, catalog_number)
+);
+
+CREATE INDEX course_deleted_at_idx ON course (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX course_credits_idx ON course (credits);
+
+-- course_history holds every version of each course row, as person_history
+CREATE TABLE course_history
+(
+    id             INTEGER     NOT NULL,
+    name           TEXT        NOT NULL,
+    capacity       INTEGER,
+    credits        INTEGER     NOT NULL,
+    department_id  INTEGER,
+    catalog_number TEXT,
+    description    TEXT        NOT NULL,
+    deleted_at     TIMESTAMPTZ,
+    valid_from     TIMESTAMPTZ NOT NULL,
+    valid_to       TIMESTAMPTZ
 );
 
-INSERT INTO course (name)
-VALUES ('Programming'),
-       ('Databases'),
-       ('UI Design');
+CREATE INDEX course_history_id_idx ON course_history (id, valid_from);
+CREATE INDEX course_history_valid_idx ON course_history (valid_from, valid_to);
+
+CREATE OR REPLACE FUNCTION course_history_version() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE course_history SET valid_to = now() WHERE id = OLD.id AND valid_to IS NULL;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO course_history (id, name, capacity, credits, department_id, catalog_number, description, deleted_at, valid_from)
+        VALUES (NEW.id, NEW.name, NEW.capacity, NEW.credits, NEW.department_id, NEW.catalog_number, NEW.description, NEW.deleted_at, now());
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
 
--- person_course
-CREATE TABLE person_course
+CREATE TRIGGER course_history_version
+    AFTER INSERT OR UPDATE OR DELETE
+    ON course
+    FOR EACH ROW
+EXECUTE FUNCTION course_history_version();
+
+INSERT INTO course (name, capacity, credits, department_id, catalog_number, description)
+VALUES ('Programming', NULL, 4, 1, '101', 'Variables, control flow, functions and data structures.'),
+       ('Databases', 30, 3, 1, '201', 'Relational modelling, SQL and transactions.'),
+       ('UI Design', 3, 2, 2, '110', 'Layout, typography and usability testing.');
+
+-- course_prerequisite says that students must complete prerequisite_id before
+-- enrolling in course_id. The services keep the graph acyclic.
+CREATE TABLE course_prerequisite
 (
-    person_id INTEGER NOT NULL,
-    course_id INTEGER NOT NULL,
-    PRIMARY KEY (person_id, course_id),
+    course_id       INTEGER NOT NULL REFERENCES course (id),
+    prerequisite_id INTEGER NOT NULL REFERENCES course (id),
+    PRIMARY KEY (course_id, prerequisite_id),
+    CHECK (course_id <> prerequisite_id)
+);
+
+CREATE INDEX course_prerequisite_prerequisite_idx ON course_prerequisite (prerequisite_id);
+
+INSERT INTO course_prerequisite (course_id, prerequisite_id)
+VALUES (2, 1);
+
+-- term is an academic term. The current term is the one that started most
+-- recently; it stays current until the next one starts.
+CREATE TABLE term
+(
+    id         SERIAL PRIMARY KEY,
+    name       TEXT NOT NULL UNIQUE,
+    start_date DATE NOT NULL,
+    end_date   DATE NOT NULL,
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX term_start_date_idx ON term (start_date);
+
+INSERT INTO term (name, start_date, end_date)
+VALUES ('Fall 2024', '2024-08-26', '2024-12-13');
+
+-- section is an offering of a course in a term; instructor_id is its
+-- professor of record, and capacity is the number of students it seats, or
+-- NULL if it is unlimited
+CREATE TABLE section
+(
+    id            SERIAL PRIMARY KEY,
+    course_id     INTEGER NOT NULL REFERENCES course (id),
+    term_id       INTEGER NOT NULL REFERENCES term (id),
+    number        INTEGER NOT NULL CHECK (number > 0),
+    instructor_id INTEGER REFERENCES person (id),
+    capacity      INTEGER CHECK (capacity > 0),
+    UNIQUE (course_id, term_id, number)
+);
+
+CREATE INDEX section_term_idx ON section (term_id, course_id);
+
+INSERT INTO section (course_id, term_id, number, instructor_id, capacity)
+VALUES (1, 1, 1, 1, NULL),
+       (2, 1, 1, 2, 30),
+       (3, 1, 1, 1, 3);
+
+-- room is a place sections meet in; capacity is the number of seats it has
+CREATE TABLE room
+(
+    id       SERIAL PRIMARY KEY,
+    name     TEXT    NOT NULL UNIQUE,
+    capacity INTEGER NOT NULL CHECK (capacity > 0)
+);
+
+INSERT INTO room (name, capacity)
+VALUES ('Hall A', 120),
+       ('Lab 1', 30),
+       ('Seminar Room', 12);
+
+-- section_meeting is a weekly meeting pattern of a section: it meets on each
+-- of days from start_time to end_time, in room_id if one is assigned. The
+-- services keep persons and rooms from being booked twice at the same time
+-- within a term.
+CREATE TABLE section_meeting
+(
+    id         SERIAL PRIMARY KEY,
+    section_id INTEGER NOT NULL REFERENCES section (id),
+    days       TEXT[]  NOT NULL CHECK (cardinality(days) > 0 AND days <@ ARRAY ['mon', 'tue', 'wed', 'thu', 'fri', 'sat', 'sun']),
+    start_time TIME    NOT NULL,
+    end_time   TIME    NOT NULL,
+    room_id    INTEGER REFERENCES room (id),
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX section_meeting_section_idx ON section_meeting (section_id);
+CREATE INDEX section_meeting_room_idx ON section_meeting (room_id);
+
+INSERT INTO section_meeting (section_id, days, start_time, end_time, room_id)
+VALUES (1, '{mon,wed}', '09:00', '10:15', 1),
+       (2, '{tue,thu}', '09:00', '10:15', 2),
+       (3, '{fri}', '13:00', '15:45', 3);
+
+-- person_section; role is what the person does in the section, and only
+-- professors may be instructors. The services keep a person to one section
+-- of a course per term.
+CREATE TABLE person_section
+(
+    person_id  INTEGER NOT NULL,
+    section_id INTEGER NOT NULL,
+    role       TEXT CHECK (role IN ('instructor', 'teaching_assistant', 'student', 'auditor')) NOT NULL DEFAULT 'student',
+    PRIMARY KEY (person_id, section_id),
     FOREIGN KEY (person_id) REFERENCES person (id),
-    FOREIGN KEY (course_id) REFERENCES course (id)
+    FOREIGN KEY (section_id) REFERENCES section (id)
+);
+
+CREATE INDEX person_section_section_idx ON person_section (section_id);
+
+-- person_section_history holds every enrollment with the period it was held,
+-- along with the course and term of its section so it can be read without
+-- the section. UpdatePerson replaces a person's enrollments in the current
+-- term, so an unchanged enrollment is closed and reopened at the same
+-- instant and remains continuous.
+CREATE TABLE person_section_history
+(
+    person_id  INTEGER     NOT NULL,
+    section_id INTEGER     NOT NULL,
+    course_id  INTEGER     NOT NULL,
+    term_id    INTEGER     NOT NULL,
+    role       TEXT        NOT NULL,
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_to   TIMESTAMPTZ
 );
 
-INSERT INTO person_course (person_id, course_id)
-VALUES (1, 1),
-       (1, 2),
-       (1, 3),
-       (2, 1),
-       (2, 2),
-       (2, 3),
-       (3, 1),
+CREATE INDEX person_section_history_person_idx ON person_section_history (person_id, valid_from);
+CREATE INDEX person_section_history_course_idx ON person_section_history (course_id, valid_from);
+
+CREATE OR REPLACE FUNCTION person_section_history_version() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE person_section_history
+        SET valid_to = now()
+        WHERE person_id = OLD.person_id
+          AND section_id = OLD.section_id
+          AND valid_to IS NULL;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO person_section_history (person_id, section_id, course_id, term_id, role, valid_from)
+        SELECT NEW.person_id, NEW.section_id, s.course_id, s.term_id, NEW.role, now()
+        FROM section s
+        WHERE s.id = NEW.section_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER person_section_history_version
+    AFTER INSERT OR UPDATE OR DELETE
+    ON person_section
+    FOR EACH ROW
+EXECUTE FUNCTION person_section_history_version();
+
+-- the services check roles up front; these triggers keep every other writer
+-- from giving a non-professor an instructor role or section
+CREATE OR REPLACE FUNCTION person_section_check_role() RETURNS trigger AS
+$$
+BEGIN
+    IF NEW.role = 'instructor' AND NOT EXISTS (SELECT 1 FROM person WHERE id = NEW.person_id AND type = 'professor') THEN
+        RAISE EXCEPTION 'person % is not a professor and cannot be an instructor', NEW.person_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER person_section_check_role
+    BEFORE INSERT OR UPDATE
+    ON person_section
+    FOR EACH ROW
+EXECUTE FUNCTION person_section_check_role();
+
+CREATE OR REPLACE FUNCTION section_check_instructor() RETURNS trigger AS
+$$
+BEGIN
+    IF NEW.instructor_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM person WHERE id = NEW.instructor_id AND type = 'professor') THEN
+        RAISE EXCEPTION 'person % is not a professor and cannot be an instructor', NEW.instructor_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER section_check_instructor
+    BEFORE INSERT OR UPDATE OF instructor_id
+    ON section
+    FOR EACH ROW
+EXECUTE FUNCTION section_check_instructor();
+
+CREATE OR REPLACE FUNCTION department_check_chair() RETURNS trigger AS
+$$
+BEGIN
+    IF NEW.chair_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM person WHERE id = NEW.chair_id AND type = 'professor') THEN
+        RAISE EXCEPTION 'person % is not a professor and cannot chair a department', NEW.chair_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER department_check_chair
+    BEFORE INSERT OR UPDATE OF chair_id
+    ON department
+    FOR EACH ROW
+EXECUTE FUNCTION department_check_chair();
+
+CREATE OR REPLACE FUNCTION person_check_instructor_type() RETURNS trigger AS
+$$
+BEGIN
+    IF NEW.type <> 'professor' AND (
+        EXISTS (SELECT 1 FROM person_section WHERE person_id = NEW.id AND role = 'instructor') OR
+        EXISTS (SELECT 1 FROM section WHERE instructor_id = NEW.id)) THEN
+        RAISE EXCEPTION 'person % is an instructor and must remain a professor', NEW.id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    IF NEW.type <> 'professor' AND EXISTS (SELECT 1 FROM department WHERE chair_id = NEW.id) THEN
+        RAISE EXCEPTION 'person % chairs a department and must remain a professor', NEW.id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER person_check_instructor_type
+    BEFORE UPDATE OF type
+    ON person
+    FOR EACH ROW
+EXECUTE FUNCTION person_check_instructor_type();
+
+INSERT INTO person_section (person_id, section_id, role)
+VALUES (1, 1, 'instructor'),
+       (1, 2, 'instructor'),
+       (1, 3, 'instructor'),
+       (2, 1, 'instructor'),
+       (2, 2, 'instructor'),
+       (2, 3, 'instructor');
+
+INSERT INTO person_section (person_id, section_id)
+VALUES (3, 1),
        (3, 2),
        (3, 3),
        (4, 1),
@@ -56,4 +404,161 @@ VALUES (1, 1),
        (4, 3),
        (5, 1),
        (5, 2),
-       (5, 3);
\ No newline at end of file
+       (5, 3);
+
+-- grade is the final grade of a student in a section: a letter of the
+-- configured scale, P or NP for pass/fail, or I for incomplete. points is the
+-- letter's value on the scale in force when it was recorded, NULL for the
+-- others; passed is whether the grade earns the course's credits. graded_by
+-- is the professor who recorded or last amended it, NULL once they are
+-- purged. The services drop a grade when its student leaves the section.
+CREATE TABLE grade
+(
+    person_id  INTEGER     NOT NULL REFERENCES person (id),
+    section_id INTEGER     NOT NULL REFERENCES section (id),
+    grade      TEXT        NOT NULL,
+    points     NUMERIC(3, 2),
+    passed     BOOLEAN     NOT NULL,
+    graded_by  INTEGER     REFERENCES person (id) ON DELETE SET NULL,
+    graded_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (person_id, section_id)
+);
+
+CREATE INDEX grade_section_idx ON grade (section_id);
+
+INSERT INTO grade (person_id, section_id, grade, points, passed, graded_by)
+VALUES (3, 1, 'A', 4.0, true, 1),
+       (4, 1, 'B+', 3.3, true, 1),
+       (5, 1, 'P', NULL, true, 1);
+
+-- section_waitlist holds the persons waiting for a student seat in a full
+-- section, first come first served by id. The services promote the first in
+-- line whenever a seat frees up.
+CREATE TABLE section_waitlist
+(
+    id         BIGSERIAL PRIMARY KEY,
+    section_id INTEGER     NOT NULL REFERENCES section (id),
+    person_id  INTEGER     NOT NULL REFERENCES person (id),
+    added_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (section_id, person_id)
+);
+
+CREATE INDEX section_waitlist_person_idx ON section_waitlist (person_id);
+
+-- audit_event
+CREATE TABLE audit_event
+(
+    id          BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ                                      NOT NULL DEFAULT now(),
+    actor       TEXT                                             NOT NULL,
+    request_id  TEXT                                             NOT NULL DEFAULT '',
+    action      TEXT CHECK (action IN ('create', 'update', 'delete', 'restore', 'purge')) NOT NULL,
+    entity      TEXT                                             NOT NULL,
+    entity_id   INTEGER                                          NOT NULL,
+    before      JSONB,
+    after       JSONB
+);
+
+CREATE INDEX audit_event_entity_idx ON audit_event (entity, entity_id, occurred_at);
+CREATE INDEX audit_event_actor_idx ON audit_event (actor, occurred_at);
+CREATE INDEX audit_event_occurred_at_idx ON audit_event (occurred_at);
+
+-- audit_event is append-only
+CREATE OR REPLACE FUNCTION audit_event_immutable() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_event is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_event_no_update
+    BEFORE UPDATE OR DELETE OR TRUNCATE
+    ON audit_event
+    FOR EACH STATEMENT
+EXECUTE FUNCTION audit_event_immutable();
+
+-- outbox_event holds domain events written in the same transaction as the
+-- change they describe. The webhook dispatcher fans each one out into
+-- webhook_delivery rows and then sets dispatched_at. position numbers events
+-- in commit order, once committed; ids are taken in insert order.
+CREATE TABLE outbox_event
+(
+    id            BIGSERIAL PRIMARY KEY,
+    position      BIGINT UNIQUE,
+    type          TEXT        NOT NULL,
+    occurred_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    actor         TEXT        NOT NULL,
+    request_id    TEXT        NOT NULL DEFAULT '',
+    entity_id     INTEGER     NOT NULL,
+    data          JSONB       NOT NULL,
+    dispatched_at TIMESTAMPTZ
+);
+
+CREATE INDEX outbox_event_undispatched_idx ON outbox_event (id) WHERE dispatched_at IS NULL;
+CREATE INDEX outbox_event_occurred_at_idx ON outbox_event (occurred_at);
+
+-- wake the API instances' event hubs when a transaction writes events;
+-- identical notifications in one transaction are delivered once, on commit
+CREATE OR REPLACE FUNCTION outbox_event_notify() RETURNS trigger AS
+$$
+BEGIN
+    PERFORM pg_notify('outbox_event', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER outbox_event_notify
+    AFTER INSERT
+    ON outbox_event
+    FOR EACH STATEMENT
+EXECUTE FUNCTION outbox_event_notify();
+
+-- number events as their transaction commits; the lock is held until it ends,
+-- so a position is only taken once every lower one is visible
+CREATE SEQUENCE outbox_event_position_seq OWNED BY outbox_event.position;
+
+CREATE OR REPLACE FUNCTION outbox_event_position() RETURNS trigger AS
+$$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('outbox_event_position'));
+    UPDATE outbox_event SET position = nextval('outbox_event_position_seq') WHERE id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER outbox_event_position
+    AFTER INSERT
+    ON outbox_event
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW
+EXECUTE FUNCTION outbox_event_position();
+
+-- webhook_subscription; an empty events array subscribes to every type
+CREATE TABLE webhook_subscription
+(
+    id         SERIAL PRIMARY KEY,
+    url        TEXT        NOT NULL,
+    secret     TEXT        NOT NULL,
+    events     TEXT[]      NOT NULL DEFAULT '{}',
+    active     BOOLEAN     NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- webhook_delivery is one event to one subscription, retried until it is
+-- delivered or dead-lettered
+CREATE TABLE webhook_delivery
+(
+    id               BIGSERIAL PRIMARY KEY,
+    subscription_id  INTEGER     NOT NULL REFERENCES webhook_subscription (id),
+    event_id         BIGINT      NOT NULL REFERENCES outbox_event (id),
+    status           TEXT CHECK (status IN ('pending', 'delivered', 'dead')) NOT NULL DEFAULT 'pending',
+    attempts         INTEGER     NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_status_code INTEGER,
+    last_error       TEXT        NOT NULL DEFAULT '',
+    delivered_at     TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX webhook_delivery_due_idx ON webhook_delivery (next_attempt_at) WHERE status = 'pending';
@@ -10,7 +10,7 @@ import (
 
 type Configuration struct {
 	Env                  string     `env:"ENV,required,required"`
-	LogLevel             slog.Level `env:"LOG_LEVEL",required,required"`
+	LogLevel             slog.Level `env:"LOG_LEVEL,required"`
 	DBName               string     `env:"DATABASE_NAME,required"`
 	DBUser               string     `env:"DATABASE_USER,required"`
 	DBPassword           string     `env:"DATABASE_PASSWORD,required"`
@@ -20,6 +20,21 @@ type Configuration struct {
 	HTTPPort             string     `env:"HTTP_PORT,required"`
 	HTTPDomain           string     `env:"HTTP_DOMAIN,required"`
 	HTTPShutdownDuration int        `env:"HTTP_SHUTDOWN_DURATION,required"`
+	GRPCPort             string     `env:"GRPC_PORT" envDefault:":9000"`
+	AdminToken           string     `env:"ADMIN_TOKEN"`
+	SoftDeleteRetention  int        `env:"SOFT_DELETE_RETENTION_DAYS" envDefault:"30"`
+	PurgeInterval        int        `env:"PURGE_INTERVAL_MINUTES" envDefault:"60"`
+	WebhookInterval      int        `env:"WEBHOOK_POLL_INTERVAL_SECONDS" envDefault:"5"`
+	WebhookTimeout       int        `env:"WEBHOOK_TIMEOUT_SECONDS" envDefault:"10"`
+	WebhookMaxAttempts   int        `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+	// GradeScale maps letter grades to grade points, e.g. "A=4.0,A-=3.7".
+	GradeScale map[string]float64 `env:"GRADE_SCALE" envKeyValSeparator:"=" envDefault:"A=4.0,A-=3.7,B+=3.3,B=3.0,B-=2.7,C+=2.3,C=2.0,C-=1.7,D+=1.3,D=1.0,D-=0.7,F=0"`
+	// CalendarSecret signs the tokens of calendar feed URLs; feeds are
+	// disabled without it.
+	CalendarSecret string `env:"CALENDAR_SECRET"`
+	// EnrollmentRulesFile is a JSON file of eligibility rules enrollments
+	// are checked against; without it there are none.
+	EnrollmentRulesFile string `env:"ENROLLMENT_RULES_FILE"`
 }
 
 func New() (Configuration, error) {
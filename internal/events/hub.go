@@ -0,0 +1,187 @@
+// Package events fans outbox events out to in-process subscribers, such as
+// Server-Sent Events streams.
+//
+// Every API instance runs a Hub that LISTENs on the outbox_event channel,
+// which a trigger NOTIFYs whenever a transaction writes to the outbox. On each
+// notification the hub reads the new events from the outbox table, so all
+// instances see every change whichever instance made it. Events are followed
+// by position, which the outbox assigns in commit order, so an event whose
+// transaction commits late is not skipped.
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+	"github.com/lib/pq"
+)
+
+// Channel is the Postgres notification channel for new outbox events.
+const Channel = "outbox_event"
+
+const (
+	// pageSize bounds the events read from the outbox per query.
+	pageSize = 500
+	// bufferSize is how many events a subscriber may fall behind by before
+	// it is dropped. A dropped stream reconnects and resumes from the outbox.
+	bufferSize = 256
+	// pollInterval is how often the hub checks the outbox without a
+	// notification, in case one was lost while reconnecting.
+	pollInterval = 30 * time.Second
+)
+
+// Subscription receives events published after it was created, in position
+// order.
+// C is closed if the subscriber falls behind or the hub shuts down.
+type Subscription struct {
+	C chan models.OutboxEvent
+}
+
+type Hub struct {
+	logger    *httplog.Logger
+	svsOutbox *services.OutboxService
+
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	last   int64
+	closed bool
+}
+
+// NewHub returns a hub that broadcasts the events after the latest one in
+// the outbox, so subscribers that do not replay start with the next change
+// even before Run.
+func NewHub(ctx context.Context, logger *httplog.Logger, svsOutbox *services.OutboxService) (*Hub, error) {
+	last, err := svsOutbox.LatestPosition(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in events.NewHub] %w", err)
+	}
+	return &Hub{
+		logger:    logger,
+		svsOutbox: svsOutbox,
+		subs:      map[*Subscription]struct{}{},
+		last:      last,
+	}, nil
+}
+
+// Run listens for outbox notifications using its own connection to
+// connectionString and broadcasts new events until ctx is cancelled. Events
+// written since NewHub are broadcast once it listens.
+func (h *Hub) Run(ctx context.Context, connectionString string) {
+	listener := pq.NewListener(connectionString, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			h.logger.Warn("Outbox listener connection event", "event", ev, "error", err)
+		}
+	})
+	defer listener.Close()
+	if err := listener.Listen(Channel); err != nil {
+		h.logger.Error("Error listening for outbox events", "error", err)
+	}
+	h.catchUp(ctx)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			h.Close()
+			return
+		case <-listener.Notify:
+			// A nil notification means the connection was re-established;
+			// catching up covers anything missed meanwhile.
+		case <-ticker.C:
+		}
+		h.catchUp(ctx)
+	}
+}
+
+// Subscribe registers a new subscription and returns it with the position of
+// the last event broadcast before it, from which the subscriber may replay.
+func (h *Hub) Subscribe() (*Subscription, int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := &Subscription{C: make(chan models.OutboxEvent, bufferSize)}
+	if h.closed {
+		close(sub.C)
+		return sub, h.last
+	}
+	h.subs[sub] = struct{}{}
+	return sub, h.last
+}
+
+// Unsubscribe removes a subscription. It is safe to call more than once.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.C)
+	}
+}
+
+// Close ends every subscription so streams finish and the server can shut
+// down. Later subscriptions are closed immediately.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subs {
+		delete(h.subs, sub)
+		close(sub.C)
+	}
+}
+
+// Replay calls fn for each event after afterPosition already in the outbox,
+// in position order, and returns the position of the last one.
+func (h *Hub) Replay(ctx context.Context, afterPosition int64, fn func(models.OutboxEvent) error) (int64, error) {
+	for {
+		events, err := h.svsOutbox.EventsAfter(ctx, afterPosition, pageSize)
+		if err != nil {
+			return afterPosition, err
+		}
+		for _, event := range events {
+			if err := fn(event); err != nil {
+				return afterPosition, err
+			}
+			afterPosition = event.Position
+		}
+		if len(events) < pageSize {
+			return afterPosition, nil
+		}
+	}
+}
+
+func (h *Hub) catchUp(ctx context.Context) {
+	h.mu.Lock()
+	last := h.last
+	h.mu.Unlock()
+
+	_, err := h.Replay(ctx, last, func(event models.OutboxEvent) error {
+		h.broadcast(event)
+		return nil
+	})
+	if err != nil {
+		h.logger.Error("Error reading new outbox events", "error", err)
+	}
+}
+
+func (h *Hub) broadcast(event models.OutboxEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.last = event.Position
+	for sub := range h.subs {
+		select {
+		case sub.C <- event:
+		default:
+			h.logger.Warn("Dropping slow event subscriber", "event", event.ID)
+			delete(h.subs, sub)
+			close(sub.C)
+		}
+	}
+}
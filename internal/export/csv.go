@@ -0,0 +1,38 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(columns))}
+	if err := cw.w.Write(columns); err != nil {
+		return nil, fmt.Errorf("[in export.newCSVWriter] failed to write header: %w", err)
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) WriteRow(values ...any) error {
+	if len(values) != len(cw.record) {
+		return fmt.Errorf("[in export.csvWriter.WriteRow] got %d values for %d columns", len(values), len(cw.record))
+	}
+	for i, v := range values {
+		cw.record[i] = cellString(v)
+	}
+	if err := cw.w.Write(cw.record); err != nil {
+		return fmt.Errorf("[in export.csvWriter.WriteRow] %w", err)
+	}
+	return nil
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
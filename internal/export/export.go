@@ -0,0 +1,94 @@
+// Package export writes tabular data to CSV, NDJSON and XLSX one row at a time
+// so that large result sets can be streamed straight to an http.ResponseWriter.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Format identifies an export encoding.
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+	FormatXLSX   Format = "xlsx"
+)
+
+// ParseFormat maps a format name (case-insensitive) to a Format. An empty
+// name defaults to CSV.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case "":
+		return FormatCSV, nil
+	case FormatCSV, FormatNDJSON, FormatXLSX:
+		return f, nil
+	default:
+		return "", fmt.Errorf("[in export.ParseFormat] unsupported format %q", name)
+	}
+}
+
+// ContentType returns the MIME type for the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Extension returns the file extension for the format, without the dot.
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// Writer writes rows of a single table. The column names passed to New are
+// used as the CSV/XLSX header and as the NDJSON object keys. Values may be
+// strings, bools, ints, floats, []string or []int (joined with "; " in
+// CSV/XLSX and emitted as an array in NDJSON).
+type Writer interface {
+	WriteRow(values ...any) error
+	// Close flushes any buffered output. It does not close the underlying
+	// io.Writer.
+	Close() error
+}
+
+// New returns a Writer for the given format that writes to w.
+func New(format Format, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatNDJSON:
+		return newNDJSONWriter(w, columns), nil
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	default:
+		return nil, fmt.Errorf("[in export.New] unsupported format %q", format)
+	}
+}
+
+// cellString renders a value for the text based formats.
+func cellString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, "; ")
+	case []int:
+		parts := make([]string, len(v))
+		for i, n := range v {
+			parts[i] = strconv.Itoa(n)
+		}
+		return strings.Join(parts, "; ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,139 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"testing"
+)
+
+var (
+	testColumns = []string{"id", "name", "active", "score", "tags", "course_ids", "note"}
+	testRow     = []any{7, "Ada <Lovelace>", true, 3.5, []string{"a", "b"}, []int{1, 2}, nil}
+)
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := New(FormatCSV, &buf, testColumns)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := w.WriteRow(testRow...); err != nil {
+		t.Fatalf("WriteRow() error = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("wrote %q before Close, want it buffered", buf.String())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := "id,name,active,score,tags,course_ids,note\n" +
+		"7,Ada <Lovelace>,true,3.5,a; b,1; 2,\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestWriteRowCountsValues(t *testing.T) {
+	for _, format := range []Format{FormatCSV, FormatNDJSON, FormatXLSX} {
+		w, err := New(format, io.Discard, testColumns)
+		if err != nil {
+			t.Fatalf("New(%s) error = %v", format, err)
+		}
+		if err := w.WriteRow(1, "two"); err == nil {
+			t.Errorf("%s WriteRow() with 2 of %d values succeeded, want an error", format, len(testColumns))
+		}
+	}
+}
+
+// xlsxCell is a cell of the worksheet written by xlsxWriter.
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline string `xml:"is>t"`
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := New(FormatXLSX, &buf, testColumns)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := w.WriteRow(testRow...); err != nil {
+		t.Fatalf("WriteRow() error = %v", err)
+	}
+	if _, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err == nil {
+		t.Errorf("workbook is readable before Close, want its directory written by Close")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("reading workbook: %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	wantNames := []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("parts = %v, want %v", names, wantNames)
+	}
+
+	f, err := zr.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatalf("opening worksheet: %v", err)
+	}
+	defer f.Close()
+	var sheet struct {
+		Rows []struct {
+			Ref   string     `xml:"r,attr"`
+			Cells []xlsxCell `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.NewDecoder(f).Decode(&sheet); err != nil {
+		t.Fatalf("decoding worksheet: %v", err)
+	}
+	if len(sheet.Rows) != 2 {
+		t.Fatalf("got %d rows, want a header and 1 row", len(sheet.Rows))
+	}
+
+	header := sheet.Rows[0]
+	if header.Ref != "1" || len(header.Cells) != len(testColumns) {
+		t.Fatalf("header row %s has %d cells, want row 1 with %d", header.Ref, len(header.Cells), len(testColumns))
+	}
+	for i, c := range header.Cells {
+		want := xlsxCell{Ref: columnName(i) + "1", Type: "inlineStr", Inline: testColumns[i]}
+		if c != want {
+			t.Errorf("header cell %d = %+v, want %+v", i, c, want)
+		}
+	}
+
+	// The nil note has no cell.
+	wantCells := []xlsxCell{
+		{Ref: "A2", Value: "7"},
+		{Ref: "B2", Type: "inlineStr", Inline: "Ada <Lovelace>"},
+		{Ref: "C2", Type: "b", Value: "1"},
+		{Ref: "D2", Value: "3.5"},
+		{Ref: "E2", Type: "inlineStr", Inline: "a; b"},
+		{Ref: "F2", Type: "inlineStr", Inline: "1; 2"},
+	}
+	if row := sheet.Rows[1]; row.Ref != "2" || !reflect.DeepEqual(row.Cells, wantCells) {
+		t.Errorf("row %s cells = %+v, want row 2 with %+v", row.Ref, row.Cells, wantCells)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := map[int]string{0: "A", 1: "B", 25: "Z", 26: "AA", 27: "AB", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for i, want := range tests {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type ndjsonWriter struct {
+	w    *bufio.Writer
+	keys [][]byte
+}
+
+func newNDJSONWriter(w io.Writer, columns []string) *ndjsonWriter {
+	keys := make([][]byte, len(columns))
+	for i, c := range columns {
+		keys[i], _ = json.Marshal(c)
+	}
+	return &ndjsonWriter{w: bufio.NewWriter(w), keys: keys}
+}
+
+// WriteRow writes one JSON object per line, preserving column order.
+func (nw *ndjsonWriter) WriteRow(values ...any) error {
+	if len(values) != len(nw.keys) {
+		return fmt.Errorf("[in export.ndjsonWriter.WriteRow] got %d values for %d columns", len(values), len(nw.keys))
+	}
+
+	nw.w.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			nw.w.WriteByte(',')
+		}
+		nw.w.Write(nw.keys[i])
+		nw.w.WriteByte(':')
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("[in export.ndjsonWriter.WriteRow] failed to marshal %q: %w", nw.keys[i], err)
+		}
+		nw.w.Write(b)
+	}
+	nw.w.WriteString("}\n")
+
+	// Flush per row so clients see progress on long exports.
+	if err := nw.w.Flush(); err != nil {
+		return fmt.Errorf("[in export.ndjsonWriter.WriteRow] %w", err)
+	}
+	return nil
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nw.w.Flush()
+}
@@ -0,0 +1,144 @@
+// Package graph serves persons, courses and enrollments over GraphQL. It
+// resolves through the same services as the REST handlers, so mutations are
+// validated, audited and published alike. Relationships are loaded in
+// batches per request, one query per level of the query rather than one per
+// item, and operations are bounded by MaxDepth and MaxComplexity.
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+)
+
+// Schema is the executable GraphQL schema.
+type Schema struct {
+	schema    graphql.Schema
+	logger    *httplog.Logger
+	svsCourse *services.CourseService
+	svsPerson *services.PersonService
+}
+
+// Request is a GraphQL request as sent over HTTP.
+type Request struct {
+	Query         string
+	OperationName string
+	Variables     map[string]any
+	// ReadOnly rejects mutations, for requests that must not change anything.
+	ReadOnly bool
+}
+
+// errReadOnly is returned for a mutation in a read-only request.
+var errReadOnly = errors.New("mutations are not allowed in this request")
+
+// Execute parses, validates and runs req. Problems with the request itself,
+// including exceeded limits, are returned as a result without data.
+func (s *Schema) Execute(ctx context.Context, req Request) *graphql.Result {
+	src := source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"})
+	doc, err := parser.Parse(parser.ParseParams{Source: src})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	validated := graphql.ValidateDocument(&s.schema, doc, graphql.SpecifiedRules)
+	if !validated.IsValid {
+		return &graphql.Result{Errors: validated.Errors}
+	}
+
+	if op, fragments := operation(doc, req.OperationName); op != nil {
+		if req.ReadOnly && op.Operation == ast.OperationTypeMutation {
+			return &graphql.Result{Errors: gqlerrors.FormatErrors(errReadOnly)}
+		}
+		if err := checkLimits(&s.schema, op, fragments); err != nil {
+			return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+		}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoaders(ctx, newLoaders(s.svsCourse, s.svsPerson)),
+	})
+}
+
+// operation returns the operation named name, or the only operation if name
+// is empty, along with the document's fragments. It returns nil if there is
+// no such operation; graphql.Execute reports that.
+func operation(doc *ast.Document, name string) (*ast.OperationDefinition, map[string]*ast.FragmentDefinition) {
+	var (
+		op         *ast.OperationDefinition
+		operations int
+		fragments  = map[string]*ast.FragmentDefinition{}
+	)
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.OperationDefinition:
+			operations++
+			if name == "" || (def.Name != nil && def.Name.Value == name) {
+				op = def
+			}
+		case *ast.FragmentDefinition:
+			fragments[def.Name.Value] = def
+		}
+	}
+	if name == "" && operations > 1 {
+		return nil, fragments
+	}
+	return op, fragments
+}
+
+// Error is a resolver error shown to clients, with a machine readable code
+// in its extensions.
+type Error struct {
+	Message  string
+	Code     string
+	Problems []validation.Problem
+}
+
+// Error codes reported in the extensions of an Error.
+const (
+	CodeNotFound     = "NOT_FOUND"
+	CodeBadUserInput = "BAD_USER_INPUT"
+	CodeInternal     = "INTERNAL"
+)
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Extensions implements gqlerrors.ExtendedError.
+func (e *Error) Extensions() map[string]any {
+	ext := map[string]any{"code": e.Code}
+	if len(e.Problems) > 0 {
+		ext["validationErrors"] = e.Problems
+	}
+	return ext
+}
+
+// clientError maps a service error to the Error shown to clients. Unexpected
+// errors are logged and hidden behind a generic message, as the REST
+// handlers do.
+func (s *Schema) clientError(err error, action string) error {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return &Error{Message: "invalid input", Code: CodeBadUserInput, Problems: validationErr.Problems}
+	case errors.Is(err, sql.ErrNoRows):
+		return &Error{Message: "not found", Code: CodeNotFound}
+	default:
+		s.logger.Error("error "+action, "error", err)
+		return &Error{Message: fmt.Sprintf("Error %s", action), Code: CodeInternal}
+	}
+}
@@ -0,0 +1,113 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	// MaxDepth is the deepest field nesting a query may select, counting the
+	// root fields as depth 1.
+	MaxDepth = 10
+	// MaxComplexity bounds the estimated number of fields a query resolves.
+	MaxComplexity = 5000
+	// listSize is the number of items a list field is assumed to return when
+	// estimating complexity.
+	listSize = 10
+)
+
+// checkLimits rejects operations nested deeper than MaxDepth or estimated to
+// cost more than MaxComplexity. Every field costs 1 plus the cost of its
+// selections, which is multiplied by listSize for list fields, so
+// persons { courses { professors { id } } } costs 1 + 10 * (1 + 10 * (1 + 10)),
+// or 1111, while nesting one more list level exceeds the limit.
+// Introspection fields are not counted so tools can load the schema. The
+// document must already be validated.
+func checkLimits(schema *graphql.Schema, op *ast.OperationDefinition, fragments map[string]*ast.FragmentDefinition) error {
+	root := schema.QueryType()
+	if op.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+
+	l := limits{schema: schema, fragments: fragments}
+	cost, depth := l.selectionSet(op.SelectionSet, root)
+	if depth > MaxDepth {
+		return fmt.Errorf("query depth %d exceeds the maximum of %d", depth, MaxDepth)
+	}
+	if cost > MaxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the maximum of %d", cost, MaxComplexity)
+	}
+	return nil
+}
+
+type limits struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+}
+
+// selectionSet returns the cost and depth of the selections on parent.
+func (l limits) selectionSet(set *ast.SelectionSet, parent *graphql.Object) (cost, depth int) {
+	if set == nil {
+		return 0, 0
+	}
+	for _, selection := range set.Selections {
+		var c, d int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			c, d = l.field(selection, parent)
+		case *ast.FragmentSpread:
+			if fragment, ok := l.fragments[selection.Name.Value]; ok {
+				c, d = l.selectionSet(fragment.SelectionSet, l.narrow(fragment.TypeCondition, parent))
+			}
+		case *ast.InlineFragment:
+			c, d = l.selectionSet(selection.SelectionSet, l.narrow(selection.TypeCondition, parent))
+		}
+		cost += c
+		depth = max(depth, d)
+	}
+	return cost, depth
+}
+
+func (l limits) field(field *ast.Field, parent *graphql.Object) (cost, depth int) {
+	name := field.Name.Value
+	if strings.HasPrefix(name, "__") || parent == nil {
+		return 0, 0
+	}
+	def, ok := parent.Fields()[name]
+	if !ok {
+		return 0, 0
+	}
+
+	multiplier := 1
+	fieldType := unwrapNonNull(def.Type)
+	if list, ok := fieldType.(*graphql.List); ok {
+		multiplier = listSize
+		fieldType = unwrapNonNull(list.OfType)
+	}
+	object, _ := fieldType.(*graphql.Object)
+
+	cost, depth = l.selectionSet(field.SelectionSet, object)
+	return 1 + multiplier*cost, 1 + depth
+}
+
+// narrow returns the object type named by a fragment's type condition, or
+// parent if there is none.
+func (l limits) narrow(condition *ast.Named, parent *graphql.Object) *graphql.Object {
+	if condition == nil {
+		return parent
+	}
+	if object, ok := l.schema.Type(condition.Name.Value).(*graphql.Object); ok {
+		return object
+	}
+	return parent
+}
+
+func unwrapNonNull(t graphql.Type) graphql.Type {
+	if nonNull, ok := t.(*graphql.NonNull); ok {
+		return nonNull.OfType
+	}
+	return t
+}
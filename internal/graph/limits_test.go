@@ -0,0 +1,95 @@
+package graph
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/rules"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// newTestSchema builds the schema over services without a database, so only
+// requests rejected before resolving can be executed.
+func newTestSchema(t *testing.T) *Schema {
+	t.Helper()
+	s, err := NewSchema(httplog.NewLogger("test"), services.NewCourseService(nil, rules.Set{}), services.NewPersonService(nil, rules.Set{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCheckLimits(t *testing.T) {
+	s := newTestSchema(t)
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"flat", "{ persons { id firstName } }", ""},
+		{"three list levels", "{ persons { courses { professors { id } } } }", ""},
+		{"four list levels", "{ persons { courses { professors { courses { id } } } } }", "query complexity 11111 exceeds the maximum of 5000"},
+		{"single objects are not multiplied", "{ person(firstName: \"Ada\") { courses { persons { courses { id } } } } }", ""},
+		{"depth", "{ course(id: 1) { persons { courses { persons { courses { persons { courses { persons { courses { persons { id } } } } } } } } } } }",
+			"query depth 11 exceeds the maximum of 10"},
+		{"fragment spread", "query { persons { courses { ...roster } } } fragment roster on Course { professors { courses { id } } }",
+			"query complexity 11111 exceeds the maximum of 5000"},
+		{"inline fragment", "{ persons { courses { ... on Course { professors { courses { id } } } } } }",
+			"query complexity 11111 exceeds the maximum of 5000"},
+		{"introspection", "{ __schema { types { name fields { name type { name ofType { name ofType { name ofType { name ofType { name } } } } } } } } }", ""},
+		{"mutation", "mutation { deleteCourse(id: 1) }", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: tt.query})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if validated := graphql.ValidateDocument(&s.schema, doc, graphql.SpecifiedRules); !validated.IsValid {
+				t.Fatalf("invalid query: %v", validated.Errors)
+			}
+			op, fragments := operation(doc, "")
+			err = checkLimits(&s.schema, op, fragments)
+			if got := errorString(err); got != tt.want {
+				t.Errorf("checkLimits() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteRejectsBeforeResolving(t *testing.T) {
+	s := newTestSchema(t)
+
+	tests := []struct {
+		name string
+		req  Request
+		want string
+	}{
+		{"limits", Request{Query: "{ persons { courses { professors { courses { id } } } } }"}, "exceeds the maximum"},
+		{"read only", Request{Query: "mutation { deleteCourse(id: 1) }", ReadOnly: true}, errReadOnly.Error()},
+		{"invalid", Request{Query: "{ persons { salary } }"}, "Cannot query field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := s.Execute(context.Background(), tt.req)
+			if result.Data != nil {
+				t.Errorf("Data = %v, want nil", result.Data)
+			}
+			if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Message, tt.want) {
+				t.Errorf("Errors = %v, want one containing %q", result.Errors, tt.want)
+			}
+		})
+	}
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
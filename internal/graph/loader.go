@@ -0,0 +1,139 @@
+package graph
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// loader batches the keys requested by sibling resolvers into one fetch.
+// graphql-go resolves a field on every item of a list before it calls any of
+// the thunks they returned, so when the first thunk runs the keys of all its
+// siblings are pending and are fetched together. Results are cached for the
+// rest of the request.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	results map[K]result[V]
+}
+
+type result[V any] struct {
+	value V
+	found bool
+	err   error
+}
+
+func newLoader[K comparable, V any](fetch func(context.Context, []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		queued:  map[K]bool{},
+		results: map[K]result[V]{},
+	}
+}
+
+// load queues key and returns a thunk for its value. The thunk reports
+// whether the key was found.
+func (l *loader[K, V]) load(ctx context.Context, key K) func() (V, bool, error) {
+	l.mu.Lock()
+	l.enqueue(key)
+	l.mu.Unlock()
+
+	return func() (V, bool, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.queued[key] {
+			l.flush(ctx)
+		}
+		res := l.results[key]
+		return res.value, res.found, res.err
+	}
+}
+
+// loadMany queues keys and returns a thunk for the values that were found,
+// in the order of keys.
+func (l *loader[K, V]) loadMany(ctx context.Context, keys []K) func() ([]V, error) {
+	l.mu.Lock()
+	for _, key := range keys {
+		l.enqueue(key)
+	}
+	l.mu.Unlock()
+
+	return func() ([]V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		values := make([]V, 0, len(keys))
+		for _, key := range keys {
+			if l.queued[key] {
+				l.flush(ctx)
+			}
+			res := l.results[key]
+			if res.err != nil {
+				return nil, res.err
+			}
+			if res.found {
+				values = append(values, res.value)
+			}
+		}
+		return values, nil
+	}
+}
+
+// clear drops the cached results, e.g. after a mutation.
+func (l *loader[K, V]) clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.results = map[K]result[V]{}
+}
+
+func (l *loader[K, V]) enqueue(key K) {
+	if _, done := l.results[key]; done || l.queued[key] {
+		return
+	}
+	l.queued[key] = true
+	l.pending = append(l.pending, key)
+}
+
+// flush fetches every pending key. The caller holds mu.
+func (l *loader[K, V]) flush(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+	values, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		delete(l.queued, key)
+		value, found := values[key]
+		l.results[key] = result[V]{value: value, found: found, err: err}
+	}
+}
+
+// loaders are the per request loaders used by the resolvers.
+type loaders struct {
+	courses *loader[int, models.Course]
+	persons *loader[int, []models.Person]
+}
+
+func newLoaders(svsCourse *services.CourseService, svsPerson *services.PersonService) *loaders {
+	return &loaders{
+		courses: newLoader(svsCourse.GetCoursesByIDs),
+		persons: newLoader(svsPerson.ListPersonsByCourses),
+	}
+}
+
+func (l *loaders) clear() {
+	l.courses.clear()
+	l.persons.clear()
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
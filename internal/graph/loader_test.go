@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakeFetch finds the keys below 100, doubled, and records every batch it
+// is asked for.
+type fakeFetch struct {
+	batches [][]int
+	err     error
+}
+
+func (f *fakeFetch) fetch(_ context.Context, keys []int) (map[int]int, error) {
+	f.batches = append(f.batches, keys)
+	if f.err != nil {
+		return nil, f.err
+	}
+	values := map[int]int{}
+	for _, key := range keys {
+		if key < 100 {
+			values[key] = key * 2
+		}
+	}
+	return values, nil
+}
+
+func TestLoaderBatchesSiblings(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeFetch{}
+	l := newLoader(f.fetch)
+
+	// Sibling resolvers all queue their keys before any thunk runs.
+	first := l.load(ctx, 1)
+	second := l.load(ctx, 2)
+	again := l.load(ctx, 1)
+	missing := l.load(ctx, 100)
+	many := l.loadMany(ctx, []int{3, 100, 2})
+
+	if value, found, err := first(); value != 2 || !found || err != nil {
+		t.Errorf("first() = %d, %t, %v, want 2, true, nil", value, found, err)
+	}
+	if value, found, err := second(); value != 4 || !found || err != nil {
+		t.Errorf("second() = %d, %t, %v, want 4, true, nil", value, found, err)
+	}
+	if value, found, err := again(); value != 2 || !found || err != nil {
+		t.Errorf("again() = %d, %t, %v, want 2, true, nil", value, found, err)
+	}
+	if _, found, err := missing(); found || err != nil {
+		t.Errorf("missing() found = %t, err = %v, want false, nil", found, err)
+	}
+	if values, err := many(); !reflect.DeepEqual(values, []int{6, 4}) || err != nil {
+		t.Errorf("many() = %v, %v, want [6 4], nil", values, err)
+	}
+	if want := [][]int{{1, 2, 100, 3}}; !reflect.DeepEqual(f.batches, want) {
+		t.Errorf("batches = %v, want %v", f.batches, want)
+	}
+
+	// Results are cached for the rest of the request.
+	if value, _, _ := l.load(ctx, 2)(); value != 4 {
+		t.Errorf("cached load = %d, want 4", value)
+	}
+	if len(f.batches) != 1 {
+		t.Errorf("batches = %v, want no new fetch for cached keys", f.batches)
+	}
+
+	// The next level of the query is fetched in a batch of its own.
+	third := l.load(ctx, 5)
+	fourth := l.load(ctx, 6)
+	third()
+	fourth()
+	if want := [][]int{{1, 2, 100, 3}, {5, 6}}; !reflect.DeepEqual(f.batches, want) {
+		t.Errorf("batches = %v, want %v", f.batches, want)
+	}
+}
+
+func TestLoaderClear(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeFetch{}
+	l := newLoader(f.fetch)
+
+	l.load(ctx, 1)()
+	l.clear()
+	l.load(ctx, 1)()
+	if want := [][]int{{1}, {1}}; !reflect.DeepEqual(f.batches, want) {
+		t.Errorf("batches = %v, want %v", f.batches, want)
+	}
+}
+
+func TestLoaderError(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeFetch{err: errors.New("connection refused")}
+	l := newLoader(f.fetch)
+
+	one := l.load(ctx, 1)
+	many := l.loadMany(ctx, []int{1, 2})
+	if _, found, err := one(); found || !errors.Is(err, f.err) {
+		t.Errorf("one() found = %t, err = %v, want false, %v", found, err, f.err)
+	}
+	if values, err := many(); values != nil || !errors.Is(err, f.err) {
+		t.Errorf("many() = %v, %v, want nil, %v", values, err, f.err)
+	}
+	if len(f.batches) != 1 {
+		t.Errorf("batches = %v, want one fetch", f.batches)
+	}
+}
@@ -0,0 +1,543 @@
+package graph
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/graphql-go/graphql"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+)
+
+// courseInput mirrors the REST course body and its rules.
+type courseInput struct {
+	Name          string `json:"name" validate:"required"`
+	Capacity      *int   `json:"capacity" validate:"omitempty,min=1"`
+	Credits       *int   `json:"credits" validate:"omitempty,min=1,max=12"`
+	DepartmentID  *int   `json:"departmentId" validate:"omitempty,min=1"`
+	CatalogNumber string `json:"catalogNumber" validate:"omitempty,regex=^[0-9]{3}[A-Z]?$"`
+	Description   string `json:"description" validate:"max=2000"`
+}
+
+// personInput mirrors the REST person body and its rules.
+type personInput struct {
+	FirstName   string `json:"firstName" validate:"required"`
+	LastName    string `json:"lastName" validate:"required"`
+	Type        string `json:"type" validate:"oneof=student professor"`
+	DateOfBirth string `json:"dateOfBirth" validate:"required,regex=^\\d{4}-\\d{2}-\\d{2}$"`
+	Email       string `json:"email" validate:"omitempty,max=254,regex=^[^@\\s]+@[^@\\s]+\\.[^@\\s]+$"`
+	Phone       string `json:"phone" validate:"omitempty,regex=^\\+[1-9][0-9]{6,14}$"`
+	Address     string `json:"address" validate:"max=500"`
+	Courses     []int  `json:"courses" validate:"dive,min=1"`
+}
+
+// NewSchema builds the GraphQL schema over the course and person services.
+func NewSchema(logger *httplog.Logger, svsCourse *services.CourseService, svsPerson *services.PersonService) (*Schema, error) {
+	s := &Schema{logger: logger, svsCourse: svsCourse, svsPerson: svsPerson}
+
+	personType := graphql.NewEnum(graphql.EnumConfig{
+		Name: "PersonType",
+		Values: graphql.EnumValueConfigMap{
+			"STUDENT":   {Value: "student"},
+			"PROFESSOR": {Value: "professor"},
+		},
+	})
+
+	role := graphql.NewEnum(graphql.EnumConfig{
+		Name: "Role",
+		Values: graphql.EnumValueConfigMap{
+			"INSTRUCTOR":         {Value: models.RoleInstructor},
+			"TEACHING_ASSISTANT": {Value: models.RoleTeachingAssistant},
+			"STUDENT":            {Value: models.RoleStudent},
+			"AUDITOR":            {Value: models.RoleAuditor},
+		},
+	})
+	enrollment := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Enrollment",
+		Description: "A person's role in one of their courses, through a section of it",
+		Fields: graphql.Fields{
+			"courseId": {Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(models.Enrollment).CourseID, nil
+			}},
+			"sectionId": {Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(models.Enrollment).SectionID, nil
+			}},
+			"role": {Type: graphql.NewNonNull(role)},
+		},
+	})
+
+	// Course and Person refer to each other, so their fields are thunks.
+	var course, person *graphql.Object
+	course = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Course",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":                 {Type: graphql.NewNonNull(graphql.Int)},
+				"name":               {Type: graphql.NewNonNull(graphql.String)},
+				"capacity":           {Type: graphql.Int, Description: "Number of student seats, null when unlimited"},
+				"credits":            {Type: graphql.NewNonNull(graphql.Int)},
+				"departmentId":       {Type: graphql.Int, Resolve: courseField(func(c models.Course) any { return c.DepartmentID })},
+				"catalogNumber":      {Type: graphql.String, Resolve: courseField(func(c models.Course) any { return optionalString(c.CatalogNumber) })},
+				"code":               {Type: graphql.String, Description: "Catalog code, e.g. CS-201, null outside a department", Resolve: courseField(func(c models.Course) any { return optionalString(c.Code) })},
+				"description":        {Type: graphql.NewNonNull(graphql.String)},
+				"persons":            {Type: listOf(person), Description: "Everyone enrolled in the course", Resolve: s.resolveCoursePersons("")},
+				"professors":         {Type: listOf(person), Resolve: s.resolveCoursePersons("professor"), DeprecationReason: "Use instructors, which is based on the role in the course"},
+				"instructors":        {Type: listOf(person), Resolve: s.resolveCourseRole(models.RoleInstructor)},
+				"teachingAssistants": {Type: listOf(person), Resolve: s.resolveCourseRole(models.RoleTeachingAssistant)},
+				"students":           {Type: listOf(person), Resolve: s.resolveCourseRole(models.RoleStudent)},
+				"auditors":           {Type: listOf(person), Resolve: s.resolveCourseRole(models.RoleAuditor)},
+			}
+		}),
+	})
+	person = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Person",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":          {Type: graphql.NewNonNull(graphql.Int)},
+				"firstName":   {Type: graphql.NewNonNull(graphql.String)},
+				"lastName":    {Type: graphql.NewNonNull(graphql.String)},
+				"type":        {Type: graphql.NewNonNull(personType)},
+				"dateOfBirth": {Type: graphql.NewNonNull(graphql.String), Resolve: personField(func(p models.Person) any { return p.DateOfBirth.Format(time.DateOnly) })},
+				"age":         {Type: graphql.NewNonNull(graphql.Int), Description: "Computed from dateOfBirth"},
+				"email":       {Type: graphql.String, Resolve: personField(func(p models.Person) any { return optionalString(p.Email) })},
+				"phone":       {Type: graphql.String, Description: "In E.164 format, e.g. +15555550100", Resolve: personField(func(p models.Person) any { return optionalString(p.Phone) })},
+				"address":     {Type: graphql.String, Resolve: personField(func(p models.Person) any { return optionalString(p.Address) })},
+				"courses":     {Type: listOf(course), Resolve: s.resolvePersonCourses},
+				"enrollments": {Type: listOf(enrollment)},
+			}
+		}),
+	})
+
+	courseIn := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CourseInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":          {Type: graphql.NewNonNull(graphql.String)},
+			"capacity":      {Type: graphql.Int, Description: "Number of student seats; omit for no limit"},
+			"credits":       {Type: graphql.Int, Description: "Defaults to 3"},
+			"departmentId":  {Type: graphql.Int},
+			"catalogNumber": {Type: graphql.String, Description: "Makes the catalog code with the department's code; needs a department"},
+			"description":   {Type: graphql.String},
+		},
+	})
+	personIn := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "PersonInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"firstName":   {Type: graphql.NewNonNull(graphql.String)},
+			"lastName":    {Type: graphql.NewNonNull(graphql.String)},
+			"type":        {Type: graphql.NewNonNull(personType)},
+			"dateOfBirth": {Type: graphql.NewNonNull(graphql.String), Description: "Formatted as 2006-01-02"},
+			"email":       {Type: graphql.String},
+			"phone":       {Type: graphql.String, Description: "In E.164 format, e.g. +15555550100"},
+			"address":     {Type: graphql.String},
+			"courses":     {Type: graphql.NewList(graphql.NewNonNull(graphql.Int)), Description: "Ids of the courses to enroll in"},
+		},
+	})
+
+	id := &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}
+	firstName := &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"persons": {
+				Type: listOf(person),
+				Args: graphql.FieldConfigArgument{
+					"firstName": {Type: graphql.String},
+					"lastName":  {Type: graphql.String},
+					"type":      {Type: personType},
+					"minAge":    {Type: graphql.Int},
+					"maxAge":    {Type: graphql.Int},
+					"course":    {Type: graphql.Int, Description: "Only persons enrolled in this course id"},
+				},
+				Resolve: s.resolvePersons,
+			},
+			"person": {
+				Type:    person,
+				Args:    graphql.FieldConfigArgument{"firstName": firstName},
+				Resolve: s.resolvePerson,
+			},
+			"courses": {
+				Type: listOf(course),
+				Args: graphql.FieldConfigArgument{
+					"name":       {Type: graphql.String, Description: "Case-insensitive substring of the course name"},
+					"department": {Type: graphql.Int, Description: "Only courses of this department id"},
+					"minCredits": {Type: graphql.Int},
+					"maxCredits": {Type: graphql.Int},
+				},
+				Resolve: s.resolveCourses,
+			},
+			"course": {
+				Type:    course,
+				Args:    graphql.FieldConfigArgument{"id": id},
+				Resolve: s.resolveCourse,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createCourse": {
+				Type:    graphql.NewNonNull(course),
+				Args:    graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(courseIn)}},
+				Resolve: s.createCourse,
+			},
+			"updateCourse": {
+				Type:    graphql.NewNonNull(course),
+				Args:    graphql.FieldConfigArgument{"id": id, "input": {Type: graphql.NewNonNull(courseIn)}},
+				Resolve: s.updateCourse,
+			},
+			"deleteCourse": {
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Soft delete a course",
+				Args:        graphql.FieldConfigArgument{"id": id},
+				Resolve:     s.deleteCourse,
+			},
+			"restoreCourse": {
+				Type:    graphql.NewNonNull(course),
+				Args:    graphql.FieldConfigArgument{"id": id},
+				Resolve: s.restoreCourse,
+			},
+			"createPerson": {
+				Type:    graphql.NewNonNull(person),
+				Args:    graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(personIn)}},
+				Resolve: s.createPerson,
+			},
+			"updatePerson": {
+				Type:    graphql.NewNonNull(person),
+				Args:    graphql.FieldConfigArgument{"firstName": firstName, "input": {Type: graphql.NewNonNull(personIn)}},
+				Resolve: s.updatePerson,
+			},
+			"deletePerson": {
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Soft delete a person",
+				Args:        graphql.FieldConfigArgument{"firstName": firstName},
+				Resolve:     s.deletePerson,
+			},
+			"restorePerson": {
+				Type:    graphql.NewNonNull(person),
+				Args:    graphql.FieldConfigArgument{"id": id},
+				Resolve: s.restorePerson,
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+	if err != nil {
+		return nil, fmt.Errorf("[in graph.NewSchema] %w", err)
+	}
+	s.schema = schema
+	return s, nil
+}
+
+func listOf(t graphql.Type) *graphql.NonNull {
+	return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t)))
+}
+
+func (s *Schema) resolvePersons(p graphql.ResolveParams) (any, error) {
+	filter := services.PersonFilter{}
+	filter.FirstName, _ = p.Args["firstName"].(string)
+	filter.LastName, _ = p.Args["lastName"].(string)
+	filter.Type, _ = p.Args["type"].(string)
+	filter.MinAge, _ = p.Args["minAge"].(int)
+	filter.MaxAge, _ = p.Args["maxAge"].(int)
+	filter.CourseID, _ = p.Args["course"].(int)
+
+	persons, err := s.svsPerson.ListPersons(p.Context, filter)
+	if err != nil {
+		return nil, s.clientError(err, "getting persons")
+	}
+	return persons, nil
+}
+
+func (s *Schema) resolvePerson(p graphql.ResolveParams) (any, error) {
+	person, err := s.svsPerson.GetPersonByFirstName(p.Context, p.Args["firstName"].(string))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, s.clientError(err, "getting person")
+	}
+	return person, nil
+}
+
+func (s *Schema) resolveCourses(p graphql.ResolveParams) (any, error) {
+	filter := services.CourseFilter{}
+	filter.Name, _ = p.Args["name"].(string)
+	filter.DepartmentID, _ = p.Args["department"].(int)
+	filter.MinCredits, _ = p.Args["minCredits"].(int)
+	filter.MaxCredits, _ = p.Args["maxCredits"].(int)
+
+	courses, err := s.svsCourse.ListCourses(p.Context, filter)
+	if err != nil {
+		return nil, s.clientError(err, "getting courses")
+	}
+	return courses, nil
+}
+
+func (s *Schema) resolveCourse(p graphql.ResolveParams) (any, error) {
+	course, err := s.svsCourse.GetCourseById(p.Context, p.Args["id"].(int))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, s.clientError(err, "getting course")
+	}
+	return course, nil
+}
+
+// resolvePersonCourses loads a person's courses in one batch with those of
+// every other person at the same level of the query.
+func (s *Schema) resolvePersonCourses(p graphql.ResolveParams) (any, error) {
+	person := p.Source.(models.Person)
+	thunk := loadersFrom(p.Context).courses.loadMany(p.Context, person.Courses)
+	return func() (any, error) {
+		courses, err := thunk()
+		if err != nil {
+			return nil, s.clientError(err, "getting courses")
+		}
+		return courses, nil
+	}, nil
+}
+
+// resolveCourseRole is resolveCoursePersons keeping the persons holding role
+// in the course.
+func (s *Schema) resolveCourseRole(role string) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		course := p.Source.(models.Course)
+		thunk := loadersFrom(p.Context).persons.load(p.Context, course.ID)
+		return func() (any, error) {
+			enrolled, _, err := thunk()
+			if err != nil {
+				return nil, s.clientError(err, "getting persons")
+			}
+			persons := make([]models.Person, 0, len(enrolled))
+			for _, person := range enrolled {
+				for _, enrollment := range person.Enrollments {
+					if enrollment.CourseID == course.ID && enrollment.Role == role {
+						persons = append(persons, person)
+						break
+					}
+				}
+			}
+			return persons, nil
+		}, nil
+	}
+}
+
+// resolveCoursePersons loads the persons enrolled in a course in one batch
+// with those of every other course at the same level of the query, keeping
+// those of personType, or everyone if it is empty.
+func (s *Schema) resolveCoursePersons(personType string) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		course := p.Source.(models.Course)
+		thunk := loadersFrom(p.Context).persons.load(p.Context, course.ID)
+		return func() (any, error) {
+			enrolled, _, err := thunk()
+			if err != nil {
+				return nil, s.clientError(err, "getting persons")
+			}
+			persons := make([]models.Person, 0, len(enrolled))
+			for _, person := range enrolled {
+				if personType == "" || person.Type == personType {
+					persons = append(persons, person)
+				}
+			}
+			return persons, nil
+		}, nil
+	}
+}
+
+func (s *Schema) createCourse(p graphql.ResolveParams) (any, error) {
+	in, err := parseCourseInput(p.Args["input"])
+	if err != nil {
+		return nil, err
+	}
+	defer loadersFrom(p.Context).clear()
+
+	course, err := s.svsCourse.CreateCourse(p.Context, in)
+	if err != nil {
+		return nil, s.clientError(err, "creating course")
+	}
+	return course, nil
+}
+
+func (s *Schema) updateCourse(p graphql.ResolveParams) (any, error) {
+	in, err := parseCourseInput(p.Args["input"])
+	if err != nil {
+		return nil, err
+	}
+	defer loadersFrom(p.Context).clear()
+
+	course, err := s.svsCourse.UpdateCourse(p.Context, p.Args["id"].(int), in)
+	if err != nil {
+		return nil, s.clientError(err, "updating course")
+	}
+	return course, nil
+}
+
+func (s *Schema) deleteCourse(p graphql.ResolveParams) (any, error) {
+	defer loadersFrom(p.Context).clear()
+
+	if err := s.svsCourse.DeleteCourse(p.Context, p.Args["id"].(int)); err != nil {
+		return nil, s.clientError(err, "deleting course")
+	}
+	return true, nil
+}
+
+func (s *Schema) restoreCourse(p graphql.ResolveParams) (any, error) {
+	defer loadersFrom(p.Context).clear()
+
+	course, err := s.svsCourse.RestoreCourse(p.Context, p.Args["id"].(int))
+	if err != nil {
+		return nil, s.clientError(err, "restoring course")
+	}
+	return course, nil
+}
+
+func (s *Schema) createPerson(p graphql.ResolveParams) (any, error) {
+	in, err := parsePersonInput(p.Args["input"])
+	if err != nil {
+		return nil, err
+	}
+	defer loadersFrom(p.Context).clear()
+
+	person, err := s.svsPerson.CreatePerson(p.Context, in)
+	if err != nil {
+		return nil, s.clientError(err, "creating person")
+	}
+	return person, nil
+}
+
+func (s *Schema) updatePerson(p graphql.ResolveParams) (any, error) {
+	in, err := parsePersonInput(p.Args["input"])
+	if err != nil {
+		return nil, err
+	}
+	defer loadersFrom(p.Context).clear()
+
+	person, err := s.svsPerson.UpdatePerson(p.Context, p.Args["firstName"].(string), in)
+	if err != nil {
+		return nil, s.clientError(err, "updating person")
+	}
+	return person, nil
+}
+
+func (s *Schema) deletePerson(p graphql.ResolveParams) (any, error) {
+	defer loadersFrom(p.Context).clear()
+
+	if err := s.svsPerson.DeletePerson(p.Context, p.Args["firstName"].(string)); err != nil {
+		return nil, s.clientError(err, "deleting person")
+	}
+	return true, nil
+}
+
+func (s *Schema) restorePerson(p graphql.ResolveParams) (any, error) {
+	defer loadersFrom(p.Context).clear()
+
+	person, err := s.svsPerson.RestorePerson(p.Context, p.Args["id"].(int))
+	if err != nil {
+		return nil, s.clientError(err, "restoring person")
+	}
+	return person, nil
+}
+
+// parseCourseInput reads a CourseInput argument and applies the REST rules.
+func parseCourseInput(arg any) (models.Course, error) {
+	fields, _ := arg.(map[string]any)
+	var in courseInput
+	in.Name, _ = fields["name"].(string)
+	if capacity, ok := fields["capacity"].(int); ok {
+		in.Capacity = &capacity
+	}
+	if credits, ok := fields["credits"].(int); ok {
+		in.Credits = &credits
+	}
+	if departmentID, ok := fields["departmentId"].(int); ok {
+		in.DepartmentID = &departmentID
+	}
+	in.CatalogNumber, _ = fields["catalogNumber"].(string)
+	in.Description, _ = fields["description"].(string)
+	problems := validation.Validate(in)
+	if in.CatalogNumber != "" && in.DepartmentID == nil {
+		problems = append(problems, validation.Problem{Name: "catalogNumber", Description: "is only allowed for courses in a department"})
+	}
+	if len(problems) > 0 {
+		return models.Course{}, &Error{Message: "invalid input", Code: CodeBadUserInput, Problems: problems}
+	}
+
+	course := models.Course{
+		Name:          in.Name,
+		Capacity:      in.Capacity,
+		DepartmentID:  in.DepartmentID,
+		CatalogNumber: in.CatalogNumber,
+		Description:   in.Description,
+	}
+	if in.Credits != nil {
+		course.Credits = *in.Credits
+	}
+	return course, nil
+}
+
+// courseField resolves a field of a course with get.
+func courseField(get func(models.Course) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return get(p.Source.(models.Course)), nil
+	}
+}
+
+// personField resolves a field of a person with get.
+func personField(get func(models.Person) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return get(p.Source.(models.Person)), nil
+	}
+}
+
+// optionalString returns nil for an empty string so it resolves to null.
+func optionalString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// parsePersonInput reads a PersonInput argument and applies the REST rules.
+func parsePersonInput(arg any) (models.Person, error) {
+	fields, _ := arg.(map[string]any)
+	var in personInput
+	in.FirstName, _ = fields["firstName"].(string)
+	in.LastName, _ = fields["lastName"].(string)
+	in.Type, _ = fields["type"].(string)
+	in.DateOfBirth, _ = fields["dateOfBirth"].(string)
+	in.Email, _ = fields["email"].(string)
+	in.Phone, _ = fields["phone"].(string)
+	in.Address, _ = fields["address"].(string)
+	courses, _ := fields["courses"].([]any)
+	for _, id := range courses {
+		courseID, _ := id.(int)
+		in.Courses = append(in.Courses, courseID)
+	}
+	problems := validation.Validate(in)
+	dateOfBirth, err := time.Parse(time.DateOnly, in.DateOfBirth)
+	if err != nil && len(problems) == 0 {
+		problems = append(problems, validation.Problem{Name: "dateOfBirth", Description: "is not a valid date"})
+	}
+	if len(problems) > 0 {
+		return models.Person{}, &Error{Message: "invalid input", Code: CodeBadUserInput, Problems: problems}
+	}
+
+	return models.Person{
+		FirstName:   in.FirstName,
+		LastName:    in.LastName,
+		Type:        in.Type,
+		DateOfBirth: dateOfBirth,
+		Email:       in.Email,
+		Phone:       in.Phone,
+		Address:     in.Address,
+		Courses:     in.Courses,
+	}, nil
+}
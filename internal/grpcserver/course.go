@@ -0,0 +1,141 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/go-chi/httplog/v2"
+	collegev1 "github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/api/college/v1"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/handlers"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+type courseServer struct {
+	collegev1.UnimplementedCourseServiceServer
+	logger    *httplog.Logger
+	svsCourse *services.CourseService
+}
+
+func (s *courseServer) ListCourses(ctx context.Context, req *collegev1.ListCoursesRequest) (*collegev1.ListCoursesResponse, error) {
+	courses, err := s.svsCourse.ListCourses(ctx, services.CourseFilter{
+		Name:         req.GetName(),
+		DepartmentID: int(req.GetDepartmentId()),
+		MinCredits:   int(req.GetMinCredits()),
+		MaxCredits:   int(req.GetMaxCredits()),
+	})
+	if err != nil {
+		return nil, statusError(s.logger, err, "getting courses")
+	}
+
+	resp := &collegev1.ListCoursesResponse{Courses: make([]*collegev1.Course, 0, len(courses))}
+	for _, course := range courses {
+		resp.Courses = append(resp.Courses, toCourse(course))
+	}
+	return resp, nil
+}
+
+func (s *courseServer) GetCourse(ctx context.Context, req *collegev1.GetCourseRequest) (*collegev1.Course, error) {
+	course, err := s.svsCourse.GetCourseById(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, statusError(s.logger, err, "getting course")
+	}
+	return toCourse(course), nil
+}
+
+func (s *courseServer) CreateCourse(ctx context.Context, req *collegev1.CreateCourseRequest) (*collegev1.Course, error) {
+	in, err := parseCourse(handlers.CourseInput{
+		Name:          req.GetName(),
+		Capacity:      optionalInt(req.Capacity),
+		Credits:       optionalInt(req.Credits),
+		DepartmentID:  optionalInt(req.DepartmentId),
+		CatalogNumber: req.GetCatalogNumber(),
+		Description:   req.GetDescription(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	course, err := s.svsCourse.CreateCourse(ctx, in)
+	if err != nil {
+		return nil, statusError(s.logger, err, "creating course")
+	}
+	return toCourse(course), nil
+}
+
+func (s *courseServer) UpdateCourse(ctx context.Context, req *collegev1.UpdateCourseRequest) (*collegev1.Course, error) {
+	in, err := parseCourse(handlers.CourseInput{
+		Name:          req.GetName(),
+		Capacity:      optionalInt(req.Capacity),
+		Credits:       optionalInt(req.Credits),
+		DepartmentID:  optionalInt(req.DepartmentId),
+		CatalogNumber: req.GetCatalogNumber(),
+		Description:   req.GetDescription(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	course, err := s.svsCourse.UpdateCourse(ctx, int(req.GetId()), in)
+	if err != nil {
+		return nil, statusError(s.logger, err, "updating course")
+	}
+	return toCourse(course), nil
+}
+
+func (s *courseServer) DeleteCourse(ctx context.Context, req *collegev1.DeleteCourseRequest) (*collegev1.DeleteCourseResponse, error) {
+	if err := s.svsCourse.DeleteCourse(ctx, int(req.GetId())); err != nil {
+		return nil, statusError(s.logger, err, "deleting course")
+	}
+	return &collegev1.DeleteCourseResponse{}, nil
+}
+
+// parseCourse applies the REST rules to a course in a request.
+func parseCourse(in handlers.CourseInput) (models.Course, error) {
+	if problems := in.Valid(); len(problems) > 0 {
+		return models.Course{}, invalidArgument(problems)
+	}
+	return in.MapTo()
+}
+
+// optionalInt converts an optional field of a request.
+func optionalInt(n *int32) *int {
+	if n == nil {
+		return nil
+	}
+	v := int(*n)
+	return &v
+}
+
+// optionalInt32 converts an optional field for a response.
+func optionalInt32(n *int) *int32 {
+	if n == nil {
+		return nil
+	}
+	v := int32(*n)
+	return &v
+}
+
+func toCourse(course models.Course) *collegev1.Course {
+	return &collegev1.Course{
+		Id:                   int32(course.ID),
+		Name:                 course.Name,
+		InstructorIds:        toIDs(course.Instructors),
+		TeachingAssistantIds: toIDs(course.TeachingAssistants),
+		StudentIds:           toIDs(course.Students),
+		AuditorIds:           toIDs(course.Auditors),
+		Capacity:             optionalInt32(course.Capacity),
+		Credits:              int32(course.Credits),
+		DepartmentId:         optionalInt32(course.DepartmentID),
+		CatalogNumber:        course.CatalogNumber,
+		Code:                 course.Code,
+		Description:          course.Description,
+	}
+}
+
+func toIDs(ids []int) []int32 {
+	out := make([]int32, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, int32(id))
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/go-chi/httplog/v2"
+	collegev1 "github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/api/college/v1"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+type enrollmentServer struct {
+	collegev1.UnimplementedEnrollmentServiceServer
+	logger    *httplog.Logger
+	svsCourse *services.CourseService
+	svsPerson *services.PersonService
+}
+
+func (s *enrollmentServer) Enroll(ctx context.Context, req *collegev1.EnrollRequest) (*collegev1.Person, error) {
+	person, err := s.svsPerson.AddEnrollment(ctx, req.GetFirstName(), int(req.GetCourseId()), fromRole(req.GetRole()))
+	if err != nil {
+		return nil, statusError(s.logger, err, "enrolling person")
+	}
+	return toPerson(person), nil
+}
+
+func (s *enrollmentServer) Unenroll(ctx context.Context, req *collegev1.UnenrollRequest) (*collegev1.Person, error) {
+	person, err := s.svsPerson.RemoveEnrollment(ctx, req.GetFirstName(), int(req.GetCourseId()))
+	if err != nil {
+		return nil, statusError(s.logger, err, "withdrawing person")
+	}
+	return toPerson(person), nil
+}
+
+// ListEnrollments reports NOT_FOUND for a course that does not exist rather
+// than an empty list.
+func (s *enrollmentServer) ListEnrollments(ctx context.Context, req *collegev1.ListEnrollmentsRequest) (*collegev1.ListEnrollmentsResponse, error) {
+	courseID := int(req.GetCourseId())
+	if _, err := s.svsCourse.GetCourseById(ctx, courseID); err != nil {
+		return nil, statusError(s.logger, err, "getting course")
+	}
+
+	persons, err := s.svsPerson.ListPersonsByCourses(ctx, []int{courseID})
+	if err != nil {
+		return nil, statusError(s.logger, err, "getting enrollments")
+	}
+	return &collegev1.ListEnrollmentsResponse{Persons: toPersons(persons[courseID])}, nil
+}
+
+func toRole(role string) collegev1.Role {
+	switch role {
+	case models.RoleInstructor:
+		return collegev1.Role_ROLE_INSTRUCTOR
+	case models.RoleTeachingAssistant:
+		return collegev1.Role_ROLE_TEACHING_ASSISTANT
+	case models.RoleStudent:
+		return collegev1.Role_ROLE_STUDENT
+	case models.RoleAuditor:
+		return collegev1.Role_ROLE_AUDITOR
+	default:
+		return collegev1.Role_ROLE_UNSPECIFIED
+	}
+}
+
+// fromRole returns "" for an unspecified role, which the services treat as
+// the default role for the person's type.
+func fromRole(role collegev1.Role) string {
+	switch role {
+	case collegev1.Role_ROLE_INSTRUCTOR:
+		return models.RoleInstructor
+	case collegev1.Role_ROLE_TEACHING_ASSISTANT:
+		return models.RoleTeachingAssistant
+	case collegev1.Role_ROLE_STUDENT:
+		return models.RoleStudent
+	case collegev1.Role_ROLE_AUDITOR:
+		return models.RoleAuditor
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,47 @@
+package grpcserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError maps a service error to a gRPC status. Validation problems are
+// attached as BadRequest field violations. Unexpected errors are logged and
+// hidden behind a generic message, as the REST handlers do.
+func statusError(logger *httplog.Logger, err error, action string) error {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return invalidArgument(validationErr.Problems)
+	case errors.Is(err, sql.ErrNoRows):
+		return status.Error(codes.NotFound, "not found")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "canceled")
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	default:
+		logger.Error("error "+action, "error", err)
+		return status.Error(codes.Internal, "Error "+action)
+	}
+}
+
+// invalidArgument reports problems with the request fields.
+func invalidArgument(problems []validation.Problem) error {
+	st := status.New(codes.InvalidArgument, "invalid request")
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(problems))
+	for _, p := range problems {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: p.Name, Description: p.Description})
+	}
+	if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
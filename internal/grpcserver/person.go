@@ -0,0 +1,158 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-chi/httplog/v2"
+	collegev1 "github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/api/college/v1"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/handlers"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+type personServer struct {
+	collegev1.UnimplementedPersonServiceServer
+	logger    *httplog.Logger
+	svsPerson *services.PersonService
+}
+
+func (s *personServer) ListPersons(ctx context.Context, req *collegev1.ListPersonsRequest) (*collegev1.ListPersonsResponse, error) {
+	persons, err := s.svsPerson.ListPersons(ctx, services.PersonFilter{
+		FirstName: req.GetFirstName(),
+		LastName:  req.GetLastName(),
+		Type:      fromPersonType(req.GetType()),
+		MinAge:    int(req.GetMinAge()),
+		MaxAge:    int(req.GetMaxAge()),
+		CourseID:  int(req.GetCourseId()),
+	})
+	if err != nil {
+		return nil, statusError(s.logger, err, "getting persons")
+	}
+	return &collegev1.ListPersonsResponse{Persons: toPersons(persons)}, nil
+}
+
+func (s *personServer) GetPerson(ctx context.Context, req *collegev1.GetPersonRequest) (*collegev1.Person, error) {
+	person, err := s.svsPerson.GetPersonByFirstName(ctx, req.GetFirstName())
+	if err != nil {
+		return nil, statusError(s.logger, err, "getting person")
+	}
+	return toPerson(person), nil
+}
+
+func (s *personServer) CreatePerson(ctx context.Context, req *collegev1.CreatePersonRequest) (*collegev1.Person, error) {
+	in, err := parsePerson(req)
+	if err != nil {
+		return nil, err
+	}
+
+	person, err := s.svsPerson.CreatePerson(ctx, in)
+	if err != nil {
+		return nil, statusError(s.logger, err, "creating person")
+	}
+	return toPerson(person), nil
+}
+
+func (s *personServer) UpdatePerson(ctx context.Context, req *collegev1.UpdatePersonRequest) (*collegev1.Person, error) {
+	in, err := parsePerson(req.GetPerson())
+	if err != nil {
+		return nil, err
+	}
+
+	person, err := s.svsPerson.UpdatePerson(ctx, req.GetFirstName(), in)
+	if err != nil {
+		return nil, statusError(s.logger, err, "updating person")
+	}
+	return toPerson(person), nil
+}
+
+func (s *personServer) DeletePerson(ctx context.Context, req *collegev1.DeletePersonRequest) (*collegev1.DeletePersonResponse, error) {
+	if err := s.svsPerson.DeletePerson(ctx, req.GetFirstName()); err != nil {
+		return nil, statusError(s.logger, err, "deleting person")
+	}
+	return &collegev1.DeletePersonResponse{}, nil
+}
+
+// parsePerson applies the REST rules to a person in a request.
+func parsePerson(req *collegev1.CreatePersonRequest) (models.Person, error) {
+	in := handlers.PersonInput{
+		FirstName:   req.GetFirstName(),
+		LastName:    req.GetLastName(),
+		Type:        fromPersonType(req.GetType()),
+		DateOfBirth: req.GetDateOfBirth(),
+		Email:       req.GetEmail(),
+		Phone:       req.GetPhone(),
+		Address:     req.GetAddress(),
+	}
+	for _, id := range req.GetCourseIds() {
+		in.Courses = append(in.Courses, int(id))
+	}
+	for _, enrollment := range req.GetEnrollments() {
+		role := fromRole(enrollment.GetRole())
+		if role == "" {
+			role = models.DefaultRole(in.Type)
+		}
+		in.Enrollments = append(in.Enrollments, handlers.CourseRoleInput{CourseID: int(enrollment.GetCourseId()), Role: role})
+	}
+	if problems := in.Valid(); len(problems) > 0 {
+		return models.Person{}, invalidArgument(problems)
+	}
+	return in.MapTo()
+}
+
+func toPerson(person models.Person) *collegev1.Person {
+	out := &collegev1.Person{
+		Id:          int32(person.ID),
+		FirstName:   person.FirstName,
+		LastName:    person.LastName,
+		Type:        toPersonType(person.Type),
+		Age:         int32(person.Age),
+		DateOfBirth: person.DateOfBirth.Format(time.DateOnly),
+		Email:       person.Email,
+		Phone:       person.Phone,
+		Address:     person.Address,
+	}
+	for _, id := range person.Courses {
+		out.CourseIds = append(out.CourseIds, int32(id))
+	}
+	for _, enrollment := range person.Enrollments {
+		out.Enrollments = append(out.Enrollments, &collegev1.Enrollment{
+			CourseId:  int32(enrollment.CourseID),
+			Role:      toRole(enrollment.Role),
+			SectionId: int32(enrollment.SectionID),
+		})
+	}
+	return out
+}
+
+func toPersons(persons []models.Person) []*collegev1.Person {
+	out := make([]*collegev1.Person, 0, len(persons))
+	for _, person := range persons {
+		out = append(out, toPerson(person))
+	}
+	return out
+}
+
+func toPersonType(t string) collegev1.PersonType {
+	switch t {
+	case "student":
+		return collegev1.PersonType_PERSON_TYPE_STUDENT
+	case "professor":
+		return collegev1.PersonType_PERSON_TYPE_PROFESSOR
+	default:
+		return collegev1.PersonType_PERSON_TYPE_UNSPECIFIED
+	}
+}
+
+// fromPersonType returns "" for PERSON_TYPE_UNSPECIFIED, which filters
+// ignore and validation rejects.
+func fromPersonType(t collegev1.PersonType) string {
+	switch t {
+	case collegev1.PersonType_PERSON_TYPE_STUDENT:
+		return "student"
+	case collegev1.PersonType_PERSON_TYPE_PROFESSOR:
+		return "professor"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,105 @@
+// Package grpcserver serves the college.v1 gRPC API (see api/college/v1) from
+// the same services as the REST API, together with the standard health and
+// reflection services.
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-chi/httplog/v2"
+	collegev1 "github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/api/college/v1"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys read from each call for the audit log, the gRPC equivalents
+// of the X-Actor and X-Request-Id headers.
+const (
+	ActorKey     = "x-actor"
+	RequestIDKey = "x-request-id"
+)
+
+// Server is a gRPC server for the college.v1 services.
+type Server struct {
+	grpc   *grpc.Server
+	health *health.Server
+	logger *httplog.Logger
+}
+
+// New registers the college.v1, health and reflection services on a new
+// server.
+func New(logger *httplog.Logger, svsCourse *services.CourseService, svsPerson *services.PersonService) *Server {
+	s := &Server{
+		grpc:   grpc.NewServer(grpc.ChainUnaryInterceptor(logCalls(logger), auditContext)),
+		health: health.NewServer(),
+		logger: logger,
+	}
+
+	collegev1.RegisterCourseServiceServer(s.grpc, &courseServer{logger: logger, svsCourse: svsCourse})
+	collegev1.RegisterPersonServiceServer(s.grpc, &personServer{logger: logger, svsPerson: svsPerson})
+	collegev1.RegisterEnrollmentServiceServer(s.grpc, &enrollmentServer{logger: logger, svsCourse: svsCourse, svsPerson: svsPerson})
+	healthpb.RegisterHealthServer(s.grpc, s.health)
+	reflection.Register(s.grpc)
+
+	for name := range s.grpc.GetServiceInfo() {
+		s.health.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	return s
+}
+
+// Serve accepts connections on lis until Shutdown is called.
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpc.Serve(lis)
+}
+
+// Shutdown reports NOT_SERVING to health checks and waits for calls in
+// flight to finish, closing them forcibly once ctx is done.
+func (s *Server) Shutdown(ctx context.Context) {
+	s.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.grpc.Stop()
+	}
+}
+
+// auditContext attaches the caller named in the call metadata to the context,
+// as handlers.AuditContext does for HTTP requests.
+func auditContext(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = services.WithAuditInfo(ctx, first(md.Get(ActorKey)), first(md.Get(RequestIDKey)))
+	return handler(ctx, req)
+}
+
+// logCalls logs each call with its status code and duration.
+func logCalls(logger *httplog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		if !strings.HasPrefix(info.FullMethod, "/grpc.") {
+			logger.Info("gRPC call", "method", info.FullMethod, "code", status.Code(err).String(), "duration", time.Since(start))
+		}
+		return resp, err
+	}
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
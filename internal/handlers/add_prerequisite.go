@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleAddPrerequisite makes a course, by its ID, require another
+func HandleAddPrerequisite(logger *httplog.Logger, svsCourse *services.CourseService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		courseID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid course ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid course ID",
+			})
+			return
+		}
+
+		prerequisiteID, problems, err := decodeValidateBody[inputPrerequisite](r)
+		if err != nil {
+			switch {
+			case errors.Is(err, errUnsupportedMediaType):
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusUnsupportedMediaType, responseErr{
+					Error: "unsupported media type",
+				})
+			case len(problems) > 0:
+				logger.Error("Problems validating input", "error", err, "problems", problems)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					ValidationErrors: problems,
+				})
+			default:
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					Error: "missing values or malformed body",
+				})
+			}
+			return
+		}
+
+		prerequisites, err := svsCourse.AddPrerequisite(ctx, courseID, prerequisiteID)
+		if err != nil {
+			var validationErr *services.ValidationError
+			switch {
+			case errors.As(err, &validationErr):
+				logger.Error("Problems adding prerequisite", "error", err, "problems", validationErr.Problems)
+				encodeResponse(w, r, logger, http.StatusUnprocessableEntity, responseErr{
+					ValidationErrors: validationErr.Problems,
+				})
+			case errors.Is(err, sql.ErrNoRows):
+				logger.Error("error adding prerequisite", "error", err)
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No course with that ID",
+				})
+			default:
+				logger.Error("error adding prerequisite", "error", err)
+				encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+					Error: "Error adding prerequisite",
+				})
+			}
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responsePrerequisites{Prerequisites: mapOutputPrerequisites(prerequisites)})
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// AdminTokenHeader carries the shared admin token configured with
+// ADMIN_TOKEN. Admins may see soft deleted records and enroll persons
+// without the prerequisites of a course or against the eligibility rules.
+const AdminTokenHeader = "X-Admin-Token"
+
+type adminKey struct{}
+
+// AdminContext marks requests carrying the admin token as made by an admin.
+// With an empty token nobody is an admin.
+func AdminContext(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			given := r.Header.Get(AdminTokenHeader)
+			if token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1 {
+				r = r.WithContext(context.WithValue(r.Context(), adminKey{}, true))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func isAdmin(r *http.Request) bool {
+	admin, _ := r.Context().Value(adminKey{}).(bool)
+	return admin
+}
+
+// RequireAdmin responds 403 to requests without the admin token.
+func RequireAdmin(logger *httplog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isAdmin(r) {
+				logger.Error("admin route requested without admin token", "path", r.URL.Path)
+				encodeResponse(w, r, logger, http.StatusForbidden, responseErr{
+					Error: "this route requires the " + AdminTokenHeader + " header",
+				})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// allowIncludeDeleted writes a 403 and returns false if soft deleted records
+// were requested by someone who is not an admin.
+func allowIncludeDeleted(w http.ResponseWriter, r *http.Request, logger *httplog.Logger, includeDeleted bool) bool {
+	if !includeDeleted || isAdmin(r) {
+		return true
+	}
+	logger.Error("include_deleted requested without admin token")
+	encodeResponse(w, r, logger, http.StatusForbidden, responseErr{
+		Error: "include_deleted requires the " + AdminTokenHeader + " header",
+	})
+	return false
+}
+
+// enrollmentOverrides reads the override_prerequisites and override_rules
+// query parameters and returns the request context, marked to skip
+// prerequisite checks and eligibility rules as asked. It writes a 400 or 403
+// and returns false if a parameter is invalid or was given by someone who is
+// not an admin.
+func enrollmentOverrides(w http.ResponseWriter, r *http.Request, logger *httplog.Logger) (context.Context, bool) {
+	ctx := r.Context()
+	overrides := []struct {
+		param string
+		apply func(context.Context) context.Context
+	}{
+		{"override_prerequisites", services.WithPrerequisiteOverride},
+		{"override_rules", services.WithRuleOverride},
+	}
+	for _, o := range overrides {
+		override, problems := parseBoolParam(r.URL.Query().Get(o.param), o.param, nil)
+		if len(problems) > 0 {
+			logger.Error("Problems validating "+o.param, "problems", problems)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				ValidationErrors: problems,
+			})
+			return nil, false
+		}
+		if !override {
+			continue
+		}
+		if !isAdmin(r) {
+			logger.Error(o.param + " requested without admin token")
+			encodeResponse(w, r, logger, http.StatusForbidden, responseErr{
+				Error: o.param + " requires the " + AdminTokenHeader + " header",
+			})
+			return nil, false
+		}
+		ctx = o.apply(ctx)
+	}
+	return ctx, true
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleAmendGrade changes the grade of a person in a section by the
+// section's ID and the person's first name. The caller must name a professor
+// teaching the section in the X-Actor header. That header is not
+// authenticated, so the route is mounted behind RequireAdmin.
+func HandleAmendGrade(logger *httplog.Logger, svsGrade *services.GradeService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		sectionID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid section ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid section ID",
+			})
+			return
+		}
+		firstName := chi.URLParam(r, "firstName")
+
+		grade, problems, err := decodeValidateBody[inputGradeAmendment](r)
+		if err != nil {
+			switch {
+			case errors.Is(err, errUnsupportedMediaType):
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusUnsupportedMediaType, responseErr{
+					Error: "unsupported media type",
+				})
+			case len(problems) > 0:
+				logger.Error("Problems validating input", "error", err, "problems", problems)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					ValidationErrors: problems,
+				})
+			default:
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					Error: "missing values or malformed body",
+				})
+			}
+			return
+		}
+
+		amended, err := svsGrade.AmendGrade(ctx, sectionID, firstName, grade)
+		if err != nil {
+			var validationErr *services.ValidationError
+			switch {
+			case errors.As(err, &validationErr):
+				logger.Error("Problems amending grade", "error", err, "problems", validationErr.Problems)
+				encodeResponse(w, r, logger, http.StatusUnprocessableEntity, responseErr{
+					ValidationErrors: validationErr.Problems,
+				})
+			case errors.Is(err, services.ErrForbidden):
+				logger.Error("error amending grade", "error", err)
+				encodeResponse(w, r, logger, http.StatusForbidden, responseErr{
+					Error: "grades can only be amended by a professor teaching the section, named in the " + ActorHeader + " header",
+				})
+			case errors.Is(err, sql.ErrNoRows):
+				logger.Error("error amending grade", "error", err)
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No section with that ID or grade of a person with that first name in it",
+				})
+			default:
+				logger.Error("error amending grade", "error", err)
+				encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+					Error: "Error amending grade",
+				})
+			}
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseGrade{Grade: mapOutputGrade(amended)})
+	}
+}
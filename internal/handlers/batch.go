@@ -0,0 +1,391 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// MaxBatchOperations caps the number of operations in one batch.
+const MaxBatchOperations = 100
+
+// HandleBatch runs a list of operations on persons, courses and enrollments
+// in one transaction. Either every operation is applied, and each one's result
+// is returned in order, or none is: the response then carries the status and
+// error of the failing operation, and every other operation is reported with
+// 424 Failed Dependency.
+//
+// Any string in an operation's target or body of the form "$ops[N].path"
+// is replaced by the value at path in the result of the earlier operation N,
+// e.g. "$ops[0].id" or "$ops[1].courses[0]".
+func HandleBatch(logger *httplog.Logger, svsBatch *services.BatchService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := enrollmentOverrides(w, r, logger)
+		if !ok {
+			return
+		}
+
+		ops, problems, err := decodeValidateBody[inputBatch, []inputBatchOperation](r)
+		if err != nil {
+			switch {
+			case errors.Is(err, errUnsupportedMediaType):
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusUnsupportedMediaType, responseErr{
+					Error: "unsupported media type",
+				})
+			case len(problems) > 0:
+				logger.Error("Problems validating input", "error", err, "problems", problems)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					ValidationErrors: problems,
+				})
+			default:
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					Error: "missing values or malformed body",
+				})
+			}
+			return
+		}
+
+		results := make([]outputBatchResult, len(ops))
+		failed := -1
+		err = svsBatch.RunBatch(ctx, func(b *services.Batch) error {
+			values := make([]any, 0, len(ops))
+			for i, op := range ops {
+				result, err := runBatchOperation(ctx, b, op, values)
+				if err != nil {
+					failed = i
+					return err
+				}
+				result.Index = i
+				results[i] = result
+
+				value, err := jsonValue(result.Data)
+				if err != nil {
+					failed = i
+					return err
+				}
+				values = append(values, value)
+			}
+			return nil
+		})
+		if err == nil {
+			encodeResponse(w, r, logger, http.StatusOK, responseBatch{Results: results})
+			return
+		}
+		if failed < 0 {
+			logger.Error("error running batch", "error", err)
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error running batch",
+			})
+			return
+		}
+
+		failure := batchFailure(err, fmt.Sprintf("operations[%d]", failed))
+		if failure.Status == http.StatusInternalServerError {
+			logger.Error("error running batch operation", "error", err, "operation", failed)
+		} else {
+			logger.Error("batch operation failed", "error", err, "operation", failed, "problems", failure.ValidationErrors)
+		}
+		for i := range results {
+			results[i] = outputBatchResult{Index: i, Status: http.StatusFailedDependency}
+		}
+		results[failed] = failure
+		results[failed].Index = failed
+
+		encodeResponse(w, r, logger, failure.Status, responseBatchErr{
+			Error:            fmt.Sprintf("operation %d failed: %s; no operations were applied", failed, failure.Error),
+			ValidationErrors: failure.ValidationErrors,
+			Results:          results,
+		})
+	}
+}
+
+// batchOperationError rejects an operation before it reaches the services.
+type batchOperationError struct {
+	status   int
+	message  string
+	problems []problem
+}
+
+func (e *batchOperationError) Error() string {
+	return e.message
+}
+
+// batchFailure describes the error of a failed operation, naming its
+// problems after the operation at prefix.
+func batchFailure(err error, prefix string) outputBatchResult {
+	var (
+		opErr         *batchOperationError
+		validationErr *services.ValidationError
+		failure       outputBatchResult
+	)
+	switch {
+	case errors.As(err, &opErr):
+		failure = outputBatchResult{Status: opErr.status, Error: opErr.message, ValidationErrors: opErr.problems}
+	case errors.As(err, &validationErr):
+		failure = outputBatchResult{Status: http.StatusUnprocessableEntity, Error: "validation failed", ValidationErrors: validationErr.Problems}
+		for i := range failure.ValidationErrors {
+			failure.ValidationErrors[i].Name = "body." + failure.ValidationErrors[i].Name
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		failure = outputBatchResult{Status: http.StatusNotFound, Error: "target not found"}
+	default:
+		failure = outputBatchResult{Status: http.StatusInternalServerError, Error: "internal error"}
+	}
+
+	problems := make([]problem, 0, len(failure.ValidationErrors))
+	for _, p := range failure.ValidationErrors {
+		p.Name = prefix + "." + p.Name
+		problems = append(problems, p)
+	}
+	failure.ValidationErrors = problems
+	return failure
+}
+
+// runBatchOperation resolves the references in op against the results of the
+// earlier operations and applies it.
+func runBatchOperation(ctx context.Context, b *services.Batch, op inputBatchOperation, values []any) (outputBatchResult, error) {
+	target, err := jsonValue(op.Target)
+	if err == nil {
+		target, err = resolveReferences(target, "target", values)
+	}
+	if err != nil {
+		return outputBatchResult{}, err
+	}
+	body, err := jsonValue(op.Body)
+	if err == nil {
+		body, err = resolveReferences(body, "body", values)
+	}
+	if err != nil {
+		return outputBatchResult{}, err
+	}
+
+	switch op.Entity + " " + op.Op {
+	case "course create":
+		in, err := decodeBatchBody[inputCourse](body)
+		if err != nil {
+			return outputBatchResult{}, err
+		}
+		course, _ := in.MapTo()
+		course, err = b.CreateCourse(ctx, course)
+		return outputBatchResult{Status: http.StatusCreated, Data: mapOutputCourse(course)}, err
+	case "course update":
+		id, err := batchCourseID(target)
+		if err != nil {
+			return outputBatchResult{}, err
+		}
+		in, err := decodeBatchBody[inputCourse](body)
+		if err != nil {
+			return outputBatchResult{}, err
+		}
+		course, _ := in.MapTo()
+		course, err = b.UpdateCourse(ctx, id, course)
+		return outputBatchResult{Status: http.StatusOK, Data: mapOutputCourse(course)}, err
+	case "course delete":
+		id, err := batchCourseID(target)
+		if err != nil {
+			return outputBatchResult{}, err
+		}
+		return outputBatchResult{Status: http.StatusOK}, b.DeleteCourse(ctx, id)
+	case "person create":
+		in, err := decodeBatchBody[inputPerson](body)
+		if err != nil {
+			return outputBatchResult{}, err
+		}
+		person, _ := in.MapTo()
+		person, err = b.CreatePerson(ctx, person)
+		return outputBatchResult{Status: http.StatusCreated, Data: mapOutputPerson(person)}, err
+	case "person update":
+		firstName, err := batchFirstName(target)
+		if err != nil {
+			return outputBatchResult{}, err
+		}
+		in, err := decodeBatchBody[inputPerson](body)
+		if err != nil {
+			return outputBatchResult{}, err
+		}
+		person, _ := in.MapTo()
+		person, err = b.UpdatePerson(ctx, firstName, person)
+		return outputBatchResult{Status: http.StatusOK, Data: mapOutputPerson(person)}, err
+	case "person delete":
+		firstName, err := batchFirstName(target)
+		if err != nil {
+			return outputBatchResult{}, err
+		}
+		return outputBatchResult{Status: http.StatusOK}, b.DeletePerson(ctx, firstName)
+	case "enrollment create":
+		in, err := decodeBatchBody[inputEnrollment](body)
+		if err != nil {
+			return outputBatchResult{}, err
+		}
+		person, err := b.AddEnrollment(ctx, in.FirstName, in.CourseID, in.Role)
+		return outputBatchResult{Status: http.StatusCreated, Data: mapOutputPerson(person)}, err
+	case "enrollment delete":
+		in, err := decodeBatchBody[inputEnrollment](body)
+		if err != nil {
+			return outputBatchResult{}, err
+		}
+		person, err := b.RemoveEnrollment(ctx, in.FirstName, in.CourseID)
+		return outputBatchResult{Status: http.StatusOK, Data: mapOutputPerson(person)}, err
+	}
+	// inputBatchOperation.ValidateStruct rejects every other combination.
+	return outputBatchResult{}, &batchOperationError{
+		status:  http.StatusBadRequest,
+		message: fmt.Sprintf("cannot %s %s", op.Op, op.Entity),
+	}
+}
+
+// decodeBatchBody decodes and validates the body of an operation the same way
+// the equivalent REST route decodes its request body.
+func decodeBatchBody[I Validator](body any) (I, error) {
+	var in I
+	raw, err := json.Marshal(body)
+	if err == nil {
+		err = decoders[0].decode(bytes.NewReader(raw), &in)
+	}
+	if err != nil {
+		return in, &batchOperationError{status: http.StatusBadRequest, message: "malformed body: " + err.Error()}
+	}
+
+	if problems := in.Valid(); len(problems) > 0 {
+		for i := range problems {
+			problems[i].Name = "body." + problems[i].Name
+		}
+		return in, &batchOperationError{status: http.StatusBadRequest, message: "invalid body", problems: problems}
+	}
+	return in, nil
+}
+
+// batchCourseID reads a course id target, given as a number or a numeric
+// string.
+func batchCourseID(target any) (int, error) {
+	var id int
+	switch t := target.(type) {
+	case float64:
+		if t == float64(int(t)) {
+			id = int(t)
+		}
+	case string:
+		id, _ = strconv.Atoi(t)
+	}
+	if id < 1 {
+		return 0, &batchOperationError{status: http.StatusBadRequest, message: "invalid target", problems: []problem{{
+			Name:        "target",
+			Description: "must be a course id",
+		}}}
+	}
+	return id, nil
+}
+
+// batchFirstName reads a person's first name target.
+func batchFirstName(target any) (string, error) {
+	firstName, _ := target.(string)
+	if firstName == "" {
+		return "", &batchOperationError{status: http.StatusBadRequest, message: "invalid target", problems: []problem{{
+			Name:        "target",
+			Description: "must be a person's first name",
+		}}}
+	}
+	return firstName, nil
+}
+
+// referencePattern matches a whole "$ops[N].path" reference.
+var referencePattern = regexp.MustCompile(`^\$ops\[(\d+)\]((?:\.[A-Za-z_][A-Za-z0-9_]*|\[\d+\])+)$`)
+
+// pathSegmentPattern splits the path of a reference into its segments.
+var pathSegmentPattern = regexp.MustCompile(`\.([A-Za-z_][A-Za-z0-9_]*)|\[(\d+)\]`)
+
+// resolveReferences returns a copy of v with every reference string replaced
+// by the value it points to in values, the JSON form of the earlier results.
+// name is the location of v reported in problems.
+func resolveReferences(v any, name string, values []any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		m := referencePattern.FindStringSubmatch(v)
+		if m == nil {
+			return v, nil
+		}
+		index, err := strconv.Atoi(m[1])
+		if err != nil || index >= len(values) {
+			return nil, invalidReference(name, fmt.Sprintf("refers to operation %s, which does not run before this one", m[1]))
+		}
+		value := values[index]
+		for _, seg := range pathSegmentPattern.FindAllStringSubmatch(m[2], -1) {
+			var ok bool
+			if seg[1] != "" {
+				var obj map[string]any
+				if obj, ok = value.(map[string]any); ok {
+					value, ok = obj[seg[1]]
+				}
+			} else {
+				var arr []any
+				i, _ := strconv.Atoi(seg[2])
+				if arr, ok = value.([]any); ok && i < len(arr) {
+					value = arr[i]
+				} else {
+					ok = false
+				}
+			}
+			if !ok {
+				return nil, invalidReference(name, fmt.Sprintf("refers to %s, which is not in the result of operation %d", v, index))
+			}
+		}
+		return value, nil
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, elem := range v {
+			resolved, err := resolveReferences(elem, name+"."+key, values)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = resolved
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, elem := range v {
+			resolved, err := resolveReferences(elem, fmt.Sprintf("%s[%d]", name, i), values)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+func invalidReference(name, description string) error {
+	return &batchOperationError{status: http.StatusBadRequest, message: "invalid reference", problems: []problem{{
+		Name:        name,
+		Description: description,
+	}}}
+}
+
+// jsonValue converts v to the generic form encoding/json decodes into, so
+// results can be referred to by their JSON field names and MessagePack bodies
+// resolve like JSON ones.
+func jsonValue(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %T: %w", v, err)
+	}
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode %T: %w", v, err)
+	}
+	return value, nil
+}
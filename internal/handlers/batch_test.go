@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+func TestBatchFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want outputBatchResult
+	}{
+		{
+			"validation error",
+			fmt.Errorf("[in services.AddEnrollment] %w", &services.ValidationError{Problems: []problem{{Name: "course_id", Description: "section 3 is full"}}}),
+			outputBatchResult{Status: http.StatusUnprocessableEntity, Error: "validation failed", ValidationErrors: []problem{
+				{Name: "operations[2].body.course_id", Description: "section 3 is full"},
+			}},
+		},
+		{
+			"operation error",
+			&batchOperationError{status: http.StatusBadRequest, message: "bad reference", problems: []problem{{Name: "target", Description: "is not an id"}}},
+			outputBatchResult{Status: http.StatusBadRequest, Error: "bad reference", ValidationErrors: []problem{
+				{Name: "operations[2].target", Description: "is not an id"},
+			}},
+		},
+		{
+			"not found",
+			fmt.Errorf("[in services.UpdatePerson] %w", sql.ErrNoRows),
+			outputBatchResult{Status: http.StatusNotFound, Error: "target not found", ValidationErrors: []problem{}},
+		},
+		{
+			"other error",
+			errors.New("connection reset"),
+			outputBatchResult{Status: http.StatusInternalServerError, Error: "internal error", ValidationErrors: []problem{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := batchFailure(tt.err, "operations[2]"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("batchFailure() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
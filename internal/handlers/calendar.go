@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/ical"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+)
+
+// calendarMediaType is the media type of iCalendar feeds.
+const calendarMediaType = "text/calendar"
+
+// calendarProdID identifies this API as the producer of its feeds.
+const calendarProdID = "-//Go API Tech Challenge//Schedules//EN"
+
+// weekdays maps the days of meetings to those of calendar events.
+var weekdays = map[string]time.Weekday{
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+	"sun": time.Sunday,
+}
+
+// calendarFeed is implemented by responses that can be rendered as an
+// iCalendar feed.
+type calendarFeed interface {
+	calendar() ical.Calendar
+}
+
+func isCalendarFeed(data any) bool {
+	_, ok := data.(calendarFeed)
+	return ok
+}
+
+// calendar makes an event of each meeting, recurring weekly from its first
+// day in the term to the last day of the term. Events are identified by
+// section and meeting so calendar apps replace them when a section changes.
+func (resp responseCalendar) calendar() ical.Calendar {
+	cal := ical.Calendar{ProdID: calendarProdID, Name: resp.Calendar.Name, Events: []ical.Event{}}
+	for _, entry := range resp.Calendar.Entries {
+		days := make([]time.Weekday, 0, len(entry.Meeting.Days))
+		for _, day := range entry.Meeting.Days {
+			days = append(days, weekdays[day])
+		}
+		first := time.Date(entry.TermStart.Year(), entry.TermStart.Month(), entry.TermStart.Day(), 0, 0, 0, 0, time.UTC)
+		last := time.Date(entry.TermEnd.Year(), entry.TermEnd.Month(), entry.TermEnd.Day(), 0, 0, 0, 0, time.UTC)
+		for len(days) > 0 && !slices.Contains(days, first.Weekday()) {
+			first = first.AddDate(0, 0, 1)
+		}
+		if len(days) == 0 || first.After(last) {
+			continue
+		}
+		start, err := time.Parse("15:04", entry.Meeting.StartTime)
+		if err != nil {
+			continue
+		}
+		end, err := time.Parse("15:04", entry.Meeting.EndTime)
+		if err != nil {
+			continue
+		}
+
+		event := ical.Event{
+			UID:      fmt.Sprintf("section-%d-meeting-%d@go-api-tech-challenge", entry.SectionID, entry.MeetingID),
+			Summary:  fmt.Sprintf("%s (section %d)", entry.CourseName, entry.SectionNumber),
+			Location: entry.RoomName,
+			Start:    first.Add(time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute),
+			End:      first.Add(time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute),
+			Days:     days,
+			Until:    last,
+			Stamp:    resp.Stamp,
+		}
+		if entry.Role != "" {
+			event.Description = "Role: " + entry.Role
+		}
+		cal.Events = append(cal.Events, event)
+	}
+	return cal
+}
+
+// writeCalendar sends a feed as text/calendar whatever the Accept header, as
+// calendar apps do not reliably ask for it.
+func writeCalendar(w http.ResponseWriter, logger *httplog.Logger, cal models.Calendar) {
+	for _, enc := range encoders {
+		if enc.mediaType == calendarMediaType {
+			writeEncoded(w, logger, enc, http.StatusOK, responseCalendar{Calendar: cal, Stamp: time.Now()})
+			return
+		}
+	}
+}
+
+// subscriptionURL is the absolute URL of the feed at path with token, on the
+// host the request was made to.
+func subscriptionURL(r *http.Request, path, token string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host + path + "?token=" + token
+}
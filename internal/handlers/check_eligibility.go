@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleCheckEligibility reports whether a person could be enrolled in a
+// course, without enrolling them
+func HandleCheckEligibility(logger *httplog.Logger, svsPerson *services.PersonService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := enrollmentOverrides(w, r, logger)
+		if !ok {
+			return
+		}
+		firstName := chi.URLParam(r, "firstName")
+
+		eligibility, problems, err := decodeValidateBody[inputEligibility](r)
+		if err != nil {
+			switch {
+			case errors.Is(err, errUnsupportedMediaType):
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusUnsupportedMediaType, responseErr{
+					Error: "unsupported media type",
+				})
+			case len(problems) > 0:
+				logger.Error("Problems validating input", "error", err, "problems", problems)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					ValidationErrors: problems,
+				})
+			default:
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					Error: "missing values or malformed body",
+				})
+			}
+			return
+		}
+
+		problems, err = svsPerson.CheckEligibility(ctx, firstName, eligibility.CourseID, eligibility.Role)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logger.Error("error checking eligibility", "error", err)
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No person with that first name",
+				})
+				return
+			}
+			logger.Error("error checking eligibility", "error", err)
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error checking eligibility",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseEligibility{Eligibility: outputEligibility{
+			Allowed:  len(problems) == 0,
+			Problems: problems,
+		}})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
 	"net/http"
 
@@ -14,29 +15,42 @@ func HandleCreateCourse(logger *httplog.Logger, svsCourse *services.CourseServic
 		courseIn, problems, err := decodeValidateBody[inputCourse](r)
 		if err != nil {
 			switch {
+			case errors.Is(err, errUnsupportedMediaType):
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusUnsupportedMediaType, responseErr{
+					Error: "unsupported media type",
+				})
 			case len(problems) > 0:
 				logger.Error("Problems validating input", "error", err, "problems", problems)
-				encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 					ValidationErrors: problems,
 				})
 			default:
 				logger.Error("BodyParser error", "error", err)
-				encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 					Error: "missing values or malformed body",
 				})
 			}
 			return
 		}
 
-		course, err := svsCourse.CreateCourse(ctx, courseIn.Name)
+		course, err := svsCourse.CreateCourse(ctx, courseIn)
 		if err != nil {
+			var validationErr *services.ValidationError
+			if errors.As(err, &validationErr) {
+				logger.Error("Problems creating course", "error", err, "problems", validationErr.Problems)
+				encodeResponse(w, r, logger, http.StatusUnprocessableEntity, responseErr{
+					ValidationErrors: validationErr.Problems,
+				})
+				return
+			}
 			logger.Error("error creating course", "error", err)
-			encodeResponse(w, logger, http.StatusInternalServerError, responseErr{
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
 				Error: "Error creating course",
 			})
 			return
 		}
 
-		encodeResponse(w, logger, http.StatusCreated, responseCourse{Course: mapOutputCourse(course)})
+		encodeResponse(w, r, logger, http.StatusCreated, responseCourse{Course: mapOutputCourse(course)})
 	}
 }
\ No newline at end of file
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleCreateDepartment creates a new department
+func HandleCreateDepartment(logger *httplog.Logger, svsDepartment *services.DepartmentService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		departmentIn, problems, err := decodeValidateBody[inputDepartment](r)
+		if err != nil {
+			switch {
+			case errors.Is(err, errUnsupportedMediaType):
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusUnsupportedMediaType, responseErr{
+					Error: "unsupported media type",
+				})
+			case len(problems) > 0:
+				logger.Error("Problems validating input", "error", err, "problems", problems)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					ValidationErrors: problems,
+				})
+			default:
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					Error: "missing values or malformed body",
+				})
+			}
+			return
+		}
+
+		department, err := svsDepartment.CreateDepartment(ctx, departmentIn)
+		if err != nil {
+			var validationErr *services.ValidationError
+			switch {
+			case errors.As(err, &validationErr):
+				logger.Error("Problems creating department", "error", err, "problems", validationErr.Problems)
+				encodeResponse(w, r, logger, http.StatusUnprocessableEntity, responseErr{
+					ValidationErrors: validationErr.Problems,
+				})
+			default:
+				logger.Error("error creating department", "error", err)
+				encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+					Error: "Error creating department",
+				})
+			}
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusCreated, responseDepartment{Department: mapOutputDepartment(department)})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
 	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
 
@@ -12,18 +13,26 @@ import (
 // HandleCreatePerson creates a new person
 func HandleCreatePerson(logger *httplog.Logger, svsPerson *services.PersonService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		ctx, ok := enrollmentOverrides(w, r, logger)
+		if !ok {
+			return
+		}
 		personIn, problems, err := decodeValidateBody[inputPerson](r)
 		if err != nil {
 			switch {
+			case errors.Is(err, errUnsupportedMediaType):
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusUnsupportedMediaType, responseErr{
+					Error: "unsupported media type",
+				})
 			case len(problems) > 0:
 				logger.Error("Problems validating input", "error", err, "problems", problems)
-				encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 					ValidationErrors: problems,
 				})
 			default:
 				logger.Error("BodyParser error", "error", err)
-				encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 					Error: "missing values or malformed body",
 				})
 			}
@@ -34,17 +43,30 @@ func HandleCreatePerson(logger *httplog.Logger, svsPerson *services.PersonServic
 			FirstName: personIn.FirstName,
 			LastName:  personIn.LastName,
 			Type:      personIn.Type,
-			Age:       personIn.Age,
+			DateOfBirth: personIn.DateOfBirth,
+			Email:     personIn.Email,
+			Phone:     personIn.Phone,
+			Address:   personIn.Address,
 			Courses:   personIn.Courses,
+			Enrollments: personIn.Enrollments,
 		})
 		if err != nil {
-			logger.Error("error creating person", "error", err)
-			encodeResponse(w, logger, http.StatusInternalServerError, responseErr{
-				Error: "Error creating person",
-			})
+			var validationErr *services.ValidationError
+			switch {
+			case errors.As(err, &validationErr):
+				logger.Error("Problems validating person", "error", err, "problems", validationErr.Problems)
+				encodeResponse(w, r, logger, http.StatusUnprocessableEntity, responseErr{
+					ValidationErrors: validationErr.Problems,
+				})
+			default:
+				logger.Error("error creating person", "error", err)
+				encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+					Error: "Error creating person",
+				})
+			}
 			return
 		}
 
-		encodeResponse(w, logger, http.StatusCreated, responsePerson{Person: mapOutputPerson(person)})
+		encodeResponse(w, r, logger, http.StatusCreated, responsePerson{Person: mapOutputPerson(person)})
 	}
 }
\ No newline at end of file
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleCreateRoom creates a new room
+func HandleCreateRoom(logger *httplog.Logger, svsRoom *services.RoomService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		roomIn, problems, err := decodeValidateBody[inputRoom](r)
+		if err != nil {
+			switch {
+			case errors.Is(err, errUnsupportedMediaType):
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusUnsupportedMediaType, responseErr{
+					Error: "unsupported media type",
+				})
+			case len(problems) > 0:
+				logger.Error("Problems validating input", "error", err, "problems", problems)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					ValidationErrors: problems,
+				})
+			default:
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					Error: "missing values or malformed body",
+				})
+			}
+			return
+		}
+
+		room, err := svsRoom.CreateRoom(ctx, roomIn)
+		if err != nil {
+			var validationErr *services.ValidationError
+			switch {
+			case errors.As(err, &validationErr):
+				logger.Error("Problems creating room", "error", err, "problems", validationErr.Problems)
+				encodeResponse(w, r, logger, http.StatusUnprocessableEntity, responseErr{
+					ValidationErrors: validationErr.Problems,
+				})
+			default:
+				logger.Error("error creating room", "error", err)
+				encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+					Error: "Error creating room",
+				})
+			}
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusCreated, responseRoom{Room: mapOutputRoom(room)})
+	}
+}
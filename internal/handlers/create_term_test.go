@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// unreachableDB is a database every query to fails, so requests that pass
+// validation end in a 500 rather than a 400.
+func unreachableDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("postgres", "host=/nonexistent sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestHandleCreateTermValidation(t *testing.T) {
+	handler := HandleCreateTerm(httplog.NewLogger("test"), services.NewTermService(unreachableDB(t)))
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"valid term", `{"name": "Fall 2025", "start_date": "2025-09-01", "end_date": "2025-12-19"}`, http.StatusInternalServerError},
+		{"one day term", `{"name": "Exam day", "start_date": "2025-12-19", "end_date": "2025-12-19"}`, http.StatusInternalServerError},
+		{"end before start", `{"name": "Fall 2025", "start_date": "2025-09-01", "end_date": "2025-08-31"}`, http.StatusBadRequest},
+		{"malformed end date", `{"name": "Fall 2025", "start_date": "2025-09-01", "end_date": "19/12/2025"}`, http.StatusBadRequest},
+		{"missing name", `{"start_date": "2025-09-01", "end_date": "2025-12-19"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/term/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			handler(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d; body %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleCreateWebhook registers a webhook subscription. The response is the
+// only one that includes the signing secret.
+func HandleCreateWebhook(logger *httplog.Logger, svsWebhook *services.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		webhookIn, problems, err := decodeValidateBody[inputWebhook](r)
+		if err != nil {
+			switch {
+			case errors.Is(err, errUnsupportedMediaType):
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusUnsupportedMediaType, responseErr{
+					Error: "unsupported media type",
+				})
+			case len(problems) > 0:
+				logger.Error("Problems validating input", "error", err, "problems", problems)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					ValidationErrors: problems,
+				})
+			default:
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					Error: "missing values or malformed body",
+				})
+			}
+			return
+		}
+
+		sub, err := svsWebhook.CreateSubscription(ctx, webhookIn)
+		if err != nil {
+			logger.Error("error creating webhook", "error", err)
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error creating webhook",
+			})
+			return
+		}
+
+		out := mapOutputWebhook(sub)
+		out.Secret = sub.Secret
+		encodeResponse(w, r, logger, http.StatusCreated, responseWebhook{Webhook: out})
+	}
+}
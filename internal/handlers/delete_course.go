@@ -16,7 +16,7 @@ func HandleDeleteCourse(logger *httplog.Logger, svsCourse *services.CourseServic
 		courseID := chi.URLParam(r, "id")
 		if courseID == "" {
 			logger.Error("missing course ID")
-			encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 				Error: "missing course ID",
 			})
 			return
@@ -26,7 +26,7 @@ func HandleDeleteCourse(logger *httplog.Logger, svsCourse *services.CourseServic
 		courseIDInt, err := strconv.Atoi(courseID)
 		if err != nil {
 			logger.Error("invalid course ID", "error", err)
-			encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 				Error: "invalid course ID",
 			})
 			return
@@ -35,12 +35,12 @@ func HandleDeleteCourse(logger *httplog.Logger, svsCourse *services.CourseServic
 		err = svsCourse.DeleteCourse(ctx, courseIDInt)
 		if err != nil {
 			logger.Error("error deleting course", "error", err)
-			encodeResponse(w, logger, http.StatusInternalServerError, responseErr{
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
 				Error: "Error deleting course",
 			})
 			return
 		}
 
-		encodeResponse(w, logger, http.StatusOK, nil)
+		encodeResponse(w, r, logger, http.StatusOK, nil)
 	}
 }
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleDeleteDepartment deletes a department that offers no courses by its
+// ID
+func HandleDeleteDepartment(logger *httplog.Logger, svsDepartment *services.DepartmentService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		departmentID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid department ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid department ID",
+			})
+			return
+		}
+
+		err = svsDepartment.DeleteDepartment(ctx, departmentID)
+		if err != nil {
+			var validationErr *services.ValidationError
+			switch {
+			case errors.As(err, &validationErr):
+				logger.Error("Problems deleting department", "error", err, "problems", validationErr.Problems)
+				encodeResponse(w, r, logger, http.StatusUnprocessableEntity, responseErr{
+					ValidationErrors: validationErr.Problems,
+				})
+			case errors.Is(err, sql.ErrNoRows):
+				logger.Error("error deleting department", "error", err)
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No department with that ID",
+				})
+			default:
+				logger.Error("error deleting department", "error", err)
+				encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+					Error: "Error deleting department",
+				})
+			}
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, nil)
+	}
+}
@@ -15,7 +15,7 @@ func HandleDeletePerson(logger *httplog.Logger, svsPerson *services.PersonServic
 		firstName := chi.URLParam(r, "firstName")
 		if firstName == "" {
 			logger.Error("missing person firstName")
-			encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 				Error: "missing person firstName",
 			})
 			return
@@ -24,12 +24,12 @@ func HandleDeletePerson(logger *httplog.Logger, svsPerson *services.PersonServic
 		err := svsPerson.DeletePerson(ctx, firstName)
 		if err != nil {
 			logger.Error("error deleting person", "error", err)
-			encodeResponse(w, logger, http.StatusInternalServerError, responseErr{
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
 				Error: "Error deleting person",
 			})
 			return
 		}
 
-		encodeResponse(w, logger, http.StatusOK, nil)
+		encodeResponse(w, r, logger, http.StatusOK, nil)
 	}
 }
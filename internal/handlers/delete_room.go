@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleDeleteRoom deletes a room no section meets in by its ID
+func HandleDeleteRoom(logger *httplog.Logger, svsRoom *services.RoomService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		roomID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid room ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid room ID",
+			})
+			return
+		}
+
+		err = svsRoom.DeleteRoom(ctx, roomID)
+		if err != nil {
+			var validationErr *services.ValidationError
+			switch {
+			case errors.As(err, &validationErr):
+				logger.Error("Problems deleting room", "error", err, "problems", validationErr.Problems)
+				encodeResponse(w, r, logger, http.StatusUnprocessableEntity, responseErr{
+					ValidationErrors: validationErr.Problems,
+				})
+			case errors.Is(err, sql.ErrNoRows):
+				logger.Error("error deleting room", "error", err)
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No room with that ID",
+				})
+			default:
+				logger.Error("error deleting room", "error", err)
+				encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+					Error: "Error deleting room",
+				})
+			}
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, nil)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleDeleteSection deletes a section nobody is enrolled in by its ID
+func HandleDeleteSection(logger *httplog.Logger, svsSection *services.SectionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		sectionID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid section ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid section ID",
+			})
+			return
+		}
+
+		err = svsSection.DeleteSection(ctx, sectionID)
+		if err != nil {
+			var validationErr *services.ValidationError
+			switch {
+			case errors.As(err, &validationErr):
+				logger.Error("Problems deleting section", "error", err, "problems", validationErr.Problems)
+				encodeResponse(w, r, logger, http.StatusUnprocessableEntity, responseErr{
+					ValidationErrors: validationErr.Problems,
+				})
+			case errors.Is(err, sql.ErrNoRows):
+				logger.Error("error deleting section", "error", err)
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No section with that ID",
+				})
+			default:
+				logger.Error("error deleting section", "error", err)
+				encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+					Error: "Error deleting section",
+				})
+			}
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, nil)
+	}
+}
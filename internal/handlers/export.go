@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/export"
+)
+
+// startExport resolves the ?format= parameter, sets the download headers and
+// lifts the server write deadline so long exports are not cut off. It writes
+// an error response and returns false if the format is not supported.
+func startExport(w http.ResponseWriter, r *http.Request, logger *httplog.Logger, name string) (export.Format, bool) {
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		logger.Error("invalid export format", "error", err)
+		encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+			ValidationErrors: []problem{{
+				Name:        "format",
+				Description: "must be csv, ndjson or xlsx",
+			}},
+		})
+		return "", false
+	}
+
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		logger.Warn("unable to clear write deadline for export", "error", err)
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().UTC().Format("20060102T150405Z"), format.Extension())
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	return format, true
+}
+
+// abortExport is called when an export fails after the response has started.
+// The status line has already been sent, so the connection is aborted instead
+// to stop the client treating a truncated file as complete.
+func abortExport(logger *httplog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	panic(http.ErrAbortHandler)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/export"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleExportCourses streams courses matching the list filters
+func HandleExportCourses(logger *httplog.Logger, svsCourse *services.CourseService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		filter, problems := parseCourseFilter(r)
+		if len(problems) > 0 {
+			logger.Error("Problems validating filters", "problems", problems)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				ValidationErrors: problems,
+			})
+			return
+		}
+		if !allowIncludeDeleted(w, r, logger, filter.IncludeDeleted) {
+			return
+		}
+
+		format, ok := startExport(w, r, logger, "courses")
+		if !ok {
+			return
+		}
+
+		ew, err := export.New(format, w, []string{"id", "name", "code", "credits", "capacity"})
+		if err != nil {
+			abortExport(logger, "error starting courses export", err)
+		}
+
+		err = svsCourse.StreamCourses(ctx, filter, func(course models.Course) error {
+			// An unlimited course has an empty capacity cell
+			var capacity any
+			if course.Capacity != nil {
+				capacity = *course.Capacity
+			}
+			return ew.WriteRow(course.ID, course.Name, course.Code, course.Credits, capacity)
+		})
+		if err != nil {
+			abortExport(logger, "error exporting courses", err)
+		}
+
+		if err := ew.Close(); err != nil {
+			abortExport(logger, "error finishing courses export", err)
+		}
+	}
+}
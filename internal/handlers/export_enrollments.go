@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/export"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleExportEnrollments streams the enrollment matrix: one row per person
+// matching the person filters and one column per course matching the course
+// filter (course_name), with true where the person is enrolled
+func HandleExportEnrollments(logger *httplog.Logger, svsCourse *services.CourseService, svsPerson *services.PersonService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		personFilter, problems := parsePersonFilter(r)
+		if len(problems) > 0 {
+			logger.Error("Problems validating filters", "problems", problems)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				ValidationErrors: problems,
+			})
+			return
+		}
+		if !allowIncludeDeleted(w, r, logger, personFilter.IncludeDeleted) {
+			return
+		}
+		courseFilter := services.CourseFilter{Name: r.URL.Query().Get("course_name"), AsOf: personFilter.AsOf}
+
+		// The course list is the column header, so it has to be known up front.
+		courses, err := svsCourse.ListCourses(ctx, courseFilter)
+		if err != nil {
+			logger.Error("error getting courses for export", "error", err)
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error retrieving data",
+			})
+			return
+		}
+
+		format, ok := startExport(w, r, logger, "enrollments")
+		if !ok {
+			return
+		}
+
+		columns := []string{"person_id", "first_name", "last_name", "type"}
+		column := make(map[int]int, len(courses))
+		for i, course := range courses {
+			columns = append(columns, course.Name)
+			column[course.ID] = 4 + i
+		}
+
+		ew, err := export.New(format, w, columns)
+		if err != nil {
+			abortExport(logger, "error starting enrollments export", err)
+		}
+
+		row := make([]any, len(columns))
+		err = svsPerson.StreamPersons(ctx, personFilter, func(person models.Person, enrolled []models.Course) error {
+			row[0], row[1], row[2], row[3] = person.ID, person.FirstName, person.LastName, person.Type
+			for i := 4; i < len(row); i++ {
+				row[i] = false
+			}
+			for _, course := range enrolled {
+				if i, ok := column[course.ID]; ok {
+					row[i] = true
+				}
+			}
+			return ew.WriteRow(row...)
+		})
+		if err != nil {
+			abortExport(logger, "error exporting enrollments", err)
+		}
+
+		if err := ew.Close(); err != nil {
+			abortExport(logger, "error finishing enrollments export", err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/export"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleExportPersons streams persons matching the list filters, with the
+// names of the courses they are enrolled in
+func HandleExportPersons(logger *httplog.Logger, svsPerson *services.PersonService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		filter, problems := parsePersonFilter(r)
+		if len(problems) > 0 {
+			logger.Error("Problems validating filters", "problems", problems)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				ValidationErrors: problems,
+			})
+			return
+		}
+		if !allowIncludeDeleted(w, r, logger, filter.IncludeDeleted) {
+			return
+		}
+
+		format, ok := startExport(w, r, logger, "persons")
+		if !ok {
+			return
+		}
+
+		ew, err := export.New(format, w, []string{"id", "first_name", "last_name", "type", "date_of_birth", "age", "email", "phone", "address", "course_ids", "course_names"})
+		if err != nil {
+			abortExport(logger, "error starting persons export", err)
+		}
+
+		err = svsPerson.StreamPersons(ctx, filter, func(person models.Person, courses []models.Course) error {
+			names := make([]string, len(courses))
+			for i, course := range courses {
+				names[i] = course.Name
+			}
+			return ew.WriteRow(person.ID, person.FirstName, person.LastName, person.Type, person.DateOfBirth.Format(time.DateOnly), person.Age,
+				person.Email, person.Phone, person.Address, person.Courses, names)
+		})
+		if err != nil {
+			abortExport(logger, "error exporting persons", err)
+		}
+
+		if err := ew.Close(); err != nil {
+			abortExport(logger, "error finishing persons export", err)
+		}
+	}
+}
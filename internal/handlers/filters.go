@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// parsePersonFilter reads the person list filters from the query string:
+// first_name, last_name, type, min_age, max_age, course (a course id),
+// include_deleted and as_of.
+func parsePersonFilter(r *http.Request) (services.PersonFilter, []problem) {
+	var problems []problem
+	query := r.URL.Query()
+
+	filter := services.PersonFilter{
+		FirstName: query.Get("first_name"),
+		LastName:  query.Get("last_name"),
+		Type:      query.Get("type"),
+	}
+
+	if filter.Type != "" && filter.Type != "student" && filter.Type != "professor" {
+		problems = append(problems, problem{
+			Name:        "type",
+			Description: "must be student or professor",
+		})
+	}
+
+	filter.MinAge, problems = parsePositiveIntParam(query.Get("min_age"), "min_age", problems)
+	filter.MaxAge, problems = parsePositiveIntParam(query.Get("max_age"), "max_age", problems)
+	filter.CourseID, problems = parsePositiveIntParam(query.Get("course"), "course", problems)
+	filter.IncludeDeleted, problems = parseBoolParam(query.Get("include_deleted"), "include_deleted", problems)
+	filter.AsOf, problems = parseAsOfParam(query.Get("as_of"), problems)
+
+	if filter.MinAge > 0 && filter.MaxAge > 0 && filter.MinAge > filter.MaxAge {
+		problems = append(problems, problem{
+			Name:        "min_age",
+			Description: "must not be greater than max_age",
+		})
+	}
+
+	return filter, problems
+}
+
+// parseCourseFilter reads the course list filters from the query string:
+// name (a case-insensitive substring match), department (an id),
+// min_credits, max_credits, include_deleted and as_of.
+func parseCourseFilter(r *http.Request) (services.CourseFilter, []problem) {
+	query := r.URL.Query()
+	filter := services.CourseFilter{
+		Name: query.Get("name"),
+	}
+
+	var problems []problem
+	filter.DepartmentID, problems = parsePositiveIntParam(query.Get("department"), "department", problems)
+	filter.MinCredits, problems = parsePositiveIntParam(query.Get("min_credits"), "min_credits", problems)
+	filter.MaxCredits, problems = parsePositiveIntParam(query.Get("max_credits"), "max_credits", problems)
+	filter.IncludeDeleted, problems = parseBoolParam(query.Get("include_deleted"), "include_deleted", problems)
+	filter.AsOf, problems = parseAsOfParam(query.Get("as_of"), problems)
+
+	if filter.MinCredits > 0 && filter.MaxCredits > 0 && filter.MinCredits > filter.MaxCredits {
+		problems = append(problems, problem{
+			Name:        "min_credits",
+			Description: "must not be greater than max_credits",
+		})
+	}
+
+	return filter, problems
+}
+
+// parseSectionFilter reads the section list filters from the query string:
+// course and term, both ids.
+func parseSectionFilter(r *http.Request) (services.SectionFilter, []problem) {
+	query := r.URL.Query()
+	var (
+		filter   services.SectionFilter
+		problems []problem
+	)
+	filter.CourseID, problems = parsePositiveIntParam(query.Get("course"), "course", problems)
+	filter.TermID, problems = parsePositiveIntParam(query.Get("term"), "term", problems)
+	return filter, problems
+}
+
+// maxListLimit bounds the limit query parameter of the person, course and
+// section lists.
+const maxListLimit = 1000
+
+// parsePage reads the paging parameters of a list from the query string:
+// after, the id of the last item of the previous page, and limit, the size of
+// the page. Lists are not paged without limit.
+func parsePage(r *http.Request, problems []problem) (after, limit int, _ []problem) {
+	query := r.URL.Query()
+	after, problems = parsePositiveIntParam(query.Get("after"), "after", problems)
+	limit, problems = parsePositiveIntParam(query.Get("limit"), "limit", problems)
+	if limit > maxListLimit {
+		problems = append(problems, problem{
+			Name:        "limit",
+			Description: "must be at most " + strconv.Itoa(maxListLimit),
+		})
+	}
+	return after, limit, problems
+}
+
+// parsePositiveIntParam parses an optional positive integer query parameter,
+// appending a problem if it is present but invalid.
+func parsePositiveIntParam(value, name string, problems []problem) (int, []problem) {
+	if value == "" {
+		return 0, problems
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, append(problems, problem{
+			Name:        name,
+			Description: "must be a positive integer",
+		})
+	}
+	return n, problems
+}
+
+// parseBoolParam parses an optional boolean query parameter, appending a
+// problem if it is present but invalid.
+func parseBoolParam(value, name string, problems []problem) (bool, []problem) {
+	if value == "" {
+		return false, problems
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, append(problems, problem{
+			Name:        name,
+			Description: "must be true or false",
+		})
+	}
+	return b, problems
+}
+
+// parseAsOfParam parses the optional as_of query parameter, the RFC 3339 time
+// a point-in-time read reconstructs the state at.
+func parseAsOfParam(value string, problems []problem) (time.Time, []problem) {
+	asOf, problems := parseTimeParam(value, "as_of", problems)
+	if asOf.After(time.Now()) {
+		return time.Time{}, append(problems, problem{
+			Name:        "as_of",
+			Description: "must not be in the future",
+		})
+	}
+	return asOf, problems
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		query        string
+		after, limit int
+		problems     []problem
+	}{
+		{"", 0, 0, nil},
+		{"?after=7&limit=50", 7, 50, nil},
+		{"?limit=1000", 0, 1000, nil},
+		{"?limit=1001", 0, 1001, []problem{{Name: "limit", Description: "must be at most 1000"}}},
+		{"?after=0", 0, 0, []problem{{Name: "after", Description: "must be a positive integer"}}},
+		{"?after=x&limit=-1", 0, 0, []problem{{Name: "after", Description: "must be a positive integer"}, {Name: "limit", Description: "must be a positive integer"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			after, limit, problems := parsePage(httptest.NewRequest("GET", "/api/person/"+tt.query, nil), nil)
+			if after != tt.after || limit != tt.limit || !reflect.DeepEqual(problems, tt.problems) {
+				t.Errorf("parsePage() = %d, %d, %+v, want %d, %d, %+v", after, limit, problems, tt.after, tt.limit, tt.problems)
+			}
+		})
+	}
+}
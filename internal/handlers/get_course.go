@@ -15,7 +15,7 @@ func HandleGetCourseByID(logger *httplog.Logger, svsCourse *services.CourseServi
 		courseID := chi.URLParam(r, "id")
 		if courseID == "" {
 			logger.Error("missing course ID")
-			encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 				Error: "missing course ID",
 			})
 			return
@@ -25,21 +25,30 @@ func HandleGetCourseByID(logger *httplog.Logger, svsCourse *services.CourseServi
 		courseIDInt, err := strconv.Atoi(courseID)
 		if err != nil {
 			logger.Error("invalid course ID", "error", err)
-			encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 				Error: "invalid course ID",
 			})
 			return
 		}
 
-		course, err := svsCourse.GetCourseById(ctx, courseIDInt)
+		asOf, problems := parseAsOfParam(r.URL.Query().Get("as_of"), nil)
+		if len(problems) > 0 {
+			logger.Error("Problems validating as_of", "problems", problems)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				ValidationErrors: problems,
+			})
+			return
+		}
+
+		course, err := svsCourse.GetCourseAsOf(ctx, courseIDInt, asOf)
 		if err != nil {
 			logger.Error("error getting course", "error", err)
-			encodeResponse(w, logger, http.StatusInternalServerError, responseErr{
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
 				Error: "Error getting course",
 			})
 			return
 		}
 
-		encodeResponse(w, logger, http.StatusOK, responseCourse{Course: mapOutputCourse(course)})
+		encodeResponse(w, r, logger, http.StatusOK, responseCourse{Course: mapOutputCourse(course)})
 	}
 }
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleGetCourseCalendar returns the meetings of the sections of a course by
+// its ID in every term as an iCalendar feed, given the token of the feed in
+// the token query parameter
+func HandleGetCourseCalendar(logger *httplog.Logger, svsCalendar *services.CalendarService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		courseID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid course ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid course ID",
+			})
+			return
+		}
+		if err := svsCalendar.CheckToken(services.CalendarCourse, courseID, r.URL.Query().Get("token")); err != nil {
+			logger.Error("error checking calendar token", "error", err)
+			encodeResponse(w, r, logger, http.StatusForbidden, responseErr{
+				Error: "invalid or missing calendar token",
+			})
+			return
+		}
+
+		cal, err := svsCalendar.CourseCalendar(ctx, courseID)
+		if err != nil {
+			logger.Error("error getting course calendar", "error", err)
+			if errors.Is(err, sql.ErrNoRows) {
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No course with that ID",
+				})
+				return
+			}
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error getting calendar",
+			})
+			return
+		}
+
+		writeCalendar(w, logger, cal)
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleGetDepartment returns a department by its ID
+func HandleGetDepartment(logger *httplog.Logger, svsDepartment *services.DepartmentService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		departmentID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid department ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid department ID",
+			})
+			return
+		}
+
+		department, err := svsDepartment.GetDepartment(ctx, departmentID)
+		if err != nil {
+			logger.Error("error getting department", "error", err)
+			if errors.Is(err, sql.ErrNoRows) {
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No department with that ID",
+				})
+				return
+			}
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error getting department",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseDepartment{Department: mapOutputDepartment(department)})
+	}
+}
@@ -15,21 +15,30 @@ func HandleGetPersonByName(logger *httplog.Logger, svsPerson *services.PersonSer
 		firstName := chi.URLParam(r, "firstName")
 		if firstName == "" {
 			logger.Error("missing first name")
-			encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 				Error: "missing first name",
 			})
 			return
 		}
 
-		person, err := svsPerson.GetPersonByFirstName(ctx, firstName)
+		asOf, problems := parseAsOfParam(r.URL.Query().Get("as_of"), nil)
+		if len(problems) > 0 {
+			logger.Error("Problems validating as_of", "problems", problems)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				ValidationErrors: problems,
+			})
+			return
+		}
+
+		person, err := svsPerson.GetPersonByFirstNameAsOf(ctx, firstName, asOf)
 		if err != nil {
 			logger.Error("error getting person", "error", err)
-			encodeResponse(w, logger, http.StatusInternalServerError, responseErr{
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
 				Error: "Error getting person",
 			})
 			return
 		}
 
-		encodeResponse(w, logger, http.StatusOK, responsePerson{Person: mapOutputPerson(person)})
+		encodeResponse(w, r, logger, http.StatusOK, responsePerson{Person: mapOutputPerson(person)})
 	}
 }
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleGetPersonCalendar returns the meetings of a person by their ID in
+// every term as an iCalendar feed, given the token of the feed in the token
+// query parameter
+func HandleGetPersonCalendar(logger *httplog.Logger, svsCalendar *services.CalendarService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		personID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid person ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid person ID",
+			})
+			return
+		}
+		if err := svsCalendar.CheckToken(services.CalendarPerson, personID, r.URL.Query().Get("token")); err != nil {
+			logger.Error("error checking calendar token", "error", err)
+			encodeResponse(w, r, logger, http.StatusForbidden, responseErr{
+				Error: "invalid or missing calendar token",
+			})
+			return
+		}
+
+		cal, err := svsCalendar.PersonCalendar(ctx, personID)
+		if err != nil {
+			logger.Error("error getting person calendar", "error", err)
+			if errors.Is(err, sql.ErrNoRows) {
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No person with that ID",
+				})
+				return
+			}
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error getting calendar",
+			})
+			return
+		}
+
+		writeCalendar(w, logger, cal)
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleGetRoom returns a room by its ID
+func HandleGetRoom(logger *httplog.Logger, svsRoom *services.RoomService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		roomID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid room ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid room ID",
+			})
+			return
+		}
+
+		room, err := svsRoom.GetRoom(ctx, roomID)
+		if err != nil {
+			logger.Error("error getting room", "error", err)
+			if errors.Is(err, sql.ErrNoRows) {
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No room with that ID",
+				})
+				return
+			}
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error getting room",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseRoom{Room: mapOutputRoom(room)})
+	}
+}
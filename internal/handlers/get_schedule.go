@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleGetSchedule returns the week of a person by their ID in the term
+// given by the term query parameter, or in the current term, as JSON, XML,
+// msgpack, plain text or HTML
+func HandleGetSchedule(logger *httplog.Logger, svsSection *services.SectionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		personID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid person ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid person ID",
+			})
+			return
+		}
+		termID, problems := parsePositiveIntParam(r.URL.Query().Get("term"), "term", nil)
+		if len(problems) > 0 {
+			logger.Error("Problems validating filters", "problems", problems)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				ValidationErrors: problems,
+			})
+			return
+		}
+
+		schedule, err := svsSection.Schedule(ctx, personID, termID)
+		if err != nil {
+			logger.Error("error getting schedule", "error", err)
+			if errors.Is(err, sql.ErrNoRows) {
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No person or term with that ID",
+				})
+				return
+			}
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error getting schedule",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseSchedule{Schedule: mapOutputSchedule(schedule)})
+	}
+}
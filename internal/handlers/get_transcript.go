@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleGetTranscript returns the transcript of a person by their ID, as
+// JSON, XML, msgpack, plain text or HTML
+func HandleGetTranscript(logger *httplog.Logger, svsGrade *services.GradeService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		personID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid person ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid person ID",
+			})
+			return
+		}
+
+		transcript, err := svsGrade.Transcript(ctx, personID)
+		if err != nil {
+			logger.Error("error getting transcript", "error", err)
+			if errors.Is(err, sql.ErrNoRows) {
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No person with that ID",
+				})
+				return
+			}
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error getting transcript",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseTranscript{Transcript: mapOutputTranscript(transcript)})
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleGetWebhook returns a webhook subscription by its ID, without its secret
+func HandleGetWebhook(logger *httplog.Logger, svsWebhook *services.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		webhookID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid webhook ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid webhook ID",
+			})
+			return
+		}
+
+		sub, err := svsWebhook.GetSubscription(ctx, webhookID)
+		if err != nil {
+			logger.Error("error getting webhook", "error", err)
+			if errors.Is(err, sql.ErrNoRows) {
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No webhook with that ID",
+				})
+				return
+			}
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error getting webhook",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseWebhook{Webhook: mapOutputWebhook(sub)})
+	}
+}
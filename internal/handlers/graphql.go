@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/graph"
+)
+
+// HandleGraphQL executes GraphQL requests. POST takes a JSON body; GET takes
+// the query, operationName and variables parameters and only runs queries,
+// so a link cannot trigger a mutation. Results are always JSON. Requests that
+// fail before execution, e.g. on a syntax error or an exceeded limit, are
+// answered with 400; errors while resolving fields are reported next to the
+// partial data with 200.
+func HandleGraphQL(logger *httplog.Logger, schema *graph.Schema) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var (
+			req      graph.Request
+			problems []problem
+			err      error
+		)
+		if r.Method == http.MethodGet {
+			req, problems = parseGraphQLQuery(r)
+		} else {
+			req, problems, err = decodeValidateBody[inputGraphQL](r)
+		}
+		if err != nil || len(problems) > 0 {
+			switch {
+			case errors.Is(err, errUnsupportedMediaType):
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusUnsupportedMediaType, responseErr{
+					Error: "unsupported media type",
+				})
+			case len(problems) > 0:
+				logger.Error("Problems validating GraphQL request", "error", err, "problems", problems)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					ValidationErrors: problems,
+				})
+			default:
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					Error: "missing values or malformed body",
+				})
+			}
+			return
+		}
+
+		result := schema.Execute(ctx, req)
+		status := http.StatusOK
+		if result.Data == nil && len(result.Errors) > 0 {
+			logger.Error("GraphQL request rejected", "errors", result.Errors)
+			status = http.StatusBadRequest
+		}
+		writeEncoded(w, logger, encoders[0], status, result)
+	}
+}
+
+// parseGraphQLQuery reads a read-only GraphQL request from the query string.
+func parseGraphQLQuery(r *http.Request) (graph.Request, []problem) {
+	query := r.URL.Query()
+	in := inputGraphQL{
+		Query:         query.Get("query"),
+		OperationName: query.Get("operationName"),
+	}
+	if raw := query.Get("variables"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &in.Variables); err != nil {
+			return graph.Request{}, []problem{{Name: "variables", Description: "must be a JSON object"}}
+		}
+	}
+	if problems := in.Valid(); len(problems) > 0 {
+		return graph.Request{}, problems
+	}
+
+	req, _ := in.MapTo()
+	req.ReadOnly = true
+	return req, nil
+}
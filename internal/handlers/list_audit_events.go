@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// maxAuditLimit bounds the limit query parameter of HandleListAuditEvents.
+const maxAuditLimit = 1000
+
+// HandleListAuditEvents is a handler that returns audit events, newest first
+func HandleListAuditEvents(logger *httplog.Logger, svsAudit *services.AuditService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		filter, problems := parseAuditFilter(r)
+		if len(problems) > 0 {
+			logger.Error("Problems validating filters", "problems", problems)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				ValidationErrors: problems,
+			})
+			return
+		}
+
+		events, err := svsAudit.ListEvents(ctx, filter)
+		if err != nil {
+			logger.Error("error getting audit events", "error", err)
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error retrieving data",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseAuditEvents{AuditEvents: mapMultipleOutputAuditEvents(events)})
+	}
+}
+
+// parseAuditFilter reads the audit filters from the query string: entity,
+// entity_id, actor, from and to (RFC 3339 timestamps) and limit.
+func parseAuditFilter(r *http.Request) (services.AuditFilter, []problem) {
+	var problems []problem
+	query := r.URL.Query()
+
+	filter := services.AuditFilter{
+		Entity: query.Get("entity"),
+		Actor:  query.Get("actor"),
+	}
+
+	switch filter.Entity {
+	case "", services.EntityCourse, services.EntityPerson, services.EntityTerm, services.EntitySection, services.EntityRoom, services.EntityDepartment, services.EntityPrerequisite, services.EntityGrade:
+	default:
+		problems = append(problems, problem{
+			Name:        "entity",
+			Description: "must be course, person, term, section, room, department, prerequisite or grade",
+		})
+	}
+
+	filter.EntityID, problems = parsePositiveIntParam(query.Get("entity_id"), "entity_id", problems)
+	filter.Limit, problems = parsePositiveIntParam(query.Get("limit"), "limit", problems)
+	if filter.Limit > maxAuditLimit {
+		problems = append(problems, problem{
+			Name:        "limit",
+			Description: "must be at most " + strconv.Itoa(maxAuditLimit),
+		})
+	}
+
+	filter.From, problems = parseTimeParam(query.Get("from"), "from", problems)
+	filter.To, problems = parseTimeParam(query.Get("to"), "to", problems)
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		problems = append(problems, problem{
+			Name:        "from",
+			Description: "must be before to",
+		})
+	}
+
+	return filter, problems
+}
+
+// parseTimeParam parses an optional RFC 3339 query parameter, appending a
+// problem if it is present but invalid.
+func parseTimeParam(value, name string, problems []problem) (time.Time, []problem) {
+	if value == "" {
+		return time.Time{}, problems
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, append(problems, problem{
+			Name:        name,
+			Description: "must be an RFC 3339 timestamp",
+		})
+	}
+	return t, problems
+}
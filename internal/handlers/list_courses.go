@@ -11,16 +11,29 @@ import (
 func HandleListCourses(logger *httplog.Logger, svsCourse *services.CourseService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		courses, err := svsCourse.ListCourses(ctx)
+		filter, problems := parseCourseFilter(r)
+		filter.After, filter.Limit, problems = parsePage(r, problems)
+		if len(problems) > 0 {
+			logger.Error("Problems validating filters", "problems", problems)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				ValidationErrors: problems,
+			})
+			return
+		}
+		if !allowIncludeDeleted(w, r, logger, filter.IncludeDeleted) {
+			return
+		}
+
+		courses, err := svsCourse.ListCourses(ctx, filter)
 		if err != nil {
 			logger.Error("error getting all courses", "error", err)
-			encodeResponse(w, logger, http.StatusInternalServerError, responseErr{
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
 				Error: "Error retrieving data",
 			})
 			return
 		}
 
 		coursesOut := mapMultipleOutputCourses(courses)
-		encodeResponse(w, logger, http.StatusOK, responseCourses{Courses: coursesOut})
+		encodeResponse(w, r, logger, http.StatusOK, responseCourses{Courses: coursesOut})
 	}
 }
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleListDepartments returns every department in code order
+func HandleListDepartments(logger *httplog.Logger, svsDepartment *services.DepartmentService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		departments, err := svsDepartment.ListDepartments(ctx)
+		if err != nil {
+			logger.Error("error getting all departments", "error", err)
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error retrieving data",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseDepartments{Departments: mapMultipleOutputDepartments(departments)})
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleListGrades returns the grades recorded in a section by its ID
+func HandleListGrades(logger *httplog.Logger, svsGrade *services.GradeService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		sectionID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid section ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid section ID",
+			})
+			return
+		}
+
+		grades, err := svsGrade.ListGrades(ctx, sectionID)
+		if err != nil {
+			logger.Error("error getting grades", "error", err)
+			if errors.Is(err, sql.ErrNoRows) {
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No section with that ID",
+				})
+				return
+			}
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error getting grades",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseGrades{Grades: mapMultipleOutputGrades(grades)})
+	}
+}
@@ -12,16 +12,29 @@ import (
 func HandleListPersons(logger *httplog.Logger, svsPerson *services.PersonService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		persons, err := svsPerson.ListPersons(ctx)
+		filter, problems := parsePersonFilter(r)
+		filter.After, filter.Limit, problems = parsePage(r, problems)
+		if len(problems) > 0 {
+			logger.Error("Problems validating filters", "problems", problems)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				ValidationErrors: problems,
+			})
+			return
+		}
+		if !allowIncludeDeleted(w, r, logger, filter.IncludeDeleted) {
+			return
+		}
+
+		persons, err := svsPerson.ListPersons(ctx, filter)
 		if err != nil {
 			logger.Error("error getting all persons", "error", err)
-			encodeResponse(w, logger, http.StatusInternalServerError, responseErr{
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
 				Error: "Error retrieving data",
 			})
 			return
 		}
 		
 		personsOut := mapMultipleOutputPersons(persons)
-		encodeResponse(w, logger, http.StatusOK, responsePersons{Persons: personsOut})
+		encodeResponse(w, r, logger, http.StatusOK, responsePersons{Persons: personsOut})
 	}
 }
\ No newline at end of file
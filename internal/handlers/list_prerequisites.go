@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleListPrerequisites returns the transitive prerequisite tree of a
+// course by its ID
+func HandleListPrerequisites(logger *httplog.Logger, svsCourse *services.CourseService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		courseID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid course ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid course ID",
+			})
+			return
+		}
+
+		prerequisites, err := svsCourse.ListPrerequisites(ctx, courseID)
+		if err != nil {
+			logger.Error("error getting prerequisites", "error", err)
+			if errors.Is(err, sql.ErrNoRows) {
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No course with that ID",
+				})
+				return
+			}
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error getting prerequisites",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responsePrerequisites{Prerequisites: mapOutputPrerequisites(prerequisites)})
+	}
+}
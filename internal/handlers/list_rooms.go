@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleListRooms returns every room in name order
+func HandleListRooms(logger *httplog.Logger, svsRoom *services.RoomService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		rooms, err := svsRoom.ListRooms(ctx)
+		if err != nil {
+			logger.Error("error getting all rooms", "error", err)
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error retrieving data",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseRooms{Rooms: mapMultipleOutputRooms(rooms)})
+	}
+}
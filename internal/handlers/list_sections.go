@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleListSections returns the sections of courses that are not deleted,
+// optionally filtered by course and term
+func HandleListSections(logger *httplog.Logger, svsSection *services.SectionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		filter, problems := parseSectionFilter(r)
+		filter.After, filter.Limit, problems = parsePage(r, problems)
+		if len(problems) > 0 {
+			logger.Error("Problems validating filters", "problems", problems)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				ValidationErrors: problems,
+			})
+			return
+		}
+
+		sections, err := svsSection.ListSections(ctx, filter)
+		if err != nil {
+			logger.Error("error getting all sections", "error", err)
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error retrieving data",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseSections{Sections: mapMultipleOutputSections(sections)})
+	}
+}
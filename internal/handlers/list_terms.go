@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleListTerms returns every term in the order they start
+func HandleListTerms(logger *httplog.Logger, svsTerm *services.TermService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		terms, err := svsTerm.ListTerms(ctx)
+		if err != nil {
+			logger.Error("error getting all terms", "error", err)
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error retrieving data",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseTerms{Terms: mapMultipleOutputTerms(terms)})
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleListWaitlistPositions returns a person's places on the waitlists of
+// the courses they are waiting for, by their first name
+func HandleListWaitlistPositions(logger *httplog.Logger, svsPerson *services.PersonService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		entries, err := svsPerson.ListWaitlistPositions(ctx, chi.URLParam(r, "firstName"))
+		if err != nil {
+			logger.Error("error getting waitlist positions", "error", err)
+			if errors.Is(err, sql.ErrNoRows) {
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No person with that first name",
+				})
+				return
+			}
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error getting waitlist positions",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseWaitlist{Entries: mapMultipleOutputWaitlistEntries(entries)})
+	}
+}
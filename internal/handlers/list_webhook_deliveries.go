@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// maxDeliveryLimit bounds the limit query parameter of
+// HandleListWebhookDeliveries.
+const maxDeliveryLimit = 1000
+
+// HandleListWebhookDeliveries returns the deliveries of a webhook
+// subscription, newest first, optionally filtered by status
+func HandleListWebhookDeliveries(logger *httplog.Logger, svsWebhook *services.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		webhookID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid webhook ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid webhook ID",
+			})
+			return
+		}
+
+		var problems []problem
+		query := r.URL.Query()
+		status := query.Get("status")
+		switch status {
+		case "", services.DeliveryPending, services.DeliveryDelivered, services.DeliveryDead:
+		default:
+			problems = append(problems, problem{
+				Name:        "status",
+				Description: "must be one of pending, delivered, dead",
+			})
+		}
+		limit, problems := parsePositiveIntParam(query.Get("limit"), "limit", problems)
+		if limit > maxDeliveryLimit {
+			problems = append(problems, problem{
+				Name:        "limit",
+				Description: "must be at most " + strconv.Itoa(maxDeliveryLimit),
+			})
+		}
+		if len(problems) > 0 {
+			logger.Error("Problems validating filters", "problems", problems)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				ValidationErrors: problems,
+			})
+			return
+		}
+
+		deliveries, err := svsWebhook.ListDeliveries(ctx, webhookID, status, limit)
+		if err != nil {
+			logger.Error("error getting webhook deliveries", "error", err)
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error retrieving data",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseWebhookDeliveries{Deliveries: mapMultipleOutputWebhookDeliveries(deliveries)})
+	}
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleListWebhooks returns every webhook subscription, without secrets
+func HandleListWebhooks(logger *httplog.Logger, svsWebhook *services.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		subs, err := svsWebhook.ListSubscriptions(r.Context())
+		if err != nil {
+			logger.Error("error getting webhooks", "error", err)
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error retrieving data",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseWebhooks{Webhooks: mapMultipleOutputWebhooks(subs)})
+	}
+}
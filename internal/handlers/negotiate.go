@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/export"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// errUnsupportedMediaType is returned by decodeValidateBody when the request
+// Content-Type has no registered decoder.
+var errUnsupportedMediaType = errors.New("unsupported media type")
+
+// encoder writes a response body in one media type. supports reports whether
+// the encoder can represent a given value; it is nil when every value is
+// supported.
+type encoder struct {
+	mediaType string
+	aliases   []string
+	supports  func(data any) bool
+	encode    func(w io.Writer, data any) error
+}
+
+// decoder reads a request body in one media type. generic decodes a body
+// into maps, slices and scalars for validation against the request schema;
+// t is the Go type the schema was generated from, for formats that can only
+// be decoded into a type.
+type decoder struct {
+	mediaTypes []string
+	decode     func(r io.Reader, v any) error
+	generic    func(body []byte, t reflect.Type) (any, error)
+}
+
+// encoders are tried in order when the client expresses no preference between
+// several acceptable types, so JSON stays the default.
+var encoders = []encoder{
+	{
+		mediaType: "application/json",
+		encode: func(w io.Writer, data any) error {
+			return json.NewEncoder(w).Encode(data)
+		},
+	},
+	{
+		mediaType: "application/xml",
+		aliases:   []string{"text/xml"},
+		encode: func(w io.Writer, data any) error {
+			if _, err := io.WriteString(w, xml.Header); err != nil {
+				return err
+			}
+			return xml.NewEncoder(w).Encode(data)
+		},
+	},
+	{
+		mediaType: "text/csv",
+		supports: func(data any) bool {
+			_, ok := data.(tabular)
+			return ok
+		},
+		encode: func(w io.Writer, data any) error {
+			table := data.(tabular)
+			ew, err := export.New(export.FormatCSV, w, table.columns())
+			if err != nil {
+				return err
+			}
+			for _, row := range table.rows() {
+				if err := ew.WriteRow(row...); err != nil {
+					return err
+				}
+			}
+			return ew.Close()
+		},
+	},
+	{
+		mediaType: "application/msgpack",
+		aliases:   []string{"application/x-msgpack", "application/vnd.msgpack"},
+		encode: func(w io.Writer, data any) error {
+			enc := msgpack.NewEncoder(w)
+			enc.SetCustomStructTag("json")
+			return enc.Encode(data)
+		},
+	},
+	{
+		mediaType: "text/plain",
+		supports:  isDocument,
+		encode: func(w io.Writer, data any) error {
+			return data.(document).text(w)
+		},
+	},
+	{
+		mediaType: "text/html",
+		supports:  isDocument,
+		encode: func(w io.Writer, data any) error {
+			return data.(document).html(w)
+		},
+	},
+	{
+		mediaType: calendarMediaType,
+		supports:  isCalendarFeed,
+		encode: func(w io.Writer, data any) error {
+			return data.(calendarFeed).calendar().Write(w)
+		},
+	},
+}
+
+var decoders = []decoder{
+	{
+		mediaTypes: []string{"application/json"},
+		decode: func(r io.Reader, v any) error {
+			dec := json.NewDecoder(r)
+			dec.DisallowUnknownFields()
+			return dec.Decode(v)
+		},
+		generic: func(body []byte, _ reflect.Type) (any, error) {
+			return decodeJSONValue(body)
+		},
+	},
+	{
+		mediaTypes: []string{"application/xml", "text/xml"},
+		decode: func(r io.Reader, v any) error {
+			return xml.NewDecoder(r).Decode(v)
+		},
+		generic: func(body []byte, t reflect.Type) (any, error) {
+			// XML has no generic form, so the body is decoded as the
+			// handler will decode it and checked as the JSON it maps to.
+			// Elements left out decode to zero values, which the handler's
+			// own validation reports.
+			if t == nil {
+				return nil, nil
+			}
+			v := reflect.New(t)
+			if err := xml.NewDecoder(bytes.NewReader(body)).Decode(v.Interface()); err != nil {
+				return nil, err
+			}
+			data, err := json.Marshal(v.Interface())
+			if err != nil {
+				return nil, err
+			}
+			return decodeJSONValue(data)
+		},
+	},
+	{
+		mediaTypes: []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"},
+		decode: func(r io.Reader, v any) error {
+			dec := msgpack.NewDecoder(r)
+			dec.SetCustomStructTag("json")
+			return dec.Decode(v)
+		},
+		generic: func(body []byte, _ reflect.Type) (any, error) {
+			var value any
+			err := msgpack.Unmarshal(body, &value)
+			return value, err
+		},
+	},
+}
+
+// decodeJSONValue decodes a JSON body generically, keeping numbers exact.
+func decodeJSONValue(body []byte) (any, error) {
+	var value any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	err := dec.Decode(&value)
+	return value, err
+}
+
+// tabular is implemented by list responses that can be rendered as CSV.
+type tabular interface {
+	columns() []string
+	rows() [][]any
+}
+
+// document is implemented by responses that can also be rendered for people
+// to read, as plain text or HTML.
+type document interface {
+	text(w io.Writer) error
+	html(w io.Writer) error
+}
+
+func isDocument(data any) bool {
+	_, ok := data.(document)
+	return ok
+}
+
+// acceptRange is one entry of an Accept header.
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept parses an Accept header into media ranges ordered by
+// preference, so ranges with q=0, which refuse the types they cover, come
+// last. An empty header accepts anything.
+func parseAccept(header string) []acceptRange {
+	if strings.TrimSpace(header) == "" {
+		return []acceptRange{{mediaType: "*/*", q: 1}}
+	}
+
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q < 0 || q > 1 {
+			continue
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+	return ranges
+}
+
+// matches reports whether the media range covers the encoder's media type or
+// one of its aliases.
+func (a acceptRange) matches(enc encoder) bool {
+	if a.mediaType == "*/*" {
+		return true
+	}
+	for _, t := range append([]string{enc.mediaType}, enc.aliases...) {
+		if a.mediaType == t {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a.mediaType, "/*"); ok && strings.HasPrefix(t, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// specificity ranks how closely the media range names the encoder's media
+// type: 2 for the type or an alias, 1 for type/*, 0 for */* and -1 if the
+// range does not cover it.
+func (a acceptRange) specificity(enc encoder) int {
+	switch {
+	case !a.matches(enc):
+		return -1
+	case a.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(a.mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// refused reports whether the most specific of ranges that covers the
+// encoder has q=0, as in "*/*, application/xml;q=0".
+func refused(ranges []acceptRange, enc encoder) bool {
+	best, q := -1, 0.0
+	for _, accept := range ranges {
+		if s := accept.specificity(enc); s > best {
+			best, q = s, accept.q
+		}
+	}
+	return best >= 0 && q == 0
+}
+
+// negotiateEncoder picks the encoder the client prefers among those that can
+// represent data.
+func negotiateEncoder(r *http.Request, data any) (encoder, bool) {
+	ranges := parseAccept(r.Header.Get("Accept"))
+	for _, accept := range ranges {
+		if accept.q == 0 {
+			break
+		}
+		for _, enc := range encoders {
+			if !accept.matches(enc) || refused(ranges, enc) {
+				continue
+			}
+			if enc.supports != nil && !enc.supports(data) {
+				continue
+			}
+			return enc, true
+		}
+	}
+	return encoder{}, false
+}
+
+// acceptable reports whether the client accepts at least one registered
+// media type, regardless of what the response will contain.
+func acceptable(r *http.Request) bool {
+	ranges := parseAccept(r.Header.Get("Accept"))
+	for _, accept := range ranges {
+		if accept.q == 0 {
+			break
+		}
+		for _, enc := range encoders {
+			if accept.matches(enc) && !refused(ranges, enc) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requestDecoder returns the decoder for the request Content-Type. A missing
+// Content-Type is treated as JSON.
+func requestDecoder(r *http.Request) (decoder, error) {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return decoders[0], nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return decoder{}, fmt.Errorf("%w: %q", errUnsupportedMediaType, contentType)
+	}
+	for _, dec := range decoders {
+		for _, t := range dec.mediaTypes {
+			if t == mediaType {
+				return dec, nil
+			}
+		}
+	}
+	return decoder{}, fmt.Errorf("%w: %q", errUnsupportedMediaType, mediaType)
+}
+
+// Negotiate rejects requests whose Accept header cannot be satisfied by any
+// registered encoder (406) or whose body is in a media type with no
+// registered decoder (415), before the handler does any work.
+func Negotiate(logger *httplog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !acceptable(r) {
+				logger.Error("no acceptable response media type", "accept", r.Header.Get("Accept"))
+				writeNotAcceptable(w, logger)
+				return
+			}
+
+			if r.ContentLength != 0 && r.Method != http.MethodGet && r.Method != http.MethodDelete {
+				if _, err := requestDecoder(r); err != nil {
+					logger.Error("unsupported request media type", "error", err)
+					encodeResponse(w, r, logger, http.StatusUnsupportedMediaType, responseErr{
+						Error: "unsupported media type",
+					})
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeNotAcceptable sends a 406 as JSON, since by definition none of the
+// types the client asked for can be produced.
+func writeNotAcceptable(w http.ResponseWriter, logger *httplog.Logger) {
+	writeEncoded(w, logger, encoders[0], http.StatusNotAcceptable, responseErr{
+		Error: "not acceptable",
+	})
+}
+
+func writeEncoded(w http.ResponseWriter, logger *httplog.Logger, enc encoder, status int, data any) {
+	w.Header().Set("Content-Type", enc.mediaType)
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(status)
+	if err := enc.encode(w, data); err != nil {
+		logger.Error("Error while marshaling data", "err", err, "data", data)
+		http.Error(w, `{"Error": "Internal server error"}`, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/httplog/v2"
+)
+
+func TestParseAccept(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []acceptRange
+	}{
+		{"", []acceptRange{{"*/*", 1}}},
+		{"application/xml", []acceptRange{{"application/xml", 1}}},
+		{
+			"text/csv;q=0.5, application/xml, application/json;q=0.8",
+			[]acceptRange{{"application/xml", 1}, {"application/json", 0.8}, {"text/csv", 0.5}},
+		},
+		{
+			"application/json;q=0.5, application/xml;q=0.5",
+			[]acceptRange{{"application/json", 0.5}, {"application/xml", 0.5}},
+		},
+		{"application/json;q=0, text/*", []acceptRange{{"text/*", 1}, {"application/json", 0}}},
+		{"application/json;q=high, not a type, text/csv;q=2, application/xml", []acceptRange{{"application/xml", 1}}},
+	}
+	for _, tt := range tests {
+		if got := parseAccept(tt.header); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseAccept(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiateEncoder(t *testing.T) {
+	table := responseCourses{}
+	tests := []struct {
+		name   string
+		accept string
+		data   any
+		want   string // "" when nothing is acceptable
+	}{
+		{"no header", "", responseErr{}, "application/json"},
+		{"any", "*/*", responseErr{}, "application/json"},
+		{"exact", "application/xml", responseErr{}, "application/xml"},
+		{"highest q wins", "application/json;q=0.5, application/xml", responseErr{}, "application/xml"},
+		{"equal q keeps header order", "application/xml, application/json", responseErr{}, "application/xml"},
+		{"alias", "text/xml", responseErr{}, "application/xml"},
+		{"msgpack alias", "application/x-msgpack", responseErr{}, "application/msgpack"},
+		{"type wildcard", "application/*", responseErr{}, "application/json"},
+		{"type wildcard covers aliases", "text/*", table, "application/xml"},
+		{"type wildcard skips unsupported", "text/*, text/xml;q=0", responseErr{}, ""},
+		{"unsupported falls back", "text/csv, application/json;q=0.1", responseErr{}, "application/json"},
+		{"q=0 excludes", "application/json;q=0, application/xml;q=0.1", responseErr{}, "application/xml"},
+		{"q=0 excludes from any", "*/*, application/json;q=0", responseErr{}, "application/xml"},
+		{"q=0 alias excludes", "*/*, text/xml;q=0, application/json;q=0", responseErr{}, "application/msgpack"},
+		{"q=0 type wildcard excludes", "*/*;q=0.5, application/*;q=0", table, "text/csv"},
+		{"exact beats q=0 wildcard", "text/*;q=0, text/csv;q=0.5", table, "text/csv"},
+		{"only q=0", "application/json;q=0", responseErr{}, ""},
+		{"unknown type", "image/png", responseErr{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", tt.accept)
+			enc, ok := negotiateEncoder(r, tt.data)
+			if got := enc.mediaType; got != tt.want || ok != (tt.want != "") {
+				t.Errorf("negotiateEncoder() = %q, %t, want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestDecoder(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        string // the decoder's first media type, "" for a 415
+	}{
+		{"", "application/json"},
+		{"application/json", "application/json"},
+		{"application/json; charset=utf-8", "application/json"},
+		{"text/xml", "application/xml"},
+		{"application/vnd.msgpack", "application/msgpack"},
+		{"text/csv", ""},
+		{"not a type", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.Header.Set("Content-Type", tt.contentType)
+		dec, err := requestDecoder(r)
+		if tt.want == "" {
+			if !errors.Is(err, errUnsupportedMediaType) {
+				t.Errorf("requestDecoder(%q) error = %v, want %v", tt.contentType, err, errUnsupportedMediaType)
+			}
+			continue
+		}
+		if err != nil || dec.mediaTypes[0] != tt.want {
+			t.Errorf("requestDecoder(%q) = %v, %v, want %q", tt.contentType, dec.mediaTypes, err, tt.want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	handler := Negotiate(httplog.NewLogger("test"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name        string
+		method      string
+		accept      string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"acceptable", http.MethodGet, "application/xml", "", "", http.StatusNoContent},
+		{"not acceptable", http.MethodGet, "image/png", "", "", http.StatusNotAcceptable},
+		{"refused", http.MethodGet, "*/*;q=0.1, application/*;q=0, text/*;q=0", "", "", http.StatusNotAcceptable},
+		{"supported body", http.MethodPost, "", "application/xml", "<person/>", http.StatusNoContent},
+		{"unsupported body", http.MethodPost, "", "text/csv", "id\n1\n", http.StatusUnsupportedMediaType},
+		{"unsupported but empty", http.MethodPost, "", "text/csv", "", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			r.Header.Set("Accept", tt.accept)
+			r.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d; body %s", w.Code, tt.want, w.Body)
+			}
+			if w.Code == http.StatusNotAcceptable && w.Header().Get("Content-Type") != "application/json" {
+				t.Errorf("406 Content-Type = %q, want application/json", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
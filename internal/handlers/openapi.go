@@ -0,0 +1,860 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/export"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/graph"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/openapi"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// Operations documents every handler in this package, keyed by
+// "METHOD pattern" exactly as the handler is mounted in routes.RegisterRoutes.
+// The request and response schemas are generated from the input*, output* and
+// response* types and registered as components of doc.
+func Operations(doc *openapi.Document) map[string]*openapi.Operation {
+	doc.Component(problem{})
+	errSchema := doc.Component(responseErr{})
+	doc.Component(outputCourse{})
+	doc.Component(outputPerson{})
+	courseIn := doc.Component(inputCourse{})
+	personIn := doc.Component(inputPerson{})
+	course := doc.Component(responseCourse{})
+	courses := doc.Component(responseCourses{})
+	person := doc.Component(responsePerson{})
+	persons := doc.Component(responsePersons{})
+	doc.Component(outputWaitlistEntry{})
+	waitlistIn := doc.Component(inputWaitlist{})
+	waitlistEntry := doc.Component(responseWaitlistEntry{})
+	waitlist := doc.Component(responseWaitlist{})
+	doc.Component(outputEligibility{})
+	eligibilityIn := doc.Component(inputEligibility{})
+	eligibility := doc.Component(responseEligibility{})
+	doc.Component(outputPrerequisite{})
+	prerequisiteIn := doc.Component(inputPrerequisite{})
+	prerequisites := doc.Component(responsePrerequisites{})
+	doc.Component(outputTerm{})
+	doc.Component(outputMeeting{})
+	doc.Component(outputSection{})
+	termIn := doc.Component(inputTerm{})
+	doc.Component(inputMeeting{})
+	sectionIn := doc.Component(inputSection{})
+	sectionEnrollmentIn := doc.Component(inputSectionEnrollment{})
+	term := doc.Component(responseTerm{})
+	terms := doc.Component(responseTerms{})
+	section := doc.Component(responseSection{})
+	sections := doc.Component(responseSections{})
+	doc.Component(outputDepartment{})
+	departmentIn := doc.Component(inputDepartment{})
+	department := doc.Component(responseDepartment{})
+	departments := doc.Component(responseDepartments{})
+	doc.Component(outputRoom{})
+	roomIn := doc.Component(inputRoom{})
+	room := doc.Component(responseRoom{})
+	rooms := doc.Component(responseRooms{})
+	doc.Component(outputScheduleEntry{})
+	doc.Component(outputScheduleDay{})
+	doc.Component(outputSchedule{})
+	schedule := doc.Component(responseSchedule{})
+	doc.Component(outputGrade{})
+	gradesIn := doc.Component(inputGrades{})
+	gradeAmendmentIn := doc.Component(inputGradeAmendment{})
+	grade := doc.Component(responseGrade{})
+	grades := doc.Component(responseGrades{})
+	doc.Component(outputTranscriptCourse{})
+	doc.Component(outputTranscriptTerm{})
+	doc.Component(outputTranscript{})
+	transcript := doc.Component(responseTranscript{})
+	doc.Component(outputSubscription{})
+	subscription := doc.Component(responseSubscription{})
+	doc.Component(outputAuditEvent{})
+	auditEvents := doc.Component(responseAuditEvents{})
+	doc.Component(outputWebhook{})
+	doc.Component(outputWebhookDelivery{})
+	webhookIn := doc.Component(inputWebhook{})
+	webhook := doc.Component(responseWebhook{})
+	webhooks := doc.Component(responseWebhooks{})
+	deliveries := doc.Component(responseWebhookDeliveries{})
+	replay := doc.Component(responseReplay{})
+	graphqlIn := doc.Component(inputGraphQL{})
+	doc.Component(inputBatchOperation{})
+	batchIn := doc.Component(inputBatch{})
+	doc.Component(outputBatchResult{})
+	batch := doc.Component(responseBatch{})
+	batchErr := doc.Component(responseBatchErr{})
+
+	courseID := openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}}
+	firstName := openapi.Parameter{Name: "firstName", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}}
+	webhookID := openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}}
+	termID := openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}}
+	sectionID := openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}}
+	departmentID := openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}}
+	roomID := openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}}
+	prerequisiteID := openapi.Parameter{Name: "prerequisiteID", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}}
+	personID := openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}}
+	asOf := queryParam("as_of", "Read the state at this RFC 3339 time instead of the current state", &openapi.Schema{Type: "string", Format: "date-time"})
+	overridePrerequisites := queryParam("override_prerequisites", "Enroll students without the prerequisites of their courses; requires the "+AdminTokenHeader+" header", &openapi.Schema{Type: "boolean"})
+	overrideRules := queryParam("override_rules", "Enroll persons against the eligibility rules; requires the "+AdminTokenHeader+" header", &openapi.Schema{Type: "boolean"})
+	calendarToken := openapi.Parameter{Name: "token", In: "query", Required: true, Description: "Token of the feed, from its subscription", Schema: &openapi.Schema{Type: "string"}}
+	includeDeleted := queryParam("include_deleted", "Also return soft deleted records; requires the "+AdminTokenHeader+" header", &openapi.Schema{Type: "boolean"})
+
+	personFilters := []openapi.Parameter{
+		queryParam("first_name", "Exact first name", &openapi.Schema{Type: "string"}),
+		queryParam("last_name", "Exact last name", &openapi.Schema{Type: "string"}),
+		queryParam("type", "Person type", &openapi.Schema{Type: "string", Enum: []any{"student", "professor"}}),
+		queryParam("min_age", "Minimum age, inclusive", positiveInt()),
+		queryParam("max_age", "Maximum age, inclusive", positiveInt()),
+		queryParam("course", "Only persons enrolled in this course id", positiveInt()),
+		includeDeleted,
+		asOf,
+	}
+	courseFilters := []openapi.Parameter{
+		queryParam("name", "Case-insensitive substring of the course name", &openapi.Schema{Type: "string"}),
+		queryParam("department", "Only courses of this department id", positiveInt()),
+		queryParam("min_credits", "Minimum credits, inclusive", positiveInt()),
+		queryParam("max_credits", "Maximum credits, inclusive", positiveInt()),
+		includeDeleted,
+		asOf,
+	}
+	sectionFilters := []openapi.Parameter{
+		queryParam("course", "Only sections of this course id", positiveInt()),
+		queryParam("term", "Only sections in this term id", positiveInt()),
+	}
+	page := []openapi.Parameter{
+		queryParam("after", "Only return items listed after the one with this id, the last of the previous page", positiveInt()),
+		queryParam("limit", "Maximum number of items, all by default; a page with fewer items is the last", intRange(1, maxListLimit)),
+	}
+	exportFormat := queryParam("format", "Export format, csv by default", &openapi.Schema{
+		Type: "string",
+		Enum: []any{string(export.FormatCSV), string(export.FormatNDJSON), string(export.FormatXLSX)},
+	})
+
+	errorResponses := func(statuses ...int) map[string]*openapi.Response {
+		responses := map[string]*openapi.Response{}
+		for _, status := range statuses {
+			responses[statusKey(status)] = &openapi.Response{
+				Description: http.StatusText(status),
+				Content:     responseContent(errSchema, false),
+			}
+		}
+		return responses
+	}
+	with := func(responses map[string]*openapi.Response, status int, resp *openapi.Response) map[string]*openapi.Response {
+		responses[statusKey(status)] = resp
+		return responses
+	}
+	ok := func(schema *openapi.Schema, tabular bool) *openapi.Response {
+		return &openapi.Response{Description: "OK", Content: responseContent(schema, tabular)}
+	}
+	exportOK := &openapi.Response{
+		Description: "Streamed export file",
+		Content: map[string]openapi.MediaType{
+			export.FormatCSV.ContentType():    {Schema: &openapi.Schema{Type: "string"}},
+			export.FormatNDJSON.ContentType(): {Schema: &openapi.Schema{Type: "string"}},
+			export.FormatXLSX.ContentType():   {Schema: &openapi.Schema{Type: "string", Format: "binary"}},
+		},
+	}
+
+	calendarOK := &openapi.Response{
+		Description: "iCalendar feed",
+		Content:     map[string]openapi.MediaType{calendarMediaType: {Schema: &openapi.Schema{Type: "string"}}},
+	}
+
+	graphqlOK := &openapi.Response{
+		Description: "GraphQL result; field errors are listed next to the partial data",
+		Content:     map[string]openapi.MediaType{"application/json": {Schema: &openapi.Schema{Type: "object"}}},
+	}
+
+	return map[string]*openapi.Operation{
+		"GET /api/course/": {
+			OperationID: "listCourses",
+			Summary:     "List courses",
+			Tags:        []string{"course"},
+			Parameters:  slices.Concat(courseFilters, page),
+			Responses:   with(errorResponses(400, 403, 406, 500), 200, ok(courses, true)),
+		},
+		"POST /api/course/": {
+			OperationID: "createCourse",
+			Summary:     "Create a course",
+			Description: courseDescription,
+			Tags:        []string{"course"},
+			RequestBody: requestBody(courseIn),
+			Responses: with(errorResponses(400, 406, 413, 415, 422, 500), 201, &openapi.Response{
+				Description: "Created",
+				Content:     responseContent(course, false),
+			}),
+		},
+		"GET /api/course/{id}": {
+			OperationID: "getCourse",
+			Summary:     "Get a course by id",
+			Tags:        []string{"course"},
+			Parameters:  []openapi.Parameter{courseID, asOf},
+			Responses:   with(errorResponses(400, 406, 500), 200, ok(course, false)),
+		},
+		"PUT /api/course/{id}": {
+			OperationID: "updateCourse",
+			Summary:     "Update a course",
+			Description: courseDescription,
+			Tags:        []string{"course"},
+			Parameters:  []openapi.Parameter{courseID},
+			RequestBody: requestBody(courseIn),
+			Responses:   with(errorResponses(400, 406, 413, 415, 422, 500), 200, ok(course, false)),
+		},
+		"DELETE /api/course/{id}": {
+			OperationID: "deleteCourse",
+			Summary:     "Soft delete a course",
+			Tags:        []string{"course"},
+			Parameters:  []openapi.Parameter{courseID},
+			Responses:   with(errorResponses(400, 406, 500), 200, &openapi.Response{Description: "Deleted"}),
+		},
+		"POST /api/course/{id}/restore": {
+			OperationID: "restoreCourse",
+			Summary:     "Restore a soft deleted course and its enrollments",
+			Tags:        []string{"course"},
+			Parameters:  []openapi.Parameter{courseID},
+			Responses:   with(errorResponses(400, 404, 406, 500), 200, ok(course, false)),
+		},
+		"GET /api/course/{id}/waitlist": {
+			OperationID: "listWaitlist",
+			Summary:     "List the persons waiting for a seat in a course, next in line first",
+			Tags:        []string{"course"},
+			Parameters:  []openapi.Parameter{courseID},
+			Responses:   with(errorResponses(400, 404, 406, 500), 200, ok(waitlist, false)),
+		},
+		"POST /api/course/{id}/waitlist": {
+			OperationID: "joinWaitlist",
+			Summary:     "Put a person on the waitlist of a full course",
+			Description: "Persons on the waitlist are enrolled as students, in the order they joined, as seats free up. " +
+				"Courses with free seats and persons already students of the course are rejected with a 422.",
+			Tags:        []string{"course"},
+			Parameters:  []openapi.Parameter{courseID, overridePrerequisites, overrideRules},
+			RequestBody: requestBody(waitlistIn),
+			Responses: with(errorResponses(400, 403, 404, 406, 413, 415, 422, 500), 201, &openapi.Response{
+				Description: "Created; the person's place on the waitlist",
+				Content:     responseContent(waitlistEntry, false),
+			}),
+		},
+		"DELETE /api/course/{id}/waitlist/{firstName}": {
+			OperationID: "leaveWaitlist",
+			Summary:     "Take a person off the waitlist of a course",
+			Tags:        []string{"course"},
+			Parameters:  []openapi.Parameter{courseID, firstName},
+			Responses:   with(errorResponses(400, 404, 406, 500), 200, &openapi.Response{Description: "Removed"}),
+		},
+		"GET /api/course/{id}/prerequisites": {
+			OperationID: "listPrerequisites",
+			Summary:     "List the prerequisites of a course, each with its own",
+			Tags:        []string{"course"},
+			Parameters:  []openapi.Parameter{courseID},
+			Responses:   with(errorResponses(400, 404, 406, 500), 200, ok(prerequisites, false)),
+		},
+		"POST /api/course/{id}/prerequisites": {
+			OperationID: "addPrerequisite",
+			Summary:     "Make a course require another",
+			Description: "Students must have completed a course's prerequisites to enroll in it. " +
+				"Prerequisites that already require the course, directly or not, would make a cycle and are rejected with a 422.",
+			Tags:        []string{"course"},
+			Parameters:  []openapi.Parameter{courseID},
+			RequestBody: requestBody(prerequisiteIn),
+			Responses:   with(errorResponses(400, 404, 406, 413, 415, 422, 500), 200, ok(prerequisites, false)),
+		},
+		"DELETE /api/course/{id}/prerequisites/{prerequisiteID}": {
+			OperationID: "removePrerequisite",
+			Summary:     "Stop a course from requiring another",
+			Tags:        []string{"course"},
+			Parameters:  []openapi.Parameter{courseID, prerequisiteID},
+			Responses:   with(errorResponses(400, 404, 406, 500), 200, &openapi.Response{Description: "Removed"}),
+		},
+		"GET /api/course/{id}/schedule.ics": {
+			OperationID: "getCourseCalendar",
+			Summary:     "Get the meetings of a course as an iCalendar feed",
+			Description: calendarDescription + " Has an event for each meeting of each section of the course.",
+			Tags:        []string{"course"},
+			Parameters:  []openapi.Parameter{courseID, calendarToken},
+			Responses:   with(errorResponses(400, 403, 404, 406, 500), 200, calendarOK),
+		},
+		"GET /api/course/{id}/schedule/subscription": {
+			OperationID: "subscribeCourseCalendar",
+			Summary:     "Get the URL of the iCalendar feed of a course",
+			Description: "Anyone may subscribe to the feed of a course. Responds 403 if calendar feeds are disabled.",
+			Tags:        []string{"course"},
+			Parameters:  []openapi.Parameter{courseID},
+			Responses:   with(errorResponses(400, 403, 404, 406, 500), 200, ok(subscription, false)),
+		},
+		"GET /api/person/": {
+			OperationID: "listPersons",
+			Summary:     "List persons",
+			Tags:        []string{"person"},
+			Parameters:  slices.Concat(personFilters, page),
+			Responses:   with(errorResponses(400, 403, 406, 500), 200, ok(persons, true)),
+		},
+		"POST /api/person/": {
+			OperationID: "createPerson",
+			Summary:     "Create a person",
+			Tags:        []string{"person"},
+			Parameters:  []openapi.Parameter{overridePrerequisites, overrideRules},
+			RequestBody: requestBody(personIn),
+			Responses: with(errorResponses(400, 403, 406, 413, 415, 422, 500), 201, &openapi.Response{
+				Description: "Created",
+				Content:     responseContent(person, false),
+			}),
+		},
+		"GET /api/person/{firstName}": {
+			OperationID: "getPerson",
+			Summary:     "Get a person by first name",
+			Tags:        []string{"person"},
+			Parameters:  []openapi.Parameter{firstName, asOf},
+			Responses:   with(errorResponses(400, 406, 500), 200, ok(person, false)),
+		},
+		"PUT /api/person/{firstName}": {
+			OperationID: "updatePerson",
+			Summary:     "Update a person by first name",
+			Tags:        []string{"person"},
+			Parameters:  []openapi.Parameter{firstName, overridePrerequisites, overrideRules},
+			RequestBody: requestBody(personIn),
+			Responses: with(errorResponses(400, 403, 406, 413, 415, 422, 500), 200, &openapi.Response{
+				Description: "OK; the updated person is returned without the data wrapper",
+				Content:     responseContent(openapi.Ref("outputPerson"), false),
+			}),
+		},
+		"DELETE /api/person/{firstName}": {
+			OperationID: "deletePerson",
+			Summary:     "Soft delete a person by first name",
+			Tags:        []string{"person"},
+			Parameters:  []openapi.Parameter{firstName},
+			Responses:   with(errorResponses(400, 406, 500), 200, &openapi.Response{Description: "Deleted"}),
+		},
+		"POST /api/person/{id}/restore": {
+			OperationID: "restorePerson",
+			Summary:     "Restore a soft deleted person and their enrollments by id",
+			Tags:        []string{"person"},
+			Parameters:  []openapi.Parameter{personID},
+			Responses:   with(errorResponses(400, 404, 406, 500), 200, ok(person, false)),
+		},
+		"GET /api/person/{firstName}/waitlist": {
+			OperationID: "listWaitlistPositions",
+			Summary:     "List a person's places on the waitlists of the courses they are waiting for",
+			Tags:        []string{"person"},
+			Parameters:  []openapi.Parameter{firstName},
+			Responses:   with(errorResponses(400, 404, 406, 500), 200, ok(waitlist, false)),
+		},
+		"POST /api/person/{firstName}/eligibility": {
+			OperationID: "checkEligibility",
+			Summary:     "Check whether a person could be enrolled in a course, without enrolling them",
+			Tags:        []string{"person"},
+			Parameters:  []openapi.Parameter{firstName, overridePrerequisites, overrideRules},
+			RequestBody: requestBody(eligibilityIn),
+			Responses:   with(errorResponses(400, 403, 404, 406, 413, 415, 500), 200, ok(eligibility, false)),
+		},
+		"GET /api/person/{id}/transcript": {
+			OperationID: "getTranscript",
+			Summary:     "Get a person's transcript by id",
+			Description: "Lists the courses the person took as a student by term, with grades, credits and term and cumulative GPAs. " +
+				"Only letter grades count towards GPAs, which are omitted until one is recorded. " +
+				"Also rendered as plain text and HTML.",
+			Tags:       []string{"person"},
+			Parameters: []openapi.Parameter{personID},
+			Responses: with(errorResponses(400, 404, 406, 500), 200, &openapi.Response{
+				Description: "OK",
+				Content:     documentContent(transcript),
+			}),
+		},
+		"GET /api/person/{id}/schedule": {
+			OperationID: "getSchedule",
+			Summary:     "Get the weekly schedule of a person",
+			Description: "Lists the meetings of the sections the person is enrolled in or the instructor of, for each weekday from Monday. " +
+				"Ask for text/plain or text/html to get a printable week view.",
+			Tags: []string{"person"},
+			Parameters: []openapi.Parameter{
+				personID,
+				queryParam("term", "Term id; defaults to the current term", positiveInt()),
+			},
+			Responses: with(errorResponses(400, 404, 406, 500), 200, &openapi.Response{
+				Description: "OK",
+				Content:     documentContent(schedule),
+			}),
+		},
+		"GET /api/person/{id}/schedule.ics": {
+			OperationID: "getPersonCalendar",
+			Summary:     "Get the meetings of a person as an iCalendar feed",
+			Description: calendarDescription + " Has an event for each meeting of each section the person is enrolled in or the instructor of.",
+			Tags:        []string{"person"},
+			Parameters:  []openapi.Parameter{personID, calendarToken},
+			Responses:   with(errorResponses(400, 403, 404, 406, 500), 200, calendarOK),
+		},
+		"GET /api/person/{id}/schedule/subscription": {
+			OperationID: "subscribePersonCalendar",
+			Summary:     "Get the URL of the iCalendar feed of a person",
+			Description: "Only a caller with the " + AdminTokenHeader + " header may subscribe. Responds 403 otherwise or if calendar feeds are disabled.",
+			Tags:        []string{"person"},
+			Parameters:  []openapi.Parameter{personID},
+			Responses:   with(errorResponses(400, 403, 404, 406, 500), 200, ok(subscription, false)),
+		},
+		"GET /api/term/": {
+			OperationID: "listTerms",
+			Summary:     "List terms in the order they start",
+			Tags:        []string{"term"},
+			Responses:   with(errorResponses(406, 500), 200, ok(terms, true)),
+		},
+		"POST /api/term/": {
+			OperationID: "createTerm",
+			Summary:     "Create a term",
+			Description: "The term that started most recently is the current term, which the course and person routes act on.",
+			Tags:        []string{"term"},
+			RequestBody: requestBody(termIn),
+			Responses: with(errorResponses(400, 406, 413, 415, 422, 500), 201, &openapi.Response{
+				Description: "Created",
+				Content:     responseContent(term, false),
+			}),
+		},
+		"GET /api/term/{id}": {
+			OperationID: "getTerm",
+			Summary:     "Get a term by id",
+			Tags:        []string{"term"},
+			Parameters:  []openapi.Parameter{termID},
+			Responses:   with(errorResponses(400, 404, 406, 500), 200, ok(term, false)),
+		},
+		"PUT /api/term/{id}": {
+			OperationID: "updateTerm",
+			Summary:     "Update a term",
+			Tags:        []string{"term"},
+			Parameters:  []openapi.Parameter{termID},
+			RequestBody: requestBody(termIn),
+			Responses:   with(errorResponses(400, 404, 406, 413, 415, 422, 500), 200, ok(term, false)),
+		},
+		"DELETE /api/term/{id}": {
+			OperationID: "deleteTerm",
+			Summary:     "Delete a term without sections",
+			Tags:        []string{"term"},
+			Parameters:  []openapi.Parameter{termID},
+			Responses:   with(errorResponses(400, 404, 406, 422, 500), 200, &openapi.Response{Description: "Deleted"}),
+		},
+		"GET /api/department/": {
+			OperationID: "listDepartments",
+			Summary:     "List departments by code",
+			Tags:        []string{"department"},
+			Responses:   with(errorResponses(406, 500), 200, ok(departments, true)),
+		},
+		"POST /api/department/": {
+			OperationID: "createDepartment",
+			Summary:     "Create a department",
+			Description: "Codes and names are unique. The chair must be a professor.",
+			Tags:        []string{"department"},
+			RequestBody: requestBody(departmentIn),
+			Responses: with(errorResponses(400, 406, 413, 415, 422, 500), 201, &openapi.Response{
+				Description: "Created",
+				Content:     responseContent(department, false),
+			}),
+		},
+		"GET /api/department/{id}": {
+			OperationID: "getDepartment",
+			Summary:     "Get a department by id",
+			Tags:        []string{"department"},
+			Parameters:  []openapi.Parameter{departmentID},
+			Responses:   with(errorResponses(400, 404, 406, 500), 200, ok(department, false)),
+		},
+		"PUT /api/department/{id}": {
+			OperationID: "updateDepartment",
+			Summary:     "Update a department",
+			Description: "A new code changes the catalog codes of the department's courses with it.",
+			Tags:        []string{"department"},
+			Parameters:  []openapi.Parameter{departmentID},
+			RequestBody: requestBody(departmentIn),
+			Responses:   with(errorResponses(400, 404, 406, 413, 415, 422, 500), 200, ok(department, false)),
+		},
+		"DELETE /api/department/{id}": {
+			OperationID: "deleteDepartment",
+			Summary:     "Delete a department that offers no courses",
+			Tags:        []string{"department"},
+			Parameters:  []openapi.Parameter{departmentID},
+			Responses:   with(errorResponses(400, 404, 406, 422, 500), 200, &openapi.Response{Description: "Deleted"}),
+		},
+		"GET /api/room/": {
+			OperationID: "listRooms",
+			Summary:     "List rooms by name",
+			Tags:        []string{"room"},
+			Responses:   with(errorResponses(406, 500), 200, ok(rooms, true)),
+		},
+		"POST /api/room/": {
+			OperationID: "createRoom",
+			Summary:     "Create a room",
+			Tags:        []string{"room"},
+			RequestBody: requestBody(roomIn),
+			Responses: with(errorResponses(400, 406, 413, 415, 422, 500), 201, &openapi.Response{
+				Description: "Created",
+				Content:     responseContent(room, false),
+			}),
+		},
+		"GET /api/room/{id}": {
+			OperationID: "getRoom",
+			Summary:     "Get a room by id",
+			Tags:        []string{"room"},
+			Parameters:  []openapi.Parameter{roomID},
+			Responses:   with(errorResponses(400, 404, 406, 500), 200, ok(room, false)),
+		},
+		"PUT /api/room/{id}": {
+			OperationID: "updateRoom",
+			Summary:     "Update a room",
+			Description: "The capacity cannot be lowered below that of a section meeting in the room.",
+			Tags:        []string{"room"},
+			Parameters:  []openapi.Parameter{roomID},
+			RequestBody: requestBody(roomIn),
+			Responses:   with(errorResponses(400, 404, 406, 413, 415, 422, 500), 200, ok(room, false)),
+		},
+		"DELETE /api/room/{id}": {
+			OperationID: "deleteRoom",
+			Summary:     "Delete a room no section meets in",
+			Tags:        []string{"room"},
+			Parameters:  []openapi.Parameter{roomID},
+			Responses:   with(errorResponses(400, 404, 406, 422, 500), 200, &openapi.Response{Description: "Deleted"}),
+		},
+		"GET /api/section/": {
+			OperationID: "listSections",
+			Summary:     "List the sections of courses",
+			Tags:        []string{"section"},
+			Parameters:  slices.Concat(sectionFilters, page),
+			Responses:   with(errorResponses(400, 406, 500), 200, ok(sections, true)),
+		},
+		"POST /api/section/": {
+			OperationID: "createSection",
+			Summary:     "Offer a course in a term",
+			Description: "Number defaults to the next free one and capacity to the course's. " +
+				"Meetings that clash with the instructor's other sections, or with another section in the same room, are rejected with a 422.",
+			Tags:        []string{"section"},
+			RequestBody: requestBody(sectionIn),
+			Responses: with(errorResponses(400, 406, 413, 415, 422, 500), 201, &openapi.Response{
+				Description: "Created",
+				Content:     responseContent(section, false),
+			}),
+		},
+		"GET /api/section/{id}": {
+			OperationID: "getSection",
+			Summary:     "Get a section by id",
+			Tags:        []string{"section"},
+			Parameters:  []openapi.Parameter{sectionID},
+			Responses:   with(errorResponses(400, 404, 406, 500), 200, ok(section, false)),
+		},
+		"PUT /api/section/{id}": {
+			OperationID: "updateSection",
+			Summary:     "Update a section",
+			Description: "The course and term of a section cannot be changed. Raising its capacity enrolls persons from its waitlist. " +
+				"Meetings replace the section's and are checked as on creation, and also against the other sections of the persons enrolled.",
+			Tags:        []string{"section"},
+			Parameters:  []openapi.Parameter{sectionID},
+			RequestBody: requestBody(sectionIn),
+			Responses:   with(errorResponses(400, 404, 406, 413, 415, 422, 500), 200, ok(section, false)),
+		},
+		"DELETE /api/section/{id}": {
+			OperationID: "deleteSection",
+			Summary:     "Delete a section nobody is enrolled in",
+			Tags:        []string{"section"},
+			Parameters:  []openapi.Parameter{sectionID},
+			Responses:   with(errorResponses(400, 404, 406, 422, 500), 200, &openapi.Response{Description: "Deleted"}),
+		},
+		"POST /api/section/{id}/enrollment": {
+			OperationID: "enrollSection",
+			Summary:     "Enroll a person in a section",
+			Description: "A person holds at most one section of a course per term, and no two sections that meet at the same time. " +
+				"Students are rejected from full sections with a 422; they can join the course's waitlist instead.",
+			Tags:        []string{"section"},
+			Parameters:  []openapi.Parameter{sectionID, overridePrerequisites, overrideRules},
+			RequestBody: requestBody(sectionEnrollmentIn),
+			Responses:   with(errorResponses(400, 403, 404, 406, 413, 415, 422, 500), 200, ok(section, false)),
+		},
+		"DELETE /api/section/{id}/enrollment/{firstName}": {
+			OperationID: "withdrawSection",
+			Summary:     "Withdraw a person from a section",
+			Tags:        []string{"section"},
+			Parameters:  []openapi.Parameter{sectionID, firstName},
+			Responses:   with(errorResponses(400, 404, 406, 500), 200, ok(section, false)),
+		},
+		"GET /api/section/{id}/grades": {
+			OperationID: "listGrades",
+			Summary:     "List the grades recorded in a section",
+			Tags:        []string{"section"},
+			Parameters:  []openapi.Parameter{sectionID},
+			Responses:   with(errorResponses(400, 404, 406, 500), 200, ok(grades, true)),
+		},
+		"POST /api/section/{id}/grades": {
+			OperationID: "submitGrades",
+			Summary:     "Record the grades of students of a section",
+			Description: "Grades are letters of the configured grade scale, P or NP for pass/fail, or I for incomplete. " +
+				"Requires the " + AdminTokenHeader + " header, and a professor teaching the section to be named as the grader in the " + ActorHeader + " header. " +
+				"Students who already have a grade are rejected with a 422; amend their grade instead.",
+			Tags:        []string{"section"},
+			Parameters:  []openapi.Parameter{sectionID},
+			RequestBody: requestBody(gradesIn),
+			Responses: with(errorResponses(400, 403, 404, 406, 413, 415, 422, 500), 201, &openapi.Response{
+				Description: "Created; every grade of the section",
+				Content:     responseContent(grades, false),
+			}),
+		},
+		"PUT /api/section/{id}/grades/{firstName}": {
+			OperationID: "amendGrade",
+			Summary:     "Amend the grade of a student in a section",
+			Description: "Requires the " + AdminTokenHeader + " header, and a professor teaching the section to be named as the grader in the " + ActorHeader + " header.",
+			Tags:        []string{"section"},
+			Parameters:  []openapi.Parameter{sectionID, firstName},
+			RequestBody: requestBody(gradeAmendmentIn),
+			Responses:   with(errorResponses(400, 403, 404, 406, 413, 415, 422, 500), 200, ok(grade, false)),
+		},
+		"GET /api/audit/": {
+			OperationID: "listAuditEvents",
+			Summary:     "List audit events, newest first",
+			Description: "Requires the " + AdminTokenHeader + " header, as events include the full records of deleted entities.",
+			Tags:        []string{"audit"},
+			Parameters: []openapi.Parameter{
+				queryParam("entity", "Audited entity", &openapi.Schema{Type: "string", Enum: []any{services.EntityCourse, services.EntityPerson, services.EntityTerm, services.EntitySection, services.EntityRoom, services.EntityDepartment, services.EntityPrerequisite, services.EntityGrade}}),
+				queryParam("entity_id", "Id of the audited entity", positiveInt()),
+				queryParam("actor", "Caller recorded from the "+ActorHeader+" header", &openapi.Schema{Type: "string"}),
+				queryParam("from", "Earliest event time, inclusive", &openapi.Schema{Type: "string", Format: "date-time"}),
+				queryParam("to", "Latest event time, exclusive", &openapi.Schema{Type: "string", Format: "date-time"}),
+				queryParam("limit", "Maximum number of events, 100 by default", intRange(1, maxAuditLimit)),
+			},
+			Responses: with(errorResponses(400, 403, 406, 500), 200, ok(auditEvents, false)),
+		},
+		"GET /api/webhook/": {
+			OperationID: "listWebhooks",
+			Summary:     "List webhook subscriptions",
+			Tags:        []string{"webhook"},
+			Responses:   with(errorResponses(403, 406, 500), 200, ok(webhooks, false)),
+		},
+		"POST /api/webhook/": {
+			OperationID: "createWebhook",
+			Summary:     "Register a webhook subscription; the response includes the signing secret",
+			Tags:        []string{"webhook"},
+			RequestBody: requestBody(webhookIn),
+			Responses: with(errorResponses(400, 403, 406, 413, 415, 500), 201, &openapi.Response{
+				Description: "Created",
+				Content:     responseContent(webhook, false),
+			}),
+		},
+		"GET /api/webhook/{id}": {
+			OperationID: "getWebhook",
+			Summary:     "Get a webhook subscription by id",
+			Tags:        []string{"webhook"},
+			Parameters:  []openapi.Parameter{webhookID},
+			Responses:   with(errorResponses(400, 403, 404, 406, 500), 200, ok(webhook, false)),
+		},
+		"PUT /api/webhook/{id}": {
+			OperationID: "updateWebhook",
+			Summary:     "Update a webhook subscription; the secret is kept",
+			Tags:        []string{"webhook"},
+			Parameters:  []openapi.Parameter{webhookID},
+			RequestBody: requestBody(webhookIn),
+			Responses:   with(errorResponses(400, 403, 404, 406, 413, 415, 500), 200, ok(webhook, false)),
+		},
+		"DELETE /api/webhook/{id}": {
+			OperationID: "deleteWebhook",
+			Summary:     "Delete a webhook subscription and its deliveries",
+			Tags:        []string{"webhook"},
+			Parameters:  []openapi.Parameter{webhookID},
+			Responses:   with(errorResponses(400, 403, 404, 406, 500), 200, &openapi.Response{Description: "Deleted"}),
+		},
+		"GET /api/webhook/{id}/deliveries": {
+			OperationID: "listWebhookDeliveries",
+			Summary:     "List deliveries of a webhook subscription, newest first",
+			Tags:        []string{"webhook"},
+			Parameters: []openapi.Parameter{
+				webhookID,
+				queryParam("status", "Delivery status", &openapi.Schema{Type: "string", Enum: []any{services.DeliveryPending, services.DeliveryDelivered, services.DeliveryDead}}),
+				queryParam("limit", "Maximum number of deliveries, 100 by default", intRange(1, maxDeliveryLimit)),
+			},
+			Responses: with(errorResponses(400, 403, 406, 500), 200, ok(deliveries, false)),
+		},
+		"POST /api/webhook/{id}/deliveries/{deliveryID}/replay": {
+			OperationID: "replayWebhookDelivery",
+			Summary:     "Send a delivery again with a fresh retry budget",
+			Tags:        []string{"webhook"},
+			Parameters: []openapi.Parameter{
+				webhookID,
+				{Name: "deliveryID", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}},
+			},
+			Responses: with(errorResponses(400, 403, 404, 406, 500), 202, &openapi.Response{
+				Description: "Accepted",
+				Content:     responseContent(replay, false),
+			}),
+		},
+		"POST /api/webhook/{id}/replay": {
+			OperationID: "replayWebhookEvents",
+			Summary:     "Send every event since a time to a webhook subscription again",
+			Tags:        []string{"webhook"},
+			Parameters: []openapi.Parameter{
+				webhookID,
+				{Name: "from", In: "query", Required: true, Description: "Earliest event time, inclusive", Schema: &openapi.Schema{Type: "string", Format: "date-time"}},
+			},
+			Responses: with(errorResponses(400, 403, 404, 406, 500), 202, &openapi.Response{
+				Description: "Accepted",
+				Content:     responseContent(replay, false),
+			}),
+		},
+		"GET /api/events": {
+			OperationID: "streamEvents",
+			Summary:     "Stream changes to persons, courses and enrollments as Server-Sent Events",
+			Description: "Each event's id is its position in the change log. Reconnect with the Last-Event-ID header, " +
+				"or the last_event_id parameter, to be sent the events missed in between. Requires the " + AdminTokenHeader + " header.",
+			Tags: []string{"events"},
+			Parameters: []openapi.Parameter{
+				queryParam("entity", "Only stream events about these entities: "+strings.Join(eventEntities, ", ")+"; repeat or separate with commas", &openapi.Schema{Type: "string"}),
+				queryParam("last_event_id", "Resume after the event with this id in the stream", &openapi.Schema{Type: "integer", Minimum: new(float64)}),
+			},
+			Responses: with(errorResponses(400, 403, 500), 200, &openapi.Response{
+				Description: "Event stream",
+				Content:     map[string]openapi.MediaType{"text/event-stream": {Schema: &openapi.Schema{Type: "string"}}},
+			}),
+		},
+		"GET /graphql": {
+			OperationID: "queryGraphQL",
+			Summary:     "Run a GraphQL query; mutations must be sent with POST",
+			Description: graphqlDescription,
+			Tags:        []string{"graphql"},
+			Parameters: []openapi.Parameter{
+				{Name: "query", In: "query", Required: true, Description: "GraphQL document", Schema: &openapi.Schema{Type: "string"}},
+				queryParam("operationName", "Operation to run if the document has several", &openapi.Schema{Type: "string"}),
+				queryParam("variables", "Variables as a JSON object", &openapi.Schema{Type: "string"}),
+			},
+			Responses: with(errorResponses(400, 500), 200, graphqlOK),
+		},
+		"POST /graphql": {
+			OperationID: "executeGraphQL",
+			Summary:     "Run a GraphQL query or mutation",
+			Description: graphqlDescription,
+			Tags:        []string{"graphql"},
+			RequestBody: requestBody(graphqlIn),
+			Responses:   with(errorResponses(400, 413, 415, 500), 200, graphqlOK),
+		},
+		"POST /api/batch": {
+			OperationID: "runBatch",
+			Summary:     "Run create, update and delete operations on persons, courses and enrollments atomically",
+			Description: batchDescription,
+			Tags:        []string{"batch"},
+			Parameters:  []openapi.Parameter{overridePrerequisites, overrideRules},
+			RequestBody: requestBody(batchIn),
+			Responses: func() map[string]*openapi.Response {
+				responses := with(errorResponses(403, 406, 413, 415), 200, ok(batch, false))
+				for _, status := range []int{400, 404, 422, 500} {
+					responses[statusKey(status)] = &openapi.Response{
+						Description: http.StatusText(status) + "; no operations were applied",
+						Content:     responseContent(batchErr, false),
+					}
+				}
+				return responses
+			}(),
+		},
+		"GET /api/export/persons": {
+			OperationID: "exportPersons",
+			Summary:     "Export persons with their course names",
+			Tags:        []string{"export"},
+			Parameters:  append([]openapi.Parameter{exportFormat}, personFilters...),
+			Responses:   with(errorResponses(400, 403, 500), 200, exportOK),
+		},
+		"GET /api/export/courses": {
+			OperationID: "exportCourses",
+			Summary:     "Export courses",
+			Tags:        []string{"export"},
+			Parameters:  append([]openapi.Parameter{exportFormat}, courseFilters...),
+			Responses:   with(errorResponses(400, 403, 500), 200, exportOK),
+		},
+		"GET /api/export/enrollments": {
+			OperationID: "exportEnrollments",
+			Summary:     "Export the person by course enrollment matrix",
+			Tags:        []string{"export"},
+			Parameters: append([]openapi.Parameter{
+				exportFormat,
+				queryParam("course_name", "Case-insensitive substring of the course names to include as columns", &openapi.Schema{Type: "string"}),
+			}, personFilters...),
+			Responses: with(errorResponses(400, 403, 500), 200, exportOK),
+		},
+	}
+}
+
+// graphqlDescription documents the GraphQL limits on both /graphql operations.
+var graphqlDescription = fmt.Sprintf("Operations may nest at most %d fields deep and are rejected with 400 if "+
+	"their estimated complexity exceeds %d. Introspection fields are not counted.", graph.MaxDepth, graph.MaxComplexity)
+
+// courseDescription documents the catalog rules checked when a course is
+// created or updated.
+var courseDescription = "A catalog_number needs a department_id and makes the catalog code with the department's code, e.g. CS-201. " +
+	"Catalog numbers are unique within a department, counting deleted courses; a department that does not exist or a taken number is rejected with a 422."
+
+// calendarDescription documents both calendar feeds.
+var calendarDescription = "Always rendered as text/calendar (RFC 5545), for calendar apps to subscribe to. " +
+	"Each event recurs weekly from the start to the end of the section's term and keeps its UID when the section changes, " +
+	"so apps replace it. Times are floating: shown at the same wall clock time in any time zone. " +
+	"Responds 403 unless token is that of the feed's subscription."
+
+// batchDescription documents the operations accepted by POST /api/batch.
+var batchDescription = fmt.Sprintf("Runs up to %d operations in order in one transaction. "+
+	"Each operation's op is create, update or delete and its entity is course, person or enrollment. "+
+	"The target of an update or delete is the course id or the person's first name, as in the REST paths. "+
+	"The body is the request body of the equivalent REST route; enrollments take {\"first_name\", \"course_id\", \"role\"} "+
+	"and cannot be updated, but creating one for a person already enrolled changes their role. A string \"$ops[N].path\", e.g. \"$ops[0].id\", anywhere in a target or body is "+
+	"replaced by the value at path in the result of the earlier operation N. If an operation fails nothing is "+
+	"applied: the response has the failing operation's status and error, and every other operation is reported "+
+	"with 424.", MaxBatchOperations)
+
+// HandleOpenAPI serves the OpenAPI document as JSON. It bypasses content
+// negotiation since the document is only published as JSON.
+func HandleOpenAPI(logger *httplog.Logger, doc *openapi.Document) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeEncoded(w, logger, encoders[0], http.StatusOK, doc)
+	}
+}
+
+func queryParam(name, description string, schema *openapi.Schema) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func positiveInt() *openapi.Schema {
+	one := 1.0
+	return &openapi.Schema{Type: "integer", Minimum: &one}
+}
+
+func intRange(min, max float64) *openapi.Schema {
+	return &openapi.Schema{Type: "integer", Minimum: &min, Maximum: &max}
+}
+
+func statusKey(status int) string {
+	return strconv.Itoa(status)
+}
+
+// requestBody documents a body accepted in every media type with a registered
+// decoder.
+func requestBody(schema *openapi.Schema) *openapi.RequestBody {
+	content := map[string]openapi.MediaType{}
+	for _, dec := range decoders {
+		content[dec.mediaTypes[0]] = openapi.MediaType{Schema: schema}
+	}
+	return &openapi.RequestBody{Required: true, Content: content}
+}
+
+// responseContent documents a response in every negotiable media type. CSV
+// is only offered for list responses.
+func responseContent(schema *openapi.Schema, tabular bool) map[string]openapi.MediaType {
+	content := map[string]openapi.MediaType{}
+	for _, enc := range encoders {
+		switch {
+		case enc.supports == nil:
+			content[enc.mediaType] = openapi.MediaType{Schema: schema}
+		case tabular && enc.mediaType == "text/csv":
+			content[enc.mediaType] = openapi.MediaType{Schema: &openapi.Schema{Type: "string"}}
+		}
+	}
+	return content
+}
+
+// documentContent documents a response that is also rendered as plain text
+// and HTML.
+func documentContent(schema *openapi.Schema) map[string]openapi.MediaType {
+	content := responseContent(schema, false)
+	for _, mediaType := range []string{"text/plain", "text/html"} {
+		content[mediaType] = openapi.MediaType{Schema: &openapi.Schema{Type: "string"}}
+	}
+	return content
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleReplayWebhookEvents queues every event since the required from
+// timestamp for delivery to a webhook subscription again
+func HandleReplayWebhookEvents(logger *httplog.Logger, svsWebhook *services.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		webhookID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid webhook ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid webhook ID",
+			})
+			return
+		}
+
+		from, problems := parseTimeParam(r.URL.Query().Get("from"), "from", nil)
+		if from.IsZero() && len(problems) == 0 {
+			problems = append(problems, problem{
+				Name:        "from",
+				Description: "is required",
+			})
+		}
+		if len(problems) > 0 {
+			logger.Error("Problems validating replay", "problems", problems)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				ValidationErrors: problems,
+			})
+			return
+		}
+
+		queued, err := svsWebhook.ReplayEvents(ctx, webhookID, from)
+		if err != nil {
+			logger.Error("error replaying webhook events", "error", err)
+			if errors.Is(err, sql.ErrNoRows) {
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No webhook with that ID",
+				})
+				return
+			}
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error replaying events",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusAccepted, responseReplay{Queued: queued})
+	}
+}
@@ -1,102 +1,465 @@
 package handlers
 
 import (
+	"encoding/xml"
 	"fmt"
-	"encoding/json"
 	"net/http"
+	"slices"
+	"time"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/graph"
 	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
 )
 
 type inputCourse struct {
-	Name        string `json:"name"`
+	XMLName     xml.Name `json:"-" xml:"course"`
+	Name        string   `json:"name" xml:"name" validate:"required"`
+	// Capacity is the number of student seats; omit it for no limit.
+	Capacity    *int     `json:"capacity,omitempty" xml:"capacity,omitempty" validate:"omitempty,min=1"`
+	// Credits defaults to 3.
+	Credits       *int   `json:"credits,omitempty" xml:"credits,omitempty" validate:"omitempty,min=1,max=12"`
+	DepartmentID  *int   `json:"department_id,omitempty" xml:"department_id,omitempty" validate:"omitempty,min=1"`
+	// CatalogNumber makes the catalog code with the department's code, e.g.
+	// 201 in CS-201. It needs a department.
+	CatalogNumber string `json:"catalog_number,omitempty" xml:"catalog_number,omitempty" validate:"omitempty,regex=^[0-9]{3}[A-Z]?$"`
+	Description   string `json:"description,omitempty" xml:"description,omitempty" validate:"max=2000"`
 }
 
 type inputPerson struct {
-	FirstName   string `json:"first_name"`
-	LastName    string `json:"last_name"`
-	Type		string `json:"type"`
-	Age         int    `json:"age"`
-	Courses     []int  `json:"courses"`
+	XMLName     xml.Name `json:"-" xml:"person"`
+	FirstName   string   `json:"first_name" xml:"first_name" validate:"required"`
+	LastName    string   `json:"last_name" xml:"last_name" validate:"required"`
+	Type		string   `json:"type" xml:"type" validate:"oneof=student professor"`
+	// DateOfBirth is formatted as 2006-01-02; the age is computed from it.
+	DateOfBirth string   `json:"date_of_birth" xml:"date_of_birth" validate:"required,regex=^\\d{4}-\\d{2}-\\d{2}$"`
+	// Email, Phone and Address are optional. Phone is in E.164 format, e.g.
+	// +15555550100.
+	Email       string   `json:"email,omitempty" xml:"email,omitempty" validate:"omitempty,max=254,regex=^[^@\\s]+@[^@\\s]+\\.[^@\\s]+$"`
+	Phone       string   `json:"phone,omitempty" xml:"phone,omitempty" validate:"omitempty,regex=^\\+[1-9][0-9]{6,14}$"`
+	Address     string   `json:"address,omitempty" xml:"address,omitempty" validate:"max=500"`
+	Courses     []int    `json:"courses,omitempty" xml:"courses>course" validate:"dive,min=1"`
+	// Enrollments gives the person a role in courses; courses listed only in
+	// Courses get the default role for the person's type.
+	Enrollments []inputCourseRole `json:"enrollments,omitempty" xml:"enrollments>enrollment"`
+}
+
+// inputCourseRole is a person's role in a course.
+type inputCourseRole struct {
+	CourseID int    `json:"course_id" xml:"course_id" validate:"min=1"`
+	Role     string `json:"role" xml:"role" validate:"oneof=instructor teaching_assistant student auditor"`
+}
+
+// CourseInput, PersonInput and CourseRoleInput are the course and person
+// bodies for other transports, such as gRPC, to convert their requests to, so
+// that Valid and MapTo apply the same rules as to REST requests.
+type (
+	CourseInput     = inputCourse
+	PersonInput     = inputPerson
+	CourseRoleInput = inputCourseRole
+)
+
+// inputWebhook registers or updates a webhook subscription. Secret is only
+// used on creation; one is generated if it is omitted. Active defaults to
+// true.
+type inputWebhook struct {
+	XMLName     xml.Name `json:"-" xml:"webhook"`
+	URL         string   `json:"url" xml:"url" validate:"required,regex=^https?://[^/]+"`
+	Events      []string `json:"events,omitempty" xml:"events>event" validate:"unique,dive,oneof=person.created person.updated person.deleted person.restored course.created course.updated course.deleted course.restored term.created term.updated term.deleted section.created section.updated section.deleted enrollment.added enrollment.removed prerequisite.added prerequisite.removed grade.submitted grade.amended room.created room.updated room.deleted department.created department.updated department.deleted"`
+	Secret      string   `json:"secret,omitempty" xml:"secret,omitempty" validate:"omitempty,min=16"`
+	Active      *bool    `json:"active,omitempty" xml:"active,omitempty"`
+}
+
+// inputGraphQL is a GraphQL request. Variables are only accepted in JSON and
+// MessagePack bodies.
+type inputGraphQL struct {
+	XMLName       xml.Name       `json:"-" xml:"request"`
+	Query         string         `json:"query" xml:"query" validate:"required"`
+	OperationName string         `json:"operationName,omitempty" xml:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty" xml:"-"`
+}
+
+// inputBatch is a list of operations run in one transaction by HandleBatch.
+// Targets and bodies are only accepted in JSON and MessagePack bodies.
+type inputBatch struct {
+	XMLName     xml.Name              `json:"-" xml:"batch"`
+	Operations  []inputBatchOperation `json:"operations" xml:"operations>operation" validate:"min=1,max=100"`
+}
+
+// inputBatchOperation is one operation of a batch. Target names the record to
+// update or delete as the equivalent REST route does in its path: a course id
+// or a person's first name. Body is the request body of that route, or an
+// inputEnrollment for enrollments.
+type inputBatchOperation struct {
+	Op          string   `json:"op" xml:"op" validate:"oneof=create update delete"`
+	Entity      string   `json:"entity" xml:"entity" validate:"oneof=course person enrollment"`
+	Target      any      `json:"target,omitempty" xml:"-"`
+	Body        any      `json:"body,omitempty" xml:"-"`
+}
+
+// inputEnrollment enrolls a person in a course, or withdraws them from it.
+// Role defaults to the person's default role when enrolling and is ignored
+// when withdrawing.
+type inputEnrollment struct {
+	XMLName     xml.Name `json:"-" xml:"enrollment"`
+	FirstName   string   `json:"first_name" xml:"first_name" validate:"required"`
+	CourseID    int      `json:"course_id" xml:"course_id" validate:"min=1"`
+	Role        string   `json:"role,omitempty" xml:"role,omitempty" validate:"omitempty,oneof=instructor teaching_assistant student auditor"`
+}
+
+// inputWaitlist puts a person on the waitlist of a course.
+type inputWaitlist struct {
+	XMLName   xml.Name `json:"-" xml:"waitlist"`
+	FirstName string   `json:"first_name" xml:"first_name" validate:"required"`
+}
+
+// inputTerm creates or updates a term. Dates are formatted as 2006-01-02.
+type inputTerm struct {
+	XMLName   xml.Name `json:"-" xml:"term"`
+	Name      string   `json:"name" xml:"name" validate:"required"`
+	StartDate string   `json:"start_date" xml:"start_date" validate:"regex=^\\d{4}-\\d{2}-\\d{2}$"`
+	EndDate   string   `json:"end_date" xml:"end_date" validate:"gtefield=StartDate,regex=^\\d{4}-\\d{2}-\\d{2}$"`
+}
+
+// inputSection creates or updates a section. Number defaults to the next
+// free one and Capacity to the course's on creation; on update, CourseID and
+// TermID must be those of the section.
+type inputSection struct {
+	XMLName      xml.Name `json:"-" xml:"section"`
+	CourseID     int      `json:"course_id" xml:"course_id" validate:"min=1"`
+	TermID       int      `json:"term_id" xml:"term_id" validate:"min=1"`
+	Number       int      `json:"number,omitempty" xml:"number,omitempty" validate:"omitempty,min=1"`
+	InstructorID *int     `json:"instructor_id,omitempty" xml:"instructor_id,omitempty" validate:"omitempty,min=1"`
+	// Capacity is the number of student seats; omit it for no limit.
+	Capacity     *int     `json:"capacity,omitempty" xml:"capacity,omitempty" validate:"omitempty,min=1"`
+	// Meetings replace those of the section; omit them for none.
+	Meetings []inputMeeting `json:"meetings,omitempty" xml:"meetings>meeting,omitempty" validate:"max=20"`
+}
+
+// inputMeeting is a weekly meeting of a section. Times are formatted as 15:04
+// and RoomID may be omitted until a room is assigned.
+type inputMeeting struct {
+	Days      []string `json:"days" xml:"days>day" validate:"min=1,unique,dive,oneof=mon tue wed thu fri sat sun"`
+	StartTime string   `json:"start_time" xml:"start_time" validate:"regex=^([01]\\d|2[0-3]):[0-5]\\d$"`
+	EndTime   string   `json:"end_time" xml:"end_time" validate:"gtfield=StartTime,regex=^([01]\\d|2[0-3]):[0-5]\\d$"`
+	RoomID    *int     `json:"room_id,omitempty" xml:"room_id,omitempty" validate:"omitempty,min=1"`
+}
+
+// inputRoom creates or updates a room.
+type inputRoom struct {
+	XMLName  xml.Name `json:"-" xml:"room"`
+	Name     string   `json:"name" xml:"name" validate:"required"`
+	Capacity int      `json:"capacity" xml:"capacity" validate:"min=1"`
+}
+
+// inputDepartment creates or updates a department. ChairID is a professor;
+// omit it for no chair.
+type inputDepartment struct {
+	XMLName xml.Name `json:"-" xml:"department"`
+	Code    string   `json:"code" xml:"code" validate:"required,regex=^[A-Z]{2,6}$"`
+	Name    string   `json:"name" xml:"name" validate:"required"`
+	ChairID *int     `json:"chair_id,omitempty" xml:"chair_id,omitempty" validate:"omitempty,min=1"`
+}
+
+// inputSectionEnrollment enrolls a person in a section. Role defaults to the
+// person's default role.
+type inputSectionEnrollment struct {
+	XMLName   xml.Name `json:"-" xml:"enrollment"`
+	FirstName string   `json:"first_name" xml:"first_name" validate:"required"`
+	Role      string   `json:"role,omitempty" xml:"role,omitempty" validate:"omitempty,oneof=instructor teaching_assistant student auditor"`
+}
+
+// inputEligibility asks whether a person could be enrolled in a course. Role
+// defaults to the person's default role.
+type inputEligibility struct {
+	XMLName  xml.Name `json:"-" xml:"eligibility"`
+	CourseID int      `json:"course_id" xml:"course_id" validate:"min=1"`
+	Role     string   `json:"role,omitempty" xml:"role,omitempty" validate:"omitempty,oneof=instructor teaching_assistant student auditor"`
+}
+
+// inputPrerequisite makes a course require another.
+type inputPrerequisite struct {
+	XMLName        xml.Name `json:"-" xml:"prerequisite"`
+	PrerequisiteID int      `json:"prerequisite_id" xml:"prerequisite_id" validate:"min=1"`
+}
+
+// inputGrades records the grades of students of a section. Grades are
+// checked against the grade scale by the service.
+type inputGrades struct {
+	XMLName xml.Name     `json:"-" xml:"grades"`
+	Grades  []inputGrade `json:"grades" xml:"grade" validate:"min=1,max=500"`
+}
+
+// inputGrade is the grade of the student with FirstName.
+type inputGrade struct {
+	FirstName string `json:"first_name" xml:"first_name" validate:"required"`
+	Grade     string `json:"grade" xml:"grade" validate:"required"`
+}
+
+// inputGradeAmendment changes a student's grade.
+type inputGradeAmendment struct {
+	XMLName xml.Name `json:"-" xml:"amendment"`
+	Grade   string   `json:"grade" xml:"grade" validate:"required"`
 }
 
 func (course inputCourse) MapTo() (models.Course, error) {
+	var credits int
+	if course.Credits != nil {
+		credits = *course.Credits
+	}
 	return models.Course{
 		ID:  0,
 		Name: course.Name,
+		Capacity: course.Capacity,
+		Credits: credits,
+		DepartmentID: course.DepartmentID,
+		CatalogNumber: course.CatalogNumber,
+		Description: course.Description,
 	}, nil
 }
 func (person inputPerson) MapTo() (models.Person, error) {
+	dateOfBirth, err := time.Parse(time.DateOnly, person.DateOfBirth)
+	if err != nil {
+		return models.Person{}, err
+	}
 	return models.Person{
 		ID:  0,
 		FirstName: person.FirstName,
 		LastName: person.LastName,
 		Type: person.Type,
-		Age: person.Age,
+		DateOfBirth: dateOfBirth,
+		Email: person.Email,
+		Phone: person.Phone,
+		Address: person.Address,
 		Courses: person.Courses,
+		Enrollments: mapInputEnrollments(person.Enrollments),
 	}, nil
-}	
+}
 
-// valid all fields of an inputCourse struct
-func (course inputCourse) Valid() []problem {
-	var problems []problem
+func mapInputEnrollments(enrollments []inputCourseRole) []models.Enrollment {
+	var mapped []models.Enrollment
+	for _, enrollment := range enrollments {
+		mapped = append(mapped, models.Enrollment{CourseID: enrollment.CourseID, Role: enrollment.Role})
+	}
+	return mapped
+}	
 
-	// validate Course Name is not blank
-	if course.Name == "" {
-		problems = append(problems, problem{
-			Name:        "name",
-			Description: "must not be blank",
-		})
+func (webhook inputWebhook) MapTo() (models.WebhookSubscription, error) {
+	active := true
+	if webhook.Active != nil {
+		active = *webhook.Active
 	}
+	return models.WebhookSubscription{
+		URL:    webhook.URL,
+		Secret: webhook.Secret,
+		Events: webhook.Events,
+		Active: active,
+	}, nil
+}
 
-	return problems
+func (req inputGraphQL) MapTo() (graph.Request, error) {
+	return graph.Request{
+		Query:         req.Query,
+		OperationName: req.OperationName,
+		Variables:     req.Variables,
+	}, nil
 }
 
-func (person inputPerson) Valid() []problem {
-	var problems []problem
-	validTypes := map[string]bool{
-		"student": true,
-		"professor": true,
+func (batch inputBatch) MapTo() ([]inputBatchOperation, error) {
+	return batch.Operations, nil
+}
+
+func (waitlist inputWaitlist) MapTo() (string, error) {
+	return waitlist.FirstName, nil
+}
+
+func (term inputTerm) MapTo() (models.Term, error) {
+	start, err := time.Parse(time.DateOnly, term.StartDate)
+	if err != nil {
+		return models.Term{}, err
+	}
+	end, err := time.Parse(time.DateOnly, term.EndDate)
+	if err != nil {
+		return models.Term{}, err
 	}
+	return models.Term{
+		Name:      term.Name,
+		StartDate: start,
+		EndDate:   end,
+	}, nil
+}
 
-	// validate Person FirstName is not blank
-	if person.FirstName == "" {
-		problems = append(problems, problem{
-			Name:        "first_name",
-			Description: "must not be blank",
+func (section inputSection) MapTo() (models.Section, error) {
+	meetings := make([]models.Meeting, 0, len(section.Meetings))
+	for _, meeting := range section.Meetings {
+		meetings = append(meetings, models.Meeting{
+			Days:      meeting.Days,
+			StartTime: meeting.StartTime,
+			EndTime:   meeting.EndTime,
+			RoomID:    meeting.RoomID,
 		})
 	}
+	return models.Section{
+		CourseID:     section.CourseID,
+		TermID:       section.TermID,
+		Number:       section.Number,
+		InstructorID: section.InstructorID,
+		Capacity:     section.Capacity,
+		Meetings:     meetings,
+	}, nil
+}
 
-	// validate Person LastName is not blank
-	if person.LastName == "" {
-		problems = append(problems, problem{
-			Name:        "last_name",
-			Description: "must not be blank",
-		})
+func (room inputRoom) MapTo() (models.Room, error) {
+	return models.Room{Name: room.Name, Capacity: room.Capacity}, nil
+}
+
+func (department inputDepartment) MapTo() (models.Department, error) {
+	return models.Department{Code: department.Code, Name: department.Name, ChairID: department.ChairID}, nil
+}
+
+func (enrollment inputSectionEnrollment) MapTo() (inputSectionEnrollment, error) {
+	return enrollment, nil
+}
+
+func (eligibility inputEligibility) MapTo() (inputEligibility, error) {
+	return eligibility, nil
+}
+
+func (grades inputGrades) MapTo() ([]models.Grade, error) {
+	mapped := make([]models.Grade, 0, len(grades.Grades))
+	for _, grade := range grades.Grades {
+		mapped = append(mapped, models.Grade{FirstName: grade.FirstName, Grade: grade.Grade})
 	}
+	return mapped, nil
+}
+
+func (amendment inputGradeAmendment) MapTo() (string, error) {
+	return amendment.Grade, nil
+}
+
+func (prerequisite inputPrerequisite) MapTo() (int, error) {
+	return prerequisite.PrerequisiteID, nil
+}
 
-	// validate Person Type is not blank
-	if !validTypes[person.Type] {
+// Valid checks the validate tags of an inputCourse, and that only courses in
+// a department have a catalog number
+func (course inputCourse) Valid() []problem {
+	problems := validation.Validate(course)
+	if course.CatalogNumber != "" && course.DepartmentID == nil {
 		problems = append(problems, problem{
-			Name:        "type",
-			Description: "must be student or professor",
+			Name:        "catalog_number",
+			Description: "is only allowed for courses in a department",
 		})
 	}
-	// validate Person Age is not blank
-	if person.Age <= 0 {
+	return problems
+}
+
+// Valid checks the validate tags of an inputPerson, and that its date of
+// birth is a real date
+func (person inputPerson) Valid() []problem {
+	problems := validation.Validate(person)
+	dateProblem := slices.ContainsFunc(problems, func(p problem) bool { return p.Name == "date_of_birth" })
+	if _, err := time.Parse(time.DateOnly, person.DateOfBirth); err != nil && !dateProblem {
 		problems = append(problems, problem{
-			Name:        "age",
-			Description: "must be greater than 0",
+			Name:        "date_of_birth",
+			Description: "is not a valid date",
 		})
 	}
+	return problems
+}
 
+// Valid checks the validate tags of an inputWebhook
+func (webhook inputWebhook) Valid() []problem {
+	return validation.Validate(webhook)
+}
+
+// Valid checks the validate tags of an inputGraphQL
+func (req inputGraphQL) Valid() []problem {
+	return validation.Validate(req)
+}
+
+// Valid checks the validate tags of an inputBatch and its operations
+func (batch inputBatch) Valid() []problem {
+	return validation.Validate(batch)
+}
+
+// ValidateStruct checks that the operation has the target and body it needs
+func (op inputBatchOperation) ValidateStruct() []problem {
+	var problems []problem
+	switch {
+	case op.Entity == "enrollment" && op.Op == "update":
+		problems = append(problems, problem{Name: "op", Description: "must be create or delete for enrollments"})
+	case op.Entity != "enrollment" && op.Op != "create" && op.Target == nil:
+		problems = append(problems, problem{Name: "target", Description: "is required to " + op.Op + " a " + op.Entity})
+	case (op.Entity == "enrollment" || op.Op == "create") && op.Target != nil:
+		problems = append(problems, problem{Name: "target", Description: "must be omitted to " + op.Op + " a " + op.Entity})
+	}
+	if op.Op != "delete" || op.Entity == "enrollment" {
+		if op.Body == nil {
+			problems = append(problems, problem{Name: "body", Description: "is required"})
+		}
+	} else if op.Body != nil {
+		problems = append(problems, problem{Name: "body", Description: "must be omitted to delete a " + op.Entity})
+	}
 	return problems
 }
 
-type problem struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+// Valid checks the validate tags of an inputEnrollment
+func (enrollment inputEnrollment) Valid() []problem {
+	return validation.Validate(enrollment)
+}
+
+// Valid checks the validate tags of an inputWaitlist
+func (waitlist inputWaitlist) Valid() []problem {
+	return validation.Validate(waitlist)
+}
+
+// Valid checks the validate tags of an inputTerm
+func (term inputTerm) Valid() []problem {
+	return validation.Validate(term)
+}
+
+// Valid checks the validate tags of an inputSection and its meetings
+func (section inputSection) Valid() []problem {
+	return validation.Validate(section)
+}
+
+// Valid checks the validate tags of an inputRoom
+func (room inputRoom) Valid() []problem {
+	return validation.Validate(room)
+}
+
+// Valid checks the validate tags of an inputDepartment
+func (department inputDepartment) Valid() []problem {
+	return validation.Validate(department)
+}
+
+// Valid checks the validate tags of an inputSectionEnrollment
+func (enrollment inputSectionEnrollment) Valid() []problem {
+	return validation.Validate(enrollment)
+}
+
+// Valid checks the validate tags of an inputEligibility
+func (eligibility inputEligibility) Valid() []problem {
+	return validation.Validate(eligibility)
+}
+
+// Valid checks the validate tags of an inputPrerequisite
+func (prerequisite inputPrerequisite) Valid() []problem {
+	return validation.Validate(prerequisite)
 }
 
+// Valid checks the validate tags of an inputGrades and its grades
+func (grades inputGrades) Valid() []problem {
+	return validation.Validate(grades)
+}
+
+// Valid checks the validate tags of an inputGradeAmendment
+func (amendment inputGradeAmendment) Valid() []problem {
+	return validation.Validate(amendment)
+}
+
+type problem = validation.Problem
+
 type Validator interface {
 	Valid() (problems []problem)
 }
@@ -113,8 +476,13 @@ type ValidatorMapper[T any] interface {
 func decodeValidateBody[I ValidatorMapper[O], O any](r *http.Request) (O, []problem, error) {
 	var inputModel I
 
-	if err := json.NewDecoder(r.Body).Decode(&inputModel); err != nil {
-		return *new(O), nil, fmt.Errorf("[in decodeValidateBody] decode json: %w", err)
+	dec, err := requestDecoder(r)
+	if err != nil {
+		return *new(O), nil, fmt.Errorf("[in decodeValidateBody] %w", err)
+	}
+
+	if err := dec.decode(r.Body, &inputModel); err != nil {
+		return *new(O), nil, fmt.Errorf("[in decodeValidateBody] decode body: %w", err)
 	}
 
 	if problems := inputModel.Valid(); len(problems) > 0 {
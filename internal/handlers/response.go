@@ -2,29 +2,277 @@ package handlers
 
 import (
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
 	"net/http"
+	"time"
+
 	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
 	"github.com/go-chi/httplog/v2"
 )
 
 type outputCourse struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
+	ID          int    `json:"id" xml:"id"`
+	Name        string `json:"name" xml:"name"`
+	// Capacity is the number of student seats, omitted when unlimited.
+	Capacity    *int   `json:"capacity,omitempty" xml:"capacity,omitempty"`
+	Credits     int    `json:"credits" xml:"credits"`
+	// DepartmentID, CatalogNumber and Code are omitted for courses outside a
+	// department. Code is the catalog code, e.g. CS-201.
+	DepartmentID  *int   `json:"department_id,omitempty" xml:"department_id,omitempty"`
+	CatalogNumber string `json:"catalog_number,omitempty" xml:"catalog_number,omitempty"`
+	Code          string `json:"code,omitempty" xml:"code,omitempty"`
+	Description   string `json:"description" xml:"description"`
+	// The ids of the persons holding each role in the course.
+	Instructors        []int `json:"instructors" xml:"instructors>person"`
+	TeachingAssistants []int `json:"teaching_assistants" xml:"teaching_assistants>person"`
+	Students           []int `json:"students" xml:"students>person"`
+	Auditors           []int `json:"auditors" xml:"auditors>person"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" xml:"deleted_at,omitempty"`
 }
 
 type outputPerson struct {
-	ID          int    `json:"id"`
-	FirstName   string `json:"first_name"`
-	LastName    string `json:"last_name"`
-	Type		string `json:"type"`
-	Age         int    `json:"age"`
-	Courses     []int  `json:"courses"`
+	ID          int    `json:"id" xml:"id"`
+	FirstName   string `json:"first_name" xml:"first_name"`
+	LastName    string `json:"last_name" xml:"last_name"`
+	Type		string `json:"type" xml:"type"`
+	DateOfBirth string `json:"date_of_birth" xml:"date_of_birth"`
+	// Age is computed from DateOfBirth, as of the time the person is read at.
+	Age         int    `json:"age" xml:"age"`
+	// Email, Phone and Address are omitted when they are not known.
+	Email       string `json:"email,omitempty" xml:"email,omitempty"`
+	Phone       string `json:"phone,omitempty" xml:"phone,omitempty"`
+	Address     string `json:"address,omitempty" xml:"address,omitempty"`
+	Courses     []int  `json:"courses" xml:"courses>course"`
+	Enrollments []outputEnrollment `json:"enrollments" xml:"enrollments>enrollment"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" xml:"deleted_at,omitempty"`
+}
+
+// outputEnrollment is a person's role in one of their courses, through a
+// section of it.
+type outputEnrollment struct {
+	CourseID  int    `json:"course_id" xml:"course_id"`
+	SectionID int    `json:"section_id" xml:"section_id"`
+	Role      string `json:"role" xml:"role"`
+}
+
+// outputWaitlistEntry is a person's place in line for a seat in a section of
+// a course, counting from 1.
+type outputWaitlistEntry struct {
+	CourseID  int       `json:"course_id" xml:"course_id"`
+	SectionID int       `json:"section_id" xml:"section_id"`
+	PersonID  int       `json:"person_id" xml:"person_id"`
+	FirstName string    `json:"first_name" xml:"first_name"`
+	LastName  string    `json:"last_name" xml:"last_name"`
+	Position  int       `json:"position" xml:"position"`
+	AddedAt   time.Time `json:"added_at" xml:"added_at"`
+}
+
+// outputPrerequisite is a course required by another, with the courses it
+// requires in turn.
+type outputPrerequisite struct {
+	CourseID      int                  `json:"course_id" xml:"course_id"`
+	Name          string               `json:"name" xml:"name"`
+	Prerequisites []outputPrerequisite `json:"prerequisites" xml:"prerequisites>prerequisite"`
+}
+
+// outputTerm is a term; dates are formatted as 2006-01-02.
+type outputTerm struct {
+	ID        int    `json:"id" xml:"id"`
+	Name      string `json:"name" xml:"name"`
+	StartDate string `json:"start_date" xml:"start_date"`
+	EndDate   string `json:"end_date" xml:"end_date"`
+	// Current is set for the term the course routes act on.
+	Current   bool   `json:"current" xml:"current"`
+}
+
+type outputSection struct {
+	ID           int  `json:"id" xml:"id"`
+	CourseID     int  `json:"course_id" xml:"course_id"`
+	TermID       int  `json:"term_id" xml:"term_id"`
+	Number       int  `json:"number" xml:"number"`
+	InstructorID *int `json:"instructor_id,omitempty" xml:"instructor_id,omitempty"`
+	// Capacity is the number of student seats, omitted when unlimited.
+	Capacity     *int `json:"capacity,omitempty" xml:"capacity,omitempty"`
+	// The ids of the persons holding each role in the section.
+	Instructors        []int `json:"instructors" xml:"instructors>person"`
+	TeachingAssistants []int `json:"teaching_assistants" xml:"teaching_assistants>person"`
+	Students           []int `json:"students" xml:"students>person"`
+	Auditors           []int `json:"auditors" xml:"auditors>person"`
+	// Meetings is when and where the section meets each week.
+	Meetings []outputMeeting `json:"meetings" xml:"meetings>meeting"`
+}
+
+// outputMeeting is a weekly meeting of a section; times are formatted as
+// 15:04 and RoomID is omitted until a room is assigned.
+type outputMeeting struct {
+	Days      []string `json:"days" xml:"days>day"`
+	StartTime string   `json:"start_time" xml:"start_time"`
+	EndTime   string   `json:"end_time" xml:"end_time"`
+	RoomID    *int     `json:"room_id,omitempty" xml:"room_id,omitempty"`
+}
+
+// outputDepartment omits ChairID when the department has no chair.
+type outputDepartment struct {
+	ID      int    `json:"id" xml:"id"`
+	Code    string `json:"code" xml:"code"`
+	Name    string `json:"name" xml:"name"`
+	ChairID *int   `json:"chair_id,omitempty" xml:"chair_id,omitempty"`
+}
+
+type outputRoom struct {
+	ID       int    `json:"id" xml:"id"`
+	Name     string `json:"name" xml:"name"`
+	Capacity int    `json:"capacity" xml:"capacity"`
+}
+
+// outputSchedule is the week of a person in a term, Monday first.
+type outputSchedule struct {
+	PersonID  int                 `json:"person_id" xml:"person_id"`
+	FirstName string              `json:"first_name" xml:"first_name"`
+	LastName  string              `json:"last_name" xml:"last_name"`
+	TermID    int                 `json:"term_id" xml:"term_id"`
+	TermName  string              `json:"term_name" xml:"term_name"`
+	Days      []outputScheduleDay `json:"days" xml:"days>day"`
+}
+
+// outputScheduleDay is one weekday of a schedule, with its meetings in the
+// order they start.
+type outputScheduleDay struct {
+	Day      string                `json:"day" xml:"day"`
+	Meetings []outputScheduleEntry `json:"meetings" xml:"meetings>meeting"`
+}
+
+// outputScheduleEntry is a meeting on a schedule; the room is omitted until
+// one is assigned.
+type outputScheduleEntry struct {
+	StartTime     string `json:"start_time" xml:"start_time"`
+	EndTime       string `json:"end_time" xml:"end_time"`
+	SectionID     int    `json:"section_id" xml:"section_id"`
+	SectionNumber int    `json:"section_number" xml:"section_number"`
+	CourseID      int    `json:"course_id" xml:"course_id"`
+	CourseName    string `json:"course_name" xml:"course_name"`
+	Role          string `json:"role" xml:"role"`
+	RoomID        *int   `json:"room_id,omitempty" xml:"room_id,omitempty"`
+	RoomName      string `json:"room_name,omitempty" xml:"room_name,omitempty"`
+}
+
+// outputSubscription is where calendar apps subscribe to a feed. Token is
+// also part of URL.
+type outputSubscription struct {
+	URL   string `json:"url" xml:"url"`
+	Token string `json:"token" xml:"token"`
+}
+
+// outputEligibility is whether a person could be enrolled in a course, and
+// the problems that would keep them out if not.
+type outputEligibility struct {
+	Allowed  bool      `json:"allowed" xml:"allowed"`
+	Problems []problem `json:"problems" xml:"problems>problem"`
+}
+
+// outputGrade is a student's final grade in a section. Points is omitted for
+// pass/fail and incomplete grades, and GradedBy once the grader is purged.
+type outputGrade struct {
+	PersonID  int       `json:"person_id" xml:"person_id"`
+	FirstName string    `json:"first_name" xml:"first_name"`
+	LastName  string    `json:"last_name" xml:"last_name"`
+	SectionID int       `json:"section_id" xml:"section_id"`
+	CourseID  int       `json:"course_id" xml:"course_id"`
+	Grade     string    `json:"grade" xml:"grade"`
+	Points    *float64  `json:"points,omitempty" xml:"points,omitempty"`
+	Passed    bool      `json:"passed" xml:"passed"`
+	GradedBy  *int      `json:"graded_by,omitempty" xml:"graded_by,omitempty"`
+	GradedAt  time.Time `json:"graded_at" xml:"graded_at"`
+}
+
+// outputTranscript is a person's courses as a student by term, with grades
+// and GPAs. GPAs are omitted until a letter grade is recorded.
+type outputTranscript struct {
+	PersonID         int                    `json:"person_id" xml:"person_id"`
+	FirstName        string                 `json:"first_name" xml:"first_name"`
+	LastName         string                 `json:"last_name" xml:"last_name"`
+	Terms            []outputTranscriptTerm `json:"terms" xml:"terms>term"`
+	CreditsAttempted int                    `json:"credits_attempted" xml:"credits_attempted"`
+	CreditsEarned    int                    `json:"credits_earned" xml:"credits_earned"`
+	GPA              *float64               `json:"gpa,omitempty" xml:"gpa,omitempty"`
+}
+
+// outputTranscriptTerm is one term of a transcript; dates are formatted as
+// 2006-01-02.
+type outputTranscriptTerm struct {
+	TermID           int                      `json:"term_id" xml:"term_id"`
+	Name             string                   `json:"name" xml:"name"`
+	StartDate        string                   `json:"start_date" xml:"start_date"`
+	EndDate          string                   `json:"end_date" xml:"end_date"`
+	Courses          []outputTranscriptCourse `json:"courses" xml:"courses>course"`
+	CreditsAttempted int                      `json:"credits_attempted" xml:"credits_attempted"`
+	CreditsEarned    int                      `json:"credits_earned" xml:"credits_earned"`
+	GPA              *float64                 `json:"gpa,omitempty" xml:"gpa,omitempty"`
+	CumulativeGPA    *float64                 `json:"cumulative_gpa,omitempty" xml:"cumulative_gpa,omitempty"`
+}
+
+// outputTranscriptCourse is a course on a transcript. Grade is omitted until
+// one is recorded.
+type outputTranscriptCourse struct {
+	CourseID  int      `json:"course_id" xml:"course_id"`
+	SectionID int      `json:"section_id" xml:"section_id"`
+	Name      string   `json:"name" xml:"name"`
+	Credits   int      `json:"credits" xml:"credits"`
+	Grade     string   `json:"grade,omitempty" xml:"grade,omitempty"`
+	Points    *float64 `json:"points,omitempty" xml:"points,omitempty"`
+}
+
+type outputAuditEvent struct {
+	ID          int64           `json:"id" xml:"id"`
+	OccurredAt  time.Time       `json:"occurred_at" xml:"occurred_at"`
+	Actor       string          `json:"actor" xml:"actor"`
+	RequestID   string          `json:"request_id" xml:"request_id"`
+	Action      string          `json:"action" xml:"action"`
+	Entity      string          `json:"entity" xml:"entity"`
+	EntityID    int             `json:"entity_id" xml:"entity_id"`
+	Before      json.RawMessage `json:"before" xml:"before"`
+	After       json.RawMessage `json:"after" xml:"after"`
+}
+
+type outputWebhook struct {
+	ID          int       `json:"id" xml:"id"`
+	URL         string    `json:"url" xml:"url"`
+	Events      []string  `json:"events" xml:"events>event"`
+	Active      bool      `json:"active" xml:"active"`
+	CreatedAt   time.Time `json:"created_at" xml:"created_at"`
+	// Secret is only reported when the subscription is created.
+	Secret      string    `json:"secret,omitempty" xml:"secret,omitempty"`
+}
+
+type outputWebhookDelivery struct {
+	ID             int64      `json:"id" xml:"id"`
+	EventID        int64      `json:"event_id" xml:"event_id"`
+	EventType      string     `json:"event_type" xml:"event_type"`
+	Status         string     `json:"status" xml:"status"`
+	Attempts       int        `json:"attempts" xml:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" xml:"next_attempt_at"`
+	LastStatusCode *int       `json:"last_status_code,omitempty" xml:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty" xml:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty" xml:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" xml:"created_at"`
 }
 
 func mapOutputCourse(course models.Course) outputCourse {
 	return outputCourse{
 		ID:   course.ID,
 		Name: course.Name,
+		Capacity: course.Capacity,
+		Credits: course.Credits,
+		DepartmentID: course.DepartmentID,
+		CatalogNumber: course.CatalogNumber,
+		Code: course.Code,
+		Description: course.Description,
+		Instructors:        course.Instructors,
+		TeachingAssistants: course.TeachingAssistants,
+		Students:           course.Students,
+		Auditors:           course.Auditors,
+		DeletedAt: course.DeletedAt,
 	}
 }
 
@@ -42,11 +290,238 @@ func mapOutputPerson(person models.Person) outputPerson {
 		FirstName: person.FirstName,
 		LastName:  person.LastName,
 		Type:      person.Type,
+		DateOfBirth: person.DateOfBirth.Format(time.DateOnly),
 		Age:       person.Age,
+		Email:     person.Email,
+		Phone:     person.Phone,
+		Address:   person.Address,
 		Courses:   person.Courses,
+		Enrollments: mapOutputEnrollments(person.Enrollments),
+		DeletedAt: person.DeletedAt,
+	}
+}
+
+func mapOutputEnrollments(enrollments []models.Enrollment) []outputEnrollment {
+	outputEnrollments := make([]outputEnrollment, 0, len(enrollments))
+	for _, enrollment := range enrollments {
+		outputEnrollments = append(outputEnrollments, outputEnrollment{
+			CourseID:  enrollment.CourseID,
+			SectionID: enrollment.SectionID,
+			Role:      enrollment.Role,
+		})
+	}
+	return outputEnrollments
+}
+
+func mapOutputWaitlistEntry(entry models.WaitlistEntry) outputWaitlistEntry {
+	return outputWaitlistEntry{
+		CourseID:  entry.CourseID,
+		SectionID: entry.SectionID,
+		PersonID:  entry.PersonID,
+		FirstName: entry.FirstName,
+		LastName:  entry.LastName,
+		Position:  entry.Position,
+		AddedAt:   entry.AddedAt,
+	}
+}
+
+func mapMultipleOutputWaitlistEntries(entries []models.WaitlistEntry) []outputWaitlistEntry {
+	outputEntries := make([]outputWaitlistEntry, 0, len(entries))
+	for _, entry := range entries {
+		outputEntries = append(outputEntries, mapOutputWaitlistEntry(entry))
+	}
+	return outputEntries
+}
+
+func mapOutputPrerequisites(prerequisites []models.Prerequisite) []outputPrerequisite {
+	outputPrerequisites := make([]outputPrerequisite, 0, len(prerequisites))
+	for _, prerequisite := range prerequisites {
+		outputPrerequisites = append(outputPrerequisites, outputPrerequisite{
+			CourseID:      prerequisite.CourseID,
+			Name:          prerequisite.Name,
+			Prerequisites: mapOutputPrerequisites(prerequisite.Prerequisites),
+		})
+	}
+	return outputPrerequisites
+}
+
+func mapOutputGrade(grade models.Grade) outputGrade {
+	return outputGrade{
+		PersonID:  grade.PersonID,
+		FirstName: grade.FirstName,
+		LastName:  grade.LastName,
+		SectionID: grade.SectionID,
+		CourseID:  grade.CourseID,
+		Grade:     grade.Grade,
+		Points:    grade.Points,
+		Passed:    grade.Passed,
+		GradedBy:  grade.GradedBy,
+		GradedAt:  grade.GradedAt,
 	}
 }
 
+func mapMultipleOutputGrades(grades []models.Grade) []outputGrade {
+	outputGrades := make([]outputGrade, 0, len(grades))
+	for _, grade := range grades {
+		outputGrades = append(outputGrades, mapOutputGrade(grade))
+	}
+	return outputGrades
+}
+
+func mapOutputTranscript(transcript models.Transcript) outputTranscript {
+	terms := make([]outputTranscriptTerm, 0, len(transcript.Terms))
+	for _, term := range transcript.Terms {
+		courses := make([]outputTranscriptCourse, 0, len(term.Courses))
+		for _, course := range term.Courses {
+			courses = append(courses, outputTranscriptCourse{
+				CourseID:  course.CourseID,
+				SectionID: course.SectionID,
+				Name:      course.Name,
+				Credits:   course.Credits,
+				Grade:     course.Grade,
+				Points:    course.Points,
+			})
+		}
+		terms = append(terms, outputTranscriptTerm{
+			TermID:           term.TermID,
+			Name:             term.Name,
+			StartDate:        term.StartDate.Format(time.DateOnly),
+			EndDate:          term.EndDate.Format(time.DateOnly),
+			Courses:          courses,
+			CreditsAttempted: term.CreditsAttempted,
+			CreditsEarned:    term.CreditsEarned,
+			GPA:              term.GPA,
+			CumulativeGPA:    term.CumulativeGPA,
+		})
+	}
+	return outputTranscript{
+		PersonID:         transcript.PersonID,
+		FirstName:        transcript.FirstName,
+		LastName:         transcript.LastName,
+		Terms:            terms,
+		CreditsAttempted: transcript.CreditsAttempted,
+		CreditsEarned:    transcript.CreditsEarned,
+		GPA:              transcript.GPA,
+	}
+}
+
+func mapOutputTerm(term models.Term) outputTerm {
+	return outputTerm{
+		ID:        term.ID,
+		Name:      term.Name,
+		StartDate: term.StartDate.Format(time.DateOnly),
+		EndDate:   term.EndDate.Format(time.DateOnly),
+		Current:   term.Current,
+	}
+}
+
+func mapMultipleOutputTerms(terms []models.Term) []outputTerm {
+	outputTerms := make([]outputTerm, 0, len(terms))
+	for _, term := range terms {
+		outputTerms = append(outputTerms, mapOutputTerm(term))
+	}
+	return outputTerms
+}
+
+func mapOutputSection(section models.Section) outputSection {
+	return outputSection{
+		ID:                 section.ID,
+		CourseID:           section.CourseID,
+		TermID:             section.TermID,
+		Number:             section.Number,
+		InstructorID:       section.InstructorID,
+		Capacity:           section.Capacity,
+		Instructors:        section.Instructors,
+		TeachingAssistants: section.TeachingAssistants,
+		Students:           section.Students,
+		Auditors:           section.Auditors,
+		Meetings:           mapOutputMeetings(section.Meetings),
+	}
+}
+
+func mapOutputMeetings(meetings []models.Meeting) []outputMeeting {
+	outputMeetings := make([]outputMeeting, 0, len(meetings))
+	for _, meeting := range meetings {
+		outputMeetings = append(outputMeetings, outputMeeting{
+			Days:      meeting.Days,
+			StartTime: meeting.StartTime,
+			EndTime:   meeting.EndTime,
+			RoomID:    meeting.RoomID,
+		})
+	}
+	return outputMeetings
+}
+
+func mapOutputDepartment(department models.Department) outputDepartment {
+	return outputDepartment{
+		ID:      department.ID,
+		Code:    department.Code,
+		Name:    department.Name,
+		ChairID: department.ChairID,
+	}
+}
+
+func mapMultipleOutputDepartments(departments []models.Department) []outputDepartment {
+	outputDepartments := make([]outputDepartment, 0, len(departments))
+	for _, department := range departments {
+		outputDepartments = append(outputDepartments, mapOutputDepartment(department))
+	}
+	return outputDepartments
+}
+
+func mapOutputRoom(room models.Room) outputRoom {
+	return outputRoom{
+		ID:       room.ID,
+		Name:     room.Name,
+		Capacity: room.Capacity,
+	}
+}
+
+func mapMultipleOutputRooms(rooms []models.Room) []outputRoom {
+	outputRooms := make([]outputRoom, 0, len(rooms))
+	for _, room := range rooms {
+		outputRooms = append(outputRooms, mapOutputRoom(room))
+	}
+	return outputRooms
+}
+
+func mapOutputSchedule(schedule models.Schedule) outputSchedule {
+	days := make([]outputScheduleDay, 0, len(schedule.Days))
+	for _, day := range schedule.Days {
+		entries := make([]outputScheduleEntry, 0, len(day.Meetings))
+		for _, entry := range day.Meetings {
+			entries = append(entries, outputScheduleEntry{
+				StartTime:     entry.StartTime,
+				EndTime:       entry.EndTime,
+				SectionID:     entry.SectionID,
+				SectionNumber: entry.SectionNumber,
+				CourseID:      entry.CourseID,
+				CourseName:    entry.CourseName,
+				Role:          entry.Role,
+				RoomID:        entry.RoomID,
+				RoomName:      entry.RoomName,
+			})
+		}
+		days = append(days, outputScheduleDay{Day: day.Day, Meetings: entries})
+	}
+	return outputSchedule{
+		PersonID:  schedule.PersonID,
+		FirstName: schedule.FirstName,
+		LastName:  schedule.LastName,
+		TermID:    schedule.TermID,
+		TermName:  schedule.TermName,
+		Days:      days,
+	}
+}
+
+func mapMultipleOutputSections(sections []models.Section) []outputSection {
+	outputSections := make([]outputSection, 0, len(sections))
+	for _, section := range sections {
+		outputSections = append(outputSections, mapOutputSection(section))
+	}
+	return outputSections
+}
+
 func mapMultipleOutputPersons(persons []models.Person) []outputPerson {
 	outputPersons := make([]outputPerson, 0, len(persons))
 	for _, person := range persons {
@@ -55,37 +530,347 @@ func mapMultipleOutputPersons(persons []models.Person) []outputPerson {
 	return outputPersons
 }
 
+func mapMultipleOutputAuditEvents(events []models.AuditEvent) []outputAuditEvent {
+	outputEvents := make([]outputAuditEvent, 0, len(events))
+	for _, event := range events {
+		outputEvents = append(outputEvents, outputAuditEvent{
+			ID:         event.ID,
+			OccurredAt: event.OccurredAt,
+			Actor:      event.Actor,
+			RequestID:  event.RequestID,
+			Action:     event.Action,
+			Entity:     event.Entity,
+			EntityID:   event.EntityID,
+			Before:     nullIfEmpty(event.Before),
+			After:      nullIfEmpty(event.After),
+		})
+	}
+	return outputEvents
+}
+
+func mapOutputWebhook(sub models.WebhookSubscription) outputWebhook {
+	return outputWebhook{
+		ID:        sub.ID,
+		URL:       sub.URL,
+		Events:    sub.Events,
+		Active:    sub.Active,
+		CreatedAt: sub.CreatedAt,
+	}
+}
+
+func mapMultipleOutputWebhooks(subs []models.WebhookSubscription) []outputWebhook {
+	outputWebhooks := make([]outputWebhook, 0, len(subs))
+	for _, sub := range subs {
+		outputWebhooks = append(outputWebhooks, mapOutputWebhook(sub))
+	}
+	return outputWebhooks
+}
+
+func mapMultipleOutputWebhookDeliveries(deliveries []models.WebhookDelivery) []outputWebhookDelivery {
+	outputDeliveries := make([]outputWebhookDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		outputDeliveries = append(outputDeliveries, outputWebhookDelivery{
+			ID:             d.ID,
+			EventID:        d.EventID,
+			EventType:      d.EventType,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			NextAttemptAt:  d.NextAttemptAt,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			DeliveredAt:    d.DeliveredAt,
+			CreatedAt:      d.CreatedAt,
+		})
+	}
+	return outputDeliveries
+}
+
+// nullIfEmpty turns a missing JSON snapshot into an explicit null.
+func nullIfEmpty(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("null")
+	}
+	return raw
+}
+
 type responseCourse struct {
-	Course outputCourse `json:"data"`
+	XMLName xml.Name     `json:"-" xml:"response"`
+	Course  outputCourse `json:"data" xml:"data"`
 }
 
 type responseCourses struct {
-	Courses []outputCourse `json:"data"`
+	XMLName xml.Name       `json:"-" xml:"response"`
+	Courses []outputCourse `json:"data" xml:"data>course"`
 }
 
 type responsePerson struct {
-	Person outputPerson `json:"data"`
+	XMLName xml.Name     `json:"-" xml:"response"`
+	Person  outputPerson `json:"data" xml:"data"`
 }
 
 type responsePersons struct {
-	Persons []outputPerson `json:"data"`
+	XMLName xml.Name       `json:"-" xml:"response"`
+	Persons []outputPerson `json:"data" xml:"data>person"`
+}
+
+type responseWaitlistEntry struct {
+	XMLName xml.Name            `json:"-" xml:"response"`
+	Entry   outputWaitlistEntry `json:"data" xml:"data"`
+}
+
+type responseWaitlist struct {
+	XMLName xml.Name              `json:"-" xml:"response"`
+	Entries []outputWaitlistEntry `json:"data" xml:"data>entry"`
+}
+
+type responsePrerequisites struct {
+	XMLName       xml.Name             `json:"-" xml:"response"`
+	Prerequisites []outputPrerequisite `json:"data" xml:"data>prerequisite"`
+}
+
+type responseTerm struct {
+	XMLName xml.Name   `json:"-" xml:"response"`
+	Term    outputTerm `json:"data" xml:"data"`
+}
+
+type responseTerms struct {
+	XMLName xml.Name     `json:"-" xml:"response"`
+	Terms   []outputTerm `json:"data" xml:"data>term"`
+}
+
+type responseSection struct {
+	XMLName xml.Name      `json:"-" xml:"response"`
+	Section outputSection `json:"data" xml:"data"`
+}
+
+type responseSections struct {
+	XMLName  xml.Name        `json:"-" xml:"response"`
+	Sections []outputSection `json:"data" xml:"data>section"`
+}
+
+type responseEligibility struct {
+	XMLName     xml.Name          `json:"-" xml:"response"`
+	Eligibility outputEligibility `json:"data" xml:"data"`
+}
+
+type responseGrade struct {
+	XMLName xml.Name    `json:"-" xml:"response"`
+	Grade   outputGrade `json:"data" xml:"data"`
+}
+
+type responseGrades struct {
+	XMLName xml.Name      `json:"-" xml:"response"`
+	Grades  []outputGrade `json:"data" xml:"data>grade"`
+}
+
+type responseDepartment struct {
+	XMLName    xml.Name         `json:"-" xml:"response"`
+	Department outputDepartment `json:"data" xml:"data"`
+}
+
+type responseDepartments struct {
+	XMLName     xml.Name           `json:"-" xml:"response"`
+	Departments []outputDepartment `json:"data" xml:"data>department"`
+}
+
+type responseRoom struct {
+	XMLName xml.Name   `json:"-" xml:"response"`
+	Room    outputRoom `json:"data" xml:"data"`
+}
+
+type responseRooms struct {
+	XMLName xml.Name     `json:"-" xml:"response"`
+	Rooms   []outputRoom `json:"data" xml:"data>room"`
+}
+
+// responseSchedule can also be rendered as plain text and HTML; see
+// schedule.go.
+type responseSchedule struct {
+	XMLName  xml.Name       `json:"-" xml:"response"`
+	Schedule outputSchedule `json:"data" xml:"data"`
+}
+
+// responseCalendar is only rendered as text/calendar; see calendar.go. Stamp
+// is when the feed was generated.
+type responseCalendar struct {
+	Calendar models.Calendar
+	Stamp    time.Time
+}
+
+type responseSubscription struct {
+	XMLName      xml.Name           `json:"-" xml:"response"`
+	Subscription outputSubscription `json:"data" xml:"data"`
+}
+
+// responseTranscript can also be rendered as plain text and HTML; see
+// transcript.go.
+type responseTranscript struct {
+	XMLName    xml.Name         `json:"-" xml:"response"`
+	Transcript outputTranscript `json:"data" xml:"data"`
+}
+
+type responseAuditEvents struct {
+	XMLName     xml.Name           `json:"-" xml:"response"`
+	AuditEvents []outputAuditEvent `json:"data" xml:"data>audit_event"`
+}
+
+type responseWebhook struct {
+	XMLName xml.Name      `json:"-" xml:"response"`
+	Webhook outputWebhook `json:"data" xml:"data"`
+}
+
+type responseWebhooks struct {
+	XMLName  xml.Name        `json:"-" xml:"response"`
+	Webhooks []outputWebhook `json:"data" xml:"data>webhook"`
+}
+
+type responseWebhookDeliveries struct {
+	XMLName    xml.Name                `json:"-" xml:"response"`
+	Deliveries []outputWebhookDelivery `json:"data" xml:"data>delivery"`
+}
+
+type responseReplay struct {
+	XMLName xml.Name `json:"-" xml:"response"`
+	// Queued is the number of deliveries queued for sending.
+	Queued  int      `json:"queued" xml:"queued"`
+}
+
+// outputBatchResult is the outcome of one batch operation. Data is the
+// response body the equivalent REST route would return.
+type outputBatchResult struct {
+	Index            int       `json:"index" xml:"index"`
+	Status           int       `json:"status" xml:"status"`
+	Data             any       `json:"data,omitempty" xml:"data,omitempty"`
+	Error            string    `json:"error,omitempty" xml:"error,omitempty"`
+	ValidationErrors []problem `json:"validation_errors,omitempty" xml:"validation_errors>problem,omitempty"`
+}
+
+type responseBatch struct {
+	XMLName xml.Name            `json:"-" xml:"response"`
+	Results []outputBatchResult `json:"data" xml:"data>result"`
+}
+
+// responseBatchErr reports a batch that was rolled back. It extends
+// responseErr with the results of the operations, which are omitted if the
+// batch was rejected before running any.
+type responseBatchErr struct {
+	XMLName          xml.Name            `json:"-" xml:"response"`
+	Error            string              `json:"error,omitempty" xml:"error,omitempty"`
+	ValidationErrors []problem           `json:"validation_errors,omitempty" xml:"validation_errors>problem,omitempty"`
+	Results          []outputBatchResult `json:"results,omitempty" xml:"results>result,omitempty"`
 }
 
 type responseMessage struct {
-	Message string `json:"message"`
+	XMLName xml.Name `json:"-" xml:"response"`
+	Message string   `json:"message" xml:"message"`
 }
 
 type responseErr struct {
-	Error            string    `json:"error,omitempty"`
-	ValidationErrors []problem `json:"validation_errors,omitempty"`
+	XMLName          xml.Name  `json:"-" xml:"response"`
+	Error            string    `json:"error,omitempty" xml:"error,omitempty"`
+	ValidationErrors []problem `json:"validation_errors,omitempty" xml:"validation_errors>problem,omitempty"`
+}
+
+func (resp responseCourses) columns() []string {
+	return []string{"id", "name", "code", "credits"}
+}
+
+func (resp responseCourses) rows() [][]any {
+	rows := make([][]any, 0, len(resp.Courses))
+	for _, course := range resp.Courses {
+		rows = append(rows, []any{course.ID, course.Name, course.Code, course.Credits})
+	}
+	return rows
+}
+
+func (resp responseTerms) columns() []string {
+	return []string{"id", "name", "start_date", "end_date", "current"}
+}
+
+func (resp responseTerms) rows() [][]any {
+	rows := make([][]any, 0, len(resp.Terms))
+	for _, term := range resp.Terms {
+		rows = append(rows, []any{term.ID, term.Name, term.StartDate, term.EndDate, term.Current})
+	}
+	return rows
 }
 
-func encodeResponse(w http.ResponseWriter, logger *httplog.Logger, status int, data any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		logger.Error("Error while marshaling data", "err", err, "data", data)
-		http.Error(w, `{"Error": "Internal server error"}`, http.StatusInternalServerError)
+func (resp responseDepartments) columns() []string {
+	return []string{"id", "code", "name", "chair_id"}
+}
+
+func (resp responseDepartments) rows() [][]any {
+	rows := make([][]any, 0, len(resp.Departments))
+	for _, department := range resp.Departments {
+		var chairID any
+		if department.ChairID != nil {
+			chairID = *department.ChairID
+		}
+		rows = append(rows, []any{department.ID, department.Code, department.Name, chairID})
+	}
+	return rows
+}
+
+func (resp responseRooms) columns() []string {
+	return []string{"id", "name", "capacity"}
+}
+
+func (resp responseRooms) rows() [][]any {
+	rows := make([][]any, 0, len(resp.Rooms))
+	for _, room := range resp.Rooms {
+		rows = append(rows, []any{room.ID, room.Name, room.Capacity})
+	}
+	return rows
+}
+
+func (resp responseSections) columns() []string {
+	return []string{"id", "course_id", "term_id", "number", "students"}
+}
+
+func (resp responseSections) rows() [][]any {
+	rows := make([][]any, 0, len(resp.Sections))
+	for _, section := range resp.Sections {
+		rows = append(rows, []any{section.ID, section.CourseID, section.TermID, section.Number, section.Students})
 	}
+	return rows
 }
 
+func (resp responseGrades) columns() []string {
+	return []string{"person_id", "first_name", "last_name", "grade", "passed"}
+}
+
+func (resp responseGrades) rows() [][]any {
+	rows := make([][]any, 0, len(resp.Grades))
+	for _, grade := range resp.Grades {
+		rows = append(rows, []any{grade.PersonID, grade.FirstName, grade.LastName, grade.Grade, grade.Passed})
+	}
+	return rows
+}
+
+func (resp responsePersons) columns() []string {
+	return []string{"id", "first_name", "last_name", "type", "date_of_birth", "age", "email", "courses"}
+}
+
+func (resp responsePersons) rows() [][]any {
+	rows := make([][]any, 0, len(resp.Persons))
+	for _, person := range resp.Persons {
+		rows = append(rows, []any{person.ID, person.FirstName, person.LastName, person.Type, person.DateOfBirth, person.Age, person.Email, person.Courses})
+	}
+	return rows
+}
+
+// encodeResponse writes data in the media type negotiated from the request's
+// Accept header. Error responses fall back to JSON rather than turning into a
+// 406 when the client's preferred type cannot represent them.
+func encodeResponse(w http.ResponseWriter, r *http.Request, logger *httplog.Logger, status int, data any) {
+	enc, ok := negotiateEncoder(r, data)
+	if !ok {
+		if status < http.StatusBadRequest {
+			logger.Error("no acceptable response media type", "accept", r.Header.Get("Accept"), "data", fmt.Sprintf("%T", data))
+			writeNotAcceptable(w, logger)
+			return
+		}
+		enc = encoders[0]
+	}
+	writeEncoded(w, logger, enc, status, data)
+}
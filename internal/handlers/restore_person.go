@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleRestorePerson restores a soft deleted person and their enrollments by
+// their ID
+func HandleRestorePerson(logger *httplog.Logger, svsPerson *services.PersonService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		personID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid person ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid person ID",
+			})
+			return
+		}
+
+		person, err := svsPerson.RestorePerson(ctx, personID)
+		if err != nil {
+			logger.Error("error restoring person", "error", err)
+			if errors.Is(err, sql.ErrNoRows) {
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No deleted person with that ID",
+				})
+				return
+			}
+			encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error restoring person",
+			})
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responsePerson{Person: mapOutputPerson(person)})
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"text/tabwriter"
+)
+
+// dayNames spells out the weekdays of a schedule.
+var dayNames = map[string]string{
+	"mon": "Monday",
+	"tue": "Tuesday",
+	"wed": "Wednesday",
+	"thu": "Thursday",
+	"fri": "Friday",
+	"sat": "Saturday",
+	"sun": "Sunday",
+}
+
+// text renders the schedule as a table of meetings per weekday.
+func (resp responseSchedule) text(w io.Writer) error {
+	s := resp.Schedule
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Schedule of %s %s (id %d), %s\n", s.FirstName, s.LastName, s.PersonID, s.TermName)
+	for _, day := range s.Days {
+		fmt.Fprintf(tw, "\n%s\n", dayNames[day.Day])
+		if len(day.Meetings) == 0 {
+			fmt.Fprintln(tw, "No meetings")
+			continue
+		}
+		for _, entry := range day.Meetings {
+			fmt.Fprintf(tw, "%s-%s\t%s\tsection %d\t%s\t%s\n",
+				entry.StartTime, entry.EndTime, entry.CourseName, entry.SectionNumber, entry.Role, formatRoom(entry.RoomName))
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// html renders the schedule as a standalone page with a column per weekday.
+func (resp responseSchedule) html(w io.Writer) error {
+	return scheduleHTML.Execute(w, resp.Schedule)
+}
+
+var scheduleHTML = template.Must(template.New("schedule").Funcs(template.FuncMap{
+	"day":  func(day string) string { return dayNames[day] },
+	"room": formatRoom,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Schedule of {{.FirstName}} {{.LastName}}</title>
+</head>
+<body>
+<h1>Schedule of {{.FirstName}} {{.LastName}}</h1>
+<p>{{.TermName}}</p>
+<table>
+<thead>
+<tr>{{range .Days}}<th>{{day .Day}}</th>{{end}}</tr>
+</thead>
+<tbody>
+<tr>
+{{- range .Days}}
+<td>
+{{- range .Meetings}}
+<p><strong>{{.StartTime}}-{{.EndTime}}</strong><br>{{.CourseName}}, section {{.SectionNumber}}<br>{{.Role}}, {{room .RoomName}}</p>
+{{- end}}
+</td>
+{{- end}}
+</tr>
+</tbody>
+</table>
+</body>
+</html>
+`))
+
+// formatRoom shows meetings without a room as such.
+func formatRoom(name string) string {
+	if name == "" {
+		return "no room"
+	}
+	return name
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/events"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/webhook"
+)
+
+// eventEntities are the values accepted by the entity parameter of
+// HandleStreamEvents, the prefixes of the event types.
+var eventEntities = []string{"person", "course", "term", "section", "enrollment", "prerequisite", "grade", "room", "department"}
+
+// heartbeatInterval keeps idle streams from being closed by proxies.
+const heartbeatInterval = 15 * time.Second
+
+// HandleStreamEvents streams changes to persons, courses and enrollments as
+// Server-Sent Events. Each event's id is its position in the outbox, which
+// follows commit order, so a client that reconnects with Last-Event-ID (or
+// ?last_event_id=) is first sent everything it missed. Without one the
+// stream starts with the next change. The entity parameter, repeated or comma
+// separated, limits the stream to those entities.
+func HandleStreamEvents(logger *httplog.Logger, hub *events.Hub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		entities, lastID, problems := parseStreamParams(r)
+		if len(problems) > 0 {
+			logger.Error("Problems validating stream parameters", "problems", problems)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				ValidationErrors: problems,
+			})
+			return
+		}
+
+		sub, current := hub.Subscribe()
+		defer hub.Unsubscribe(sub)
+		if lastID < 0 {
+			lastID = current
+		}
+
+		rc := http.NewResponseController(w)
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			logger.Warn("unable to clear write deadline for event stream", "error", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "retry: 3000\n\n")
+
+		send := func(event models.OutboxEvent) error {
+			if entities != nil && !entities[entityOf(event.Type)] {
+				return nil
+			}
+			if err := writeSSE(w, event); err != nil {
+				return err
+			}
+			return rc.Flush()
+		}
+
+		// Catch up from the outbox before relaying live events; anything
+		// both replayed and broadcast is skipped by position.
+		lastID, err := hub.Replay(ctx, lastID, send)
+		if err != nil {
+			logger.Error("error replaying events", "error", err)
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-sub.C:
+				if !ok {
+					// Dropped for falling behind, or shutting down; the client
+					// reconnects and resumes from its last event id.
+					return
+				}
+				if event.Position <= lastID {
+					continue
+				}
+				lastID = event.Position
+				if err := send(event); err != nil {
+					logger.Info("event stream closed", "error", err)
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+				if err := rc.Flush(); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// parseStreamParams reads the entity filter and the id to resume after. A nil
+// entity set means every entity; an id of -1 means start from now.
+func parseStreamParams(r *http.Request) (map[string]bool, int64, []problem) {
+	var problems []problem
+	query := r.URL.Query()
+
+	var entities map[string]bool
+	for _, value := range query["entity"] {
+		for _, entity := range strings.Split(value, ",") {
+			entity = strings.TrimSpace(entity)
+			if !slices.Contains(eventEntities, entity) {
+				problems = append(problems, problem{
+					Name:        "entity",
+					Description: "must be one of " + strings.Join(eventEntities, ", "),
+				})
+				continue
+			}
+			if entities == nil {
+				entities = map[string]bool{}
+			}
+			entities[entity] = true
+		}
+	}
+
+	lastID := int64(-1)
+	raw := r.Header.Get("Last-Event-ID")
+	name := "Last-Event-ID"
+	if raw == "" {
+		raw, name = query.Get("last_event_id"), "last_event_id"
+	}
+	if raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id < 0 {
+			problems = append(problems, problem{
+				Name:        name,
+				Description: "must be a non-negative integer",
+			})
+		}
+		lastID = id
+	}
+
+	return entities, lastID, problems
+}
+
+func writeSSE(w http.ResponseWriter, event models.OutboxEvent) error {
+	data, err := json.Marshal(webhook.Payload{
+		ID:         event.ID,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Actor:      event.Actor,
+		RequestID:  event.RequestID,
+		Data:       event.Data,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Position, event.Type, data)
+	return err
+}
+
+// entityOf returns the entity an event type is about, e.g. "person" for
+// "person.created".
+func entityOf(eventType string) string {
+	entity, _, _ := strings.Cut(eventType, ".")
+	return entity
+}
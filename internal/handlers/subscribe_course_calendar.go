@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleSubscribeCourseCalendar returns the URL of the calendar feed of a
+// course by its ID
+func HandleSubscribeCourseCalendar(logger *httplog.Logger, svsCalendar *services.CalendarService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		courseID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid course ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid course ID",
+			})
+			return
+		}
+
+		token, err := svsCalendar.CourseToken(ctx, courseID)
+		if err != nil {
+			logger.Error("error getting course calendar token", "error", err)
+			switch {
+			case errors.Is(err, services.ErrForbidden):
+				encodeResponse(w, r, logger, http.StatusForbidden, responseErr{
+					Error: "calendar feeds are disabled",
+				})
+			case errors.Is(err, sql.ErrNoRows):
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No course with that ID",
+				})
+			default:
+				encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+					Error: "Error getting calendar subscription",
+				})
+			}
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseSubscription{Subscription: outputSubscription{
+			URL:   subscriptionURL(r, "/api/course/"+strconv.Itoa(courseID)+"/schedule.ics", token),
+			Token: token,
+		}})
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// HandleSubscribePersonCalendar returns the URL of the calendar feed of a
+// person by their ID. Routes allow it to admins only.
+func HandleSubscribePersonCalendar(logger *httplog.Logger, svsCalendar *services.CalendarService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		personID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			logger.Error("invalid person ID", "error", err)
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+				Error: "invalid person ID",
+			})
+			return
+		}
+
+		token, err := svsCalendar.PersonToken(ctx, personID)
+		if err != nil {
+			logger.Error("error getting person calendar token", "error", err)
+			switch {
+			case errors.Is(err, services.ErrForbidden):
+				encodeResponse(w, r, logger, http.StatusForbidden, responseErr{
+					Error: "calendar feeds are disabled",
+				})
+			case errors.Is(err, sql.ErrNoRows):
+				encodeResponse(w, r, logger, http.StatusNotFound, responseErr{
+					Error: "No person with that ID",
+				})
+			default:
+				encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+					Error: "Error getting calendar subscription",
+				})
+			}
+			return
+		}
+
+		encodeResponse(w, r, logger, http.StatusOK, responseSubscription{Subscription: outputSubscription{
+			URL:   subscriptionURL(r, "/api/person/"+strconv.Itoa(personID)+"/schedule.ics", token),
+			Token: token,
+		}})
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// text renders the transcript as a table of courses per term.
+func (resp responseTranscript) text(w io.Writer) error {
+	t := resp.Transcript
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Transcript of %s %s (id %d)\n", t.FirstName, t.LastName, t.PersonID)
+	for _, term := range t.Terms {
+		fmt.Fprintf(tw, "\n%s, %s to %s\n", term.Name, term.StartDate, term.EndDate)
+		fmt.Fprintln(tw, "Course\tName\tCredits\tGrade\tPoints")
+		for _, course := range term.Courses {
+			fmt.Fprintf(tw, "%d\t%s\t%d\t%s\t%s\n", course.CourseID, course.Name, course.Credits, formatGrade(course.Grade), formatPoints(course.Points))
+		}
+		fmt.Fprintf(tw, "Term: %d of %d credits earned, GPA %s, cumulative GPA %s\n",
+			term.CreditsEarned, term.CreditsAttempted, formatPoints(term.GPA), formatPoints(term.CumulativeGPA))
+	}
+	fmt.Fprintf(tw, "\nTotal: %d of %d credits earned, GPA %s\n", t.CreditsEarned, t.CreditsAttempted, formatPoints(t.GPA))
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// html renders the transcript as a standalone page with a table per term.
+func (resp responseTranscript) html(w io.Writer) error {
+	return transcriptHTML.Execute(w, resp.Transcript)
+}
+
+var transcriptHTML = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"grade":  formatGrade,
+	"points": formatPoints,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Transcript of {{.FirstName}} {{.LastName}}</title>
+</head>
+<body>
+<h1>Transcript of {{.FirstName}} {{.LastName}}</h1>
+{{range .Terms}}
+<h2>{{.Name}}</h2>
+<p>{{.StartDate}} to {{.EndDate}}</p>
+<table>
+<thead>
+<tr><th>Course</th><th>Name</th><th>Credits</th><th>Grade</th><th>Points</th></tr>
+</thead>
+<tbody>
+{{- range .Courses}}
+<tr><td>{{.CourseID}}</td><td>{{.Name}}</td><td>{{.Credits}}</td><td>{{grade .Grade}}</td><td>{{points .Points}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+<p>{{.CreditsEarned}} of {{.CreditsAttempted}} credits earned, GPA {{points .GPA}}, cumulative GPA {{points .CumulativeGPA}}</p>
+{{end}}
+<p><strong>Total:</strong> {{.CreditsEarned}} of {{.CreditsAttempted}} credits earned, GPA {{points .GPA}}</p>
+</body>
+</html>
+`))
+
+// formatGrade shows courses without a grade as in progress.
+func formatGrade(grade string) string {
+	if grade == "" {
+		return "in progress"
+	}
+	return grade
+}
+
+// formatPoints formats grade points and GPAs with two decimals, or as a dash
+// if there are none.
+func formatPoints(points *float64) string {
+	if points == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*points, 'f', 2, 64)
+}
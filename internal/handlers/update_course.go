@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
@@ -16,7 +17,7 @@ func HandleUpdateCourse(logger *httplog.Logger, svsCourse *services.CourseServic
 		courseID := chi.URLParam(r, "id")
 		if courseID == "" {
 			logger.Error("missing course ID")
-			encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 				Error: "missing course ID",
 			})
 			return
@@ -26,7 +27,7 @@ func HandleUpdateCourse(logger *httplog.Logger, svsCourse *services.CourseServic
 		courseIDInt, err := strconv.Atoi(courseID)
 		if err != nil {
 			logger.Error("invalid course ID", "error", err)
-			encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 				Error: "invalid course ID",
 			})
 			return
@@ -35,29 +36,43 @@ func HandleUpdateCourse(logger *httplog.Logger, svsCourse *services.CourseServic
 		courseIn, problems, err := decodeValidateBody[inputCourse, models.Course](r)
 		if err != nil {
 			switch {
+			case errors.Is(err, errUnsupportedMediaType):
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusUnsupportedMediaType, responseErr{
+					Error: "unsupported media type",
+				})
 			case len(problems) > 0:
 				logger.Error("Problems validating input", "error", err, "problems", problems)
-				encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 					ValidationErrors: problems,
 				})
 			default:
 				logger.Error("BodyParser error", "error", err)
-				encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 					Error: "missing values or malformed body",
 				})
 			}
 			return
 		}
 
-		updatedCourse, err := svsCourse.UpdateCourse(ctx, courseIDInt, courseIn.Name)
+		updatedCourse, err := svsCourse.UpdateCourse(ctx, courseIDInt, courseIn)
 		if err != nil {
-			logger.Error("error updating course", "error", err)
-			encodeResponse(w, logger, http.StatusInternalServerError, responseErr{
-				Error: "Error updating course",
-			})
+			var validationErr *services.ValidationError
+			switch {
+			case errors.As(err, &validationErr):
+				logger.Error("Problems validating course", "error", err, "problems", validationErr.Problems)
+				encodeResponse(w, r, logger, http.StatusUnprocessableEntity, responseErr{
+					ValidationErrors: validationErr.Problems,
+				})
+			default:
+				logger.Error("error updating course", "error", err)
+				encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+					Error: "Error updating course",
+				})
+			}
 			return
 		}
 
-		encodeResponse(w, logger, http.StatusOK, responseCourse{Course: mapOutputCourse(updatedCourse)})
+		encodeResponse(w, r, logger, http.StatusOK, responseCourse{Course: mapOutputCourse(updatedCourse)})
 	}
 }
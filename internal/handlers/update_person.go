@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -12,11 +13,14 @@ import (
 // HandleUpdatePerson updates person by their firstName
 func HandleUpdatePerson(logger *httplog.Logger, svsPerson *services.PersonService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		ctx, ok := enrollmentOverrides(w, r, logger)
+		if !ok {
+			return
+		}
 		firstName := chi.URLParam(r, "firstName")
 		if firstName == "" {
 			logger.Error("missing person firstName")
-			encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+			encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 				Error: "missing person firstName",
 			})
 			return
@@ -25,14 +29,19 @@ func HandleUpdatePerson(logger *httplog.Logger, svsPerson *services.PersonServic
 		personIn, problems, err := decodeValidateBody[inputPerson, models.Person](r)
 		if err != nil {
 			switch {
+			case errors.Is(err, errUnsupportedMediaType):
+				logger.Error("BodyParser error", "error", err)
+				encodeResponse(w, r, logger, http.StatusUnsupportedMediaType, responseErr{
+					Error: "unsupported media type",
+				})
 			case len(problems) > 0:
 				logger.Error("Problems validating input", "error", err, "problems", problems)
-				encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 					ValidationErrors: problems,
 				})
 			default:
 				logger.Error("BodyParser error", "error", err)
-				encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
 					Error: "missing values or malformed body",
 				})
 			}
@@ -40,20 +49,33 @@ func HandleUpdatePerson(logger *httplog.Logger, svsPerson *services.PersonServic
 		}
 
 		updatedPerson, err := svsPerson.UpdatePerson(ctx, firstName, models.Person{
-			FirstName: personIn.FirstName,
-			LastName:  personIn.LastName,
-			Type:      personIn.Type,
-			Age:       personIn.Age,
-			Courses:   personIn.Courses,
+			FirstName:   personIn.FirstName,
+			LastName:    personIn.LastName,
+			Type:        personIn.Type,
+			DateOfBirth: personIn.DateOfBirth,
+			Email:       personIn.Email,
+			Phone:       personIn.Phone,
+			Address:     personIn.Address,
+			Courses:     personIn.Courses,
+			Enrollments: personIn.Enrollments,
 		})
 		if err != nil {
-			logger.Error("error updating person", "error", err)
-			encodeResponse(w, logger, http.StatusInternalServerError, responseErr{
-				Error: "Error updating person",
-			})
+			var validationErr *services.ValidationError
+			switch {
+			case errors.As(err, &validationErr):
+				logger.Error("Problems validating person", "error", err, "problems", validationErr.Problems)
+				encodeResponse(w, r, logger, http.StatusUnprocessableEntity, responseErr{
+					ValidationErrors: validationErr.Problems,
+				})
+			default:
+				logger.Error("error updating person", "error", err)
+				encodeResponse(w, r, logger, http.StatusInternalServerError, responseErr{
+					Error: "Error updating person",
+				})
+			}
 			return
 		}
 
-		encodeResponse(w, logger, http.StatusOK, updatedPerson)
+		encodeResponse(w, r, logger, http.StatusOK, updatedPerson)
 	}
 }
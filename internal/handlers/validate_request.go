@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/openapi"
+)
+
+// maxBodyBytes caps the request bodies ValidateRequest reads.
+const maxBodyBytes = 1 << 20
+
+// ValidateRequest checks path parameters, query parameters and request bodies
+// against the operation documented for the matched route in the OpenAPI
+// document, and responds 400 with every violation before the handler runs.
+// Violations are named by JSON Pointer, rooted at /path, /query or /body.
+//
+// spec is called per request because the document is generated from the
+// router after all routes, and therefore this middleware, are registered.
+// Requests for undocumented routes are passed through untouched. Bodies over
+// maxBodyBytes are rejected with 413.
+func ValidateRequest(logger *httplog.Logger, spec func() *openapi.Document) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			doc := spec()
+			if doc == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			_, op, pathParams := doc.Match(r.Method, r.URL.Path)
+			if op == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			violations := validateParams(doc, op, pathParams, r)
+
+			if op.RequestBody != nil {
+				bodyViolations, err := validateBody(doc, op, w, r)
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					logger.Error("Request body too large", "limit", tooLarge.Limit)
+					encodeResponse(w, r, logger, http.StatusRequestEntityTooLarge, responseErr{
+						Error: "request body too large",
+					})
+					return
+				}
+				if err != nil {
+					logger.Error("BodyParser error", "error", err)
+					encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+						Error: "missing values or malformed body",
+					})
+					return
+				}
+				violations = append(violations, bodyViolations...)
+			}
+
+			if len(violations) > 0 {
+				problems := make([]problem, 0, len(violations))
+				for _, v := range violations {
+					problems = append(problems, problem{Name: v.Pointer, Description: v.Message})
+				}
+				logger.Error("Problems validating request against schema", "problems", problems)
+				encodeResponse(w, r, logger, http.StatusBadRequest, responseErr{
+					ValidationErrors: problems,
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func validateParams(doc *openapi.Document, op *openapi.Operation, pathParams map[string]string, r *http.Request) []openapi.Violation {
+	var violations []openapi.Violation
+	query := r.URL.Query()
+
+	for _, param := range op.Parameters {
+		var (
+			raw     string
+			present bool
+		)
+		switch param.In {
+		case "path":
+			raw, present = pathParams[param.Name]
+		case "query":
+			present = query.Has(param.Name)
+			raw = query.Get(param.Name)
+		default:
+			continue
+		}
+
+		pointer := "/" + param.In + "/" + param.Name
+		if !present {
+			if param.Required {
+				violations = append(violations, openapi.Violation{Pointer: pointer, Message: "is required"})
+			}
+			continue
+		}
+
+		value, err := doc.ParseParam(param.Schema, raw)
+		if err != nil {
+			violations = append(violations, openapi.Violation{Pointer: pointer, Message: err.Error()})
+			continue
+		}
+		violations = append(violations, doc.Validate(param.Schema, value, pointer)...)
+	}
+
+	return violations
+}
+
+// validateBody decodes the body generically for validation and then restores
+// it so the handler can decode it into its input type. Bodies in every media
+// type with a registered decoder are validated, under the schema documented
+// for the decoder's first media type. Reading more than maxBodyBytes fails
+// with an *http.MaxBytesError.
+func validateBody(doc *openapi.Document, op *openapi.Operation, w http.ResponseWriter, r *http.Request) ([]openapi.Violation, error) {
+	dec, err := requestDecoder(r)
+	if err != nil {
+		// Left for Negotiate to reject as an unsupported media type.
+		return nil, nil
+	}
+
+	content, ok := op.RequestBody.Content[dec.mediaTypes[0]]
+	if !ok {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		return nil, err
+	}
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			return []openapi.Violation{{Pointer: "/body", Message: "is required"}}, nil
+		}
+		return nil, nil
+	}
+
+	value, err := dec.generic(body, doc.TypeOf(content.Schema))
+	if err != nil {
+		return nil, err
+	}
+	return doc.Validate(content.Schema, value, "/body"), nil
+}
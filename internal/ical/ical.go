@@ -0,0 +1,118 @@
+// Package ical writes iCalendar (RFC 5545) feeds of weekly recurring events,
+// for calendar apps to subscribe to.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Calendar is a feed of events. Name is shown by calendar apps that support
+// the X-WR-CALNAME extension.
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+// Event recurs weekly on Days, from its first occurrence Start to End until
+// the day Until, inclusive. Times are floating: they are shown at the same
+// wall clock time in whatever time zone the calendar is viewed in. Calendar
+// apps replace an event they already have with one with the same UID.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	Days        []time.Weekday
+	Until       time.Time
+	// Stamp is when the event was last changed or, if that is not known,
+	// when the feed was generated.
+	Stamp time.Time
+}
+
+const (
+	floatingLayout = "20060102T150405"
+	utcLayout      = "20060102T150405Z"
+)
+
+// byDay are the RRULE BYDAY codes of the weekdays.
+var byDay = [...]string{
+	time.Sunday:    "SU",
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+}
+
+// Write writes the calendar to w, with CRLF line endings and lines folded at
+// 75 octets as RFC 5545 requires.
+func (c Calendar) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", c.ProdID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+	for _, e := range c.Events {
+		days := make([]string, 0, len(e.Days))
+		for _, day := range e.Days {
+			days = append(days, byDay[day])
+		}
+		until := time.Date(e.Until.Year(), e.Until.Month(), e.Until.Day(), 23, 59, 59, 0, time.UTC)
+
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", e.Stamp.UTC().Format(utcLayout))
+		line("DTSTART", e.Start.Format(floatingLayout))
+		line("DTEND", e.End.Format(floatingLayout))
+		line("RRULE", fmt.Sprintf("FREQ=WEEKLY;BYDAY=%s;UNTIL=%s", strings.Join(days, ","), until.Format(floatingLayout)))
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escape(e.Location))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// escape escapes a TEXT value.
+var escape = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace
+
+// writeFolded writes a content line, folding it onto continuation lines that
+// start with a space so no line is longer than 75 octets, without splitting
+// a UTF-8 sequence.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,133 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestWrite(t *testing.T) {
+	cal := Calendar{
+		ProdID: "-//Test//EN",
+		Name:   "Ada; Lovelace",
+		Events: []Event{{
+			UID:         "section-1-meeting-3@test",
+			Summary:     `Math, \ Logic`,
+			Description: "Role: student\nRoom: A",
+			Location:    "Hall 1",
+			Start:       time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC),
+			End:         time.Date(2025, 9, 1, 10, 30, 0, 0, time.UTC),
+			Days:        []time.Weekday{time.Monday, time.Wednesday},
+			Until:       time.Date(2025, 12, 19, 0, 0, 0, 0, time.UTC),
+			Stamp:       time.Date(2025, 8, 1, 8, 0, 0, 0, time.FixedZone("", 2*60*60)),
+		}},
+	}
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Test//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		`X-WR-CALNAME:Ada\; Lovelace`,
+		"BEGIN:VEVENT",
+		"UID:section-1-meeting-3@test",
+		"DTSTAMP:20250801T060000Z",
+		"DTSTART:20250901T090000",
+		"DTEND:20250901T103000",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20251219T235959",
+		`SUMMARY:Math\, \\ Logic`,
+		`DESCRIPTION:Role: student\nRoom: A`,
+		"LOCATION:Hall 1",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	var buf bytes.Buffer
+	if err := cal.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteOmitsEmptyOptionalProperties(t *testing.T) {
+	var buf bytes.Buffer
+	cal := Calendar{ProdID: "-//Test//EN", Events: []Event{{UID: "a", Summary: "b", Days: []time.Weekday{time.Friday}}}}
+	if err := cal.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"X-WR-CALNAME:", "DESCRIPTION:", "LOCATION:"} {
+		if strings.Contains(buf.String(), name) {
+			t.Errorf("Write() wrote %s for an empty value:\n%s", name, buf.String())
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain", "plain"},
+		{`a\b`, `a\\b`},
+		{"a;b,c", `a\;b\,c`},
+		{"a\nb", `a\nb`},
+		{"a\r\nb", `a\nb`},
+		{`\;`, `\\\;`},
+	}
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteFolded(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:Math"},
+		{"exactly 75 octets", "SUMMARY:" + strings.Repeat("a", 67)},
+		{"76 octets", "SUMMARY:" + strings.Repeat("a", 68)},
+		{"several continuations", "DESCRIPTION:" + strings.Repeat("abcdefghij", 30)},
+		// Each é is two octets and ends up across the 75th octet at some offset.
+		{"two octet runes", "SUMMARY:" + strings.Repeat("é", 100)},
+		{"two octet runes shifted", "SUMMARY:x" + strings.Repeat("é", 100)},
+		{"three octet runes", "SUMMARY:" + strings.Repeat("€", 60)},
+		{"four octet runes", "LOCATION:" + strings.Repeat("𝄞", 50)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := bufio.NewWriter(&buf)
+			writeFolded(w, tt.line)
+			w.Flush()
+
+			out := buf.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output %q does not end in CRLF", out)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			for i, line := range lines {
+				if len(line) > 75 {
+					t.Errorf("line %d is %d octets: %q", i, len(line), line)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a UTF-8 sequence: %q", i, line)
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, line)
+				}
+			}
+			if len(tt.line) > 75 && len(lines) < 2 {
+				t.Errorf("line of %d octets was not folded", len(tt.line))
+			}
+			if unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); unfolded != tt.line {
+				t.Errorf("unfolded = %q, want %q", unfolded, tt.line)
+			}
+		})
+	}
+}
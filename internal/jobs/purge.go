@@ -0,0 +1,45 @@
+// Package jobs holds background work that runs alongside the HTTP server.
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+)
+
+// RunPurge permanently removes persons and courses that have been soft
+// deleted for longer than retention, once at start and then every interval,
+// until ctx is cancelled. Each purged row is recorded in the audit log.
+func RunPurge(ctx context.Context, logger *httplog.Logger, interval, retention time.Duration, svsCourse *services.CourseService, svsPerson *services.PersonService) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purge(ctx, logger, retention, svsCourse, svsPerson)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purge(ctx context.Context, logger *httplog.Logger, retention time.Duration, svsCourse *services.CourseService, svsPerson *services.PersonService) {
+	cutoff := time.Now().Add(-retention)
+
+	persons, err := svsPerson.PurgePersons(ctx, cutoff)
+	if err != nil {
+		logger.Error("Error purging deleted persons", "error", err)
+	}
+	courses, err := svsCourse.PurgeCourses(ctx, cutoff)
+	if err != nil {
+		logger.Error("Error purging deleted courses", "error", err)
+	}
+
+	if persons > 0 || courses > 0 {
+		logger.Info("Purged soft deleted records", "persons", persons, "courses", courses, "cutoff", cutoff)
+	}
+}
@@ -0,0 +1,158 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/webhook"
+)
+
+// WebhookOptions configures RunWebhooks.
+type WebhookOptions struct {
+	// Interval between polls of the outbox and the delivery queue.
+	Interval time.Duration
+	// Timeout for each delivery request.
+	Timeout time.Duration
+	// MaxAttempts before a delivery is dead-lettered.
+	MaxAttempts int
+	// BaseBackoff is the delay before the first retry; it doubles with each
+	// further attempt, up to MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// batchSize bounds the events fanned out and deliveries sent per poll.
+const batchSize = 50
+
+// RunWebhooks dispatches outbox events to webhook subscriptions until ctx is
+// cancelled. Each poll fans new events out into deliveries and sends the
+// deliveries that are due, concurrently. Failed deliveries are retried with
+// exponential backoff and dead-lettered after MaxAttempts; they can be
+// replayed through the webhook endpoints.
+func RunWebhooks(ctx context.Context, logger *httplog.Logger, svsWebhook *services.WebhookService, opts WebhookOptions) {
+	client := &http.Client{Timeout: opts.Timeout}
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		dispatch(ctx, logger, svsWebhook, client, opts)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func dispatch(ctx context.Context, logger *httplog.Logger, svsWebhook *services.WebhookService, client *http.Client, opts WebhookOptions) {
+	for {
+		n, err := svsWebhook.EnqueueDeliveries(ctx, batchSize)
+		if err != nil {
+			logger.Error("Error enqueueing webhook deliveries", "error", err)
+			break
+		}
+		if n < batchSize {
+			break
+		}
+	}
+
+	// A claim outlives the request timeout so a slow but successful delivery
+	// is not sent twice.
+	deliveries, err := svsWebhook.ClaimDeliveries(ctx, batchSize, 2*opts.Timeout+opts.Interval)
+	if err != nil {
+		logger.Error("Error claiming webhook deliveries", "error", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, d := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			deliver(ctx, logger, svsWebhook, client, opts, d)
+		}()
+	}
+	wg.Wait()
+}
+
+func deliver(ctx context.Context, logger *httplog.Logger, svsWebhook *services.WebhookService, client *http.Client, opts WebhookOptions, d services.PendingDelivery) {
+	status, err := send(ctx, client, d)
+	if err == nil {
+		if err := svsWebhook.CompleteDelivery(ctx, d.ID, status); err != nil {
+			logger.Error("Error recording webhook delivery", "error", err, "delivery", d.ID)
+		}
+		return
+	}
+
+	var retryAt time.Time
+	if d.Attempt < opts.MaxAttempts {
+		retryAt = time.Now().Add(backoff(d.Attempt, opts.BaseBackoff, opts.MaxBackoff))
+	}
+	logger.Warn("Webhook delivery failed", "error", err, "delivery", d.ID, "attempt", d.Attempt, "dead", retryAt.IsZero())
+	if err := svsWebhook.FailDelivery(ctx, d.ID, status, err.Error(), retryAt); err != nil {
+		logger.Error("Error recording webhook delivery failure", "error", err, "delivery", d.ID)
+	}
+}
+
+// send POSTs the signed event and returns the response status. Any status
+// outside 2xx is an error.
+func send(ctx context.Context, client *http.Client, d services.PendingDelivery) (int, error) {
+	body, err := json.Marshal(webhook.Payload{
+		ID:         d.Event.ID,
+		Type:       d.Event.Type,
+		OccurredAt: d.Event.OccurredAt,
+		Actor:      d.Event.Actor,
+		RequestID:  d.Event.RequestID,
+		Data:       d.Event.Data,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "user-microservice-webhooks")
+	req.Header.Set(webhook.HeaderID, strconv.FormatInt(d.Event.ID, 10))
+	req.Header.Set(webhook.HeaderEvent, d.Event.Type)
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(d.Secret, time.Now(), body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay after the given failed attempt: base doubled for
+// each earlier attempt, capped at max, with up to 20% jitter so retries from
+// an outage do not arrive in lockstep.
+func backoff(attempt int, base, max time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d - time.Duration(rand.Int64N(int64(d)/5+1))
+}
@@ -0,0 +1,97 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/webhook"
+)
+
+func TestBackoff(t *testing.T) {
+	base, max := time.Second, time.Minute
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{6, 32 * time.Second},
+		{7, time.Minute},
+		{8, time.Minute},
+		{1000, time.Minute},
+	}
+	for _, tt := range tests {
+		for range 50 {
+			got := backoff(tt.attempt, base, max)
+			if got > tt.want || got < tt.want-tt.want/5 {
+				t.Fatalf("backoff(%d) = %v, want within 20%% below %v", tt.attempt, got, tt.want)
+			}
+		}
+	}
+
+	if got := backoff(3, time.Hour, time.Minute); got > time.Minute {
+		t.Errorf("backoff with base above max = %v, want at most %v", got, time.Minute)
+	}
+}
+
+func TestSend(t *testing.T) {
+	var (
+		header http.Header
+		body   []byte
+		status = http.StatusNoContent
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	d := services.PendingDelivery{
+		ID:     7,
+		URL:    server.URL,
+		Secret: "whsec",
+		Event: models.OutboxEvent{
+			ID:         42,
+			Type:       "person.created",
+			OccurredAt: time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC),
+			Actor:      "Steve",
+			Data:       json.RawMessage(`{"id":1}`),
+		},
+	}
+
+	got, err := send(context.Background(), server.Client(), d)
+	if err != nil || got != http.StatusNoContent {
+		t.Fatalf("send() = %d, %v, want %d, nil", got, err, http.StatusNoContent)
+	}
+	if id := header.Get(webhook.HeaderID); id != "42" {
+		t.Errorf("%s = %q, want %q", webhook.HeaderID, id, "42")
+	}
+	if event := header.Get(webhook.HeaderEvent); event != "person.created" {
+		t.Errorf("%s = %q, want %q", webhook.HeaderEvent, event, "person.created")
+	}
+	if err := webhook.Verify("whsec", header.Get(webhook.HeaderSignature), body, time.Minute, time.Now()); err != nil {
+		t.Errorf("Verify() = %v", err)
+	}
+	var payload webhook.Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.ID != 42 || payload.Type != "person.created" || payload.Actor != "Steve" || string(payload.Data) != `{"id":1}` {
+		t.Errorf("payload = %+v", payload)
+	}
+
+	status = http.StatusServiceUnavailable
+	if got, err := send(context.Background(), server.Client(), d); err == nil || got != http.StatusServiceUnavailable {
+		t.Errorf("send() = %d, %v, want %d and an error", got, err, http.StatusServiceUnavailable)
+	}
+}
@@ -1,10 +1,35 @@
 package models
 
+import "time"
+
 type Course struct {
 	ID 		int    `json:"id"`
 	Name 	string `json:"name"`
+	// Capacity is the number of students its sections seat unless they are
+	// created with their own, or nil if it is unlimited.
+	Capacity *int `json:"capacity"`
+	// Credits is what the course is worth on a transcript.
+	Credits int `json:"credits"`
+	// DepartmentID is the department offering the course, or nil if there is
+	// none. CatalogNumber is only set for courses in a department, and Code
+	// is then their catalog code, e.g. CS-201.
+	DepartmentID  *int   `json:"department_id"`
+	CatalogNumber string `json:"catalog_number"`
+	Code          string `json:"code"`
+	Description   string `json:"description"`
+	// The ids of the persons holding each role in the course's sections in
+	// the current term, see Enrollment.
+	Instructors        []int `json:"instructors"`
+	TeachingAssistants []int `json:"teaching_assistants"`
+	Students           []int `json:"students"`
+	Auditors           []int `json:"auditors"`
+	// DeletedAt is set when the course has been soft deleted.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
+// DefaultCredits is what a course is worth unless it says otherwise.
+const DefaultCredits = 3
+
 func (Course) TableName() string {
 	return "course"
 }
\ No newline at end of file
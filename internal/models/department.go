@@ -0,0 +1,16 @@
+package models
+
+// Department offers courses. Code prefixes the catalog codes of its courses,
+// e.g. CS in CS-201.
+type Department struct {
+	ID   int    `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// ChairID is the professor chairing the department, or nil if there is
+	// none.
+	ChairID *int `json:"chair_id"`
+}
+
+func (Department) TableName() string {
+	return "department"
+}
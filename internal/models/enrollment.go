@@ -0,0 +1,30 @@
+package models
+
+// Roles a person can hold in a course section.
+const (
+	RoleInstructor        = "instructor"
+	RoleTeachingAssistant = "teaching_assistant"
+	RoleStudent           = "student"
+	RoleAuditor           = "auditor"
+)
+
+// Enrollment is a person's role in a section of a course. Only professors
+// can be instructors.
+type Enrollment struct {
+	CourseID  int    `json:"course_id"`
+	SectionID int    `json:"section_id"`
+	Role      string `json:"role"`
+}
+
+func (Enrollment) TableName() string {
+	return "person_section"
+}
+
+// DefaultRole is the role a person of personType gets in courses they are
+// added to without one: professors teach and students attend.
+func DefaultRole(personType string) string {
+	if personType == "professor" {
+		return RoleInstructor
+	}
+	return RoleStudent
+}
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// Grades recorded besides the letters of the grade scale. They carry no
+// grade points.
+const (
+	GradePass       = "P"
+	GradeNoPass     = "NP"
+	GradeIncomplete = "I"
+)
+
+// Grade is the final grade of a student in a section.
+type Grade struct {
+	PersonID  int    `json:"person_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	SectionID int    `json:"section_id"`
+	CourseID  int    `json:"course_id"`
+	Grade     string `json:"grade"`
+	// Points is the letter grade's value on the grade scale in force when it
+	// was recorded, or nil for pass/fail and incomplete grades.
+	Points *float64 `json:"points"`
+	// Passed is set for grades that earn the course's credits.
+	Passed bool `json:"passed"`
+	// GradedBy is the id of the professor who recorded or last amended the
+	// grade, or nil if they have been purged.
+	GradedBy *int      `json:"graded_by"`
+	GradedAt time.Time `json:"graded_at"`
+}
+
+func (Grade) TableName() string {
+	return "grade"
+}
+
+// Transcript is a person's courses as a student, by term, with their grades
+// and grade point averages. GPAs are nil until a letter grade is recorded.
+type Transcript struct {
+	PersonID         int              `json:"person_id"`
+	FirstName        string           `json:"first_name"`
+	LastName         string           `json:"last_name"`
+	Terms            []TranscriptTerm `json:"terms"`
+	CreditsAttempted int              `json:"credits_attempted"`
+	CreditsEarned    int              `json:"credits_earned"`
+	GPA              *float64         `json:"gpa"`
+}
+
+// TranscriptTerm is the part of a transcript for one term. CumulativeGPA
+// counts every term up to and including this one.
+type TranscriptTerm struct {
+	TermID           int                `json:"term_id"`
+	Name             string             `json:"name"`
+	StartDate        time.Time          `json:"start_date"`
+	EndDate          time.Time          `json:"end_date"`
+	Courses          []TranscriptCourse `json:"courses"`
+	CreditsAttempted int                `json:"credits_attempted"`
+	CreditsEarned    int                `json:"credits_earned"`
+	GPA              *float64           `json:"gpa"`
+	CumulativeGPA    *float64           `json:"cumulative_gpa"`
+}
+
+// TranscriptCourse is a course on a transcript. Grade is empty until one is
+// recorded.
+type TranscriptCourse struct {
+	CourseID  int      `json:"course_id"`
+	SectionID int      `json:"section_id"`
+	Name      string   `json:"name"`
+	Credits   int      `json:"credits"`
+	Grade     string   `json:"grade"`
+	Points    *float64 `json:"points"`
+}
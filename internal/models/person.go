@@ -1,12 +1,37 @@
 package models
 
+import "time"
+
 type Person struct {
 	ID        int    `json:"id"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Type      string `json:"type"`
-	Age       int    `json:"age"`
+	// DateOfBirth is a date, at midnight UTC. Age is computed from it when
+	// the person is read.
+	DateOfBirth time.Time `json:"date_of_birth"`
+	Age         int       `json:"age"`
+	// Email is unique whatever its case. Phone is in E.164 format, e.g.
+	// +15555550100. Each is empty if it is not known.
+	Email   string `json:"email"`
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
 	Courses   []int  `json:"courses"`
+	// Enrollments holds the person's section of and role in each of Courses.
+	// Both cover the current term only.
+	Enrollments []Enrollment `json:"enrollments"`
+	// DeletedAt is set when the person has been soft deleted.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// AgeOn returns the age in whole years on the date day of someone born on
+// dateOfBirth.
+func AgeOn(dateOfBirth, day time.Time) int {
+	age := day.Year() - dateOfBirth.Year()
+	if day.Month() < dateOfBirth.Month() || day.Month() == dateOfBirth.Month() && day.Day() < dateOfBirth.Day() {
+		age--
+	}
+	return age
 }
 
 func (Person) TableName() string {
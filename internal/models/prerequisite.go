@@ -0,0 +1,13 @@
+package models
+
+// Prerequisite is a course students must complete before enrolling in
+// another, with the courses it requires in turn.
+type Prerequisite struct {
+	CourseID      int            `json:"course_id"`
+	Name          string         `json:"name"`
+	Prerequisites []Prerequisite `json:"prerequisites"`
+}
+
+func (Prerequisite) TableName() string {
+	return "course_prerequisite"
+}
@@ -0,0 +1,14 @@
+package models
+
+// Room is a place sections meet in.
+type Room struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Capacity is the number of seats. A section can only meet in a room
+	// that seats its capacity.
+	Capacity int `json:"capacity"`
+}
+
+func (Room) TableName() string {
+	return "room"
+}
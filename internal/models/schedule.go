@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Schedule is the week of a person in a term: what they meet for on each
+// weekday, Monday first.
+type Schedule struct {
+	PersonID  int           `json:"person_id"`
+	FirstName string        `json:"first_name"`
+	LastName  string        `json:"last_name"`
+	TermID    int           `json:"term_id"`
+	TermName  string        `json:"term_name"`
+	Days      []ScheduleDay `json:"days"`
+}
+
+// ScheduleDay is one weekday of a schedule, with its meetings in the order
+// they start.
+type ScheduleDay struct {
+	Day      string          `json:"day"`
+	Meetings []ScheduleEntry `json:"meetings"`
+}
+
+// ScheduleEntry is a meeting of a section a person holds or teaches, with
+// their role in it. RoomID and RoomName are empty if no room is assigned.
+type ScheduleEntry struct {
+	StartTime     string `json:"start_time"`
+	EndTime       string `json:"end_time"`
+	SectionID     int    `json:"section_id"`
+	SectionNumber int    `json:"section_number"`
+	CourseID      int    `json:"course_id"`
+	CourseName    string `json:"course_name"`
+	Role          string `json:"role"`
+	RoomID        *int   `json:"room_id"`
+	RoomName      string `json:"room_name"`
+}
+
+// Calendar is every meeting of the sections a person is enrolled in or the
+// instructor of, or of the sections of a course, in every term, for calendar
+// feeds.
+type Calendar struct {
+	Name    string          `json:"name"`
+	Entries []CalendarEntry `json:"entries"`
+}
+
+// CalendarEntry is a meeting of a section, which recurs weekly from the
+// start to the end of the section's term. MeetingID identifies the meeting
+// for as long as it exists. Role is empty in course calendars.
+type CalendarEntry struct {
+	SectionID     int       `json:"section_id"`
+	SectionNumber int       `json:"section_number"`
+	CourseID      int       `json:"course_id"`
+	CourseName    string    `json:"course_name"`
+	TermStart     time.Time `json:"term_start"`
+	TermEnd       time.Time `json:"term_end"`
+	MeetingID     int       `json:"meeting_id"`
+	Meeting       Meeting   `json:"meeting"`
+	RoomName      string    `json:"room_name"`
+	Role          string    `json:"role"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "slices"
+
+// Section is an offering of a course in a term. Persons enroll in sections;
+// see Enrollment.
+type Section struct {
+	ID       int `json:"id"`
+	CourseID int `json:"course_id"`
+	TermID   int `json:"term_id"`
+	// Number tells the sections of a course in a term apart, counting from 1.
+	Number int `json:"number"`
+	// InstructorID is the professor of record, or nil if none is assigned.
+	// Other teaching staff enroll with the instructor or teaching assistant
+	// role.
+	InstructorID *int `json:"instructor_id"`
+	// Capacity is the number of students the section seats, or nil if it is
+	// unlimited. Students enrolling in a full section join its waitlist.
+	Capacity *int `json:"capacity"`
+	// The ids of the persons holding each role in the section.
+	Instructors        []int `json:"instructors"`
+	TeachingAssistants []int `json:"teaching_assistants"`
+	Students           []int `json:"students"`
+	Auditors           []int `json:"auditors"`
+	// Meetings is when and where the section meets each week.
+	Meetings []Meeting `json:"meetings"`
+}
+
+func (Section) TableName() string {
+	return "section"
+}
+
+// Weekdays are the days a meeting can be on, in the order of a week.
+var Weekdays = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// Meeting is a weekly meeting pattern of a section: it meets on each of Days
+// from StartTime to EndTime, formatted as 15:04, in RoomID unless it is nil.
+type Meeting struct {
+	Days      []string `json:"days"`
+	StartTime string   `json:"start_time"`
+	EndTime   string   `json:"end_time"`
+	RoomID    *int     `json:"room_id"`
+}
+
+// Overlaps reports whether the meeting shares a day with other and the two
+// are in session at the same time on it.
+func (m Meeting) Overlaps(other Meeting) bool {
+	for _, day := range m.Days {
+		if slices.Contains(other.Days, day) {
+			return m.StartTime < other.EndTime && other.StartTime < m.EndTime
+		}
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Term is an academic term. StartDate and EndDate are dates, at midnight UTC.
+type Term struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	// Current is set for the term the course and person routes act on: the
+	// one that started most recently.
+	Current bool `json:"current"`
+}
+
+func (Term) TableName() string {
+	return "term"
+}
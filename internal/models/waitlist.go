@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// WaitlistEntry is a person's place in line for a student seat in a full
+// section. Position counts from 1 for the next person to be enrolled.
+type WaitlistEntry struct {
+	CourseID  int       `json:"course_id"`
+	SectionID int       `json:"section_id"`
+	PersonID  int       `json:"person_id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Position  int       `json:"position"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+func (WaitlistEntry) TableName() string {
+	return "section_waitlist"
+}
@@ -0,0 +1,245 @@
+// Package openapi models an OpenAPI 3.1 document and generates JSON Schemas
+// for Go types from their struct tags.
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+
+	names map[reflect.Type]string
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// PathItem holds the operations for one path, keyed by lower case HTTP method
+// as in the OpenAPI document.
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+	Patch  *Operation `json:"patch,omitempty"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the subset of JSON Schema 2020-12 used by this API.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	UniqueItems          bool               `json:"uniqueItems,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+}
+
+// New returns an empty document.
+func New(title, version string) *Document {
+	return &Document{
+		OpenAPI:    Version,
+		Info:       Info{Title: title, Version: version},
+		Paths:      map[string]*PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}},
+		names:      map[reflect.Type]string{},
+	}
+}
+
+// AddOperation adds op under the given method and chi route pattern. chi and
+// OpenAPI share the {param} syntax, so patterns are used as-is.
+func (d *Document) AddOperation(method, pattern string, op *Operation) error {
+	item, ok := d.Paths[pattern]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[pattern] = item
+	}
+
+	slot := item.operation(method)
+	if slot == nil {
+		return fmt.Errorf("[in openapi.AddOperation] unsupported method %s", method)
+	}
+	if *slot != nil {
+		return fmt.Errorf("[in openapi.AddOperation] duplicate operation %s %s", method, pattern)
+	}
+	*slot = op
+	return nil
+}
+
+// Operation returns the operation for method and pattern, or nil.
+func (d *Document) Operation(method, pattern string) *Operation {
+	item, ok := d.Paths[pattern]
+	if !ok {
+		return nil
+	}
+	if slot := item.operation(method); slot != nil {
+		return *slot
+	}
+	return nil
+}
+
+// Routes lists every documented operation as "METHOD pattern", sorted.
+func (d *Document) Routes() []string {
+	var routes []string
+	for pattern, item := range d.Paths {
+		for _, method := range []string{"GET", "PUT", "POST", "DELETE", "PATCH"} {
+			if slot := item.operation(method); slot != nil && *slot != nil {
+				routes = append(routes, method+" "+pattern)
+			}
+		}
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+func (p *PathItem) operation(method string) **Operation {
+	switch strings.ToUpper(method) {
+	case "GET":
+		return &p.Get
+	case "PUT":
+		return &p.Put
+	case "POST":
+		return &p.Post
+	case "DELETE":
+		return &p.Delete
+	case "PATCH":
+		return &p.Patch
+	default:
+		return nil
+	}
+}
+
+// Resolve follows a local component $ref.
+func (d *Document) Resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// TypeOf returns the Go type a component $ref was generated from by
+// Component, or nil if s is not such a reference.
+func (d *Document) TypeOf(s *Schema) reflect.Type {
+	if s == nil || s.Ref == "" {
+		return nil
+	}
+	name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+	for t, n := range d.names {
+		if n == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// Match finds the documented operation for a request, returning its path
+// template and the path parameter values. Trailing slashes are ignored, as
+// chi treats "/api/course" and "/api/course/" alike, and literal segments win
+// over parameters when more than one template matches.
+func (d *Document) Match(method, path string) (string, *Operation, map[string]string) {
+	var (
+		bestPattern string
+		bestOp      *Operation
+		bestParams  map[string]string
+	)
+	segments := splitPath(path)
+	for pattern := range d.Paths {
+		op := d.Operation(method, pattern)
+		if op == nil {
+			continue
+		}
+		params, ok := matchSegments(splitPath(pattern), segments)
+		if !ok {
+			continue
+		}
+		if bestOp == nil || len(params) < len(bestParams) {
+			bestPattern, bestOp, bestParams = pattern, op, params
+		}
+	}
+	return bestPattern, bestOp, bestParams
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func matchSegments(pattern, path []string) (map[string]string, bool) {
+	if len(pattern) != len(path) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, seg := range pattern {
+		if name, ok := strings.CutPrefix(seg, "{"); ok && strings.HasSuffix(name, "}") {
+			if path[i] == "" {
+				return nil, false
+			}
+			params[strings.TrimSuffix(name, "}")] = path[i]
+			continue
+		}
+		if seg != path[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
@@ -0,0 +1,235 @@
+package openapi
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+)
+
+var (
+	xmlNameType    = reflect.TypeOf(xml.Name{})
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Ref returns a reference to a schema in components.
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// Component registers the schema of v's type under its Go type name and
+// returns a reference to it. Later schemas that embed the same type refer to
+// the component instead of inlining it, so register inner types first. A
+// type that contains itself refers to its own component.
+func (d *Document) Component(v any) *Schema {
+	t := reflect.TypeOf(v)
+	if name, ok := d.names[t]; ok {
+		return Ref(name)
+	}
+	d.names[t] = t.Name()
+	d.Components.Schemas[t.Name()] = d.schemaFor(t)
+	return Ref(t.Name())
+}
+
+// SchemaFor generates a schema for t from its json tags. Fields are required
+// unless tagged omitempty, and no other properties are allowed. Constraints
+// are translated from validate tags (see package validation) so the document
+// describes the same rules the handlers enforce. Keywords with no validate
+// rule come from an openapi tag of comma separated key=value pairs, e.g.
+//
+//	`openapi:"format=date,description=Date of birth"`
+//
+// Supported keys are enum, format, pattern, minimum, maximum, minLength,
+// maxLength, minItems, maxItems and description.
+func (d *Document) SchemaFor(t reflect.Type) *Schema {
+	if name, ok := d.names[t]; ok {
+		return Ref(name)
+	}
+	return d.schemaFor(t)
+}
+
+// schemaFor generates the schema of t itself, even if it is a component.
+func (d *Document) schemaFor(t reflect.Type) *Schema {
+	if t == rawMessageType {
+		// Arbitrary JSON, including null.
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return d.SchemaFor(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: d.SchemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		return d.structSchema(t)
+	default:
+		return &Schema{}
+	}
+}
+
+// structSchema closes the object to unknown properties, matching how the
+// API decodes request bodies.
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	closed := false
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: &closed}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Type == xmlNameType {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := d.SchemaFor(field.Type)
+		if tag := field.Tag.Get("validate"); tag != "" {
+			prop = applyValidateTag(prop, tag)
+		}
+		if tag := field.Tag.Get("openapi"); tag != "" {
+			prop = applyTag(prop, tag)
+		}
+		s.Properties[name] = prop
+
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// applyTag copies s (which may be a shared component reference) and adds the
+// keywords from an openapi struct tag.
+func applyTag(s *Schema, tag string) *Schema {
+	out := *s
+	for _, kv := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(kv, "=")
+		switch key {
+		case "enum":
+			for _, v := range strings.Split(value, "|") {
+				out.Enum = append(out.Enum, v)
+			}
+		case "format":
+			out.Format = value
+		case "pattern":
+			out.Pattern = value
+		case "description":
+			out.Description = value
+		case "minimum":
+			out.Minimum = parseFloat(value)
+		case "maximum":
+			out.Maximum = parseFloat(value)
+		case "minLength":
+			out.MinLength = parseInt(value)
+		case "maxLength":
+			out.MaxLength = parseInt(value)
+		case "minItems":
+			out.MinItems = parseInt(value)
+		case "maxItems":
+			out.MaxItems = parseInt(value)
+		}
+	}
+	return &out
+}
+
+// applyValidateTag copies s and adds the keywords equivalent to the rules in a
+// validate tag. Rules after dive apply to the array items. Cross-field and
+// custom rules have no JSON Schema equivalent and are skipped.
+func applyValidateTag(s *Schema, tag string) *Schema {
+	out := *s
+	target := &out
+	for _, rule := range validation.ParseTag(tag) {
+		switch rule.Name {
+		case "dive":
+			if target.Items == nil {
+				return &out
+			}
+			items := *target.Items
+			target.Items = &items
+			target = &items
+		case "required":
+			if target.Type == "string" {
+				target.MinLength = parseInt("1")
+			}
+		case "min":
+			switch target.Type {
+			case "string":
+				target.MinLength = parseInt(rule.Param)
+			case "array":
+				target.MinItems = parseInt(rule.Param)
+			default:
+				target.Minimum = parseFloat(rule.Param)
+			}
+		case "max":
+			switch target.Type {
+			case "string":
+				target.MaxLength = parseInt(rule.Param)
+			case "array":
+				target.MaxItems = parseInt(rule.Param)
+			default:
+				target.Maximum = parseFloat(rule.Param)
+			}
+		case "len":
+			switch target.Type {
+			case "string":
+				target.MinLength, target.MaxLength = parseInt(rule.Param), parseInt(rule.Param)
+			case "array":
+				target.MinItems, target.MaxItems = parseInt(rule.Param), parseInt(rule.Param)
+			}
+		case "oneof":
+			for _, option := range strings.Fields(rule.Param) {
+				if target.Type == "integer" {
+					if n, err := strconv.Atoi(option); err == nil {
+						target.Enum = append(target.Enum, n)
+						continue
+					}
+				}
+				target.Enum = append(target.Enum, option)
+			}
+		case "regex":
+			target.Pattern = rule.Param
+		case "unique":
+			target.UniqueItems = true
+		}
+	}
+	return &out
+}
+
+func parseFloat(s string) *float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+func parseInt(s string) *int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
@@ -0,0 +1,257 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Violation is a single schema violation. Pointer is an RFC 6901 JSON Pointer
+// to the offending value.
+type Violation struct {
+	Pointer string
+	Message string
+}
+
+var (
+	patternsMu sync.Mutex
+	patterns   = map[string]*regexp.Regexp{}
+)
+
+// Validate checks a decoded JSON value against s and returns every violation
+// found. Numbers are expected as json.Number (see json.Decoder.UseNumber) or
+// any Go numeric type.
+func (d *Document) Validate(s *Schema, v any, pointer string) []Violation {
+	s = d.Resolve(s)
+	if s == nil {
+		return nil
+	}
+
+	var violations []Violation
+	fail := func(format string, args ...any) {
+		violations = append(violations, Violation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Type != "" && !hasType(v, s.Type) {
+		fail("must be of type %s", s.Type)
+		return violations
+	}
+
+	if len(s.Enum) > 0 && !inEnum(v, s.Enum) {
+		fail("must be %s", joinEnum(s.Enum))
+	}
+
+	switch v := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			if *s.MinLength == 1 {
+				fail("must not be blank")
+			} else {
+				fail("must be at least %d characters", *s.MinLength)
+			}
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("must be at most %d characters", *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			if re, err := compilePattern(s.Pattern); err == nil && !re.MatchString(v) {
+				fail("has an invalid format")
+			}
+		}
+
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("must have at most %d items", *s.MaxItems)
+		}
+		for i, item := range v {
+			if s.UniqueItems {
+				for j := 0; j < i; j++ {
+					if reflect.DeepEqual(v[j], item) {
+						violations = append(violations, Violation{
+							Pointer: pointer + "/" + strconv.Itoa(i),
+							Message: fmt.Sprintf("duplicates item %d", j),
+						})
+						break
+					}
+				}
+			}
+			violations = append(violations, d.Validate(s.Items, item, pointer+"/"+strconv.Itoa(i))...)
+		}
+
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				violations = append(violations, Violation{
+					Pointer: pointer + "/" + escapePointer(name),
+					Message: "is required",
+				})
+			}
+		}
+		for _, name := range sortedKeys(v) {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					violations = append(violations, Violation{
+						Pointer: pointer + "/" + escapePointer(name),
+						Message: "is not a recognised field",
+					})
+				}
+				continue
+			}
+			violations = append(violations, d.Validate(prop, v[name], pointer+"/"+escapePointer(name))...)
+		}
+
+	default:
+		if f, ok := toFloat(v); ok {
+			if s.Minimum != nil && f < *s.Minimum {
+				fail("must be at least %s", formatNumber(*s.Minimum))
+			}
+			if s.Maximum != nil && f > *s.Maximum {
+				fail("must be at most %s", formatNumber(*s.Maximum))
+			}
+		}
+	}
+
+	return violations
+}
+
+// ParseParam converts a raw path or query parameter to the JSON type its
+// schema expects, so it can be passed to Validate.
+func (d *Document) ParseParam(s *Schema, raw string) (any, error) {
+	s = d.Resolve(s)
+	if s == nil {
+		return raw, nil
+	}
+	switch s.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return json.Number(raw), nil
+	case "number":
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return json.Number(raw), nil
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		return b, nil
+	default:
+		return raw, nil
+	}
+}
+
+func hasType(v any, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	case "number":
+		_, ok := toFloat(v)
+		return ok
+	case "integer":
+		if n, ok := v.(json.Number); ok {
+			_, err := n.Int64()
+			return err == nil
+		}
+		f, ok := toFloat(v)
+		return ok && f == float64(int64(f))
+	default:
+		return true
+	}
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	}
+	return 0, false
+}
+
+func inEnum(v any, enum []any) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinEnum(enum []any) string {
+	parts := make([]string, len(enum))
+	for i, e := range enum {
+		parts[i] = fmt.Sprint(e)
+	}
+	if len(parts) == 2 {
+		return parts[0] + " or " + parts[1]
+	}
+	return "one of " + strings.Join(parts, ", ")
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	patternsMu.Lock()
+	defer patternsMu.Unlock()
+	if re, ok := patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns[pattern] = re
+	return re, nil
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
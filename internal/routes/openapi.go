@@ -0,0 +1,90 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/handlers"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/openapi"
+)
+
+const (
+	specPath = "/openapi.json"
+	docsPath = "/docs"
+)
+
+var pathParamPattern = regexp.MustCompile(`\{([^}/]+)\}`)
+
+// BuildSpec generates the OpenAPI document for the API routes registered on
+// router, taking each operation from handlers.Operations. It fails if a
+// registered route is undocumented, if a documented operation is not
+// registered, or if an operation does not declare its path parameters. The
+// documentation routes themselves are not part of the document.
+func BuildSpec(router chi.Routes) (*openapi.Document, error) {
+	doc := openapi.New("Go API Tech Challenge", "1.0.0")
+	operations := handlers.Operations(doc)
+
+	var problems []error
+	err := chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if route == specPath || strings.HasPrefix(route, docsPath) {
+			return nil
+		}
+
+		key := method + " " + route
+		op, ok := operations[key]
+		if !ok {
+			problems = append(problems, fmt.Errorf("route %s is not documented", key))
+			return nil
+		}
+		delete(operations, key)
+
+		if err := checkPathParams(route, op); err != nil {
+			problems = append(problems, fmt.Errorf("route %s: %w", key, err))
+		}
+		return doc.AddOperation(method, route, op)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[in routes.BuildSpec] failed to walk routes: %w", err)
+	}
+
+	stale := make([]string, 0, len(operations))
+	for key := range operations {
+		stale = append(stale, key)
+	}
+	sort.Strings(stale)
+	for _, key := range stale {
+		problems = append(problems, fmt.Errorf("operation %s is documented but not registered", key))
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("[in routes.BuildSpec] routes and spec differ: %w", errors.Join(problems...))
+	}
+	return doc, nil
+}
+
+// checkPathParams verifies that op declares exactly the path parameters in
+// the route pattern.
+func checkPathParams(route string, op *openapi.Operation) error {
+	want := map[string]bool{}
+	for _, m := range pathParamPattern.FindAllStringSubmatch(route, -1) {
+		want[m[1]] = true
+	}
+	for _, param := range op.Parameters {
+		if param.In != "path" {
+			continue
+		}
+		if !want[param.Name] {
+			return fmt.Errorf("path parameter %q is not in the pattern", param.Name)
+		}
+		delete(want, param.Name)
+	}
+	for name := range want {
+		return fmt.Errorf("path parameter %q is not documented", name)
+	}
+	return nil
+}
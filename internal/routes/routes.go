@@ -1,30 +1,161 @@
 package routes
 
 import (
+	"fmt"
+	"net/http"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/events"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/graph"
 	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/handlers"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/openapi"
 	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/httplog/v2"
+	"github.com/swaggest/swgui/v5emb"
 )
 
-// RegisterRoutes sets up all the API routes
-func RegisterRoutes(router *chi.Mux, logger *httplog.Logger, svsCourse *services.CourseService, svsPerson *services.PersonService) {
+// RegisterRoutes sets up all the API routes. It returns an error if the
+// OpenAPI specification cannot be built from them, since requests could not
+// be validated against it.
+func RegisterRoutes(router *chi.Mux, logger *httplog.Logger, svsCourse *services.CourseService, svsPerson *services.PersonService, svsTerm *services.TermService, svsSection *services.SectionService, svsRoom *services.RoomService, svsDepartment *services.DepartmentService, svsGrade *services.GradeService, svsCalendar *services.CalendarService, svsAudit *services.AuditService, svsWebhook *services.WebhookService, svsBatch *services.BatchService, hub *events.Hub) error {
+	// Validate requests against the spec built from these routes below
+	var doc *openapi.Document
+	router.Use(handlers.ValidateRequest(logger, func() *openapi.Document { return doc }))
+	router.Use(handlers.AuditContext)
+
 	// Course-related routes
 	router.Route("/api/course", func(router chi.Router) {
+		router.Use(handlers.Negotiate(logger))
 		router.Get("/", handlers.HandleListCourses(logger, svsCourse))
 		router.Post("/", handlers.HandleCreateCourse(logger, svsCourse))
 		router.Get("/{id}", handlers.HandleGetCourseByID(logger, svsCourse))
 		router.Put("/{id}", handlers.HandleUpdateCourse(logger, svsCourse))
 		router.Delete("/{id}", handlers.HandleDeleteCourse(logger, svsCourse))
+		router.Post("/{id}/restore", handlers.HandleRestoreCourse(logger, svsCourse))
+		router.Get("/{id}/waitlist", handlers.HandleListWaitlist(logger, svsCourse))
+		router.Post("/{id}/waitlist", handlers.HandleJoinWaitlist(logger, svsCourse))
+		router.Delete("/{id}/waitlist/{firstName}", handlers.HandleLeaveWaitlist(logger, svsCourse))
+		router.Get("/{id}/prerequisites", handlers.HandleListPrerequisites(logger, svsCourse))
+		router.Post("/{id}/prerequisites", handlers.HandleAddPrerequisite(logger, svsCourse))
+		router.Delete("/{id}/prerequisites/{prerequisiteID}", handlers.HandleRemovePrerequisite(logger, svsCourse))
+		router.Get("/{id}/schedule.ics", handlers.HandleGetCourseCalendar(logger, svsCalendar))
+		router.Get("/{id}/schedule/subscription", handlers.HandleSubscribeCourseCalendar(logger, svsCalendar))
 	})
 
 	// Person-related routes
 	router.Route("/api/person", func(router chi.Router) {
+		router.Use(handlers.Negotiate(logger))
 		router.Get("/", handlers.HandleListPersons(logger, svsPerson))
 		router.Post("/", handlers.HandleCreatePerson(logger, svsPerson))
 		router.Get("/{firstName}", handlers.HandleGetPersonByName(logger, svsPerson))
 		router.Put("/{firstName}", handlers.HandleUpdatePerson(logger, svsPerson))
 		router.Delete("/{firstName}", handlers.HandleDeletePerson(logger, svsPerson))
+		router.Post("/{id}/restore", handlers.HandleRestorePerson(logger, svsPerson))
+		router.Get("/{firstName}/waitlist", handlers.HandleListWaitlistPositions(logger, svsPerson))
+		router.Post("/{firstName}/eligibility", handlers.HandleCheckEligibility(logger, svsPerson))
+		router.Get("/{id}/transcript", handlers.HandleGetTranscript(logger, svsGrade))
+		router.Get("/{id}/schedule", handlers.HandleGetSchedule(logger, svsSection))
+		router.Get("/{id}/schedule.ics", handlers.HandleGetPersonCalendar(logger, svsCalendar))
+		router.With(handlers.RequireAdmin(logger)).Get("/{id}/schedule/subscription", handlers.HandleSubscribePersonCalendar(logger, svsCalendar))
+	})
+
+	// Term-related routes
+	router.Route("/api/term", func(router chi.Router) {
+		router.Use(handlers.Negotiate(logger))
+		router.Get("/", handlers.HandleListTerms(logger, svsTerm))
+		router.Post("/", handlers.HandleCreateTerm(logger, svsTerm))
+		router.Get("/{id}", handlers.HandleGetTerm(logger, svsTerm))
+		router.Put("/{id}", handlers.HandleUpdateTerm(logger, svsTerm))
+		router.Delete("/{id}", handlers.HandleDeleteTerm(logger, svsTerm))
+	})
+
+	// Section-related routes
+	router.Route("/api/section", func(router chi.Router) {
+		router.Use(handlers.Negotiate(logger))
+		router.Get("/", handlers.HandleListSections(logger, svsSection))
+		router.Post("/", handlers.HandleCreateSection(logger, svsSection))
+		router.Get("/{id}", handlers.HandleGetSection(logger, svsSection))
+		router.Put("/{id}", handlers.HandleUpdateSection(logger, svsSection))
+		router.Delete("/{id}", handlers.HandleDeleteSection(logger, svsSection))
+		router.Post("/{id}/enrollment", handlers.HandleEnrollSection(logger, svsSection))
+		router.Delete("/{id}/enrollment/{firstName}", handlers.HandleWithdrawSection(logger, svsSection))
+		router.Get("/{id}/grades", handlers.HandleListGrades(logger, svsGrade))
+		// X-Actor is not authenticated, so grading also takes the admin token
+		router.With(handlers.RequireAdmin(logger)).Post("/{id}/grades", handlers.HandleSubmitGrades(logger, svsGrade))
+		router.With(handlers.RequireAdmin(logger)).Put("/{id}/grades/{firstName}", handlers.HandleAmendGrade(logger, svsGrade))
+	})
+
+	// Room-related routes
+	router.Route("/api/room", func(router chi.Router) {
+		router.Use(handlers.Negotiate(logger))
+		router.Get("/", handlers.HandleListRooms(logger, svsRoom))
+		router.Post("/", handlers.HandleCreateRoom(logger, svsRoom))
+		router.Get("/{id}", handlers.HandleGetRoom(logger, svsRoom))
+		router.Put("/{id}", handlers.HandleUpdateRoom(logger, svsRoom))
+		router.Delete("/{id}", handlers.HandleDeleteRoom(logger, svsRoom))
+	})
+
+	// Department-related routes
+	router.Route("/api/department", func(router chi.Router) {
+		router.Use(handlers.Negotiate(logger))
+		router.Get("/", handlers.HandleListDepartments(logger, svsDepartment))
+		router.Post("/", handlers.HandleCreateDepartment(logger, svsDepartment))
+		router.Get("/{id}", handlers.HandleGetDepartment(logger, svsDepartment))
+		router.Put("/{id}", handlers.HandleUpdateDepartment(logger, svsDepartment))
+		router.Delete("/{id}", handlers.HandleDeleteDepartment(logger, svsDepartment))
 	})
+
+	// Audit routes, for admins only as events hold deleted records
+	router.Route("/api/audit", func(router chi.Router) {
+		router.Use(handlers.Negotiate(logger))
+		router.Use(handlers.RequireAdmin(logger))
+		router.Get("/", handlers.HandleListAuditEvents(logger, svsAudit))
+	})
+
+	// Webhook subscription routes, for admins only
+	router.Route("/api/webhook", func(router chi.Router) {
+		router.Use(handlers.Negotiate(logger))
+		router.Use(handlers.RequireAdmin(logger))
+		router.Get("/", handlers.HandleListWebhooks(logger, svsWebhook))
+		router.Post("/", handlers.HandleCreateWebhook(logger, svsWebhook))
+		router.Get("/{id}", handlers.HandleGetWebhook(logger, svsWebhook))
+		router.Put("/{id}", handlers.HandleUpdateWebhook(logger, svsWebhook))
+		router.Delete("/{id}", handlers.HandleDeleteWebhook(logger, svsWebhook))
+		router.Get("/{id}/deliveries", handlers.HandleListWebhookDeliveries(logger, svsWebhook))
+		router.Post("/{id}/deliveries/{deliveryID}/replay", handlers.HandleReplayWebhookDelivery(logger, svsWebhook))
+		router.Post("/{id}/replay", handlers.HandleReplayWebhookEvents(logger, svsWebhook))
+	})
+
+	// Batch route; targets and bodies of operations cannot be sent as XML
+	router.With(handlers.Negotiate(logger)).Post("/api/batch", handlers.HandleBatch(logger, svsBatch))
+
+	// Change feed, for admins only; Server-Sent Events are not content
+	// negotiated
+	router.With(handlers.RequireAdmin(logger)).Get("/api/events", handlers.HandleStreamEvents(logger, hub))
+
+	// GraphQL over the same services; results are always JSON
+	schema, err := graph.NewSchema(logger, svsCourse, svsPerson)
+	if err != nil {
+		return fmt.Errorf("[in routes.RegisterRoutes] failed to build GraphQL schema: %w", err)
+	}
+	router.Get("/graphql", handlers.HandleGraphQL(logger, schema))
+	router.Post("/graphql", handlers.HandleGraphQL(logger, schema))
+
+	// Export routes
+	router.Route("/api/export", func(router chi.Router) {
+		router.Get("/persons", handlers.HandleExportPersons(logger, svsPerson))
+		router.Get("/courses", handlers.HandleExportCourses(logger, svsCourse))
+		router.Get("/enrollments", handlers.HandleExportEnrollments(logger, svsCourse, svsPerson))
+	})
+
+	// API documentation, generated from the routes registered above
+	doc, err = BuildSpec(router)
+	if err != nil {
+		return fmt.Errorf("[in routes.RegisterRoutes] OpenAPI specification is out of date: %w", err)
+	}
+	router.Get(specPath, handlers.HandleOpenAPI(logger, doc))
+	router.Get(docsPath, http.RedirectHandler(docsPath+"/", http.StatusMovedPermanently).ServeHTTP)
+	router.Get(docsPath+"/*", v5emb.New(doc.Info.Title, specPath, docsPath+"/").ServeHTTP)
+	return nil
 }
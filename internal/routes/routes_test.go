@@ -0,0 +1,140 @@
+package routes
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/rules"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/services"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// newTestRouter registers every route over services without a database, so
+// only requests rejected before reaching a service can be made.
+func newTestRouter(t *testing.T) *chi.Mux {
+	t.Helper()
+	router := chi.NewRouter()
+	err := RegisterRoutes(router, httplog.NewLogger("test"), services.NewCourseService(nil, rules.Set{}), services.NewPersonService(nil, rules.Set{}), services.NewTermService(nil), services.NewSectionService(nil, rules.Set{}), services.NewRoomService(nil), services.NewDepartmentService(nil), services.NewGradeService(nil, nil), services.NewCalendarService(nil, ""), services.NewAuditService(nil), services.NewWebhookService(nil), services.NewBatchService(nil, rules.Set{}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return router
+}
+
+func TestSpecMatchesRoutes(t *testing.T) {
+	router := newTestRouter(t)
+
+	doc, err := BuildSpec(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(doc.Routes()) == 0 {
+		t.Fatal("spec documents no routes")
+	}
+	for _, route := range doc.Routes() {
+		t.Log(route)
+	}
+}
+
+func TestValidateRequestBodyMediaTypes(t *testing.T) {
+	router := newTestRouter(t)
+
+	term := map[string]any{"name": "Fall 2025", "start_date": "2025-09-01", "end_date": "19/12/2025"}
+	packed, err := msgpack.Marshal(term)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xmlBody := []byte(`<term><name>Fall 2025</name><start_date>2025-09-01</start_date><end_date>19/12/2025</end_date></term>`)
+
+	tests := []struct {
+		contentType string
+		body        []byte
+	}{
+		{"application/json", []byte(`{"name": "Fall 2025", "start_date": "2025-09-01", "end_date": "19/12/2025"}`)},
+		{"application/xml", xmlBody},
+		{"text/xml; charset=utf-8", xmlBody},
+		{"application/msgpack", packed},
+		{"application/x-msgpack", packed},
+		{"application/vnd.msgpack", packed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/term/", bytes.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "/body/end_date") {
+				t.Errorf("status = %d, want %d with /body/end_date; body %s", w.Code, http.StatusBadRequest, w.Body)
+			}
+		})
+	}
+}
+
+func TestValidateRequestBodyTooLarge(t *testing.T) {
+	router := newTestRouter(t)
+
+	body := `{"name": "` + strings.Repeat("a", 1<<20) + `", "start_date": "2025-09-01", "end_date": "2025-12-19"}`
+	r := httptest.NewRequest(http.MethodPost, "/api/term/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d; body %s", w.Code, http.StatusRequestEntityTooLarge, w.Body)
+	}
+}
+
+func TestGradingRequiresAdmin(t *testing.T) {
+	router := newTestRouter(t)
+
+	tests := []struct {
+		method, path, body string
+	}{
+		{http.MethodPost, "/api/section/1/grades", `{"grades": [{"first_name": "Ada", "grade": "A"}]}`},
+		{http.MethodPut, "/api/section/1/grades/Ada", `{"grade": "A"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("X-Actor", "Steve")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			if w.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d; body %s", w.Code, http.StatusForbidden, w.Body)
+			}
+		})
+	}
+}
+
+func TestPersonCalendarSubscriptionRequiresAdmin(t *testing.T) {
+	router := newTestRouter(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/person/1/schedule/subscription", nil)
+	r.Header.Set("X-Actor", "Steve")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d; body %s", w.Code, http.StatusForbidden, w.Body)
+	}
+}
+
+func TestAuditAndEventsRequireAdmin(t *testing.T) {
+	router := newTestRouter(t)
+
+	for _, path := range []string{"/api/audit/", "/api/events"} {
+		t.Run(path, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			if w.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d; body %s", w.Code, http.StatusForbidden, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Package rules evaluates enrollment eligibility rules, such as "students
+// under 16 need approval" or "professors teach at most 3 courses", declared
+// in a JSON file:
+//
+//	{
+//	  "rules": [
+//	    {
+//	      "name": "course-load",
+//	      "message": "students take at most 6 courses per term",
+//	      "person_type": "student",
+//	      "roles": ["student", "auditor"],
+//	      "max_courses": 6
+//	    }
+//	  ]
+//	}
+//
+// A rule applies to persons of person_type, if set, whose age is between
+// min_age and max_age, inclusive, where set. It counts the enrollments of a
+// term with one of roles and in a course of one of departments, by code; an
+// empty list matches any. A change that adds such an enrollment breaks the
+// rule if the term then has more than max_courses of them, or at all if
+// max_courses is not set.
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+)
+
+// Set is the rules every enrollment change is checked against. The zero
+// value has none.
+type Set struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule is one eligibility rule. Message describes it to whoever breaks it.
+type Rule struct {
+	Name        string   `json:"name" validate:"required,max=64"`
+	Message     string   `json:"message" validate:"required,max=500"`
+	PersonType  string   `json:"person_type" validate:"omitempty,oneof=student professor"`
+	MinAge      *int     `json:"min_age" validate:"omitempty,min=0"`
+	MaxAge      *int     `json:"max_age" validate:"omitempty,min=0"`
+	Roles       []string `json:"roles" validate:"unique,dive,oneof=instructor teaching_assistant student auditor"`
+	Departments []string `json:"departments" validate:"unique,dive,required"`
+	MaxCourses  *int     `json:"max_courses" validate:"omitempty,min=0"`
+}
+
+// ValidateStruct reports rule names used twice.
+func (s Set) ValidateStruct() []validation.Problem {
+	var problems []validation.Problem
+	for i, rule := range s.Rules {
+		if first := slices.IndexFunc(s.Rules[:i], func(r Rule) bool { return r.Name == rule.Name }); first >= 0 {
+			problems = append(problems, validation.Problem{
+				Name:        fmt.Sprintf("rules[%d].name", i),
+				Description: fmt.Sprintf("duplicates rules[%d].name", first),
+			})
+		}
+	}
+	return problems
+}
+
+// ValidateStruct reports an age range that is empty.
+func (r Rule) ValidateStruct() []validation.Problem {
+	if r.MinAge != nil && r.MaxAge != nil && *r.MinAge > *r.MaxAge {
+		return []validation.Problem{{Name: "max_age", Description: "must be greater than or equal to min_age"}}
+	}
+	return nil
+}
+
+// Load reads the rules in the file at path. An empty path means no rules.
+func Load(path string) (Set, error) {
+	if path == "" {
+		return Set{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Set{}, fmt.Errorf("[in rules.Load] failed to read rules: %w", err)
+	}
+
+	var set Set
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(&set); err != nil {
+		return Set{}, fmt.Errorf("[in rules.Load] failed to parse rules in %s: %w", path, err)
+	}
+	if problems := validation.Validate(set); len(problems) > 0 {
+		descriptions := make([]string, 0, len(problems))
+		for _, p := range problems {
+			descriptions = append(descriptions, p.Name+" "+p.Description)
+		}
+		return Set{}, fmt.Errorf("[in rules.Load] invalid rules in %s: %s", path, strings.Join(descriptions, "; "))
+	}
+	return set, nil
+}
+
+// Person is who an enrollment change is for.
+type Person struct {
+	Type string
+	Age  int
+}
+
+// Enrollment is a section a person holds once a change is made, in the term
+// of the change. New is set if the change adds it or gives it a new role, and
+// Field then names the input asking for it.
+type Enrollment struct {
+	Department string
+	Role       string
+	New        bool
+	Field      string
+}
+
+// Check returns a problem for each rule the change to enrollments breaks,
+// named by the field of the first new enrollment the rule counts.
+func (s Set) Check(person Person, enrollments []Enrollment) []validation.Problem {
+	var problems []validation.Problem
+	for _, rule := range s.Rules {
+		if !rule.appliesTo(person) {
+			continue
+		}
+		count, field, added := 0, "", false
+		for _, enrollment := range enrollments {
+			if !rule.counts(enrollment) {
+				continue
+			}
+			count++
+			if enrollment.New && !added {
+				field, added = enrollment.Field, true
+			}
+		}
+		if added && (rule.MaxCourses == nil || count > *rule.MaxCourses) {
+			problems = append(problems, validation.Problem{
+				Name:        field,
+				Description: fmt.Sprintf("%s (rule %s)", rule.Message, rule.Name),
+			})
+		}
+	}
+	return problems
+}
+
+func (r Rule) appliesTo(person Person) bool {
+	return (r.PersonType == "" || r.PersonType == person.Type) &&
+		(r.MinAge == nil || person.Age >= *r.MinAge) &&
+		(r.MaxAge == nil || person.Age <= *r.MaxAge)
+}
+
+func (r Rule) counts(enrollment Enrollment) bool {
+	return (len(r.Roles) == 0 || slices.Contains(r.Roles, enrollment.Role)) &&
+		(len(r.Departments) == 0 || slices.Contains(r.Departments, enrollment.Department))
+}
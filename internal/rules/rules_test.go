@@ -0,0 +1,143 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+)
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestCheck(t *testing.T) {
+	student := Person{Type: "student", Age: 20}
+	held := Enrollment{Department: "CS", Role: "student"}
+	added := func(field string) Enrollment {
+		return Enrollment{Department: "CS", Role: "student", New: true, Field: field}
+	}
+	problem := func(field string) []validation.Problem {
+		return []validation.Problem{{Name: field, Description: "not allowed (rule r)"}}
+	}
+
+	tests := []struct {
+		name        string
+		rule        Rule
+		person      Person
+		enrollments []Enrollment
+		want        []validation.Problem
+	}{
+		{"no max_courses breaks on any new enrollment", Rule{}, student, []Enrollment{added("courses[0]")}, problem("courses[0]")},
+		{"no max_courses ignores held enrollments", Rule{}, student, []Enrollment{held, held}, nil},
+		{"max_courses 0 breaks on any new enrollment", Rule{MaxCourses: intPtr(0)}, student, []Enrollment{added("courses[0]")}, problem("courses[0]")},
+		{"max_courses 0 ignores held enrollments", Rule{MaxCourses: intPtr(0)}, student, []Enrollment{held}, nil},
+		{"at max_courses", Rule{MaxCourses: intPtr(2)}, student, []Enrollment{held, added("course_id")}, nil},
+		{"over max_courses", Rule{MaxCourses: intPtr(2)}, student, []Enrollment{held, held, added("course_id")}, problem("course_id")},
+		{"over max_courses without new enrollments", Rule{MaxCourses: intPtr(2)}, student, []Enrollment{held, held, held}, nil},
+		{"person type matches", Rule{PersonType: "student"}, student, []Enrollment{added("f")}, problem("f")},
+		{"person type differs", Rule{PersonType: "professor"}, student, []Enrollment{added("f")}, nil},
+		{"at min_age", Rule{MinAge: intPtr(20)}, student, []Enrollment{added("f")}, problem("f")},
+		{"under min_age", Rule{MinAge: intPtr(21)}, student, []Enrollment{added("f")}, nil},
+		{"at max_age", Rule{MaxAge: intPtr(20)}, student, []Enrollment{added("f")}, problem("f")},
+		{"over max_age", Rule{MaxAge: intPtr(19)}, student, []Enrollment{added("f")}, nil},
+		{"within age bounds", Rule{MinAge: intPtr(18), MaxAge: intPtr(22)}, student, []Enrollment{added("f")}, problem("f")},
+		{"role counted", Rule{Roles: []string{"auditor", "student"}}, student, []Enrollment{added("f")}, problem("f")},
+		{"role not counted", Rule{Roles: []string{"auditor"}}, student, []Enrollment{added("f")}, nil},
+		{"department counted", Rule{Departments: []string{"CS"}}, student, []Enrollment{added("f")}, problem("f")},
+		{"department not counted", Rule{Departments: []string{"MATH"}}, student, []Enrollment{added("f")}, nil},
+		{"only counted enrollments reach max_courses", Rule{Departments: []string{"CS"}, MaxCourses: intPtr(1)}, student,
+			[]Enrollment{{Department: "MATH", Role: "student"}, added("f")}, nil},
+		{"named by the first new counted enrollment", Rule{Departments: []string{"CS"}, MaxCourses: intPtr(1)}, student,
+			[]Enrollment{{Department: "MATH", Role: "student", New: true, Field: "courses[0]"}, held, added("courses[1]"), added("courses[2]")},
+			problem("courses[1]")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Name, tt.rule.Message = "r", "not allowed"
+			got := Set{Rules: []Rule{tt.rule}}.Check(tt.person, tt.enrollments)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckEveryRule(t *testing.T) {
+	set := Set{Rules: []Rule{
+		{Name: "minors", Message: "students under 16 need approval", MaxAge: intPtr(15)},
+		{Name: "no-auditing", Message: "students may not audit", Roles: []string{"auditor"}},
+		{Name: "load", Message: "at most 1 course", MaxCourses: intPtr(1)},
+	}}
+	got := set.Check(Person{Type: "student", Age: 15}, []Enrollment{
+		{Role: "student"},
+		{Role: "auditor", New: true, Field: "role"},
+	})
+	want := []validation.Problem{
+		{Name: "role", Description: "students under 16 need approval (rule minors)"},
+		{Name: "role", Description: "students may not audit (rule no-auditing)"},
+		{Name: "role", Description: "at most 1 course (rule load)"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Set
+		wantErr string
+	}{
+		{"max_courses unset and 0", `{"rules": [
+			{"name": "a", "message": "m"},
+			{"name": "b", "message": "m", "person_type": "student", "min_age": 0, "max_age": 15, "roles": ["auditor"], "departments": ["CS"], "max_courses": 0}
+		]}`, Set{Rules: []Rule{
+			{Name: "a", Message: "m"},
+			{Name: "b", Message: "m", PersonType: "student", MinAge: intPtr(0), MaxAge: intPtr(15), Roles: []string{"auditor"}, Departments: []string{"CS"}, MaxCourses: intPtr(0)},
+		}}, ""},
+		{"no rules", `{}`, Set{}, ""},
+		{"unknown field", `{"rules": [{"name": "a", "message": "m", "max_course": 1}]}`, Set{}, `unknown field "max_course"`},
+		{"malformed", `{"rules": [`, Set{}, "failed to parse rules"},
+		{"duplicate names", `{"rules": [{"name": "a", "message": "m"}, {"name": "a", "message": "m"}]}`, Set{}, "rules[1].name duplicates rules[0].name"},
+		{"empty age range", `{"rules": [{"name": "a", "message": "m", "min_age": 16, "max_age": 15}]}`, Set{}, "rules[0].max_age must be greater than or equal to min_age"},
+		{"negative max_courses", `{"rules": [{"name": "a", "message": "m", "max_courses": -1}]}`, Set{}, "rules[0].max_courses must be at least 0"},
+		{"unknown role", `{"rules": [{"name": "a", "message": "m", "roles": ["dean"]}]}`, Set{}, "rules[0].roles[0] must be one of"},
+		{"missing message", `{"rules": [{"name": "a"}]}`, Set{}, "rules[0].message must not be blank"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rules.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadWithoutPath(t *testing.T) {
+	set, err := Load("")
+	if err != nil || len(set.Rules) != 0 {
+		t.Errorf("Load(\"\") = %+v, %v, want no rules", set, err)
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Load(missing file) did not fail")
+	}
+}
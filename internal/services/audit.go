@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+)
+
+// Audit actions recorded in audit_event.action.
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+)
+
+// Audited entities recorded in audit_event.entity.
+const (
+	EntityCourse     = "course"
+	EntityPerson     = "person"
+	EntityTerm       = "term"
+	EntitySection    = "section"
+	EntityRoom       = "room"
+	EntityDepartment = "department"
+	// EntityPrerequisite events are keyed by the course that has the
+	// prerequisite.
+	EntityPrerequisite = "prerequisite"
+	// EntityGrade events are keyed by the section of the grade.
+	EntityGrade = "grade"
+)
+
+// DefaultAuditLimit caps ListEvents when the filter sets no limit.
+const DefaultAuditLimit = 100
+
+type auditInfoKey struct{}
+
+type auditInfo struct {
+	actor     string
+	requestID string
+}
+
+// WithAuditInfo attaches the caller and request ID to ctx so that mutations
+// made with it are attributed in the audit log.
+func WithAuditInfo(ctx context.Context, actor, requestID string) context.Context {
+	return context.WithValue(ctx, auditInfoKey{}, auditInfo{actor: actor, requestID: requestID})
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// recordAudit appends an audit event in the caller's transaction, so the
+// event is stored if and only if the change it describes is committed.
+// before and after are stored as JSON; pass nil for the side that does not
+// exist.
+func recordAudit(ctx context.Context, q querier, action, entity string, entityID int, before, after any) error {
+	info := callerInfo(ctx)
+
+	beforeJSON, err := snapshot(before)
+	if err != nil {
+		return fmt.Errorf("[in services.recordAudit] failed to encode before snapshot: %w", err)
+	}
+	afterJSON, err := snapshot(after)
+	if err != nil {
+		return fmt.Errorf("[in services.recordAudit] failed to encode after snapshot: %w", err)
+	}
+
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO audit_event (actor, request_id, action, entity, entity_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		info.actor, info.requestID, action, entity, entityID, beforeJSON, afterJSON)
+	if err != nil {
+		return fmt.Errorf("[in services.recordAudit] failed to record %s of %s %d: %w", action, entity, entityID, err)
+	}
+	return nil
+}
+
+// callerInfo returns the actor and request ID attached with WithAuditInfo.
+func callerInfo(ctx context.Context) auditInfo {
+	info, ok := ctx.Value(auditInfoKey{}).(auditInfo)
+	switch {
+	case !ok:
+		// Not called on behalf of a request, e.g. the purge job.
+		info.actor = "system"
+	case info.actor == "":
+		info.actor = "anonymous"
+	}
+	return info
+}
+
+func snapshot(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+type AuditService struct {
+	DB *sql.DB
+}
+
+func NewAuditService(db *sql.DB) *AuditService {
+	return &AuditService{
+		DB: db,
+	}
+}
+
+// AuditFilter narrows the events returned by ListEvents. Zero values are
+// ignored; From is inclusive and To exclusive.
+type AuditFilter struct {
+	Entity   string
+	EntityID int
+	Actor    string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+func (f AuditFilter) where() (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.Entity != "" {
+		add("entity = $%d", f.Entity)
+	}
+	if f.EntityID > 0 {
+		add("entity_id = $%d", f.EntityID)
+	}
+	if f.Actor != "" {
+		add("actor = $%d", f.Actor)
+	}
+	if !f.From.IsZero() {
+		add("occurred_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		add("occurred_at < $%d", f.To)
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// ListEvents returns audit events matching filter, newest first.
+func (a *AuditService) ListEvents(ctx context.Context, filter AuditFilter) ([]models.AuditEvent, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultAuditLimit
+	}
+
+	where, args := filter.where()
+	args = append(args, limit)
+	rows, err := a.DB.QueryContext(ctx, `
+		SELECT id, occurred_at, actor, request_id, action, entity, entity_id, before, after
+		FROM audit_event`+where+fmt.Sprintf(`
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT $%d`, len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.ListEvents] failed to get audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.AuditEvent
+	for rows.Next() {
+		var (
+			event         models.AuditEvent
+			before, after []byte
+		)
+		err := rows.Scan(&event.ID, &event.OccurredAt, &event.Actor, &event.RequestID, &event.Action, &event.Entity, &event.EntityID, &before, &after)
+		if err != nil {
+			return nil, fmt.Errorf("[in services.ListEvents] failed to scan audit event from row: %w", err)
+		}
+		event.Before, event.After = before, after
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.ListEvents] failed to scan audit events: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/rules"
+)
+
+// BatchService runs several writes to persons, courses and enrollments in a
+// single transaction. Enrollment changes are checked against Rules.
+type BatchService struct {
+	DB    *sql.DB
+	Rules rules.Set
+}
+
+func NewBatchService(db *sql.DB, eligibility rules.Set) *BatchService {
+	return &BatchService{
+		DB:    db,
+		Rules: eligibility,
+	}
+}
+
+// Batch is the transaction of a batch. Its methods behave like the
+// CourseService and PersonService methods of the same name, but nothing they
+// write is visible to others until the batch commits.
+type Batch struct {
+	tx    *sql.Tx
+	rules rules.Set
+}
+
+// RunBatch calls fn with a new batch and commits it if fn returns nil. Any
+// error rolls back every write made through the batch.
+func (s *BatchService) RunBatch(ctx context.Context, fn func(b *Batch) error) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("[in services.RunBatch] failed to begin transaction: %w", err)
+	}
+
+	if err = fn(&Batch{tx: tx, rules: s.Rules}); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("[in services.RunBatch] %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("[in services.RunBatch] failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (b *Batch) CreateCourse(ctx context.Context, course models.Course) (models.Course, error) {
+	return createCourse(ctx, b.tx, course)
+}
+
+func (b *Batch) UpdateCourse(ctx context.Context, courseID int, updatedCourse models.Course) (models.Course, error) {
+	return updateCourse(ctx, b.tx, courseID, updatedCourse)
+}
+
+func (b *Batch) DeleteCourse(ctx context.Context, id int) error {
+	return deleteCourse(ctx, b.tx, id)
+}
+
+func (b *Batch) CreatePerson(ctx context.Context, person models.Person) (models.Person, error) {
+	return createPerson(ctx, b.tx, b.rules, person)
+}
+
+func (b *Batch) UpdatePerson(ctx context.Context, firstName string, updatedPerson models.Person) (models.Person, error) {
+	return updatePerson(ctx, b.tx, b.rules, firstName, updatedPerson)
+}
+
+func (b *Batch) DeletePerson(ctx context.Context, firstName string) error {
+	return deletePerson(ctx, b.tx, b.rules, firstName)
+}
+
+func (b *Batch) AddEnrollment(ctx context.Context, firstName string, courseID int, role string) (models.Person, error) {
+	return addEnrollment(ctx, b.tx, b.rules, firstName, courseID, role)
+}
+
+func (b *Batch) RemoveEnrollment(ctx context.Context, firstName string, courseID int) (models.Person, error) {
+	return removeEnrollment(ctx, b.tx, b.rules, firstName, courseID)
+}
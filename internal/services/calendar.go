@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/lib/pq"
+)
+
+// Calendar feeds are read by calendar apps, which cannot send headers, so
+// their URLs carry a token instead. A token is an HMAC of the feed and id
+// under the configured secret: it never expires, and changing the secret
+// revokes every token issued so far. Without a secret there are no feeds.
+
+// Calendar feeds, which tokens are bound to.
+const (
+	CalendarPerson = "person"
+	CalendarCourse = "course"
+)
+
+type CalendarService struct {
+	DB     *sql.DB
+	Secret []byte
+}
+
+func NewCalendarService(db *sql.DB, secret string) *CalendarService {
+	return &CalendarService{
+		DB:     db,
+		Secret: []byte(secret),
+	}
+}
+
+// token returns the token of the feed of the person or course with id.
+func (c *CalendarService) token(feed string, id int) string {
+	mac := hmac.New(sha256.New, c.Secret)
+	fmt.Fprintf(mac, "%s:%d", feed, id)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// CheckToken returns an error wrapping ErrForbidden unless token is that of
+// the feed of the person or course with id.
+func (c *CalendarService) CheckToken(feed string, id int, token string) error {
+	if len(c.Secret) == 0 {
+		return fmt.Errorf("[in services.CheckToken] calendar feeds are disabled: %w", ErrForbidden)
+	}
+	if !hmac.Equal([]byte(token), []byte(c.token(feed, id))) {
+		return fmt.Errorf("[in services.CheckToken] invalid token for %s %d: %w", feed, id, ErrForbidden)
+	}
+	return nil
+}
+
+// PersonToken returns the token of the calendar feed of the person with
+// personID, which only admins may get as the routes require. It returns an
+// error wrapping sql.ErrNoRows if there is no such person.
+func (c *CalendarService) PersonToken(ctx context.Context, personID int) (string, error) {
+	if len(c.Secret) == 0 {
+		return "", fmt.Errorf("[in services.PersonToken] calendar feeds are disabled: %w", ErrForbidden)
+	}
+	var exists bool
+	err := c.DB.QueryRowContext(ctx, "SELECT true FROM person WHERE id = $1 AND deleted_at IS NULL", personID).Scan(&exists)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("[in services.PersonToken] person with id %d not found: %w", personID, err)
+		}
+		return "", fmt.Errorf("[in services.PersonToken] failed to get person with id %d: %w", personID, err)
+	}
+	return c.token(CalendarPerson, personID), nil
+}
+
+// CourseToken returns the token of the calendar feed of a course that is not
+// deleted, which anyone may get. It returns an error wrapping sql.ErrNoRows
+// if there is no such course.
+func (c *CalendarService) CourseToken(ctx context.Context, courseID int) (string, error) {
+	if len(c.Secret) == 0 {
+		return "", fmt.Errorf("[in services.CourseToken] calendar feeds are disabled: %w", ErrForbidden)
+	}
+	if _, err := courseName(ctx, c.DB, courseID); err != nil {
+		return "", fmt.Errorf("[in services.CourseToken] %w", err)
+	}
+	return c.token(CalendarCourse, courseID), nil
+}
+
+// PersonCalendar returns the meetings of the sections the person with
+// personID is enrolled in or the instructor of, in every term, with their
+// role in each. It returns an error wrapping sql.ErrNoRows if there is no
+// such person.
+func (c *CalendarService) PersonCalendar(ctx context.Context, personID int) (models.Calendar, error) {
+	var firstName, lastName string
+	err := c.DB.QueryRowContext(ctx, "SELECT first_name, last_name FROM person WHERE id = $1 AND deleted_at IS NULL", personID).Scan(&firstName, &lastName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Calendar{}, fmt.Errorf("[in services.PersonCalendar] person with id %d not found: %w", personID, err)
+		}
+		return models.Calendar{}, fmt.Errorf("[in services.PersonCalendar] failed to get person with id %d: %w", personID, err)
+	}
+
+	entries, err := queryCalendar(ctx, c.DB, "COALESCE(ps.role, '"+models.RoleInstructor+"')",
+		"LEFT JOIN person_section ps ON ps.section_id = s.id AND ps.person_id = $1",
+		"ps.person_id IS NOT NULL OR s.instructor_id = $1", personID)
+	if err != nil {
+		return models.Calendar{}, fmt.Errorf("[in services.PersonCalendar] %w", err)
+	}
+	return models.Calendar{Name: "Schedule of " + firstName + " " + lastName, Entries: entries}, nil
+}
+
+// CourseCalendar returns the meetings of the sections of a course that is
+// not deleted, in every term. It returns an error wrapping sql.ErrNoRows if
+// there is no such course.
+func (c *CalendarService) CourseCalendar(ctx context.Context, courseID int) (models.Calendar, error) {
+	name, err := courseName(ctx, c.DB, courseID)
+	if err != nil {
+		return models.Calendar{}, fmt.Errorf("[in services.CourseCalendar] %w", err)
+	}
+
+	entries, err := queryCalendar(ctx, c.DB, "''", "", "s.course_id = $1", courseID)
+	if err != nil {
+		return models.Calendar{}, fmt.Errorf("[in services.CourseCalendar] %w", err)
+	}
+	return models.Calendar{Name: "Schedule of " + name, Entries: entries}, nil
+}
+
+// courseName returns the name of a course that is not deleted, or an error
+// wrapping sql.ErrNoRows if there is no such course.
+func courseName(ctx context.Context, q querier, courseID int) (string, error) {
+	var name string
+	err := q.QueryRowContext(ctx, "SELECT name FROM course WHERE id = $1 AND deleted_at IS NULL", courseID).Scan(&name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("course with id %d not found: %w", courseID, err)
+		}
+		return "", fmt.Errorf("failed to get course with id %d: %w", courseID, err)
+	}
+	return name, nil
+}
+
+// queryCalendar returns the meetings of the sections s of courses that are
+// not deleted matching where, after joining join, in the order of their
+// terms, courses and sections. role selects the role of each entry.
+func queryCalendar(ctx context.Context, q querier, role, join, where string, args ...any) ([]models.CalendarEntry, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT s.id, s.number, c.id, c.name, t.start_date, t.end_date,
+		       m.id, m.days, to_char(m.start_time, 'HH24:MI'), to_char(m.end_time, 'HH24:MI'), m.room_id, COALESCE(r.name, ''),
+		       `+role+`
+		FROM section s
+		JOIN course c ON c.id = s.course_id AND c.deleted_at IS NULL
+		JOIN term t ON t.id = s.term_id
+		JOIN section_meeting m ON m.section_id = s.id
+		LEFT JOIN room r ON r.id = m.room_id
+		`+join+`
+		WHERE `+where+`
+		ORDER BY t.start_date, s.term_id, s.course_id, s.number, m.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.CalendarEntry{}
+	for rows.Next() {
+		var (
+			entry models.CalendarEntry
+			days  pq.StringArray
+		)
+		err := rows.Scan(&entry.SectionID, &entry.SectionNumber, &entry.CourseID, &entry.CourseName, &entry.TermStart, &entry.TermEnd,
+			&entry.MeetingID, &days, &entry.Meeting.StartTime, &entry.Meeting.EndTime, &entry.Meeting.RoomID, &entry.RoomName, &entry.Role)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan calendar entry from row: %w", err)
+		}
+		entry.Meeting.Days = days
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan calendar entries: %w", err)
+	}
+	return entries, nil
+}
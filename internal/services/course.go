@@ -4,21 +4,36 @@ import (
 	"database/sql"
 	"context"
 	"fmt"
+	"strings"
+	"time"
+
 	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/rules"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+	"github.com/lib/pq"
 )
 
+// CourseService manages courses and their waitlists. Joining a waitlist is
+// checked against Rules, as the seat is given without asking again.
 type CourseService struct {
-	DB *sql.DB
+	DB    *sql.DB
+	Rules rules.Set
 }
 
-func NewCourseService(db *sql.DB) *CourseService {
+func NewCourseService(db *sql.DB, eligibility rules.Set) *CourseService {
 	return &CourseService{
-		DB: db,
+		DB:    db,
+		Rules: eligibility,
 	}
 }
 
-func (c *CourseService) ListCourses(ctx context.Context) ([]models.Course, error) {
-	rows, err := c.DB.Query("SELECT * FROM course ORDER BY id")
+// ListCourses returns the courses matching filter with the persons holding
+// each role in their sections in the current term, in the order of their ids.
+func (c *CourseService) ListCourses(ctx context.Context, filter CourseFilter) ([]models.Course, error) {
+	where, args := filter.where()
+	limit, args := limitClause(filter.Limit, args)
+	rel := filter.relations()
+	rows, err := c.DB.QueryContext(ctx, "SELECT "+courseColumns+", c.deleted_at, "+courseRosterColumns(rel)+" FROM "+rel.course+" c"+where+" ORDER BY c.id"+limit, args...)
 	if err != nil {
 		return []models.Course{}, fmt.Errorf("[in services.ListCourses] failed to get courses: %w", err)
 	}
@@ -26,11 +41,15 @@ func (c *CourseService) ListCourses(ctx context.Context) ([]models.Course, error
 
 	var courses []models.Course
 	for rows.Next() {
-		var course models.Course
-		err := rows.Scan(&course.ID, &course.Name)
+		var (
+			course models.Course
+			r      roster
+		)
+		err := rows.Scan(append(append(courseDest(&course), &course.DeletedAt), r.dest()...)...)
 		if err != nil {
 			return []models.Course{}, fmt.Errorf("[in services.ListCourses] failed to scan course from row: %w", err)
 		}
+		r.apply(&course)
 		courses = append(courses, course)
 	}
 
@@ -42,61 +61,519 @@ func (c *CourseService) ListCourses(ctx context.Context) ([]models.Course, error
 }
 
 func (c *CourseService) GetCourseById(ctx context.Context, id int) (models.Course, error) {
-	var course models.Course
-	err := c.DB.QueryRow("SELECT * FROM course WHERE id = $1", id).Scan(&course.ID, &course.Name)
+	return c.GetCourseAsOf(ctx, id, time.Time{})
+}
+
+// GetCourseAsOf returns the course and its roster as they were at asOf, or as
+// they are now if asOf is zero.
+func (c *CourseService) GetCourseAsOf(ctx context.Context, id int, asOf time.Time) (models.Course, error) {
+	args := []any{id}
+	if !asOf.IsZero() {
+		args = append(args, asOf)
+	}
+
+	var (
+		course models.Course
+		r      roster
+	)
+	rel := relationsAt(asOf, "$2")
+	err := c.DB.QueryRowContext(ctx, "SELECT "+courseColumns+", "+courseRosterColumns(rel)+" FROM "+rel.course+" c WHERE c.id = $1 AND c.deleted_at IS NULL", args...).
+		Scan(append(courseDest(&course), r.dest()...)...)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return models.Course{}, fmt.Errorf("[in services.GetCourseById] course with id %d not found: %w", id, err)
+			return models.Course{}, fmt.Errorf("[in services.GetCourseAsOf] course with id %d not found: %w", id, err)
 		}
-		return models.Course{}, fmt.Errorf("[in services.GetCourseById] failed to get course with id %d: %w", id, err)
+		return models.Course{}, fmt.Errorf("[in services.GetCourseAsOf] failed to get course with id %d: %w", id, err)
 	}
+	r.apply(&course)
 
 	return course, nil
 }
 
-func (c *CourseService) CreateCourse(ctx context.Context, courseName string) (models.Course, error) {
-	var newID int
-	err := c.DB.QueryRow("INSERT INTO course (name) VALUES ($1) RETURNING id", courseName).Scan(&newID)
+// GetCoursesByIDs returns the courses with the given ids that exist and are
+// not deleted, keyed by id, with their rosters, using a single query.
+func (c *CourseService) GetCoursesByIDs(ctx context.Context, ids []int) (map[int]models.Course, error) {
+	rows, err := c.DB.QueryContext(ctx, "SELECT "+courseColumns+", "+courseRosterColumns(relationsAt(time.Time{}, ""))+" FROM course c WHERE c.id = ANY($1) AND c.deleted_at IS NULL", pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("[in services.GetCoursesByIDs] failed to get courses: %w", err)
+	}
+	defer rows.Close()
+
+	courses := make(map[int]models.Course, len(ids))
+	for rows.Next() {
+		var (
+			course models.Course
+			r      roster
+		)
+		if err := rows.Scan(append(courseDest(&course), r.dest()...)...); err != nil {
+			return nil, fmt.Errorf("[in services.GetCoursesByIDs] failed to scan course from row: %w", err)
+		}
+		r.apply(&course)
+		courses[course.ID] = course
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.GetCoursesByIDs] failed to scan courses: %w", err)
+	}
+
+	return courses, nil
+}
+
+// CreateCourse adds a course, worth DefaultCredits if its credits are 0. A
+// department that does not exist or a catalog number already used in the
+// department is rejected with a *ValidationError.
+func (c *CourseService) CreateCourse(ctx context.Context, course models.Course) (models.Course, error) {
+	tx, err := c.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Course{}, fmt.Errorf("[in services.CreateCourse] failed to begin transaction: %w", err)
+	}
+
+	course, err = createCourse(ctx, tx, course)
 	if err != nil {
-		return models.Course{}, fmt.Errorf("[in services.CreateCourse] failed to create course: %w", err)
+		tx.Rollback()
+		return models.Course{}, fmt.Errorf("[in services.CreateCourse] %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Course{}, fmt.Errorf("[in services.CreateCourse] failed to commit transaction: %w", err)
 	}
 
-	return models.Course{ID: newID, Name: courseName}, nil
+	return course, nil
 }
 
-func (c *CourseService) UpdateCourse(ctx context.Context, courseID int, newCourseName string) (models.Course, error) {
-	result, err := c.DB.Exec("UPDATE course SET name = $1 WHERE id = $2", newCourseName, courseID)
+// UpdateCourse replaces the name, credits, catalog code and description of a
+// course as CreateCourse sets them, and sets the capacity of the sections
+// created for it from now on. Existing sections keep theirs; see
+// UpdateSection.
+func (c *CourseService) UpdateCourse(ctx context.Context, courseID int, updatedCourse models.Course) (models.Course, error) {
+	tx, err := c.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return models.Course{}, fmt.Errorf("[in services.UpdateCourse] failed to update course with id %d: %w", courseID, err)
+		return models.Course{}, fmt.Errorf("[in services.UpdateCourse] failed to begin transaction: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	course, err := updateCourse(ctx, tx, courseID, updatedCourse)
 	if err != nil {
-		return models.Course{}, fmt.Errorf("[in services.UpdateCourse] failed to get rows affected: %w", err)
+		tx.Rollback()
+		return models.Course{}, fmt.Errorf("[in services.UpdateCourse] %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return models.Course{}, fmt.Errorf("[in services.UpdateCourse] course with id %d not found", courseID)
+	if err = tx.Commit(); err != nil {
+		return models.Course{}, fmt.Errorf("[in services.UpdateCourse] failed to commit transaction: %w", err)
 	}
 
-	return models.Course{ID: courseID, Name: newCourseName}, nil
+	return course, nil
 }
 
 func (c *CourseService) DeleteCourse(ctx context.Context, id int) error {
-	result, err := c.DB.Exec("DELETE FROM course WHERE id = $1", id)
+	tx, err := c.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("[in services.DeleteCourse] failed to begin transaction: %w", err)
+	}
+
+	if err = deleteCourse(ctx, tx, id); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("[in services.DeleteCourse] %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("[in services.DeleteCourse] failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// createCourse inserts a course in the caller's transaction.
+func createCourse(ctx context.Context, tx *sql.Tx, newCourse models.Course) (models.Course, error) {
+	if err := checkCourse(ctx, tx, 0, &newCourse); err != nil {
+		return models.Course{}, err
+	}
+	var newID int
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO course (name, capacity, credits, department_id, catalog_number, description)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+		RETURNING id`,
+		newCourse.Name, newCourse.Capacity, newCourse.Credits, newCourse.DepartmentID, newCourse.CatalogNumber, newCourse.Description).Scan(&newID)
+	if err != nil {
+		return models.Course{}, fmt.Errorf("failed to create course: %w", err)
+	}
+	course, err := getCourseForUpdate(ctx, tx, newID)
+	if err != nil {
+		return models.Course{}, err
+	}
+
+	if err = recordAudit(ctx, tx, AuditCreate, EntityCourse, newID, nil, course); err != nil {
+		return models.Course{}, err
+	}
+	if err = publish(ctx, tx, EventCourseCreated, newID, course); err != nil {
+		return models.Course{}, err
+	}
+	return course, nil
+}
+
+// updateCourse replaces a course in the caller's transaction.
+func updateCourse(ctx context.Context, tx *sql.Tx, courseID int, updatedCourse models.Course) (models.Course, error) {
+	before, err := getCourseForUpdate(ctx, tx, courseID)
+	if err != nil {
+		return models.Course{}, err
+	}
+
+	if err = checkCourse(ctx, tx, courseID, &updatedCourse); err != nil {
+		return models.Course{}, err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE course
+		SET name = $1, capacity = $2, credits = $3, department_id = $4, catalog_number = NULLIF($5, ''), description = $6
+		WHERE id = $7`,
+		updatedCourse.Name, updatedCourse.Capacity, updatedCourse.Credits, updatedCourse.DepartmentID, updatedCourse.CatalogNumber, updatedCourse.Description, courseID)
+	if err != nil {
+		return models.Course{}, fmt.Errorf("failed to update course with id %d: %w", courseID, err)
+	}
+	after, err := getCourseForUpdate(ctx, tx, courseID)
+	if err != nil {
+		return models.Course{}, err
+	}
+
+	if err = recordAudit(ctx, tx, AuditUpdate, EntityCourse, courseID, before, after); err != nil {
+		return models.Course{}, err
+	}
+	if err = publish(ctx, tx, EventCourseUpdated, courseID, after); err != nil {
+		return models.Course{}, err
+	}
+	return after, nil
+}
+
+// deleteCourse soft deletes a course in the caller's transaction.
+func deleteCourse(ctx context.Context, tx *sql.Tx, id int) error {
+	before, err := getCourseForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	// Soft delete: enrollments are kept so RestoreCourse can bring them back
+	var deletedAt time.Time
+	err = tx.QueryRowContext(ctx, "UPDATE course SET deleted_at = now() WHERE id = $1 RETURNING deleted_at", id).Scan(&deletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to delete course with id %d: %w", id, err)
+	}
+	after := before
+	after.DeletedAt = &deletedAt
+
+	if err = recordAudit(ctx, tx, AuditDelete, EntityCourse, id, before, after); err != nil {
+		return err
+	}
+	return publish(ctx, tx, EventCourseDeleted, id, after)
+}
+
+// RestoreCourse undoes a soft delete. The course's sections and enrollments
+// were kept, so they reappear with it, and seats freed meanwhile go to the
+// persons waiting for them.
+func (c *CourseService) RestoreCourse(ctx context.Context, id int) (models.Course, error) {
+	tx, err := c.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Course{}, fmt.Errorf("[in services.RestoreCourse] failed to begin transaction: %w", err)
+	}
+
+	var before models.Course
+	err = tx.QueryRowContext(ctx, "SELECT "+courseColumns+", c.deleted_at FROM course c WHERE c.id = $1 AND c.deleted_at IS NOT NULL FOR UPDATE", id).
+		Scan(append(courseDest(&before), &before.DeletedAt)...)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return models.Course{}, fmt.Errorf("[in services.RestoreCourse] deleted course with id %d not found: %w", id, err)
+		}
+		return models.Course{}, fmt.Errorf("[in services.RestoreCourse] failed to get course with id %d: %w", id, err)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE course SET deleted_at = NULL WHERE id = $1", id)
+	if err != nil {
+		tx.Rollback()
+		return models.Course{}, fmt.Errorf("[in services.RestoreCourse] failed to restore course with id %d: %w", id, err)
+	}
+	sectionIDs, err := querySectionIDs(ctx, tx, id)
+	if err == nil {
+		err = lockWaitlisted(ctx, tx, sectionIDs)
+	}
+	if err == nil {
+		err = promoteSections(ctx, tx, c.Rules, sectionIDs)
+	}
+	if err != nil {
+		tx.Rollback()
+		return models.Course{}, fmt.Errorf("[in services.RestoreCourse] %w", err)
+	}
+	after := before
+	after.DeletedAt = nil
+	if err = queryRoster(ctx, tx, &after); err != nil {
+		tx.Rollback()
+		return models.Course{}, fmt.Errorf("[in services.RestoreCourse] %w", err)
+	}
+
+	if err = recordAudit(ctx, tx, AuditRestore, EntityCourse, id, before, after); err != nil {
+		tx.Rollback()
+		return models.Course{}, fmt.Errorf("[in services.RestoreCourse] %w", err)
+	}
+	if err = publish(ctx, tx, EventCourseRestored, id, after); err != nil {
+		tx.Rollback()
+		return models.Course{}, fmt.Errorf("[in services.RestoreCourse] %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Course{}, fmt.Errorf("[in services.RestoreCourse] failed to commit transaction: %w", err)
+	}
+
+	return after, nil
+}
+
+// PurgeCourses permanently removes courses soft deleted before cutoff,
+// together with their sections, meetings, enrollments, grades and
+// prerequisites, and returns how many were removed.
+func (c *CourseService) PurgeCourses(ctx context.Context, cutoff time.Time) (int, error) {
+	tx, err := c.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("[in services.PurgeCourses] failed to begin transaction: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM grade
+		WHERE section_id IN (SELECT s.id FROM section s JOIN course c ON c.id = s.course_id WHERE c.deleted_at < $1)`, cutoff)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("[in services.PurgeCourses] failed to delete grades: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM person_section
+		WHERE section_id IN (SELECT s.id FROM section s JOIN course c ON c.id = s.course_id WHERE c.deleted_at < $1)`, cutoff)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("[in services.PurgeCourses] failed to delete enrollments: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM section_waitlist
+		WHERE section_id IN (SELECT s.id FROM section s JOIN course c ON c.id = s.course_id WHERE c.deleted_at < $1)`, cutoff)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("[in services.PurgeCourses] failed to delete waitlists: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM section_meeting
+		WHERE section_id IN (SELECT s.id FROM section s JOIN course c ON c.id = s.course_id WHERE c.deleted_at < $1)`, cutoff)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("[in services.PurgeCourses] failed to delete meetings: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM section
+		WHERE course_id IN (SELECT id FROM course WHERE deleted_at < $1)`, cutoff)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("[in services.PurgeCourses] failed to delete sections: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM course_prerequisite
+		WHERE course_id IN (SELECT id FROM course WHERE deleted_at < $1)
+		   OR prerequisite_id IN (SELECT id FROM course WHERE deleted_at < $1)`, cutoff)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("[in services.PurgeCourses] failed to delete prerequisites: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, "DELETE FROM course c WHERE c.deleted_at < $1 RETURNING "+courseColumns+", c.deleted_at", cutoff)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("[in services.PurgeCourses] failed to delete courses: %w", err)
+	}
+	var purged []models.Course
+	for rows.Next() {
+		var course models.Course
+		if err := rows.Scan(append(courseDest(&course), &course.DeletedAt)...); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return 0, fmt.Errorf("[in services.PurgeCourses] failed to scan purged course: %w", err)
+		}
+		purged = append(purged, course)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("[in services.PurgeCourses] failed to scan purged courses: %w", err)
+	}
+
+	for _, course := range purged {
+		if err = recordAudit(ctx, tx, AuditPurge, EntityCourse, course.ID, course, nil); err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("[in services.PurgeCourses] %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("[in services.PurgeCourses] failed to commit transaction: %w", err)
+	}
+
+	return len(purged), nil
+}
+
+// courseColumns selects a course c with its catalog code, into the fields
+// returned by courseDest.
+const courseColumns = "c.id, c.name, c.capacity, c.credits, c.department_id, COALESCE(c.catalog_number, ''), " +
+	"COALESCE((SELECT d.code || '-' || c.catalog_number FROM department d WHERE d.id = c.department_id), ''), c.description"
+
+func courseDest(course *models.Course) []any {
+	return []any{&course.ID, &course.Name, &course.Capacity, &course.Credits, &course.DepartmentID, &course.CatalogNumber, &course.Code, &course.Description}
+}
+
+// checkCourse gives a course without credits DefaultCredits, and returns a
+// *ValidationError if its department does not exist or another course in it,
+// deleted or not, has its catalog number. The department is locked FOR SHARE
+// so it cannot be deleted before the transaction commits, or FOR NO KEY
+// UPDATE if the course has a catalog number so concurrent changes cannot both
+// take the same one. id is 0 for new courses.
+func checkCourse(ctx context.Context, tx *sql.Tx, id int, course *models.Course) error {
+	if course.Credits == 0 {
+		course.Credits = models.DefaultCredits
+	}
+	if course.DepartmentID == nil {
+		if course.CatalogNumber != "" {
+			return &ValidationError{Problems: []validation.Problem{{
+				Name:        "catalog_number",
+				Description: "is only allowed for courses in a department",
+			}}}
+		}
+		return nil
+	}
+
+	lock := "FOR SHARE"
+	if course.CatalogNumber != "" {
+		lock = "FOR NO KEY UPDATE"
+	}
+	var code string
+	err := tx.QueryRowContext(ctx, "SELECT code FROM department WHERE id = $1 "+lock, *course.DepartmentID).Scan(&code)
+	if err == sql.ErrNoRows {
+		return &ValidationError{Problems: []validation.Problem{{
+			Name:        "department_id",
+			Description: fmt.Sprintf("department %d does not exist", *course.DepartmentID),
+		}}}
+	}
 	if err != nil {
-		return fmt.Errorf("[in services.DeleteCourse] failed to delete course with id %d: %w", id, err)
+		return fmt.Errorf("failed to get department with id %d: %w", *course.DepartmentID, err)
+	}
+	if course.CatalogNumber == "" {
+		return nil
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	var otherID int
+	err = tx.QueryRowContext(ctx, "SELECT id FROM course WHERE department_id = $1 AND catalog_number = $2 AND id <> $3",
+		*course.DepartmentID, course.CatalogNumber, id).Scan(&otherID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("[in services.DeleteCourse] failed to get rows affected: %w", err)
+		return fmt.Errorf("failed to check catalog code %s-%s: %w", code, course.CatalogNumber, err)
 	}
+	return &ValidationError{Problems: []validation.Problem{{
+		Name:        "catalog_number",
+		Description: fmt.Sprintf("%s-%s is already course %d", code, course.CatalogNumber, otherID),
+	}}}
+}
+
+// getCourseForUpdate loads a course that has not been deleted and its roster,
+// and locks the course row for the rest of the transaction.
+func getCourseForUpdate(ctx context.Context, q querier, id int) (models.Course, error) {
+	var course models.Course
+	err := q.QueryRowContext(ctx, "SELECT "+courseColumns+" FROM course c WHERE c.id = $1 AND c.deleted_at IS NULL FOR UPDATE", id).Scan(courseDest(&course)...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Course{}, fmt.Errorf("course with id %d not found: %w", id, err)
+		}
+		return models.Course{}, fmt.Errorf("failed to get course with id %d: %w", id, err)
+	}
+	if err = queryRoster(ctx, q, &course); err != nil {
+		return models.Course{}, err
+	}
+	return course, nil
+}
+
+// roster holds the person ids per role selected by rosterColumns, in the
+// order of rosterRoles.
+type roster [4]pq.Int64Array
+
+var rosterRoles = [4]string{models.RoleInstructor, models.RoleTeachingAssistant, models.RoleStudent, models.RoleAuditor}
+
+// rosterColumns selects the ids of the persons that are not deleted holding
+// each role in the enrollments pc matching match, one array column per entry
+// of rosterRoles.
+func rosterColumns(enrollments, persons, match string) string {
+	columns := make([]string, 0, len(rosterRoles))
+	for _, role := range rosterRoles {
+		columns = append(columns, `COALESCE((
+			SELECT array_agg(pc.person_id ORDER BY pc.person_id)
+			FROM `+enrollments+` pc
+			JOIN `+persons+` p ON p.id = pc.person_id AND p.deleted_at IS NULL
+			WHERE `+match+` AND pc.role = '`+role+`'), '{}')`)
+	}
+	return strings.Join(columns, ", ")
+}
+
+// courseRosterColumns selects the roster of course c.
+func courseRosterColumns(rel relations) string {
+	return rosterColumns(rel.personCourse, rel.person, "pc.course_id = c.id")
+}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("[in services.DeleteCourse] course with id %d not found", id)
+func (r *roster) dest() []any {
+	return []any{&r[0], &r[1], &r[2], &r[3]}
+}
+
+// apply sets the course's role lists, which are never nil.
+func (r *roster) apply(course *models.Course) {
+	r.applyTo(&course.Instructors, &course.TeachingAssistants, &course.Students, &course.Auditors)
+}
+
+func (r *roster) applyTo(lists ...*[]int) {
+	for i, ids := range r {
+		*lists[i] = make([]int, 0, len(ids))
+		for _, id := range ids {
+			*lists[i] = append(*lists[i], int(id))
+		}
 	}
+}
 
+// queryRoster fills in the current roster of a course.
+func queryRoster(ctx context.Context, q querier, course *models.Course) error {
+	var r roster
+	err := q.QueryRowContext(ctx, "SELECT "+courseRosterColumns(relationsAt(time.Time{}, ""))+" FROM course c WHERE c.id = $1", course.ID).Scan(r.dest()...)
+	if err != nil {
+		return fmt.Errorf("[in services.queryRoster] failed to get roster of course with id %d: %w", course.ID, err)
+	}
+	r.apply(course)
 	return nil
 }
 
+// StreamCourses calls fn for each course matching filter, in id order, without
+// loading the full result set into memory.
+func (c *CourseService) StreamCourses(ctx context.Context, filter CourseFilter, fn func(models.Course) error) error {
+	where, args := filter.where()
+	rows, err := c.DB.QueryContext(ctx, "SELECT "+courseColumns+", c.deleted_at FROM "+filter.relations().course+" c"+where+" ORDER BY c.id", args...)
+	if err != nil {
+		return fmt.Errorf("[in services.StreamCourses] failed to get courses: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var course models.Course
+		if err := rows.Scan(append(courseDest(&course), &course.DeletedAt)...); err != nil {
+			return fmt.Errorf("[in services.StreamCourses] failed to scan course from row: %w", err)
+		}
+		if err := fn(course); err != nil {
+			return fmt.Errorf("[in services.StreamCourses] %w", err)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("[in services.StreamCourses] failed to scan courses: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+)
+
+type DepartmentService struct {
+	DB *sql.DB
+}
+
+func NewDepartmentService(db *sql.DB) *DepartmentService {
+	return &DepartmentService{
+		DB: db,
+	}
+}
+
+func scanDepartment(row interface{ Scan(...any) error }) (models.Department, error) {
+	var department models.Department
+	err := row.Scan(&department.ID, &department.Code, &department.Name, &department.ChairID)
+	return department, err
+}
+
+// ListDepartments returns every department in code order.
+func (d *DepartmentService) ListDepartments(ctx context.Context) ([]models.Department, error) {
+	rows, err := d.DB.QueryContext(ctx, "SELECT id, code, name, chair_id FROM department ORDER BY code")
+	if err != nil {
+		return nil, fmt.Errorf("[in services.ListDepartments] failed to get departments: %w", err)
+	}
+	defer rows.Close()
+
+	departments := []models.Department{}
+	for rows.Next() {
+		department, err := scanDepartment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("[in services.ListDepartments] failed to scan department from row: %w", err)
+		}
+		departments = append(departments, department)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.ListDepartments] failed to scan departments: %w", err)
+	}
+
+	return departments, nil
+}
+
+func (d *DepartmentService) GetDepartment(ctx context.Context, id int) (models.Department, error) {
+	department, err := getDepartment(ctx, d.DB, id, "")
+	if err != nil {
+		return models.Department{}, fmt.Errorf("[in services.GetDepartment] %w", err)
+	}
+	return department, nil
+}
+
+// CreateDepartment adds a department. A code or name already in use, or a
+// chair that is not a professor, is rejected with a *ValidationError.
+func (d *DepartmentService) CreateDepartment(ctx context.Context, department models.Department) (models.Department, error) {
+	return d.inTx(ctx, "CreateDepartment", func(tx *sql.Tx) (models.Department, error) {
+		if err := checkDepartment(ctx, tx, 0, department); err != nil {
+			return models.Department{}, err
+		}
+
+		err := tx.QueryRowContext(ctx, "INSERT INTO department (code, name, chair_id) VALUES ($1, $2, $3) RETURNING id",
+			department.Code, department.Name, department.ChairID).Scan(&department.ID)
+		if err != nil {
+			return models.Department{}, fmt.Errorf("failed to create department: %w", err)
+		}
+
+		if err = recordAudit(ctx, tx, AuditCreate, EntityDepartment, department.ID, nil, department); err != nil {
+			return models.Department{}, err
+		}
+		if err = publish(ctx, tx, EventDepartmentCreated, department.ID, department); err != nil {
+			return models.Department{}, err
+		}
+		return department, nil
+	})
+}
+
+// UpdateDepartment replaces a department as CreateDepartment checks it. A new
+// code changes the catalog codes of its courses with it.
+func (d *DepartmentService) UpdateDepartment(ctx context.Context, id int, department models.Department) (models.Department, error) {
+	return d.inTx(ctx, "UpdateDepartment", func(tx *sql.Tx) (models.Department, error) {
+		before, err := getDepartment(ctx, tx, id, "FOR UPDATE")
+		if err != nil {
+			return models.Department{}, err
+		}
+		if err = checkDepartment(ctx, tx, id, department); err != nil {
+			return models.Department{}, err
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE department SET code = $1, name = $2, chair_id = $3 WHERE id = $4",
+			department.Code, department.Name, department.ChairID, id)
+		if err != nil {
+			return models.Department{}, fmt.Errorf("failed to update department with id %d: %w", id, err)
+		}
+		department.ID = id
+
+		if err = recordAudit(ctx, tx, AuditUpdate, EntityDepartment, id, before, department); err != nil {
+			return models.Department{}, err
+		}
+		if err = publish(ctx, tx, EventDepartmentUpdated, id, department); err != nil {
+			return models.Department{}, err
+		}
+		return department, nil
+	})
+}
+
+// DeleteDepartment removes a department that offers no courses, deleted or
+// not; one that does is rejected with a *ValidationError.
+func (d *DepartmentService) DeleteDepartment(ctx context.Context, id int) error {
+	_, err := d.inTx(ctx, "DeleteDepartment", func(tx *sql.Tx) (models.Department, error) {
+		before, err := getDepartment(ctx, tx, id, "FOR UPDATE")
+		if err != nil {
+			return models.Department{}, err
+		}
+
+		var courses int
+		if err = tx.QueryRowContext(ctx, "SELECT count(*) FROM course WHERE department_id = $1", id).Scan(&courses); err != nil {
+			return models.Department{}, fmt.Errorf("failed to count the courses of department %d: %w", id, err)
+		}
+		if courses > 0 {
+			return models.Department{}, &ValidationError{Problems: []validation.Problem{{
+				Name:        "id",
+				Description: fmt.Sprintf("department %d offers %d courses; move them first", id, courses),
+			}}}
+		}
+
+		if _, err = tx.ExecContext(ctx, "DELETE FROM department WHERE id = $1", id); err != nil {
+			return models.Department{}, fmt.Errorf("failed to delete department with id %d: %w", id, err)
+		}
+		if err = recordAudit(ctx, tx, AuditDelete, EntityDepartment, id, before, nil); err != nil {
+			return models.Department{}, err
+		}
+		return models.Department{}, publish(ctx, tx, EventDepartmentDeleted, id, before)
+	})
+	return err
+}
+
+// getDepartment loads a department, applying lock, e.g. "FOR UPDATE", if it
+// is not empty. It returns an error wrapping sql.ErrNoRows if there is no
+// such department.
+func getDepartment(ctx context.Context, q querier, id int, lock string) (models.Department, error) {
+	department, err := scanDepartment(q.QueryRowContext(ctx, "SELECT id, code, name, chair_id FROM department WHERE id = $1 "+lock, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Department{}, fmt.Errorf("department with id %d not found: %w", id, err)
+		}
+		return models.Department{}, fmt.Errorf("failed to get department with id %d: %w", id, err)
+	}
+	return department, nil
+}
+
+// checkDepartment returns a *ValidationError if a department other than the
+// one with id has the code or name of department, or if its chair is not a
+// professor that is not deleted. The chair is locked FOR SHARE so they cannot
+// stop being a professor before the transaction commits.
+func checkDepartment(ctx context.Context, tx *sql.Tx, id int, department models.Department) error {
+	var problems []validation.Problem
+	var codeTaken, nameTaken bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM department WHERE code = $1 AND id <> $3),
+		       EXISTS (SELECT 1 FROM department WHERE name = $2 AND id <> $3)`,
+		department.Code, department.Name, id).Scan(&codeTaken, &nameTaken)
+	if err != nil {
+		return fmt.Errorf("failed to check department %s: %w", department.Code, err)
+	}
+	if codeTaken {
+		problems = append(problems, validation.Problem{
+			Name:        "code",
+			Description: fmt.Sprintf("department %s already exists", department.Code),
+		})
+	}
+	if nameTaken {
+		problems = append(problems, validation.Problem{
+			Name:        "name",
+			Description: fmt.Sprintf("department %s already exists", department.Name),
+		})
+	}
+
+	if department.ChairID != nil {
+		var isProfessor bool
+		err := tx.QueryRowContext(ctx, "SELECT type = 'professor' FROM person WHERE id = $1 AND deleted_at IS NULL FOR SHARE",
+			*department.ChairID).Scan(&isProfessor)
+		switch {
+		case err == sql.ErrNoRows:
+			problems = append(problems, validation.Problem{
+				Name:        "chair_id",
+				Description: fmt.Sprintf("person %d does not exist", *department.ChairID),
+			})
+		case err != nil:
+			return fmt.Errorf("failed to get person with id %d: %w", *department.ChairID, err)
+		case !isProfessor:
+			problems = append(problems, validation.Problem{
+				Name:        "chair_id",
+				Description: fmt.Sprintf("person %d is not a professor", *department.ChairID),
+			})
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// checkNotChairing returns a *ValidationError if the person chairs a
+// department. They can only stop being a professor once they no longer do.
+func checkNotChairing(ctx context.Context, tx *sql.Tx, personID int) error {
+	var code string
+	err := tx.QueryRowContext(ctx, "SELECT code FROM department WHERE chair_id = $1 ORDER BY code LIMIT 1", personID).Scan(&code)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up departments chaired by person with id %d: %w", personID, err)
+	}
+	return &ValidationError{Problems: []validation.Problem{{
+		Name:        "type",
+		Description: fmt.Sprintf("must remain professor while chairing department %s", code),
+	}}}
+}
+
+// inTx runs fn in a new transaction, committing it if fn succeeds.
+func (d *DepartmentService) inTx(ctx context.Context, method string, fn func(tx *sql.Tx) (models.Department, error)) (models.Department, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Department{}, fmt.Errorf("[in services.%s] failed to begin transaction: %w", method, err)
+	}
+
+	department, err := fn(tx)
+	if err != nil {
+		tx.Rollback()
+		return models.Department{}, fmt.Errorf("[in services.%s] %w", method, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Department{}, fmt.Errorf("[in services.%s] failed to commit transaction: %w", method, err)
+	}
+
+	return department, nil
+}
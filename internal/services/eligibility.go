@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/rules"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+	"github.com/lib/pq"
+)
+
+type ruleOverrideKey struct{}
+
+// WithRuleOverride lets enrollments made with the returned context skip the
+// eligibility rules. Handlers only set it for admins.
+func WithRuleOverride(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ruleOverrideKey{}, true)
+}
+
+func rulesOverridden(ctx context.Context) bool {
+	override, _ := ctx.Value(ruleOverrideKey{}).(bool)
+	return override
+}
+
+// CheckEligibility reports whether AddEnrollment would enroll the person with
+// firstName in a course with role, without enrolling them. It returns the
+// problems AddEnrollment would reject the enrollment with, including those
+// of the eligibility rules, or none if it would be allowed. It returns an
+// error wrapping sql.ErrNoRows if there is no such person.
+//
+// The enrollment is made in a transaction that is rolled back, so the check
+// takes the locks AddEnrollment does until it returns: the person, the
+// persons waiting on their sections and the section, waiting for concurrent
+// changes to them. The enrollment and any waitlist changes are discarded
+// with the transaction, though ids drawn from sequences stay used.
+func (p *PersonService) CheckEligibility(ctx context.Context, firstName string, courseID int, role string) ([]validation.Problem, error) {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.CheckEligibility] failed to begin transaction: %w", err)
+	}
+	// Nothing is kept: the enrollment is only tried
+	defer tx.Rollback()
+
+	_, err = addEnrollment(ctx, tx, p.Rules, firstName, courseID, role)
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Problems, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("[in services.CheckEligibility] %w", err)
+	}
+	return []validation.Problem{}, nil
+}
+
+// checkRules returns a *ValidationError with a problem for each rule of set
+// the person breaks by holding enrollments, all in one term. The enrollments
+// whose sections are keys of added are new, and named by the field they map
+// to. Nothing is checked if the rules are overridden.
+func checkRules(ctx context.Context, q querier, set rules.Set, person models.Person, enrollments []models.Enrollment, added map[int]string) error {
+	if len(set.Rules) == 0 || len(added) == 0 || rulesOverridden(ctx) {
+		return nil
+	}
+
+	sectionIDs := make([]int, 0, len(enrollments))
+	for _, enrollment := range enrollments {
+		sectionIDs = append(sectionIDs, enrollment.SectionID)
+	}
+	departments, err := sectionDepartments(ctx, q, sectionIDs)
+	if err != nil {
+		return err
+	}
+
+	facts := make([]rules.Enrollment, 0, len(enrollments))
+	for _, enrollment := range enrollments {
+		field, isNew := added[enrollment.SectionID]
+		facts = append(facts, rules.Enrollment{
+			Department: departments[enrollment.SectionID],
+			Role:       enrollment.Role,
+			New:        isNew,
+			Field:      field,
+		})
+	}
+	if problems := set.Check(rules.Person{Type: person.Type, Age: person.Age}, facts); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// checkSectionRules checks the rules of set as checkRules does for the person
+// holding a section with role, in addition to, or instead of their current
+// role in, their sections of the same term, naming field.
+func checkSectionRules(ctx context.Context, q querier, set rules.Set, person models.Person, sectionID int, role, field string) error {
+	if len(set.Rules) == 0 || rulesOverridden(ctx) {
+		return nil
+	}
+
+	enrollments, err := termEnrollments(ctx, q, person.ID, sectionID)
+	if err != nil {
+		return err
+	}
+	enrollments = append(enrollments, models.Enrollment{SectionID: sectionID, Role: role})
+	return checkRules(ctx, q, set, person, enrollments, map[int]string{sectionID: field})
+}
+
+// termEnrollments returns the enrollments of a person in courses that are not
+// deleted in the term of a section, leaving out the section itself.
+func termEnrollments(ctx context.Context, q querier, personID, sectionID int) ([]models.Enrollment, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT s.course_id, ps.section_id, ps.role
+		FROM person_section ps
+		JOIN section s ON s.id = ps.section_id
+		JOIN course c ON c.id = s.course_id AND c.deleted_at IS NULL
+		JOIN section target ON target.id = $2 AND target.term_id = s.term_id
+		WHERE ps.person_id = $1 AND ps.section_id <> $2`, personID, sectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the enrollments of person with id %d in the term of section %d: %w", personID, sectionID, err)
+	}
+	defer rows.Close()
+
+	var enrollments []models.Enrollment
+	for rows.Next() {
+		var enrollment models.Enrollment
+		if err := rows.Scan(&enrollment.CourseID, &enrollment.SectionID, &enrollment.Role); err != nil {
+			return nil, fmt.Errorf("failed to scan enrollment: %w", err)
+		}
+		enrollments = append(enrollments, enrollment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan enrollments: %w", err)
+	}
+	return enrollments, nil
+}
+
+// sectionDepartments returns the department code of the course of each of
+// sectionIDs, leaving out courses without a department.
+func sectionDepartments(ctx context.Context, q querier, sectionIDs []int) (map[int]string, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT s.id, d.code
+		FROM section s
+		JOIN course c ON c.id = s.course_id
+		JOIN department d ON d.id = c.department_id
+		WHERE s.id = ANY($1)`, pq.Array(sectionIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the departments of sections: %w", err)
+	}
+	defer rows.Close()
+
+	departments := make(map[int]string, len(sectionIDs))
+	for rows.Next() {
+		var (
+			sectionID int
+			code      string
+		)
+		if err := rows.Scan(&sectionID, &code); err != nil {
+			return nil, fmt.Errorf("failed to scan department: %w", err)
+		}
+		departments[sectionID] = code
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan departments: %w", err)
+	}
+	return departments, nil
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+)
+
+// ValidationError reports input that is well formed but refers to data that
+// does not exist, detected before anything is written. Handlers return its
+// problems to the client as a 422.
+type ValidationError struct {
+	Problems []validation.Problem
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%d validation problems", len(e.Problems))
+}
+
+// ErrForbidden is wrapped by errors for changes the caller may not make.
+// Handlers return them to the client as a 403.
+var ErrForbidden = errors.New("forbidden")
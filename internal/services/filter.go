@@ -0,0 +1,192 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PersonFilter narrows the persons returned by ListPersons and StreamPersons.
+// Zero values are ignored.
+type PersonFilter struct {
+	FirstName string
+	LastName  string
+	Type      string
+	// MinAge and MaxAge bound the ages of the persons, inclusive, as of AsOf
+	// if it is set.
+	MinAge   int
+	MaxAge   int
+	CourseID int
+	// IncludeDeleted also returns soft deleted persons.
+	IncludeDeleted bool
+	// AsOf reads the persons and enrollments as they were at that time.
+	AsOf time.Time
+	// After and Limit page through ListPersons: only persons with a greater
+	// id, and at most Limit of them.
+	After int
+	Limit int
+}
+
+// CourseFilter narrows the courses returned by ListCourses and StreamCourses.
+// Zero values are ignored.
+type CourseFilter struct {
+	Name         string
+	DepartmentID int
+	// MinCredits and MaxCredits bound the credits of the courses, inclusive.
+	MinCredits int
+	MaxCredits int
+	// IncludeDeleted also returns soft deleted courses.
+	IncludeDeleted bool
+	// AsOf reads the courses as they were at that time.
+	AsOf time.Time
+	// After and Limit page through ListCourses: only courses with a greater
+	// id, and at most Limit of them.
+	After int
+	Limit int
+}
+
+// SectionFilter narrows the sections returned by ListSections. Zero values
+// are ignored.
+type SectionFilter struct {
+	CourseID int
+	TermID   int
+	// After and Limit page through ListSections: only sections listed after
+	// the section with id After, and at most Limit of them.
+	After int
+	Limit int
+}
+
+// relations returns the relations to read from; AsOf, if set, is $1.
+func (f PersonFilter) relations() relations {
+	return relationsAt(f.AsOf, "$1")
+}
+
+// where builds the WHERE clause for a person query aliased as p, numbering
+// placeholders from 1. AsOf, if set, is always the first argument.
+func (f PersonFilter) where() (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if !f.AsOf.IsZero() {
+		args = append(args, f.AsOf)
+	}
+
+	if !f.IncludeDeleted {
+		conds = append(conds, "p.deleted_at IS NULL")
+	}
+	if f.FirstName != "" {
+		add("p.first_name = $%d", f.FirstName)
+	}
+	if f.LastName != "" {
+		add("p.last_name = $%d", f.LastName)
+	}
+	if f.Type != "" {
+		add("p.type = $%d", f.Type)
+	}
+	if f.MinAge > 0 {
+		add("p.date_of_birth <= "+f.relations().today+" - make_interval(years => $%d)", f.MinAge)
+	}
+	if f.MaxAge > 0 {
+		add("p.date_of_birth > "+f.relations().today+" - make_interval(years => $%d + 1)", f.MaxAge)
+	}
+	if f.CourseID > 0 {
+		add("EXISTS (SELECT 1 FROM "+f.relations().personCourse+" f_pc WHERE f_pc.person_id = p.id AND f_pc.course_id = $%d)", f.CourseID)
+	}
+	if f.After > 0 {
+		add("p.id > $%d", f.After)
+	}
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// relations returns the relations to read from; AsOf, if set, is $1.
+func (f CourseFilter) relations() relations {
+	return relationsAt(f.AsOf, "$1")
+}
+
+// where builds the WHERE clause for a course query aliased as c, numbering
+// placeholders from 1. AsOf, if set, is always the first argument.
+func (f CourseFilter) where() (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	if !f.AsOf.IsZero() {
+		args = append(args, f.AsOf)
+	}
+	if !f.IncludeDeleted {
+		conds = append(conds, "c.deleted_at IS NULL")
+	}
+	if f.Name != "" {
+		args = append(args, "%"+f.Name+"%")
+		conds = append(conds, fmt.Sprintf("c.name ILIKE $%d", len(args)))
+	}
+	if f.DepartmentID > 0 {
+		args = append(args, f.DepartmentID)
+		conds = append(conds, fmt.Sprintf("c.department_id = $%d", len(args)))
+	}
+	if f.MinCredits > 0 {
+		args = append(args, f.MinCredits)
+		conds = append(conds, fmt.Sprintf("c.credits >= $%d", len(args)))
+	}
+	if f.MaxCredits > 0 {
+		args = append(args, f.MaxCredits)
+		conds = append(conds, fmt.Sprintf("c.credits <= $%d", len(args)))
+	}
+	if f.After > 0 {
+		args = append(args, f.After)
+		conds = append(conds, fmt.Sprintf("c.id > $%d", len(args)))
+	}
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// where builds the WHERE clause for a section query aliased as s, numbering
+// placeholders from 1.
+func (f SectionFilter) where() (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	if f.CourseID > 0 {
+		args = append(args, f.CourseID)
+		conds = append(conds, fmt.Sprintf("s.course_id = $%d", len(args)))
+	}
+	if f.TermID > 0 {
+		args = append(args, f.TermID)
+		conds = append(conds, fmt.Sprintf("s.term_id = $%d", len(args)))
+	}
+	if f.After > 0 {
+		// Sections are listed by term, course and number, so the page
+		// continues after the position of section After in that order.
+		args = append(args, f.After)
+		conds = append(conds, fmt.Sprintf(`(t.start_date, s.term_id, s.course_id, s.number) >
+			(SELECT a_t.start_date, a_s.term_id, a_s.course_id, a_s.number FROM section a_s JOIN term a_t ON a_t.id = a_s.term_id WHERE a_s.id = $%d)`, len(args)))
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// limitClause returns the LIMIT clause for n appended to args, or nothing if n is
+// not positive.
+func limitClause(n int, args []any) (string, []any) {
+	if n <= 0 {
+		return "", args
+	}
+	args = append(args, n)
+	return fmt.Sprintf(" LIMIT $%d", len(args)), args
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPersonFilterWhere(t *testing.T) {
+	asOf := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		filter    PersonFilter
+		wantWhere string
+		wantArgs  []any
+	}{
+		{"default", PersonFilter{}, " WHERE p.deleted_at IS NULL", nil},
+		{"no conditions", PersonFilter{IncludeDeleted: true}, "", nil},
+		{"as_of without conditions", PersonFilter{IncludeDeleted: true, AsOf: asOf}, "", []any{asOf}},
+		{"as_of comes first", PersonFilter{IncludeDeleted: true, AsOf: asOf, FirstName: "Ada", Type: "student"},
+			" WHERE p.first_name = $2 AND p.type = $3", []any{asOf, "Ada", "student"}},
+		{"after", PersonFilter{After: 20}, " WHERE p.deleted_at IS NULL AND p.id > $1", []any{20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := tt.filter.where()
+			if where != tt.wantWhere || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("where() = %q, %v, want %q, %v", where, args, tt.wantWhere, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestPersonFilterWhereNumbersAgeAfterAsOf(t *testing.T) {
+	asOf := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	where, args := PersonFilter{AsOf: asOf, MinAge: 18, CourseID: 3}.where()
+	if !strings.Contains(where, "make_interval(years => $2)") || !strings.Contains(where, "f_pc.course_id = $3") {
+		t.Errorf("where() = %q, want age as $2 and course as $3", where)
+	}
+	if want := []any{asOf, 18, 3}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
+
+func TestCourseFilterWhere(t *testing.T) {
+	asOf := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		filter    CourseFilter
+		wantWhere string
+		wantArgs  []any
+	}{
+		{"default", CourseFilter{}, " WHERE c.deleted_at IS NULL", nil},
+		{"no conditions", CourseFilter{IncludeDeleted: true}, "", nil},
+		{"as_of without conditions", CourseFilter{IncludeDeleted: true, AsOf: asOf}, "", []any{asOf}},
+		{"as_of comes first", CourseFilter{AsOf: asOf, Name: "math", MaxCredits: 4},
+			" WHERE c.deleted_at IS NULL AND c.name ILIKE $2 AND c.credits <= $3", []any{asOf, "%math%", 4}},
+		{"after", CourseFilter{After: 20, Limit: 10}, " WHERE c.deleted_at IS NULL AND c.id > $1", []any{20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := tt.filter.where()
+			if where != tt.wantWhere || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("where() = %q, %v, want %q, %v", where, args, tt.wantWhere, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSectionFilterWhereAfter(t *testing.T) {
+	where, args := SectionFilter{TermID: 2, After: 9}.where()
+	if !strings.HasPrefix(where, " WHERE s.term_id = $1 AND (t.start_date, s.term_id, s.course_id, s.number) >") ||
+		!strings.Contains(where, "WHERE a_s.id = $2)") {
+		t.Errorf("where() = %q, want the term and the position after section $2", where)
+	}
+	if want := []any{2, 9}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
+
+func TestLimitClause(t *testing.T) {
+	if clause, args := limitClause(0, []any{1}); clause != "" || !reflect.DeepEqual(args, []any{1}) {
+		t.Errorf("limitClause(0) = %q, %v, want no clause", clause, args)
+	}
+	if clause, args := limitClause(50, []any{1}); clause != " LIMIT $2" || !reflect.DeepEqual(args, []any{1, 50}) {
+		t.Errorf("limitClause(50) = %q, %v, want %q, [1 50]", clause, args, " LIMIT $2")
+	}
+}
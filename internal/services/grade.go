@@ -0,0 +1,484 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+	"github.com/lib/pq"
+)
+
+// GradeScale maps the letter grades students can be given to their grade
+// points, e.g. "A" to 4.0. Letters worth no points are failing grades.
+type GradeScale map[string]float64
+
+// Check reports a scale without letters, with points grade can not store, or
+// that redefines P, NP or I.
+func (s GradeScale) Check() error {
+	if len(s) == 0 {
+		return errors.New("[in services.Check] the grade scale has no letters")
+	}
+	for letter, points := range s {
+		switch {
+		case letter == "" || strings.TrimSpace(letter) != letter:
+			return fmt.Errorf("[in services.Check] grade %q is not a letter", letter)
+		case letter == models.GradePass || letter == models.GradeNoPass || letter == models.GradeIncomplete:
+			return fmt.Errorf("[in services.Check] grade %s is reserved for pass/fail and incomplete grades", letter)
+		case points < 0 || points > 9.99:
+			return fmt.Errorf("[in services.Check] grade %s is worth %g points; points must be between 0 and 9.99", letter, points)
+		}
+	}
+	return nil
+}
+
+// value returns the grade points of grade, nil if it is not a letter, and
+// whether it passes. ok is false if grade is not on the scale.
+func (s GradeScale) value(grade string) (points *float64, passed, ok bool) {
+	switch grade {
+	case models.GradePass:
+		return nil, true, true
+	case models.GradeNoPass, models.GradeIncomplete:
+		return nil, false, true
+	}
+	p, ok := s[grade]
+	if !ok {
+		return nil, false, false
+	}
+	return &p, p > 0, true
+}
+
+// grades lists every grade, the letters best first.
+func (s GradeScale) grades() []string {
+	letters := make([]string, 0, len(s)+3)
+	for letter := range s {
+		letters = append(letters, letter)
+	}
+	slices.SortFunc(letters, func(a, b string) int {
+		return cmp.Or(cmp.Compare(s[b], s[a]), cmp.Compare(a, b))
+	})
+	return append(letters, models.GradePass, models.GradeNoPass, models.GradeIncomplete)
+}
+
+type GradeService struct {
+	DB    *sql.DB
+	Scale GradeScale
+}
+
+func NewGradeService(db *sql.DB, scale GradeScale) *GradeService {
+	return &GradeService{
+		DB:    db,
+		Scale: scale,
+	}
+}
+
+// ListGrades returns the grades recorded in a section of a course that is not
+// deleted, by first name. It returns an error wrapping sql.ErrNoRows if there
+// is no such section.
+func (g *GradeService) ListGrades(ctx context.Context, sectionID int) ([]models.Grade, error) {
+	if _, err := getSection(ctx, g.DB, sectionID, ""); err != nil {
+		return nil, fmt.Errorf("[in services.ListGrades] %w", err)
+	}
+	grades, err := queryGrades(ctx, g.DB, "g.section_id = $1", sectionID)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.ListGrades] %w", err)
+	}
+	return grades, nil
+}
+
+// SubmitGrades records the grades of students of a section, given by
+// FirstName and Grade, and returns every grade of the section. The caller
+// named in the audit info must be a professor teaching the section, or an
+// error wrapping ErrForbidden is returned. Grades not on the scale, persons
+// who are not students of the section and students who already have a grade
+// are rejected with a *ValidationError; see AmendGrade.
+func (g *GradeService) SubmitGrades(ctx context.Context, sectionID int, grades []models.Grade) ([]models.Grade, error) {
+	tx, err := g.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.SubmitGrades] failed to begin transaction: %w", err)
+	}
+
+	submitted, err := submitGrades(ctx, tx, g.Scale, sectionID, grades)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("[in services.SubmitGrades] %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("[in services.SubmitGrades] failed to commit transaction: %w", err)
+	}
+
+	return submitted, nil
+}
+
+// AmendGrade changes the grade of the person with firstName in a section. As
+// with SubmitGrades, only a professor teaching the section may amend it. It
+// returns an error wrapping sql.ErrNoRows if the person has no grade in the
+// section.
+func (g *GradeService) AmendGrade(ctx context.Context, sectionID int, firstName, grade string) (models.Grade, error) {
+	tx, err := g.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Grade{}, fmt.Errorf("[in services.AmendGrade] failed to begin transaction: %w", err)
+	}
+
+	amended, err := amendGrade(ctx, tx, g.Scale, sectionID, firstName, grade)
+	if err != nil {
+		tx.Rollback()
+		return models.Grade{}, fmt.Errorf("[in services.AmendGrade] %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Grade{}, fmt.Errorf("[in services.AmendGrade] failed to commit transaction: %w", err)
+	}
+
+	return amended, nil
+}
+
+// submitGrades records grades in the caller's transaction.
+func submitGrades(ctx context.Context, tx *sql.Tx, scale GradeScale, sectionID int, grades []models.Grade) ([]models.Grade, error) {
+	// Lock the section so its grades and roster hold still
+	section, err := getSection(ctx, tx, sectionID, "FOR UPDATE OF s")
+	if err != nil {
+		return nil, err
+	}
+	graderID, err := checkGrader(ctx, tx, section)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []validation.Problem
+	personIDs := make([]int, len(grades))
+	seen := make(map[string]bool, len(grades))
+	for i, grade := range grades {
+		if _, _, ok := scale.value(grade.Grade); !ok {
+			problems = append(problems, validation.Problem{
+				Name:        fmt.Sprintf("grades[%d].grade", i),
+				Description: "must be one of " + strings.Join(scale.grades(), ", "),
+			})
+		}
+
+		field := fmt.Sprintf("grades[%d].first_name", i)
+		if seen[grade.FirstName] {
+			problems = append(problems, validation.Problem{
+				Name:        field,
+				Description: fmt.Sprintf("%s is graded more than once", grade.FirstName),
+			})
+			continue
+		}
+		seen[grade.FirstName] = true
+
+		var (
+			role   sql.NullString
+			graded bool
+		)
+		err := tx.QueryRowContext(ctx, `
+			SELECT p.id, ps.role, EXISTS (SELECT 1 FROM grade g WHERE g.person_id = p.id AND g.section_id = $2)
+			FROM person p
+			LEFT JOIN person_section ps ON ps.person_id = p.id AND ps.section_id = $2
+			WHERE p.first_name = $1 AND p.deleted_at IS NULL`, grade.FirstName, sectionID).Scan(&personIDs[i], &role, &graded)
+		switch {
+		case err == sql.ErrNoRows:
+			problems = append(problems, validation.Problem{
+				Name:        field,
+				Description: fmt.Sprintf("no person named %s", grade.FirstName),
+			})
+		case err != nil:
+			return nil, fmt.Errorf("failed to get person with first name %s: %w", grade.FirstName, err)
+		case role.String != models.RoleStudent:
+			problems = append(problems, validation.Problem{
+				Name:        field,
+				Description: fmt.Sprintf("%s is not a student of section %d", grade.FirstName, sectionID),
+			})
+		case graded:
+			problems = append(problems, validation.Problem{
+				Name:        field,
+				Description: fmt.Sprintf("%s already has a grade in section %d; amend it instead", grade.FirstName, sectionID),
+			})
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	for i, grade := range grades {
+		points, passed, _ := scale.value(grade.Grade)
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO grade (person_id, section_id, grade, points, passed, graded_by)
+			VALUES ($1, $2, $3, $4, $5, $6)`, personIDs[i], sectionID, grade.Grade, points, passed, graderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record grade of person with id %d in section %d: %w", personIDs[i], sectionID, err)
+		}
+
+		recorded, err := getGrade(ctx, tx, personIDs[i], sectionID)
+		if err != nil {
+			return nil, err
+		}
+		if err = recordAudit(ctx, tx, AuditCreate, EntityGrade, sectionID, nil, recorded); err != nil {
+			return nil, err
+		}
+		if err = publish(ctx, tx, EventGradeSubmitted, sectionID, recorded); err != nil {
+			return nil, err
+		}
+	}
+
+	return queryGrades(ctx, tx, "g.section_id = $1", sectionID)
+}
+
+// amendGrade changes a grade in the caller's transaction.
+func amendGrade(ctx context.Context, tx *sql.Tx, scale GradeScale, sectionID int, firstName, grade string) (models.Grade, error) {
+	section, err := getSection(ctx, tx, sectionID, "FOR UPDATE OF s")
+	if err != nil {
+		return models.Grade{}, err
+	}
+	graderID, err := checkGrader(ctx, tx, section)
+	if err != nil {
+		return models.Grade{}, err
+	}
+
+	points, passed, ok := scale.value(grade)
+	if !ok {
+		return models.Grade{}, &ValidationError{Problems: []validation.Problem{{
+			Name:        "grade",
+			Description: "must be one of " + strings.Join(scale.grades(), ", "),
+		}}}
+	}
+
+	var personID int
+	err = tx.QueryRowContext(ctx, "SELECT id FROM person WHERE first_name = $1 AND deleted_at IS NULL", firstName).Scan(&personID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Grade{}, fmt.Errorf("person with first name %s not found: %w", firstName, err)
+		}
+		return models.Grade{}, fmt.Errorf("failed to get person with first name %s: %w", firstName, err)
+	}
+	before, err := getGrade(ctx, tx, personID, sectionID)
+	if err != nil {
+		return models.Grade{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE grade SET grade = $3, points = $4, passed = $5, graded_by = $6, graded_at = now()
+		WHERE person_id = $1 AND section_id = $2`, personID, sectionID, grade, points, passed, graderID)
+	if err != nil {
+		return models.Grade{}, fmt.Errorf("failed to amend grade of person with id %d in section %d: %w", personID, sectionID, err)
+	}
+	after, err := getGrade(ctx, tx, personID, sectionID)
+	if err != nil {
+		return models.Grade{}, err
+	}
+
+	if err = recordAudit(ctx, tx, AuditUpdate, EntityGrade, sectionID, before, after); err != nil {
+		return models.Grade{}, err
+	}
+	if err = publish(ctx, tx, EventGradeAmended, sectionID, after); err != nil {
+		return models.Grade{}, err
+	}
+	return after, nil
+}
+
+// checkGrader returns the id of the caller named in the audit info if they
+// are a professor teaching section, or else an error wrapping ErrForbidden.
+// The name is taken on trust; handlers only grade for admins.
+func checkGrader(ctx context.Context, q querier, section models.Section) (int, error) {
+	actor := callerInfo(ctx).actor
+	teachers := slices.Clone(section.Instructors)
+	if section.InstructorID != nil {
+		teachers = append(teachers, *section.InstructorID)
+	}
+
+	var id int
+	err := q.QueryRowContext(ctx, `
+		SELECT id FROM person
+		WHERE first_name = $1 AND type = 'professor' AND deleted_at IS NULL AND id = ANY($2)`,
+		actor, pq.Array(teachers)).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("%s does not teach section %d: %w", actor, section.ID, ErrForbidden)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get grader %s: %w", actor, err)
+	}
+	return id, nil
+}
+
+// dropStaleGrades removes the grades of a person in sections they are no
+// longer a student of, recording each in the audit log.
+func dropStaleGrades(ctx context.Context, tx *sql.Tx, personID int) error {
+	stale, err := queryGrades(ctx, tx, `g.person_id = $1 AND NOT EXISTS (
+			SELECT 1 FROM person_section ps
+			WHERE ps.person_id = g.person_id AND ps.section_id = g.section_id AND ps.role = '`+models.RoleStudent+`')`, personID)
+	if err != nil {
+		return err
+	}
+	for _, grade := range stale {
+		_, err := tx.ExecContext(ctx, "DELETE FROM grade WHERE person_id = $1 AND section_id = $2", personID, grade.SectionID)
+		if err != nil {
+			return fmt.Errorf("failed to drop grade of person with id %d in section %d: %w", personID, grade.SectionID, err)
+		}
+		if err = recordAudit(ctx, tx, AuditDelete, EntityGrade, grade.SectionID, grade, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getGrade returns the grade of a person in a section, or an error wrapping
+// sql.ErrNoRows if there is none.
+func getGrade(ctx context.Context, q querier, personID, sectionID int) (models.Grade, error) {
+	grades, err := queryGrades(ctx, q, "g.person_id = $1 AND g.section_id = $2", personID, sectionID)
+	if err != nil {
+		return models.Grade{}, err
+	}
+	if len(grades) == 0 {
+		return models.Grade{}, fmt.Errorf("person with id %d has no grade in section %d: %w", personID, sectionID, sql.ErrNoRows)
+	}
+	return grades[0], nil
+}
+
+// queryGrades returns the grades matching where, which can refer to the grade
+// g, its person p and its section s, ordered by first name.
+func queryGrades(ctx context.Context, q querier, where string, args ...any) ([]models.Grade, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT g.person_id, p.first_name, p.last_name, g.section_id, s.course_id, g.grade, g.points, g.passed, g.graded_by, g.graded_at
+		FROM grade g
+		JOIN person p ON p.id = g.person_id
+		JOIN section s ON s.id = g.section_id
+		WHERE `+where+`
+		ORDER BY p.first_name, g.person_id, g.section_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grades: %w", err)
+	}
+	defer rows.Close()
+
+	grades := []models.Grade{}
+	for rows.Next() {
+		var grade models.Grade
+		err := rows.Scan(&grade.PersonID, &grade.FirstName, &grade.LastName, &grade.SectionID, &grade.CourseID,
+			&grade.Grade, &grade.Points, &grade.Passed, &grade.GradedBy, &grade.GradedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan grade: %w", err)
+		}
+		grades = append(grades, grade)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan grades: %w", err)
+	}
+	return grades, nil
+}
+
+// Transcript returns the courses the person with id took as a student in
+// every term, with their grades, credits and GPAs. Only letter grades count
+// towards the GPA; credits are attempted once a final grade is recorded and
+// earned with a passing one. Courses that are deleted are left out. It returns
+// an error wrapping sql.ErrNoRows if there is no such person.
+func (g *GradeService) Transcript(ctx context.Context, personID int) (models.Transcript, error) {
+	transcript := models.Transcript{PersonID: personID, Terms: []models.TranscriptTerm{}}
+	err := g.DB.QueryRowContext(ctx, "SELECT first_name, last_name FROM person WHERE id = $1 AND deleted_at IS NULL", personID).
+		Scan(&transcript.FirstName, &transcript.LastName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Transcript{}, fmt.Errorf("[in services.Transcript] person with id %d not found: %w", personID, err)
+		}
+		return models.Transcript{}, fmt.Errorf("[in services.Transcript] failed to get person with id %d: %w", personID, err)
+	}
+
+	rows, err := g.DB.QueryContext(ctx, `
+		SELECT t.id, t.name, t.start_date, t.end_date, c.id, s.id, c.name, c.credits,
+			COALESCE(g.grade, ''), g.points, COALESCE(g.passed, false)
+		FROM person_section ps
+		JOIN section s ON s.id = ps.section_id
+		JOIN course c ON c.id = s.course_id AND c.deleted_at IS NULL
+		JOIN term t ON t.id = s.term_id
+		LEFT JOIN grade g ON g.person_id = ps.person_id AND g.section_id = ps.section_id
+		WHERE ps.person_id = $1 AND ps.role = '`+models.RoleStudent+`'
+		ORDER BY t.start_date, t.id, c.name, c.id`, personID)
+	if err != nil {
+		return models.Transcript{}, fmt.Errorf("[in services.Transcript] failed to get courses of person with id %d: %w", personID, err)
+	}
+	defer rows.Close()
+
+	b := transcriptBuilder{transcript: transcript}
+	for rows.Next() {
+		var (
+			term   models.TranscriptTerm
+			course models.TranscriptCourse
+			passed bool
+		)
+		err := rows.Scan(&term.TermID, &term.Name, &term.StartDate, &term.EndDate, &course.CourseID, &course.SectionID,
+			&course.Name, &course.Credits, &course.Grade, &course.Points, &passed)
+		if err != nil {
+			return models.Transcript{}, fmt.Errorf("[in services.Transcript] failed to scan course: %w", err)
+		}
+		b.add(term, course, passed)
+	}
+	if err = rows.Err(); err != nil {
+		return models.Transcript{}, fmt.Errorf("[in services.Transcript] failed to scan courses: %w", err)
+	}
+	return b.done(), nil
+}
+
+// transcriptBuilder totals the credits and GPAs of a transcript as its
+// courses are added in term order.
+type transcriptBuilder struct {
+	transcript          models.Transcript
+	termGPA, cumulative gpa
+}
+
+// add adds course, taken in term, passed or not. Courses of a term follow
+// each other.
+func (b *transcriptBuilder) add(term models.TranscriptTerm, course models.TranscriptCourse, passed bool) {
+	t := &b.transcript
+	if n := len(t.Terms); n == 0 || t.Terms[n-1].TermID != term.TermID {
+		term.Courses = []models.TranscriptCourse{}
+		t.Terms = append(t.Terms, term)
+		b.termGPA = gpa{}
+	}
+	current := &t.Terms[len(t.Terms)-1]
+	current.Courses = append(current.Courses, course)
+
+	if course.Grade != "" && course.Grade != models.GradeIncomplete {
+		current.CreditsAttempted += course.Credits
+		t.CreditsAttempted += course.Credits
+	}
+	if passed {
+		current.CreditsEarned += course.Credits
+		t.CreditsEarned += course.Credits
+	}
+	if course.Points != nil {
+		b.termGPA.add(*course.Points, course.Credits)
+		b.cumulative.add(*course.Points, course.Credits)
+	}
+	current.GPA = b.termGPA.value()
+	current.CumulativeGPA = b.cumulative.value()
+}
+
+func (b *transcriptBuilder) done() models.Transcript {
+	b.transcript.GPA = b.cumulative.value()
+	return b.transcript
+}
+
+// gpa accumulates grade points weighted by credits.
+type gpa struct {
+	points  float64
+	credits int
+}
+
+func (g *gpa) add(points float64, credits int) {
+	g.points += points * float64(credits)
+	g.credits += credits
+}
+
+// value returns the average rounded to two decimals, or nil if no letter
+// grade was added.
+func (g gpa) value() *float64 {
+	if g.credits == 0 {
+		return nil
+	}
+	v := math.Round(g.points/float64(g.credits)*100) / 100
+	return &v
+}
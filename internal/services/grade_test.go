@@ -0,0 +1,115 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+)
+
+var testScale = GradeScale{"A": 4, "B": 3, "C": 2, "F": 0}
+
+func TestGradeScaleValue(t *testing.T) {
+	tests := []struct {
+		grade  string
+		points *float64
+		passed bool
+		ok     bool
+	}{
+		{"A", ptr(4.0), true, true},
+		{"F", ptr(0.0), false, true},
+		{models.GradePass, nil, true, true},
+		{models.GradeNoPass, nil, false, true},
+		{models.GradeIncomplete, nil, false, true},
+		{"Z", nil, false, false},
+		{"", nil, false, false},
+	}
+	for _, tt := range tests {
+		points, passed, ok := testScale.value(tt.grade)
+		if !reflect.DeepEqual(points, tt.points) || passed != tt.passed || ok != tt.ok {
+			t.Errorf("value(%q) = %v, %t, %t, want %v, %t, %t", tt.grade, deref(points), passed, ok, deref(tt.points), tt.passed, tt.ok)
+		}
+	}
+}
+
+func TestTranscriptBuilder(t *testing.T) {
+	var b transcriptBuilder
+	add := func(termID, credits int, grade string) {
+		course := models.TranscriptCourse{Credits: credits, Grade: grade}
+		var passed bool
+		if grade != "" {
+			course.Points, passed, _ = testScale.value(grade)
+		}
+		b.add(models.TranscriptTerm{TermID: termID}, course, passed)
+	}
+
+	// Letter grades count towards the GPA; pass/fail grades only for credits.
+	// Ungraded courses count for neither.
+	add(1, 3, "A")
+	add(1, 4, "B")
+	add(1, 2, models.GradePass)
+	add(1, 3, "")
+	// Failing grades are attempted but not earned; incompletes are neither.
+	add(2, 3, "F")
+	add(2, 1, models.GradeNoPass)
+	add(2, 2, models.GradeIncomplete)
+	add(2, 2, "C")
+	// A term without letter grades has no GPA of its own.
+	add(3, 3, models.GradePass)
+
+	got := b.done()
+	tests := []struct {
+		name               string
+		attempted, earned  int
+		gpa, cumulativeGPA *float64
+	}{
+		{"term 1", 9, 9, ptr(3.43), ptr(3.43)},
+		{"term 2", 6, 2, ptr(0.8), ptr(2.33)},
+		{"term 3", 3, 3, nil, ptr(2.33)},
+	}
+	if len(got.Terms) != len(tests) {
+		t.Fatalf("got %d terms, want %d", len(got.Terms), len(tests))
+	}
+	for i, tt := range tests {
+		term := got.Terms[i]
+		if term.CreditsAttempted != tt.attempted || term.CreditsEarned != tt.earned {
+			t.Errorf("%s credits = %d attempted, %d earned, want %d, %d", tt.name, term.CreditsAttempted, term.CreditsEarned, tt.attempted, tt.earned)
+		}
+		if !reflect.DeepEqual(term.GPA, tt.gpa) || !reflect.DeepEqual(term.CumulativeGPA, tt.cumulativeGPA) {
+			t.Errorf("%s GPA = %v, cumulative %v, want %v, %v", tt.name, deref(term.GPA), deref(term.CumulativeGPA), deref(tt.gpa), deref(tt.cumulativeGPA))
+		}
+	}
+	if len(got.Terms[0].Courses) != 4 || len(got.Terms[1].Courses) != 4 || len(got.Terms[2].Courses) != 1 {
+		t.Errorf("courses per term = %d, %d, %d, want 4, 4, 1", len(got.Terms[0].Courses), len(got.Terms[1].Courses), len(got.Terms[2].Courses))
+	}
+	if got.CreditsAttempted != 18 || got.CreditsEarned != 14 {
+		t.Errorf("credits = %d attempted, %d earned, want 18, 14", got.CreditsAttempted, got.CreditsEarned)
+	}
+	if !reflect.DeepEqual(got.GPA, ptr(2.33)) {
+		t.Errorf("GPA = %v, want 2.33", deref(got.GPA))
+	}
+}
+
+func TestTranscriptBuilderWithoutGrades(t *testing.T) {
+	b := transcriptBuilder{transcript: models.Transcript{Terms: []models.TranscriptTerm{}}}
+	b.add(models.TranscriptTerm{TermID: 1}, models.TranscriptCourse{Credits: 3}, false)
+	got := b.done()
+	if got.GPA != nil || got.Terms[0].GPA != nil || got.Terms[0].CumulativeGPA != nil {
+		t.Errorf("GPAs = %v, %v, %v, want nil until a letter grade is recorded", deref(got.GPA), deref(got.Terms[0].GPA), deref(got.Terms[0].CumulativeGPA))
+	}
+	if got.CreditsAttempted != 0 || got.CreditsEarned != 0 {
+		t.Errorf("credits = %d attempted, %d earned, want 0, 0", got.CreditsAttempted, got.CreditsEarned)
+	}
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+// deref shows a GPA in failure messages.
+func deref(v *float64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+)
+
+// Domain event types written to the outbox.
+const (
+	EventPersonCreated       = "person.created"
+	EventPersonUpdated       = "person.updated"
+	EventPersonDeleted       = "person.deleted"
+	EventPersonRestored      = "person.restored"
+	EventCourseCreated       = "course.created"
+	EventCourseUpdated       = "course.updated"
+	EventCourseDeleted       = "course.deleted"
+	EventCourseRestored      = "course.restored"
+	EventTermCreated         = "term.created"
+	EventTermUpdated         = "term.updated"
+	EventTermDeleted         = "term.deleted"
+	EventSectionCreated      = "section.created"
+	EventSectionUpdated      = "section.updated"
+	EventSectionDeleted      = "section.deleted"
+	EventEnrollmentAdded     = "enrollment.added"
+	EventEnrollmentRemoved   = "enrollment.removed"
+	EventPrerequisiteAdded   = "prerequisite.added"
+	EventPrerequisiteRemoved = "prerequisite.removed"
+	EventGradeSubmitted      = "grade.submitted"
+	EventGradeAmended        = "grade.amended"
+	EventRoomCreated         = "room.created"
+	EventRoomUpdated         = "room.updated"
+	EventRoomDeleted         = "room.deleted"
+	EventDepartmentCreated   = "department.created"
+	EventDepartmentUpdated   = "department.updated"
+	EventDepartmentDeleted   = "department.deleted"
+)
+
+// EventTypes lists every domain event type, for validating subscriptions.
+var EventTypes = []string{
+	EventPersonCreated, EventPersonUpdated, EventPersonDeleted, EventPersonRestored,
+	EventCourseCreated, EventCourseUpdated, EventCourseDeleted, EventCourseRestored,
+	EventTermCreated, EventTermUpdated, EventTermDeleted,
+	EventSectionCreated, EventSectionUpdated, EventSectionDeleted,
+	EventEnrollmentAdded, EventEnrollmentRemoved,
+	EventPrerequisiteAdded, EventPrerequisiteRemoved,
+	EventGradeSubmitted, EventGradeAmended,
+	EventRoomCreated, EventRoomUpdated, EventRoomDeleted,
+	EventDepartmentCreated, EventDepartmentUpdated, EventDepartmentDeleted,
+}
+
+// enrollment is the data of enrollment events.
+type enrollment struct {
+	PersonID  int `json:"person_id"`
+	CourseID  int `json:"course_id"`
+	SectionID int `json:"section_id"`
+}
+
+// prerequisite is the data of prerequisite events.
+type prerequisite struct {
+	CourseID       int `json:"course_id"`
+	PrerequisiteID int `json:"prerequisite_id"`
+}
+
+// publish writes a domain event to the outbox in the caller's transaction, so
+// it is delivered if and only if the change is committed. entityID is the
+// person or course the event is about; data is stored as JSON.
+func publish(ctx context.Context, q querier, eventType string, entityID int, data any) error {
+	info := callerInfo(ctx)
+
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("[in services.publish] failed to encode %s data: %w", eventType, err)
+	}
+
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO outbox_event (type, actor, request_id, entity_id, data)
+		VALUES ($1, $2, $3, $4, $5)`,
+		eventType, info.actor, info.requestID, entityID, dataJSON)
+	if err != nil {
+		return fmt.Errorf("[in services.publish] failed to write %s event for %d: %w", eventType, entityID, err)
+	}
+	return nil
+}
+
+// publishEnrollmentChanges publishes enrollment.added and enrollment.removed
+// events for the difference between the sections of a person's old and new
+// enrollments. Role changes are not published.
+func publishEnrollmentChanges(ctx context.Context, q querier, personID int, before, after []models.Enrollment) error {
+	had := make(map[int]bool, len(before))
+	for _, e := range before {
+		had[e.SectionID] = true
+	}
+	has := make(map[int]bool, len(after))
+	for _, e := range after {
+		has[e.SectionID] = true
+		if !had[e.SectionID] {
+			if err := publish(ctx, q, EventEnrollmentAdded, personID, enrollment{PersonID: personID, CourseID: e.CourseID, SectionID: e.SectionID}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, e := range before {
+		if !has[e.SectionID] {
+			if err := publish(ctx, q, EventEnrollmentRemoved, personID, enrollment{PersonID: personID, CourseID: e.CourseID, SectionID: e.SectionID}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// OutboxService reads the outbox as an ordered event log, for streaming
+// changes to clients. Events are ordered by position, which is taken as their
+// transaction commits, so an event is never read after one with a higher
+// position; ids are taken in insert order and may commit out of order.
+type OutboxService struct {
+	DB *sql.DB
+}
+
+func NewOutboxService(db *sql.DB) *OutboxService {
+	return &OutboxService{
+		DB: db,
+	}
+}
+
+// LatestPosition returns the position of the newest event, or 0 if there is
+// none.
+func (o *OutboxService) LatestPosition(ctx context.Context) (int64, error) {
+	var position int64
+	err := o.DB.QueryRowContext(ctx, "SELECT COALESCE(max(position), 0) FROM outbox_event").Scan(&position)
+	if err != nil {
+		return 0, fmt.Errorf("[in services.LatestPosition] failed to get latest event position: %w", err)
+	}
+	return position, nil
+}
+
+// EventsAfter returns up to limit events with a position greater than
+// afterPosition, in position order.
+func (o *OutboxService) EventsAfter(ctx context.Context, afterPosition int64, limit int) ([]models.OutboxEvent, error) {
+	rows, err := o.DB.QueryContext(ctx, `
+		SELECT id, position, type, occurred_at, actor, request_id, entity_id, data
+		FROM outbox_event
+		WHERE position > $1
+		ORDER BY position
+		LIMIT $2`, afterPosition, limit)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.EventsAfter] failed to get events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.OutboxEvent
+	for rows.Next() {
+		var (
+			event models.OutboxEvent
+			data  []byte
+		)
+		err := rows.Scan(&event.ID, &event.Position, &event.Type, &event.OccurredAt, &event.Actor, &event.RequestID, &event.EntityID, &data)
+		if err != nil {
+			return nil, fmt.Errorf("[in services.EventsAfter] failed to scan event from row: %w", err)
+		}
+		event.Data = data
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.EventsAfter] failed to scan events: %w", err)
+	}
+
+	return events, nil
+}
@@ -3,23 +3,48 @@ package services
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/rules"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+	"github.com/lib/pq"
 )
 
+// PersonService manages persons and their enrollments. Enrollment changes are
+// checked against Rules.
 type PersonService struct {
-	DB *sql.DB
+	DB    *sql.DB
+	Rules rules.Set
 }
 
-func NewPersonService(db *sql.DB) *PersonService {
+func NewPersonService(db *sql.DB, eligibility rules.Set) *PersonService {
 	return &PersonService{
-		DB: db,
+		DB:    db,
+		Rules: eligibility,
 	}
 }
 
-func (p *PersonService) ListPersons(ctx context.Context) ([]models.Person, error) {
-	rows, err := p.DB.Query("SELECT id, first_name, last_name, type, age FROM person ORDER BY id")
+// ListPersons returns the persons matching filter with their course ids,
+// sections and roles, which are aggregated in the same query, in the order of
+// their ids.
+func (p *PersonService) ListPersons(ctx context.Context, filter PersonFilter) ([]models.Person, error) {
+	where, args := filter.where()
+	limit, args := limitClause(filter.Limit, args)
+	rel := filter.relations()
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT `+personColumns(rel.today)+`, p.deleted_at,
+			COALESCE(array_agg(c.id ORDER BY c.id) FILTER (WHERE c.id IS NOT NULL), '{}'),
+			COALESCE(array_agg(pc.section_id ORDER BY c.id) FILTER (WHERE c.id IS NOT NULL), '{}'),
+			COALESCE(array_agg(pc.role ORDER BY c.id) FILTER (WHERE c.id IS NOT NULL), '{}')
+		FROM `+rel.person+` p
+		LEFT JOIN `+rel.personCourse+` pc ON pc.person_id = p.id
+		LEFT JOIN `+rel.course+` c ON c.id = pc.course_id AND c.deleted_at IS NULL`+where+`
+		GROUP BY p.id, p.first_name, p.last_name, p.type, p.date_of_birth, p.email, p.phone, p.address, p.deleted_at
+		ORDER BY p.id`+limit, args...)
 	if err != nil {
 		return nil, fmt.Errorf("[in services.ListPersons] failed to get persons: %w", err)
 	}
@@ -27,18 +52,17 @@ func (p *PersonService) ListPersons(ctx context.Context) ([]models.Person, error
 
 	var persons []models.Person
 	for rows.Next() {
-		var person models.Person
-		err := rows.Scan(&person.ID, &person.FirstName, &person.LastName, &person.Type, &person.Age)
+		var (
+			person     models.Person
+			courseIDs  pq.Int64Array
+			sectionIDs pq.Int64Array
+			roles      pq.StringArray
+		)
+		err := rows.Scan(append(personDest(&person), &person.DeletedAt, &courseIDs, &sectionIDs, &roles)...)
 		if err != nil {
 			return nil, fmt.Errorf("[in services.ListPersons] failed to scan person from row: %w", err)
 		}
-
-		// Fetch courses for the current person
-		courseIDs, err := p.getCoursesForPerson(person.ID)
-		if err != nil {
-			return nil, err
-		}
-		person.Courses = courseIDs
+		setEnrollments(&person, zipEnrollments(courseIDs, sectionIDs, roles))
 
 		persons = append(persons, person)
 	}
@@ -50,184 +74,1130 @@ func (p *PersonService) ListPersons(ctx context.Context) ([]models.Person, error
 	return persons, nil
 }
 
+// ListPersonsByCourses returns the persons enrolled in each of the courses in
+// the current term, keyed by course id, with all of their enrollments.
+// Deleted persons and courses are left out. It loads every course in one
+// query so callers resolving many courses at once avoid a query per course.
+func (p *PersonService) ListPersonsByCourses(ctx context.Context, courseIDs []int) (map[int][]models.Person, error) {
+	rel := relationsAt(time.Time{}, "")
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT pc.course_id, `+personColumns(rel.today)+`,
+			COALESCE(e.course_ids, '{}'), COALESCE(e.section_ids, '{}'), COALESCE(e.roles, '{}')
+		FROM `+rel.personCourse+` pc
+		JOIN person p ON p.id = pc.person_id AND p.deleted_at IS NULL
+		JOIN course c ON c.id = pc.course_id AND c.deleted_at IS NULL
+		CROSS JOIN LATERAL (
+			SELECT array_agg(all_pc.course_id ORDER BY all_pc.course_id) AS course_ids,
+				array_agg(all_pc.section_id ORDER BY all_pc.course_id) AS section_ids,
+				array_agg(all_pc.role ORDER BY all_pc.course_id) AS roles
+			FROM `+rel.personCourse+` all_pc
+			JOIN course all_c ON all_c.id = all_pc.course_id AND all_c.deleted_at IS NULL
+			WHERE all_pc.person_id = p.id
+		) e
+		WHERE pc.course_id = ANY($1)
+		ORDER BY pc.course_id, p.id`, pq.Array(courseIDs))
+	if err != nil {
+		return nil, fmt.Errorf("[in services.ListPersonsByCourses] failed to get persons: %w", err)
+	}
+	defer rows.Close()
+
+	persons := make(map[int][]models.Person, len(courseIDs))
+	for rows.Next() {
+		var (
+			courseID   int
+			person     models.Person
+			courseIDs  pq.Int64Array
+			sectionIDs pq.Int64Array
+			roles      pq.StringArray
+		)
+		err := rows.Scan(append(append([]any{&courseID}, personDest(&person)...), &courseIDs, &sectionIDs, &roles)...)
+		if err != nil {
+			return nil, fmt.Errorf("[in services.ListPersonsByCourses] failed to scan person from row: %w", err)
+		}
+		setEnrollments(&person, zipEnrollments(courseIDs, sectionIDs, roles))
+		persons[courseID] = append(persons[courseID], person)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.ListPersonsByCourses] failed to scan persons: %w", err)
+	}
+
+	return persons, nil
+}
+
 func (p *PersonService) GetPersonByFirstName(ctx context.Context, firstName string) (models.Person, error) {
+	return p.GetPersonByFirstNameAsOf(ctx, firstName, time.Time{})
+}
+
+// GetPersonByFirstNameAsOf returns the person and their enrollments as they
+// were at asOf, or as they are now if asOf is zero.
+func (p *PersonService) GetPersonByFirstNameAsOf(ctx context.Context, firstName string, asOf time.Time) (models.Person, error) {
+	args := []any{firstName}
+	if !asOf.IsZero() {
+		args = append(args, asOf)
+	}
+
 	var person models.Person
-	err := p.DB.QueryRow("SELECT id, first_name, last_name, type, age FROM person WHERE first_name = $1", firstName).Scan(&person.ID, &person.FirstName, &person.LastName, &person.Type, &person.Age)
+	rel := relationsAt(asOf, "$2")
+	err := p.DB.QueryRowContext(ctx, "SELECT "+personColumns(rel.today)+" FROM "+rel.person+" p WHERE p.first_name = $1 AND p.deleted_at IS NULL", args...).
+		Scan(personDest(&person)...)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return models.Person{}, fmt.Errorf("[in services.GetPersonByFirstName] person with first name %s not found: %w", firstName, err)
+			return models.Person{}, fmt.Errorf("[in services.GetPersonByFirstNameAsOf] person with first name %s not found: %w", firstName, err)
 		}
-		return models.Person{}, fmt.Errorf("[in services.GetPersonByFirstName] failed to get person with first name %s: %w", firstName, err)
+		return models.Person{}, fmt.Errorf("[in services.GetPersonByFirstNameAsOf] failed to get person with first name %s: %w", firstName, err)
 	}
 
 	// Fetch courses for the person
-	courseIDs, err := p.getCoursesForPerson(person.ID)
+	enrollments, err := queryEnrollmentsForPersonAsOf(ctx, p.DB, person.ID, asOf)
 	if err != nil {
 		return models.Person{}, err
 	}
-	person.Courses = courseIDs
+	setEnrollments(&person, enrollments)
 
 	return person, nil
 }
 
 func (p *PersonService) UpdatePerson(ctx context.Context, firstName string, updatedPerson models.Person) (models.Person, error) {
-	// Validate the updated person object
-	if updatedPerson.FirstName == "" || updatedPerson.LastName == "" || updatedPerson.Type == "" || updatedPerson.Age <= 0 {
-		return models.Person{}, fmt.Errorf("[in services.UpdatePerson] invalid person data")
-	}
-
 	tx, err := p.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return models.Person{}, fmt.Errorf("[in services.UpdatePerson] failed to begin transaction: %w", err)
 	}
 
-	// Fetch the person_id using the old firstName
-	var personID int
-	err = tx.QueryRowContext(ctx, "SELECT id FROM person WHERE first_name = $1", firstName).Scan(&personID)
+	updatedPerson, err = updatePerson(ctx, tx, p.Rules, firstName, updatedPerson)
 	if err != nil {
 		tx.Rollback()
-		return models.Person{}, fmt.Errorf("[in services.UpdatePerson] failed to fetch person with first name %s: %w", firstName, err)
+		return models.Person{}, fmt.Errorf("[in services.UpdatePerson] %w", err)
 	}
 
-	// Update the person details
-	_, err = tx.ExecContext(ctx, "UPDATE person SET first_name = $1, last_name = $2, type = $3, age = $4 WHERE id = $5",
-		updatedPerson.FirstName, updatedPerson.LastName, updatedPerson.Type, updatedPerson.Age, personID)
+	// Commit the transaction
+	err = tx.Commit()
 	if err != nil {
-		tx.Rollback()
-		return models.Person{}, fmt.Errorf("[in services.UpdatePerson] failed to update person with id %d: %w", personID, err)
+		return models.Person{}, fmt.Errorf("[in services.UpdatePerson] failed to commit transaction: %w", err)
 	}
 
-	// Clear existing courses
-	_, err = tx.ExecContext(ctx, "DELETE FROM person_course WHERE person_id = $1", personID)
+	return updatedPerson, nil
+}
+
+func (p *PersonService) CreatePerson(ctx context.Context, person models.Person) (models.Person, error) {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Person{}, fmt.Errorf("[in services.CreatePerson] failed to begin transaction: %w", err)
+	}
+
+	createdPerson, err := createPerson(ctx, tx, p.Rules, person)
 	if err != nil {
 		tx.Rollback()
-		return models.Person{}, fmt.Errorf("[in services.UpdatePerson] failed to clear existing courses for person with id %d: %w", personID, err)
+		return models.Person{}, fmt.Errorf("[in services.CreatePerson] %w", err)
 	}
 
-	// Associate new courses
-	for _, courseID := range updatedPerson.Courses {
-		_, err = tx.ExecContext(ctx, "INSERT INTO person_course (person_id, course_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", personID, courseID)
-		if err != nil {
-			tx.Rollback()
-			return models.Person{}, fmt.Errorf("[in services.UpdatePerson] failed to associate new courses with person id %d: %w", personID, err)
-		}
+	err = tx.Commit()
+	if err != nil {
+		return models.Person{}, fmt.Errorf("[in services.CreatePerson] failed to commit transaction: %w", err)
+	}
+
+	return createdPerson, nil
+}
+
+func (p *PersonService) DeletePerson(ctx context.Context, firstName string) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("[in services.DeletePerson] failed to begin transaction: %w", err)
+	}
+
+	if err = deletePerson(ctx, tx, p.Rules, firstName); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("[in services.DeletePerson] %w", err)
 	}
 
 	// Commit the transaction
 	err = tx.Commit()
 	if err != nil {
-		return models.Person{}, fmt.Errorf("[in services.UpdatePerson] failed to commit transaction: %w", err)
+		return fmt.Errorf("[in services.DeletePerson] failed to commit transaction: %w", err)
 	}
 
-	return updatedPerson, nil
+	return nil
 }
 
-func (p *PersonService) CreatePerson(ctx context.Context, person models.Person) (models.Person, error) {
-	var newID int
-	tx, err := p.DB.BeginTx(ctx, nil)
+// updatePerson replaces the details and current term enrollments of the
+// person with firstName in the caller's transaction.
+func updatePerson(ctx context.Context, tx *sql.Tx, set rules.Set, firstName string, updatedPerson models.Person) (models.Person, error) {
+	// Validate the updated person object
+	if updatedPerson.FirstName == "" || updatedPerson.LastName == "" || updatedPerson.Type == "" || updatedPerson.DateOfBirth.IsZero() {
+		return models.Person{}, fmt.Errorf("invalid person data")
+	}
+
+	// Fetch the current state of the person using the old firstName
+	before, err := getPersonForUpdate(ctx, tx, firstName)
 	if err != nil {
-		return models.Person{}, fmt.Errorf("[in services.CreatePerson] failed to begin transaction: %w", err)
+		return models.Person{}, err
+	}
+	personID := before.ID
+	if err = lockWaitlisted(ctx, tx, studentSections(before.Enrollments)); err != nil {
+		return models.Person{}, err
+	}
+	if err = checkPerson(ctx, tx, personID, &updatedPerson); err != nil {
+		return models.Person{}, err
 	}
 
-	err = tx.QueryRowContext(ctx, "INSERT INTO person (first_name, last_name, type, age) VALUES ($1, $2, $3, $4) RETURNING id", person.FirstName, person.LastName, person.Type, person.Age).Scan(&newID)
+	// Check the requested courses, roles and seats before changing anything
+	enrollments, err := resolveEnrollments(ctx, tx, set, personID, updatedPerson)
 	if err != nil {
-		tx.Rollback()
-		return models.Person{}, fmt.Errorf("[in services.CreatePerson] failed to create person: %w", err)
+		return models.Person{}, err
+	}
+	setEnrollments(&updatedPerson, enrollments)
+	if updatedPerson.Type != "professor" {
+		if err = checkNotInstructing(ctx, tx, personID); err != nil {
+			return models.Person{}, err
+		}
+		if err = checkNotChairing(ctx, tx, personID); err != nil {
+			return models.Person{}, err
+		}
+	}
+
+	// Clear existing courses of the current term. Enrollments in deleted
+	// courses are kept so they come back if the course is restored.
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM person_section ps
+		USING section s, course c
+		WHERE s.id = ps.section_id AND c.id = s.course_id AND ps.person_id = $1
+		  AND c.deleted_at IS NULL AND s.term_id = `+currentTerm("CURRENT_DATE"), personID)
+	if err != nil {
+		return models.Person{}, fmt.Errorf("failed to clear existing courses for person with id %d: %w", personID, err)
 	}
 
-	for _, courseID := range person.Courses {
-		_, err = tx.ExecContext(ctx, "INSERT INTO person_course (person_id, course_id) VALUES ($1, $2)", newID, courseID)
+	// Update the person details once they no longer hold roles their new
+	// type may not
+	_, err = tx.ExecContext(ctx, `
+		UPDATE person SET first_name = $1, last_name = $2, type = $3, date_of_birth = $4, email = NULLIF($5, ''), phone = $6, address = $7
+		WHERE id = $8`,
+		updatedPerson.FirstName, updatedPerson.LastName, updatedPerson.Type, updatedPerson.DateOfBirth,
+		updatedPerson.Email, updatedPerson.Phone, updatedPerson.Address, personID)
+	if err != nil {
+		if taken := emailTaken(err, updatedPerson.Email); taken != nil {
+			return models.Person{}, taken
+		}
+		return models.Person{}, fmt.Errorf("failed to update person with id %d: %w", personID, err)
+	}
+
+	// Associate new courses
+	for _, enrollment := range updatedPerson.Enrollments {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO person_section (person_id, section_id, role) VALUES ($1, $2, $3)
+			ON CONFLICT (person_id, section_id) DO UPDATE SET role = EXCLUDED.role`, personID, enrollment.SectionID, enrollment.Role)
 		if err != nil {
-			tx.Rollback()
-			return models.Person{}, fmt.Errorf("[in services.CreatePerson] failed to associate course with person: %w", err)
+			return models.Person{}, fmt.Errorf("failed to associate new courses with person id %d: %w", personID, err)
 		}
 	}
+	if err = leaveFilledWaitlists(ctx, tx, personID); err != nil {
+		return models.Person{}, err
+	}
+	if err = dropStaleGrades(ctx, tx, personID); err != nil {
+		return models.Person{}, err
+	}
 
-	err = tx.Commit()
+	updatedPerson.ID = personID
+	if err = recordAudit(ctx, tx, AuditUpdate, EntityPerson, personID, before, updatedPerson); err != nil {
+		return models.Person{}, err
+	}
+	if err = publish(ctx, tx, EventPersonUpdated, personID, updatedPerson); err != nil {
+		return models.Person{}, err
+	}
+	if err = publishEnrollmentChanges(ctx, tx, personID, before.Enrollments, updatedPerson.Enrollments); err != nil {
+		return models.Person{}, err
+	}
+	if err = promoteFreedSeats(ctx, tx, set, before.Enrollments, updatedPerson.Enrollments); err != nil {
+		return models.Person{}, err
+	}
+	return updatedPerson, nil
+}
+
+// createPerson inserts a person and their enrollments in the caller's
+// transaction.
+func createPerson(ctx context.Context, tx *sql.Tx, set rules.Set, person models.Person) (models.Person, error) {
+	if err := checkPerson(ctx, tx, 0, &person); err != nil {
+		return models.Person{}, err
+	}
+
+	// Check the requested courses, roles and seats before inserting anything
+	enrollments, err := resolveEnrollments(ctx, tx, set, 0, person)
 	if err != nil {
-		return models.Person{}, fmt.Errorf("[in services.CreatePerson] failed to commit transaction: %w", err)
+		return models.Person{}, err
+	}
+	setEnrollments(&person, enrollments)
+
+	var newID int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO person (first_name, last_name, type, date_of_birth, email, phone, address)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7) RETURNING id`,
+		person.FirstName, person.LastName, person.Type, person.DateOfBirth, person.Email, person.Phone, person.Address).Scan(&newID)
+	if err != nil {
+		if taken := emailTaken(err, person.Email); taken != nil {
+			return models.Person{}, taken
+		}
+		return models.Person{}, fmt.Errorf("failed to create person: %w", err)
+	}
+
+	for _, enrollment := range person.Enrollments {
+		_, err = tx.ExecContext(ctx, "INSERT INTO person_section (person_id, section_id, role) VALUES ($1, $2, $3)", newID, enrollment.SectionID, enrollment.Role)
+		if err != nil {
+			return models.Person{}, fmt.Errorf("failed to associate course with person: %w", err)
+		}
 	}
 
 	createdPerson := models.Person{
-		ID:        newID,
-		FirstName: person.FirstName,
-		LastName:  person.LastName,
-		Type:      person.Type,
-		Age:       person.Age,
-		Courses:   person.Courses,
+		ID:          newID,
+		FirstName:   person.FirstName,
+		LastName:    person.LastName,
+		Type:        person.Type,
+		DateOfBirth: person.DateOfBirth,
+		Age:         person.Age,
+		Email:       person.Email,
+		Phone:       person.Phone,
+		Address:     person.Address,
+		Courses:     person.Courses,
+		Enrollments: person.Enrollments,
 	}
 
+	if err = recordAudit(ctx, tx, AuditCreate, EntityPerson, newID, nil, createdPerson); err != nil {
+		return models.Person{}, err
+	}
+	if err = publish(ctx, tx, EventPersonCreated, newID, createdPerson); err != nil {
+		return models.Person{}, err
+	}
+	if err = publishEnrollmentChanges(ctx, tx, newID, nil, createdPerson.Enrollments); err != nil {
+		return models.Person{}, err
+	}
 	return createdPerson, nil
 }
 
-func (p *PersonService) DeletePerson(ctx context.Context, firstName string) error {
+// deletePerson soft deletes the person with firstName in the caller's
+// transaction. Their waitlist places are given up and their student seats, in
+// every term, go to the next persons in line who meet the checks of
+// AddEnrollment and the rules of set.
+func deletePerson(ctx context.Context, tx *sql.Tx, set rules.Set, firstName string) error {
+	// Fetch the current state of the person using the firstName
+	before, err := getPersonForUpdate(ctx, tx, firstName)
+	if err != nil {
+		return err
+	}
+	personID := before.ID
+	seats, err := allStudentSections(ctx, tx, personID)
+	if err != nil {
+		return err
+	}
+	if err = lockWaitlisted(ctx, tx, seats); err != nil {
+		return err
+	}
+
+	// Soft delete: enrollments are kept so RestorePerson can bring them back
+	var deletedAt time.Time
+	err = tx.QueryRowContext(ctx, "UPDATE person SET deleted_at = now() WHERE id = $1 RETURNING deleted_at", personID).Scan(&deletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to delete person with id %d: %w", personID, err)
+	}
+	after := before
+	after.DeletedAt = &deletedAt
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM section_waitlist WHERE person_id = $1", personID); err != nil {
+		return fmt.Errorf("failed to remove person with id %d from waitlists: %w", personID, err)
+	}
+
+	if err = recordAudit(ctx, tx, AuditDelete, EntityPerson, personID, before, after); err != nil {
+		return err
+	}
+	if err = publish(ctx, tx, EventPersonDeleted, personID, after); err != nil {
+		return err
+	}
+	return promoteSections(ctx, tx, set, seats)
+}
+
+// RestorePerson undoes a soft delete by id. The person's enrollments were
+// kept, so they reappear with them, even in sections that have since filled
+// up.
+func (p *PersonService) RestorePerson(ctx context.Context, id int) (models.Person, error) {
 	tx, err := p.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("[in services.DeletePerson] failed to begin transaction: %w", err)
+		return models.Person{}, fmt.Errorf("[in services.RestorePerson] failed to begin transaction: %w", err)
+	}
+
+	var before models.Person
+	err = tx.QueryRowContext(ctx, "SELECT "+personColumns("CURRENT_DATE")+", p.deleted_at FROM person p WHERE p.id = $1 AND p.deleted_at IS NOT NULL FOR UPDATE", id).
+		Scan(append(personDest(&before), &before.DeletedAt)...)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return models.Person{}, fmt.Errorf("[in services.RestorePerson] deleted person with id %d not found: %w", id, err)
+		}
+		return models.Person{}, fmt.Errorf("[in services.RestorePerson] failed to get person with id %d: %w", id, err)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE person SET deleted_at = NULL WHERE id = $1", id)
+	if err != nil {
+		tx.Rollback()
+		return models.Person{}, fmt.Errorf("[in services.RestorePerson] failed to restore person with id %d: %w", id, err)
 	}
 
-	// Fetch the person_id using the firstName
-	var personID int
-	err = tx.QueryRowContext(ctx, "SELECT id FROM person WHERE first_name = $1", firstName).Scan(&personID)
+	enrollments, err := queryEnrollmentsForPerson(ctx, tx, id)
 	if err != nil {
 		tx.Rollback()
-		return fmt.Errorf("[in services.DeletePerson] failed to fetch person with first name %s: %w", firstName, err)
+		return models.Person{}, err
+	}
+	setEnrollments(&before, enrollments)
+	after := before
+	after.DeletedAt = nil
+
+	if err = recordAudit(ctx, tx, AuditRestore, EntityPerson, id, before, after); err != nil {
+		tx.Rollback()
+		return models.Person{}, fmt.Errorf("[in services.RestorePerson] %w", err)
+	}
+	if err = publish(ctx, tx, EventPersonRestored, id, after); err != nil {
+		tx.Rollback()
+		return models.Person{}, fmt.Errorf("[in services.RestorePerson] %w", err)
 	}
 
-	// Clear associated courses first
-	_, err = tx.ExecContext(ctx, "DELETE FROM person_course WHERE person_id = $1", personID)
+	if err = tx.Commit(); err != nil {
+		return models.Person{}, fmt.Errorf("[in services.RestorePerson] failed to commit transaction: %w", err)
+	}
+
+	return after, nil
+}
+
+// PurgePersons permanently removes persons soft deleted before cutoff,
+// together with their enrollments and grades, leaves the sections they taught
+// and departments they chaired without one, and returns how many were
+// removed.
+func (p *PersonService) PurgePersons(ctx context.Context, cutoff time.Time) (int, error) {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("[in services.PurgePersons] failed to begin transaction: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM grade
+		WHERE person_id IN (SELECT id FROM person WHERE deleted_at < $1)`, cutoff)
 	if err != nil {
 		tx.Rollback()
-		return fmt.Errorf("[in services.DeletePerson] failed to clear associated courses for person with id %d: %w", personID, err)
+		return 0, fmt.Errorf("[in services.PurgePersons] failed to delete grades: %w", err)
 	}
 
-	// Then delete the person
-	result, err := tx.ExecContext(ctx, "DELETE FROM person WHERE id = $1", personID)
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM person_section
+		WHERE person_id IN (SELECT id FROM person WHERE deleted_at < $1)`, cutoff)
 	if err != nil {
 		tx.Rollback()
-		return fmt.Errorf("[in services.DeletePerson] failed to delete person with id %d: %w", personID, err)
+		return 0, fmt.Errorf("[in services.PurgePersons] failed to delete enrollments: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM section_waitlist
+		WHERE person_id IN (SELECT id FROM person WHERE deleted_at < $1)`, cutoff)
 	if err != nil {
 		tx.Rollback()
-		return fmt.Errorf("[in services.DeletePerson] failed to get rows affected: %w", err)
+		return 0, fmt.Errorf("[in services.PurgePersons] failed to delete waitlist places: %w", err)
 	}
 
-	if rowsAffected == 0 {
+	_, err = tx.ExecContext(ctx, `
+		UPDATE section SET instructor_id = NULL
+		WHERE instructor_id IN (SELECT id FROM person WHERE deleted_at < $1)`, cutoff)
+	if err != nil {
 		tx.Rollback()
-		return fmt.Errorf("[in services.DeletePerson] person with id %d not found", personID)
+		return 0, fmt.Errorf("[in services.PurgePersons] failed to unassign sections: %w", err)
 	}
 
-	// Commit the transaction
-	err = tx.Commit()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE department SET chair_id = NULL
+		WHERE chair_id IN (SELECT id FROM person WHERE deleted_at < $1)`, cutoff)
 	if err != nil {
-		return fmt.Errorf("[in services.DeletePerson] failed to commit transaction: %w", err)
+		tx.Rollback()
+		return 0, fmt.Errorf("[in services.PurgePersons] failed to unassign department chairs: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, "DELETE FROM person p WHERE p.deleted_at < $1 RETURNING "+personColumns("CURRENT_DATE")+", p.deleted_at", cutoff)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("[in services.PurgePersons] failed to delete persons: %w", err)
+	}
+	var purged []models.Person
+	for rows.Next() {
+		var person models.Person
+		if err := rows.Scan(append(personDest(&person), &person.DeletedAt)...); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return 0, fmt.Errorf("[in services.PurgePersons] failed to scan purged person: %w", err)
+		}
+		purged = append(purged, person)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("[in services.PurgePersons] failed to scan purged persons: %w", err)
+	}
+
+	for _, person := range purged {
+		if err = recordAudit(ctx, tx, AuditPurge, EntityPerson, person.ID, person, nil); err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("[in services.PurgePersons] %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("[in services.PurgePersons] failed to commit transaction: %w", err)
+	}
+
+	return len(purged), nil
+}
+
+// AddEnrollment enrolls the person with firstName in a course that exists
+// and is not deleted with role, or with the default role for their type if
+// role is empty. They are enrolled in the course's section in the current
+// term, see courseSections. Enrolling a person again only changes their role,
+// and only if one is given. A student seat in a full section or in a course
+// whose prerequisites the person has not completed, and an enrollment that
+// breaks an eligibility rule, are rejected with a *ValidationError; see
+// JoinWaitlist, WithPrerequisiteOverride and WithRuleOverride.
+func (p *PersonService) AddEnrollment(ctx context.Context, firstName string, courseID int, role string) (models.Person, error) {
+	return p.inTx(ctx, "AddEnrollment", func(tx *sql.Tx) (models.Person, error) {
+		return addEnrollment(ctx, tx, p.Rules, firstName, courseID, role)
+	})
+}
+
+// RemoveEnrollment withdraws the person with firstName from their section of
+// a course in the current term, giving their seat to the next person on its
+// waitlist. It returns an error wrapping sql.ErrNoRows if they are not
+// enrolled in it.
+func (p *PersonService) RemoveEnrollment(ctx context.Context, firstName string, courseID int) (models.Person, error) {
+	return p.inTx(ctx, "RemoveEnrollment", func(tx *sql.Tx) (models.Person, error) {
+		return removeEnrollment(ctx, tx, p.Rules, firstName, courseID)
+	})
+}
+
+// inTx runs fn in a new transaction, committing it if fn succeeds.
+func (p *PersonService) inTx(ctx context.Context, method string, fn func(tx *sql.Tx) (models.Person, error)) (models.Person, error) {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Person{}, fmt.Errorf("[in services.%s] failed to begin transaction: %w", method, err)
+	}
+
+	person, err := fn(tx)
+	if err != nil {
+		tx.Rollback()
+		return models.Person{}, fmt.Errorf("[in services.%s] %w", method, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Person{}, fmt.Errorf("[in services.%s] failed to commit transaction: %w", method, err)
+	}
+
+	return person, nil
+}
+
+// addEnrollment enrolls a person in a course in the caller's transaction.
+func addEnrollment(ctx context.Context, tx *sql.Tx, set rules.Set, firstName string, courseID int, role string) (models.Person, error) {
+	return changeEnrollment(ctx, tx, set, firstName, func(person models.Person) error {
+		sections, err := courseSections(ctx, tx, person.ID, []int{courseID})
+		if err != nil {
+			return err
+		}
+		if _, ok := sections[courseID]; !ok {
+			return &ValidationError{Problems: []validation.Problem{{
+				Name:        "course_id",
+				Description: fmt.Sprintf("course %d does not exist", courseID),
+			}}}
+		}
+		if sections[courseID] == 0 {
+			return &ValidationError{Problems: []validation.Problem{{
+				Name:        "course_id",
+				Description: fmt.Sprintf("course %d has no section in the current term", courseID),
+			}}}
+		}
+		return enroll(ctx, tx, set, person, sections[courseID], role, "course_id")
+	})
+}
+
+// removeEnrollment withdraws a person from a course in the caller's
+// transaction, promoting its waitlist under the rules of set.
+func removeEnrollment(ctx context.Context, tx *sql.Tx, set rules.Set, firstName string, courseID int) (models.Person, error) {
+	return changeEnrollment(ctx, tx, set, firstName, func(person models.Person) error {
+		personID := person.ID
+		res, err := tx.ExecContext(ctx, `
+			DELETE FROM person_section ps
+			USING section s
+			WHERE s.id = ps.section_id AND ps.person_id = $1 AND s.course_id = $2
+			  AND s.term_id = `+currentTerm("CURRENT_DATE"), personID, courseID)
+		if err != nil {
+			return fmt.Errorf("failed to withdraw person with id %d from course %d: %w", personID, courseID, err)
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return fmt.Errorf("person with id %d is not enrolled in course %d: %w", personID, courseID, sql.ErrNoRows)
+		}
+		return nil
+	})
+}
+
+// enroll enrolls a person in a section with role, or with the default role
+// for their type if role is empty, in the caller's transaction. Enrolling
+// them again only changes their role, and only if one is given. An unknown
+// or full section, missing prerequisites, a section that meets at the same
+// time as another they hold and a new role that breaks a rule of set are
+// reported as a *ValidationError naming field.
+func enroll(ctx context.Context, tx *sql.Tx, set rules.Set, person models.Person, sectionID int, role, field string) error {
+	personID := person.ID
+	if role == models.RoleInstructor && person.Type != "professor" {
+		return &ValidationError{Problems: []validation.Problem{{
+			Name:        "role",
+			Description: "only professors can be instructors",
+		}}}
+	}
+
+	capacity, taken, err := lockSeats(ctx, tx, sectionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &ValidationError{Problems: []validation.Problem{{
+			Name:        field,
+			Description: fmt.Sprintf("section %d does not exist", sectionID),
+		}}}
+	}
+	if err != nil {
+		return err
+	}
+
+	current, err := sectionRole(ctx, tx, personID, sectionID)
+	if err != nil {
+		return err
+	}
+	wanted := role
+	if wanted == "" && current == "" {
+		wanted = models.DefaultRole(person.Type)
+	}
+	if err = checkPrerequisites(ctx, tx, personID, sectionID, wanted, field); err != nil {
+		return err
+	}
+	if err = checkSchedule(ctx, tx, personID, sectionID, field); err != nil {
+		return err
+	}
+	if wanted != "" && wanted != current {
+		if err = checkSectionRules(ctx, tx, set, person, sectionID, wanted, field); err != nil {
+			return err
+		}
+	}
+	if wanted == models.RoleStudent && current != models.RoleStudent && capacity != nil && taken >= *capacity {
+		return &ValidationError{Problems: []validation.Problem{{
+			Name:        field,
+			Description: fmt.Sprintf("section %d is full; join its waitlist instead", sectionID),
+		}}}
+	}
+
+	if role == "" {
+		_, err = tx.ExecContext(ctx, "INSERT INTO person_section (person_id, section_id, role) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+			personID, sectionID, models.DefaultRole(person.Type))
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO person_section (person_id, section_id, role) VALUES ($1, $2, $3)
+			ON CONFLICT (person_id, section_id) DO UPDATE SET role = EXCLUDED.role`, personID, sectionID, role)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to enroll person with id %d in section %d: %w", personID, sectionID, err)
+	}
+	return leaveFilledWaitlists(ctx, tx, personID)
+}
+
+// changeEnrollment locks the person with firstName, applies change and, if
+// their courses or roles changed, records the update in the audit log,
+// publishes the enrollment events, drops the grades of sections they are no
+// longer a student of and gives any student seat they gave up to the next
+// person on the waitlist, checking them against the rules of set.
+func changeEnrollment(ctx context.Context, tx *sql.Tx, set rules.Set, firstName string, change func(person models.Person) error) (models.Person, error) {
+	before, err := getPersonForUpdate(ctx, tx, firstName)
+	if err != nil {
+		return models.Person{}, err
+	}
+	if err = lockWaitlisted(ctx, tx, studentSections(before.Enrollments)); err != nil {
+		return models.Person{}, err
+	}
+
+	if err = change(before); err != nil {
+		return models.Person{}, err
+	}
+
+	after := before
+	enrollments, err := queryEnrollmentsForPerson(ctx, tx, before.ID)
+	if err != nil {
+		return models.Person{}, err
+	}
+	setEnrollments(&after, enrollments)
+
+	if !slices.Equal(before.Enrollments, after.Enrollments) {
+		if err = recordAudit(ctx, tx, AuditUpdate, EntityPerson, before.ID, before, after); err != nil {
+			return models.Person{}, err
+		}
+		if err = publishEnrollmentChanges(ctx, tx, before.ID, before.Enrollments, after.Enrollments); err != nil {
+			return models.Person{}, err
+		}
+		if err = dropStaleGrades(ctx, tx, before.ID); err != nil {
+			return models.Person{}, err
+		}
+		if err = promoteFreedSeats(ctx, tx, set, before.Enrollments, after.Enrollments); err != nil {
+			return models.Person{}, err
+		}
+	}
+
+	return after, nil
+}
+
+// StreamPersons calls fn for each person matching filter, in id order, along
+// with the courses they are enrolled in. Course names are resolved in the same
+// query so the result set is never held in memory.
+func (p *PersonService) StreamPersons(ctx context.Context, filter PersonFilter, fn func(models.Person, []models.Course) error) error {
+	where, args := filter.where()
+	rel := filter.relations()
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT `+personColumns(rel.today)+`, p.deleted_at,
+			COALESCE(array_agg(c.id ORDER BY c.id) FILTER (WHERE c.id IS NOT NULL), '{}'),
+			COALESCE(array_agg(c.name ORDER BY c.id) FILTER (WHERE c.id IS NOT NULL), '{}')
+		FROM `+rel.person+` p
+		LEFT JOIN `+rel.personCourse+` pc ON pc.person_id = p.id
+		LEFT JOIN `+rel.course+` c ON c.id = pc.course_id AND c.deleted_at IS NULL`+where+`
+		GROUP BY p.id, p.first_name, p.last_name, p.type, p.date_of_birth, p.email, p.phone, p.address, p.deleted_at
+		ORDER BY p.id`, args...)
+	if err != nil {
+		return fmt.Errorf("[in services.StreamPersons] failed to get persons: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			person      models.Person
+			courseIDs   pq.Int64Array
+			courseNames pq.StringArray
+		)
+		err := rows.Scan(append(personDest(&person), &person.DeletedAt, &courseIDs, &courseNames)...)
+		if err != nil {
+			return fmt.Errorf("[in services.StreamPersons] failed to scan person from row: %w", err)
+		}
+
+		courses := make([]models.Course, 0, len(courseIDs))
+		person.Courses = make([]int, 0, len(courseIDs))
+		for i, id := range courseIDs {
+			person.Courses = append(person.Courses, int(id))
+			courses = append(courses, models.Course{ID: int(id), Name: courseNames[i]})
+		}
+
+		if err := fn(person, courses); err != nil {
+			return fmt.Errorf("[in services.StreamPersons] %w", err)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("[in services.StreamPersons] failed to scan persons: %w", err)
+	}
+
+	return nil
+}
+
+// resolveEnrollments combines the courses person.Courses the person is added
+// to with their default role and the explicit person.Enrollments, whose roles
+// take precedence, in that order, and places each in the course's section in
+// the current term, see courseSections. It checks the courses with
+// resolveCourses, that only professors are given the instructor role, that
+// the person with personID, or 0 for a new person, has completed the
+// prerequisites of and finds a free seat in every course they become a
+// student of, that no two of the sections meet at the same time and that the
+// enrollments they gain break no rule of set, returning every problem as one
+// *ValidationError. The sections stay locked until the transaction ends.
+func resolveEnrollments(ctx context.Context, tx *sql.Tx, set rules.Set, personID int, person models.Person) ([]models.Enrollment, error) {
+	personType, courseIDs, enrollments := person.Type, person.Courses, person.Enrollments
+	var problems []validation.Problem
+	collect := func(err error) error {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			problems = append(problems, validationErr.Problems...)
+			return nil
+		}
+		return err
+	}
+
+	explicit := make([]int, 0, len(enrollments))
+	for i, enrollment := range enrollments {
+		explicit = append(explicit, enrollment.CourseID)
+		if enrollment.Role == models.RoleInstructor && personType != "professor" {
+			problems = append(problems, validation.Problem{
+				Name:        fmt.Sprintf("enrollments[%d].role", i),
+				Description: "only professors can be instructors",
+			})
+		}
+	}
+	if _, err := resolveCourses(ctx, tx, "enrollments", explicit); collect(err) != nil {
+		return nil, err
+	}
+	courseIDs, err := resolveCourses(ctx, tx, "courses", courseIDs)
+	if collect(err) != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	sections, err := courseSections(ctx, tx, personID, append(slices.Clone(courseIDs), explicit...))
+	if err != nil {
+		return nil, err
+	}
+	noSection := func(name string, courseID int) {
+		problems = append(problems, validation.Problem{
+			Name:        name,
+			Description: fmt.Sprintf("course %d has no section in the current term", courseID),
+		})
+	}
+	for i, id := range courseIDs {
+		if sections[id] == 0 {
+			noSection(fmt.Sprintf("courses[%d]", i), id)
+		}
+	}
+	for i, enrollment := range enrollments {
+		if sections[enrollment.CourseID] == 0 {
+			noSection(fmt.Sprintf("enrollments[%d].course_id", i), enrollment.CourseID)
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	roles := make(map[int]string, len(courseIDs)+len(enrollments))
+	var ordered []int
+	for _, id := range courseIDs {
+		roles[id] = models.DefaultRole(personType)
+		ordered = append(ordered, id)
+	}
+	for _, enrollment := range enrollments {
+		if _, ok := roles[enrollment.CourseID]; !ok {
+			ordered = append(ordered, enrollment.CourseID)
+		}
+		roles[enrollment.CourseID] = enrollment.Role
 	}
 
+	var (
+		resolved   []models.Enrollment
+		sectionIDs []int
+	)
+	for _, id := range ordered {
+		resolved = append(resolved, models.Enrollment{CourseID: id, SectionID: sections[id], Role: roles[id]})
+		sectionIDs = append(sectionIDs, sections[id])
+	}
+	if err := lockSections(ctx, tx, sectionIDs); err != nil {
+		return nil, err
+	}
+
+	missing, err := missingPrerequisites(ctx, tx, personID, resolved)
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range courseIDs {
+		if len(missing[id]) > 0 && roles[id] == models.RoleStudent {
+			problems = append(problems, validation.Problem{
+				Name:        fmt.Sprintf("courses[%d]", i),
+				Description: describeMissing(id, missing[id]),
+			})
+			delete(missing, id)
+		}
+	}
+	for i, enrollment := range enrollments {
+		if len(missing[enrollment.CourseID]) > 0 {
+			problems = append(problems, validation.Problem{
+				Name:        fmt.Sprintf("enrollments[%d].course_id", i),
+				Description: describeMissing(enrollment.CourseID, missing[enrollment.CourseID]),
+			})
+			delete(missing, enrollment.CourseID)
+		}
+	}
+
+	full, err := fullSections(ctx, tx, personID, resolved)
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range courseIDs {
+		if full[sections[id]] && roles[id] == models.RoleStudent {
+			problems = append(problems, validation.Problem{
+				Name:        fmt.Sprintf("courses[%d]", i),
+				Description: fmt.Sprintf("section %d of course %d is full; join its waitlist instead", sections[id], id),
+			})
+			delete(full, sections[id])
+		}
+	}
+	for i, enrollment := range enrollments {
+		if full[sections[enrollment.CourseID]] {
+			problems = append(problems, validation.Problem{
+				Name:        fmt.Sprintf("enrollments[%d].course_id", i),
+				Description: fmt.Sprintf("section %d of course %d is full; join its waitlist instead", sections[enrollment.CourseID], enrollment.CourseID),
+			})
+			delete(full, sections[enrollment.CourseID])
+		}
+	}
+
+	clashes, err := clashingSections(ctx, tx, personID, sectionIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range courseIDs {
+		if clash, ok := clashes[sections[id]]; ok {
+			problems = append(problems, validation.Problem{Name: fmt.Sprintf("courses[%d]", i), Description: clash})
+			delete(clashes, sections[id])
+		}
+	}
+	for i, enrollment := range enrollments {
+		if clash, ok := clashes[sections[enrollment.CourseID]]; ok {
+			problems = append(problems, validation.Problem{Name: fmt.Sprintf("enrollments[%d].course_id", i), Description: clash})
+			delete(clashes, sections[enrollment.CourseID])
+		}
+	}
+
+	// The rules only apply to the sections and roles the person gains
+	held, err := queryEnrollmentsForPerson(ctx, tx, personID)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[int]string, len(resolved))
+	for i, id := range courseIDs {
+		fields[id] = fmt.Sprintf("courses[%d]", i)
+	}
+	for i, enrollment := range enrollments {
+		fields[enrollment.CourseID] = fmt.Sprintf("enrollments[%d].course_id", i)
+	}
+	added := make(map[int]string)
+	for _, enrollment := range resolved {
+		if !slices.Contains(held, enrollment) {
+			added[enrollment.SectionID] = fields[enrollment.CourseID]
+		}
+	}
+	if err = checkRules(ctx, tx, set, person, resolved, added); collect(err) != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return resolved, nil
+}
+
+// checkNotInstructing returns a *ValidationError if the person is the
+// instructor of record of a section, or teaches one whose enrollments
+// updatePerson keeps: those of other terms and of deleted courses. They can
+// only stop being a professor once they no longer do.
+func checkNotInstructing(ctx context.Context, tx *sql.Tx, personID int) error {
+	var sectionID int
+	err := tx.QueryRowContext(ctx, `
+		SELECT s.id
+		FROM section s
+		JOIN course c ON c.id = s.course_id
+		WHERE s.instructor_id = $1
+		   OR (EXISTS (SELECT 1 FROM person_section ps WHERE ps.section_id = s.id AND ps.person_id = $1 AND ps.role = $2)
+		       AND (c.deleted_at IS NOT NULL OR s.term_id IS DISTINCT FROM `+currentTerm("CURRENT_DATE")+`))
+		ORDER BY s.id
+		LIMIT 1`, personID, models.RoleInstructor).Scan(&sectionID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up sections taught by person with id %d: %w", personID, err)
+	}
+	return &ValidationError{Problems: []validation.Problem{{
+		Name:        "type",
+		Description: fmt.Sprintf("must remain professor while instructing section %d", sectionID),
+	}}}
+}
+
+// resolveCourses removes duplicate course ids, keeping the first occurrence,
+// and checks that every remaining id exists and is not deleted using a single
+// query. The courses
+// are locked FOR SHARE so they cannot be deleted before the transaction
+// commits. Unknown ids are returned as a *ValidationError with a problem on
+// each offending field[i] position of the original list.
+func resolveCourses(ctx context.Context, tx *sql.Tx, field string, courseIDs []int) ([]int, error) {
+	if len(courseIDs) == 0 {
+		return courseIDs, nil
+	}
+
+	unique := make([]int, 0, len(courseIDs))
+	seen := make(map[int]bool, len(courseIDs))
+	for _, id := range courseIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM course WHERE id = ANY($1) AND deleted_at IS NULL FOR SHARE", pq.Array(unique))
+	if err != nil {
+		return nil, fmt.Errorf("[in services.resolveCourses] failed to look up courses: %w", err)
+	}
+	defer rows.Close()
+
+	found := make(map[int]bool, len(unique))
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("[in services.resolveCourses] failed to scan course ID: %w", err)
+		}
+		found[id] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.resolveCourses] failed to scan course IDs: %w", err)
+	}
+
+	var problems []validation.Problem
+	for i, id := range courseIDs {
+		if !found[id] {
+			problems = append(problems, validation.Problem{
+				Name:        fmt.Sprintf("%s[%d]", field, i),
+				Description: fmt.Sprintf("course %d does not exist", id),
+			})
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return unique, nil
+}
+
+// personColumns selects a person p with their age on the date today, e.g.
+// CURRENT_DATE, into the fields returned by personDest.
+func personColumns(today string) string {
+	return "p.id, p.first_name, p.last_name, p.type, p.date_of_birth, date_part('year', age(" + today + ", p.date_of_birth))::int, " +
+		"COALESCE(p.email, ''), p.phone, p.address"
+}
+
+func personDest(person *models.Person) []any {
+	return []any{&person.ID, &person.FirstName, &person.LastName, &person.Type, &person.DateOfBirth, &person.Age,
+		&person.Email, &person.Phone, &person.Address}
+}
+
+// checkPerson sets the age of a person from their date of birth, and returns
+// a *ValidationError if they were born in the future or another person,
+// deleted or not, has their email in any case. id is 0 for new persons.
+func checkPerson(ctx context.Context, tx *sql.Tx, id int, person *models.Person) error {
+	var problems []validation.Problem
+	now := time.Now()
+	if person.DateOfBirth.After(now) {
+		problems = append(problems, validation.Problem{
+			Name:        "date_of_birth",
+			Description: "must not be in the future",
+		})
+	}
+	person.Age = models.AgeOn(person.DateOfBirth, now)
+
+	if person.Email != "" {
+		var taken bool
+		err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM person WHERE lower(email) = lower($1) AND id <> $2)", person.Email, id).Scan(&taken)
+		if err != nil {
+			return fmt.Errorf("failed to check email %s: %w", person.Email, err)
+		}
+		if taken {
+			problems = append(problems, emailProblem(person.Email))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// emailProblem reports that email is used by another person.
+func emailProblem(email string) validation.Problem {
+	return validation.Problem{
+		Name:        "email",
+		Description: fmt.Sprintf("%s is already in use", email),
+	}
+}
+
+// emailTaken returns a *ValidationError if err violates the unique index on
+// emails, as when another transaction takes email after checkPerson, and nil
+// otherwise.
+func emailTaken(err error, email string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "person_email_idx" {
+		return &ValidationError{Problems: []validation.Problem{emailProblem(email)}}
+	}
 	return nil
 }
 
-// Helper method to retrieve courses for a person
-func (p *PersonService) getCoursesForPerson(personID int) ([]int, error) {
-	rows, err := p.DB.Query("SELECT course_id FROM person_course WHERE person_id = $1", personID)
+// getPersonForUpdate loads a person that has not been deleted and their
+// enrollments by first name, and locks the person row for the rest of the
+// transaction.
+func getPersonForUpdate(ctx context.Context, q querier, firstName string) (models.Person, error) {
+	var person models.Person
+	err := q.QueryRowContext(ctx, "SELECT "+personColumns("CURRENT_DATE")+" FROM person p WHERE p.first_name = $1 AND p.deleted_at IS NULL FOR UPDATE", firstName).
+		Scan(personDest(&person)...)
 	if err != nil {
-		return nil, fmt.Errorf("[in services.getCoursesForPerson] failed to get courses: %w", err)
+		if err == sql.ErrNoRows {
+			return models.Person{}, fmt.Errorf("person with first name %s not found: %w", firstName, err)
+		}
+		return models.Person{}, fmt.Errorf("failed to fetch person with first name %s: %w", firstName, err)
+	}
+
+	enrollments, err := queryEnrollmentsForPerson(ctx, q, person.ID)
+	if err != nil {
+		return models.Person{}, err
+	}
+	setEnrollments(&person, enrollments)
+	return person, nil
+}
+
+// queryEnrollmentsForPerson retrieves a person's enrollments in the current
+// term, skipping deleted courses.
+func queryEnrollmentsForPerson(ctx context.Context, q querier, personID int) ([]models.Enrollment, error) {
+	return queryEnrollmentsForPersonAsOf(ctx, q, personID, time.Time{})
+}
+
+// queryEnrollmentsForPersonAsOf retrieves the enrollments a person held at
+// asOf in the term current then, or holds now in the current term if asOf is
+// zero, in course id order.
+func queryEnrollmentsForPersonAsOf(ctx context.Context, q querier, personID int, asOf time.Time) ([]models.Enrollment, error) {
+	args := []any{personID}
+	if !asOf.IsZero() {
+		args = append(args, asOf)
+	}
+	rel := relationsAt(asOf, "$2")
+	rows, err := q.QueryContext(ctx, `
+		SELECT pc.course_id, pc.section_id, pc.role
+		FROM `+rel.personCourse+` pc
+		JOIN `+rel.course+` c ON c.id = pc.course_id
+		WHERE pc.person_id = $1 AND c.deleted_at IS NULL
+		ORDER BY pc.course_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.queryEnrollmentsForPersonAsOf] failed to get enrollments: %w", err)
 	}
 	defer rows.Close()
 
-	var courseIDs []int
+	var enrollments []models.Enrollment
 	for rows.Next() {
-		var courseID int
-		if err := rows.Scan(&courseID); err != nil {
-			return nil, fmt.Errorf("[in services.getCoursesForPerson] failed to scan course ID: %w", err)
+		var enrollment models.Enrollment
+		if err := rows.Scan(&enrollment.CourseID, &enrollment.SectionID, &enrollment.Role); err != nil {
+			return nil, fmt.Errorf("[in services.queryEnrollmentsForPersonAsOf] failed to scan enrollment: %w", err)
 		}
-		courseIDs = append(courseIDs, courseID)
+		enrollments = append(enrollments, enrollment)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("[in services.getCoursesForPerson] failed to scan course IDs: %w", err)
+		return nil, fmt.Errorf("[in services.queryEnrollmentsForPersonAsOf] failed to scan enrollments: %w", err)
 	}
 
-	return courseIDs, nil
+	return enrollments, nil
+}
+
+// setEnrollments sets a person's enrollments and the course ids they hold.
+func setEnrollments(person *models.Person, enrollments []models.Enrollment) {
+	person.Enrollments = enrollments
+	person.Courses = nil
+	for _, enrollment := range enrollments {
+		person.Courses = append(person.Courses, enrollment.CourseID)
+	}
+}
+
+// zipEnrollments combines the course ids, section ids and roles aggregated by
+// a query.
+func zipEnrollments(courseIDs, sectionIDs pq.Int64Array, roles pq.StringArray) []models.Enrollment {
+	var enrollments []models.Enrollment
+	for i, id := range courseIDs {
+		enrollments = append(enrollments, models.Enrollment{CourseID: int(id), SectionID: int(sectionIDs[i]), Role: roles[i]})
+	}
+	return enrollments
 }
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+	"github.com/lib/pq"
+)
+
+func TestEmailTaken(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"email index", &pq.Error{Code: "23505", Constraint: "person_email_idx"}, true},
+		{"wrapped", fmt.Errorf("exec: %w", &pq.Error{Code: "23505", Constraint: "person_email_idx"}), true},
+		{"other index", &pq.Error{Code: "23505", Constraint: "person_first_name_key"}, false},
+		{"other code", &pq.Error{Code: "23503", Constraint: "person_email_idx"}, false},
+		{"not postgres", errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := emailTaken(tt.err, "ada@example.com")
+			if !tt.want {
+				if got != nil {
+					t.Errorf("emailTaken() = %v, want nil", got)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(got, &validationErr) {
+				t.Fatalf("emailTaken() = %v, want a *ValidationError", got)
+			}
+			want := []validation.Problem{{Name: "email", Description: "ada@example.com is already in use"}}
+			if !reflect.DeepEqual(validationErr.Problems, want) {
+				t.Errorf("problems = %+v, want %+v", validationErr.Problems, want)
+			}
+		})
+	}
+}
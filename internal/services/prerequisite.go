@@ -0,0 +1,373 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+	"github.com/lib/pq"
+)
+
+type prerequisiteOverrideKey struct{}
+
+// WithPrerequisiteOverride lets enrollments made with the returned context
+// skip the prerequisite check. Handlers only set it for admins.
+func WithPrerequisiteOverride(ctx context.Context) context.Context {
+	return context.WithValue(ctx, prerequisiteOverrideKey{}, true)
+}
+
+func prerequisitesOverridden(ctx context.Context) bool {
+	override, _ := ctx.Value(prerequisiteOverrideKey{}).(bool)
+	return override
+}
+
+// completedCourse is the condition that the person with id person completed
+// the course with id course: they have a passing grade in one of its
+// sections.
+func completedCourse(person, course string) string {
+	return `EXISTS (
+		SELECT 1
+		FROM grade done
+		JOIN section done_s ON done_s.id = done.section_id
+		WHERE done.person_id = ` + person + ` AND done_s.course_id = ` + course + ` AND done.passed)`
+}
+
+// ListPrerequisites returns the prerequisites of a course that is not
+// deleted, each with its own, down to courses without any. A course required
+// along several paths appears under each. Deleted prerequisites are left out.
+// It returns an error wrapping sql.ErrNoRows if there is no such course.
+func (c *CourseService) ListPrerequisites(ctx context.Context, courseID int) ([]models.Prerequisite, error) {
+	var exists bool
+	err := c.DB.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM course WHERE id = $1 AND deleted_at IS NULL)", courseID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.ListPrerequisites] failed to get course with id %d: %w", courseID, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("[in services.ListPrerequisites] course with id %d not found: %w", courseID, sql.ErrNoRows)
+	}
+
+	prerequisites, err := queryPrerequisites(ctx, c.DB, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.ListPrerequisites] %w", err)
+	}
+	return prerequisites, nil
+}
+
+// AddPrerequisite makes a course require another and returns its
+// prerequisites as ListPrerequisites does. Adding one it already has changes
+// nothing. Unknown prerequisites and those that already require the course,
+// which would make a cycle, are rejected with a *ValidationError.
+func (c *CourseService) AddPrerequisite(ctx context.Context, courseID, prerequisiteID int) ([]models.Prerequisite, error) {
+	tx, err := c.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.AddPrerequisite] failed to begin transaction: %w", err)
+	}
+
+	prerequisites, err := addPrerequisite(ctx, tx, courseID, prerequisiteID)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("[in services.AddPrerequisite] %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("[in services.AddPrerequisite] failed to commit transaction: %w", err)
+	}
+
+	return prerequisites, nil
+}
+
+// RemovePrerequisite stops a course from requiring another. It returns an
+// error wrapping sql.ErrNoRows if it did not.
+func (c *CourseService) RemovePrerequisite(ctx context.Context, courseID, prerequisiteID int) error {
+	tx, err := c.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("[in services.RemovePrerequisite] failed to begin transaction: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM course_prerequisite WHERE course_id = $1 AND prerequisite_id = $2", courseID, prerequisiteID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("[in services.RemovePrerequisite] failed to remove prerequisite %d of course %d: %w", prerequisiteID, courseID, err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		tx.Rollback()
+		return fmt.Errorf("[in services.RemovePrerequisite] course %d does not require course %d: %w", courseID, prerequisiteID, sql.ErrNoRows)
+	}
+
+	edge := prerequisite{CourseID: courseID, PrerequisiteID: prerequisiteID}
+	if err = recordAudit(ctx, tx, AuditDelete, EntityPrerequisite, courseID, edge, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = publish(ctx, tx, EventPrerequisiteRemoved, courseID, edge); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("[in services.RemovePrerequisite] failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// addPrerequisite adds a prerequisite in the caller's transaction.
+func addPrerequisite(ctx context.Context, tx *sql.Tx, courseID, prerequisiteID int) ([]models.Prerequisite, error) {
+	// Only one transaction at a time may add edges, so that two of them
+	// cannot each close half of a cycle. Removing edges cannot make one.
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE course_prerequisite IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return nil, fmt.Errorf("failed to lock prerequisites: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM course WHERE id = ANY($1) AND deleted_at IS NULL FOR SHARE", pq.Array([]int{courseID, prerequisiteID}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock courses: %w", err)
+	}
+	found := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan course id: %w", err)
+		}
+		found[id] = true
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan course ids: %w", err)
+	}
+	if !found[courseID] {
+		return nil, fmt.Errorf("course with id %d not found: %w", courseID, sql.ErrNoRows)
+	}
+
+	var problem string
+	switch {
+	case prerequisiteID == courseID:
+		problem = "a course cannot require itself"
+	case !found[prerequisiteID]:
+		problem = fmt.Sprintf("course %d does not exist", prerequisiteID)
+	default:
+		path, err := prerequisitePath(ctx, tx, prerequisiteID, courseID)
+		if err != nil {
+			return nil, err
+		}
+		if path != nil {
+			problem = fmt.Sprintf("course %d already requires course %d (%s), so requiring it would make a cycle", prerequisiteID, courseID, joinIDs(path, " -> "))
+		}
+	}
+	if problem != "" {
+		return nil, &ValidationError{Problems: []validation.Problem{{Name: "prerequisite_id", Description: problem}}}
+	}
+
+	res, err := tx.ExecContext(ctx, "INSERT INTO course_prerequisite (course_id, prerequisite_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", courseID, prerequisiteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add prerequisite %d to course %d: %w", prerequisiteID, courseID, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		edge := prerequisite{CourseID: courseID, PrerequisiteID: prerequisiteID}
+		if err = recordAudit(ctx, tx, AuditCreate, EntityPrerequisite, courseID, nil, edge); err != nil {
+			return nil, err
+		}
+		if err = publish(ctx, tx, EventPrerequisiteAdded, courseID, edge); err != nil {
+			return nil, err
+		}
+	}
+
+	return queryPrerequisites(ctx, tx, courseID)
+}
+
+// prerequisitePath returns a chain of prerequisites leading from the course
+// with id from to the course with id to, both included, or nil if from does
+// not require to, directly or indirectly.
+func prerequisitePath(ctx context.Context, q querier, from, to int) ([]int, error) {
+	rows, err := q.QueryContext(ctx, `
+		WITH RECURSIVE edge (course_id, prerequisite_id) AS (
+			SELECT course_id, prerequisite_id FROM course_prerequisite WHERE course_id = $1
+			UNION
+			SELECT cp.course_id, cp.prerequisite_id
+			FROM edge e
+			JOIN course_prerequisite cp ON cp.course_id = e.prerequisite_id
+		)
+		SELECT course_id, prerequisite_id FROM edge ORDER BY course_id, prerequisite_id`, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look for prerequisites from course %d to %d: %w", from, to, err)
+	}
+	defer rows.Close()
+
+	required := map[int][]int{}
+	for rows.Next() {
+		var course, prerequisite int
+		if err := rows.Scan(&course, &prerequisite); err != nil {
+			return nil, fmt.Errorf("failed to scan prerequisite: %w", err)
+		}
+		required[course] = append(required[course], prerequisite)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan the prerequisites of course %d: %w", from, err)
+	}
+
+	return findPath(required, from, to), nil
+}
+
+// findPath returns the shortest chain leading from from to to in required,
+// which maps each course to the courses it requires directly, or nil if there
+// is none. A course leads to itself.
+func findPath(required map[int][]int, from, to int) []int {
+	previous := map[int]int{from: from}
+	queue := []int{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == to {
+			path := []int{id}
+			for id != from {
+				id = previous[id]
+				path = append(path, id)
+			}
+			slices.Reverse(path)
+			return path
+		}
+		for _, next := range required[id] {
+			if _, seen := previous[next]; !seen {
+				previous[next] = id
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// queryPrerequisites loads the prerequisite tree of a course.
+func queryPrerequisites(ctx context.Context, q querier, courseID int) ([]models.Prerequisite, error) {
+	rows, err := q.QueryContext(ctx, `
+		WITH RECURSIVE edge (course_id, prerequisite_id) AS (
+			SELECT course_id, prerequisite_id FROM course_prerequisite WHERE course_id = $1
+			UNION
+			SELECT cp.course_id, cp.prerequisite_id
+			FROM edge e
+			JOIN course_prerequisite cp ON cp.course_id = e.prerequisite_id
+		)
+		SELECT e.course_id, e.prerequisite_id, p.name
+		FROM edge e
+		JOIN course p ON p.id = e.prerequisite_id AND p.deleted_at IS NULL
+		ORDER BY e.prerequisite_id`, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the prerequisites of course %d: %w", courseID, err)
+	}
+	defer rows.Close()
+
+	type node struct {
+		id   int
+		name string
+	}
+	required := map[int][]node{}
+	for rows.Next() {
+		var (
+			course int
+			n      node
+		)
+		if err := rows.Scan(&course, &n.id, &n.name); err != nil {
+			return nil, fmt.Errorf("failed to scan prerequisite: %w", err)
+		}
+		required[course] = append(required[course], n)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan the prerequisites of course %d: %w", courseID, err)
+	}
+
+	// The graph is acyclic, so this terminates.
+	var build func(id int) []models.Prerequisite
+	build = func(id int) []models.Prerequisite {
+		prerequisites := make([]models.Prerequisite, 0, len(required[id]))
+		for _, n := range required[id] {
+			prerequisites = append(prerequisites, models.Prerequisite{CourseID: n.id, Name: n.name, Prerequisites: build(n.id)})
+		}
+		return prerequisites
+	}
+	return build(courseID), nil
+}
+
+// missingPrerequisites returns, keyed by course id, the prerequisites that
+// are not deleted and that the person with personID has not completed, of the
+// courses among enrollments they would become a student of. Courses they are
+// already a student of, where they keep their seat, are left out, and
+// nothing is returned if ctx carries WithPrerequisiteOverride.
+func missingPrerequisites(ctx context.Context, q querier, personID int, enrollments []models.Enrollment) (map[int][]int, error) {
+	if prerequisitesOverridden(ctx) {
+		return nil, nil
+	}
+	var sectionIDs []int
+	for _, enrollment := range enrollments {
+		if enrollment.Role == models.RoleStudent {
+			sectionIDs = append(sectionIDs, enrollment.SectionID)
+		}
+	}
+	if len(sectionIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT s.course_id, cp.prerequisite_id
+		FROM section s
+		JOIN course_prerequisite cp ON cp.course_id = s.course_id
+		JOIN course p ON p.id = cp.prerequisite_id AND p.deleted_at IS NULL
+		WHERE s.id = ANY($1)
+		  AND NOT EXISTS (SELECT 1 FROM person_section WHERE person_id = $2 AND section_id = s.id AND role = $3)
+		  AND NOT `+completedCourse("$2", "cp.prerequisite_id")+`
+		ORDER BY s.course_id, cp.prerequisite_id`, pq.Array(sectionIDs), personID, models.RoleStudent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the prerequisites of person with id %d: %w", personID, err)
+	}
+	defer rows.Close()
+
+	missing := map[int][]int{}
+	for rows.Next() {
+		var courseID, prerequisiteID int
+		if err := rows.Scan(&courseID, &prerequisiteID); err != nil {
+			return nil, fmt.Errorf("failed to scan missing prerequisite: %w", err)
+		}
+		missing[courseID] = append(missing[courseID], prerequisiteID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan missing prerequisites: %w", err)
+	}
+	return missing, nil
+}
+
+// checkPrerequisites returns a *ValidationError naming field if the person
+// with personID would become a student of a section with role without having
+// completed the prerequisites of its course; see missingPrerequisites.
+func checkPrerequisites(ctx context.Context, q querier, personID, sectionID int, role, field string) error {
+	missing, err := missingPrerequisites(ctx, q, personID, []models.Enrollment{{SectionID: sectionID, Role: role}})
+	if err != nil {
+		return err
+	}
+	for courseID, ids := range missing {
+		return &ValidationError{Problems: []validation.Problem{{
+			Name:        field,
+			Description: describeMissing(courseID, ids),
+		}}}
+	}
+	return nil
+}
+
+func describeMissing(courseID int, prerequisiteIDs []int) string {
+	noun := "course"
+	if len(prerequisiteIDs) > 1 {
+		noun = "courses"
+	}
+	return fmt.Sprintf("course %d requires completing %s %s first", courseID, noun, joinIDs(prerequisiteIDs, ", "))
+}
+
+func joinIDs(ids []int, sep string) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.Itoa(id))
+	}
+	return strings.Join(parts, sep)
+}
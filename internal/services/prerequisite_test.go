@@ -0,0 +1,77 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		required map[int][]int
+		// adding makes course require prerequisite, which is a cycle if
+		// prerequisite already leads to course along want
+		course, prerequisite int
+		want                 []int
+	}{
+		{
+			name:         "direct cycle",
+			required:     map[int][]int{2: {1}},
+			course:       1,
+			prerequisite: 2,
+			want:         []int{2, 1},
+		},
+		{
+			name:         "indirect cycle",
+			required:     map[int][]int{4: {3}, 3: {2}, 2: {1}},
+			course:       1,
+			prerequisite: 4,
+			want:         []int{4, 3, 2, 1},
+		},
+		{
+			name:         "shortest of several cycles",
+			required:     map[int][]int{4: {2, 3}, 3: {1}, 2: {5}, 5: {1}},
+			course:       1,
+			prerequisite: 4,
+			want:         []int{4, 3, 1},
+		},
+		{
+			name:         "self reference",
+			required:     map[int][]int{},
+			course:       1,
+			prerequisite: 1,
+			want:         []int{1},
+		},
+		{
+			name:         "existing cycle elsewhere",
+			required:     map[int][]int{2: {3}, 3: {2}},
+			course:       1,
+			prerequisite: 2,
+			want:         nil,
+		},
+		{
+			// 4 requires 2 and 3, which both require 1: 1 is reached twice
+			// but nothing leads back to 4
+			name:         "diamond",
+			required:     map[int][]int{2: {1}, 3: {1}, 4: {2}},
+			course:       4,
+			prerequisite: 3,
+			want:         nil,
+		},
+		{
+			name:         "unrelated",
+			required:     map[int][]int{2: {1}},
+			course:       3,
+			prerequisite: 2,
+			want:         nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findPath(tt.required, tt.prerequisite, tt.course)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findPath(%v, %d, %d) = %v, want %v", tt.required, tt.prerequisite, tt.course, got, tt.want)
+			}
+		})
+	}
+}
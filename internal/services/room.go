@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+)
+
+type RoomService struct {
+	DB *sql.DB
+}
+
+func NewRoomService(db *sql.DB) *RoomService {
+	return &RoomService{
+		DB: db,
+	}
+}
+
+func scanRoom(row interface{ Scan(...any) error }) (models.Room, error) {
+	var room models.Room
+	err := row.Scan(&room.ID, &room.Name, &room.Capacity)
+	return room, err
+}
+
+// ListRooms returns every room in name order.
+func (r *RoomService) ListRooms(ctx context.Context) ([]models.Room, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT id, name, capacity FROM room ORDER BY name, id")
+	if err != nil {
+		return nil, fmt.Errorf("[in services.ListRooms] failed to get rooms: %w", err)
+	}
+	defer rows.Close()
+
+	rooms := []models.Room{}
+	for rows.Next() {
+		room, err := scanRoom(rows)
+		if err != nil {
+			return nil, fmt.Errorf("[in services.ListRooms] failed to scan room from row: %w", err)
+		}
+		rooms = append(rooms, room)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.ListRooms] failed to scan rooms: %w", err)
+	}
+
+	return rooms, nil
+}
+
+func (r *RoomService) GetRoom(ctx context.Context, id int) (models.Room, error) {
+	room, err := getRoom(ctx, r.DB, id, "")
+	if err != nil {
+		return models.Room{}, fmt.Errorf("[in services.GetRoom] %w", err)
+	}
+	return room, nil
+}
+
+// CreateRoom adds a room. A name already in use is rejected with a
+// *ValidationError.
+func (r *RoomService) CreateRoom(ctx context.Context, room models.Room) (models.Room, error) {
+	return r.inTx(ctx, "CreateRoom", func(tx *sql.Tx) (models.Room, error) {
+		if err := checkRoomName(ctx, tx, 0, room.Name); err != nil {
+			return models.Room{}, err
+		}
+
+		err := tx.QueryRowContext(ctx, "INSERT INTO room (name, capacity) VALUES ($1, $2) RETURNING id",
+			room.Name, room.Capacity).Scan(&room.ID)
+		if err != nil {
+			return models.Room{}, fmt.Errorf("failed to create room: %w", err)
+		}
+
+		if err = recordAudit(ctx, tx, AuditCreate, EntityRoom, room.ID, nil, room); err != nil {
+			return models.Room{}, err
+		}
+		if err = publish(ctx, tx, EventRoomCreated, room.ID, room); err != nil {
+			return models.Room{}, err
+		}
+		return room, nil
+	})
+}
+
+// UpdateRoom renames a room and sets its capacity, which cannot be lowered
+// below the capacity of a section meeting in it.
+func (r *RoomService) UpdateRoom(ctx context.Context, id int, room models.Room) (models.Room, error) {
+	return r.inTx(ctx, "UpdateRoom", func(tx *sql.Tx) (models.Room, error) {
+		before, err := getRoom(ctx, tx, id, "FOR UPDATE")
+		if err != nil {
+			return models.Room{}, err
+		}
+		if err = checkRoomName(ctx, tx, id, room.Name); err != nil {
+			return models.Room{}, err
+		}
+
+		var sectionID int
+		err = tx.QueryRowContext(ctx, `
+			SELECT s.id
+			FROM section s
+			JOIN section_meeting m ON m.section_id = s.id
+			WHERE m.room_id = $1 AND s.capacity > $2
+			ORDER BY s.capacity DESC, s.id
+			LIMIT 1`, id, room.Capacity).Scan(&sectionID)
+		if err == nil {
+			return models.Room{}, &ValidationError{Problems: []validation.Problem{{
+				Name:        "capacity",
+				Description: fmt.Sprintf("section %d meets in room %d and seats more; move it first", sectionID, id),
+			}}}
+		}
+		if err != sql.ErrNoRows {
+			return models.Room{}, fmt.Errorf("failed to check the sections meeting in room %d: %w", id, err)
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE room SET name = $1, capacity = $2 WHERE id = $3", room.Name, room.Capacity, id)
+		if err != nil {
+			return models.Room{}, fmt.Errorf("failed to update room with id %d: %w", id, err)
+		}
+		room.ID = id
+
+		if err = recordAudit(ctx, tx, AuditUpdate, EntityRoom, id, before, room); err != nil {
+			return models.Room{}, err
+		}
+		if err = publish(ctx, tx, EventRoomUpdated, id, room); err != nil {
+			return models.Room{}, err
+		}
+		return room, nil
+	})
+}
+
+// DeleteRoom removes a room no section meets in; one that a section does is
+// rejected with a *ValidationError.
+func (r *RoomService) DeleteRoom(ctx context.Context, id int) error {
+	_, err := r.inTx(ctx, "DeleteRoom", func(tx *sql.Tx) (models.Room, error) {
+		before, err := getRoom(ctx, tx, id, "FOR UPDATE")
+		if err != nil {
+			return models.Room{}, err
+		}
+
+		var sections int
+		if err = tx.QueryRowContext(ctx, "SELECT count(DISTINCT section_id) FROM section_meeting WHERE room_id = $1", id).Scan(&sections); err != nil {
+			return models.Room{}, fmt.Errorf("failed to count the sections meeting in room %d: %w", id, err)
+		}
+		if sections > 0 {
+			return models.Room{}, &ValidationError{Problems: []validation.Problem{{
+				Name:        "id",
+				Description: fmt.Sprintf("%d sections meet in room %d; move them first", sections, id),
+			}}}
+		}
+
+		if _, err = tx.ExecContext(ctx, "DELETE FROM room WHERE id = $1", id); err != nil {
+			return models.Room{}, fmt.Errorf("failed to delete room with id %d: %w", id, err)
+		}
+		if err = recordAudit(ctx, tx, AuditDelete, EntityRoom, id, before, nil); err != nil {
+			return models.Room{}, err
+		}
+		return models.Room{}, publish(ctx, tx, EventRoomDeleted, id, before)
+	})
+	return err
+}
+
+// getRoom loads a room, applying lock, e.g. "FOR UPDATE", if it is not empty.
+// It returns an error wrapping sql.ErrNoRows if there is no such room.
+func getRoom(ctx context.Context, q querier, id int, lock string) (models.Room, error) {
+	room, err := scanRoom(q.QueryRowContext(ctx, "SELECT id, name, capacity FROM room WHERE id = $1 "+lock, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Room{}, fmt.Errorf("room with id %d not found: %w", id, err)
+		}
+		return models.Room{}, fmt.Errorf("failed to get room with id %d: %w", id, err)
+	}
+	return room, nil
+}
+
+// checkRoomName returns a *ValidationError if a room other than the one with
+// id is named name.
+func checkRoomName(ctx context.Context, tx *sql.Tx, id int, name string) error {
+	var taken bool
+	err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM room WHERE name = $1 AND id <> $2)", name, id).Scan(&taken)
+	if err != nil {
+		return fmt.Errorf("failed to check room name %s: %w", name, err)
+	}
+	if taken {
+		return &ValidationError{Problems: []validation.Problem{{
+			Name:        "name",
+			Description: fmt.Sprintf("room %s already exists", name),
+		}}}
+	}
+	return nil
+}
+
+// inTx runs fn in a new transaction, committing it if fn succeeds.
+func (r *RoomService) inTx(ctx context.Context, method string, fn func(tx *sql.Tx) (models.Room, error)) (models.Room, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Room{}, fmt.Errorf("[in services.%s] failed to begin transaction: %w", method, err)
+	}
+
+	room, err := fn(tx)
+	if err != nil {
+		tx.Rollback()
+		return models.Room{}, fmt.Errorf("[in services.%s] %w", method, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Room{}, fmt.Errorf("[in services.%s] failed to commit transaction: %w", method, err)
+	}
+
+	return room, nil
+}
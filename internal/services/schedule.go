@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+	"github.com/lib/pq"
+)
+
+// Sections meet weekly, on the days and at the times of their meetings, for
+// as long as their term lasts. A person cannot hold or teach two sections of
+// a term that meet at the same time, and a room cannot host two such
+// sections; meetings of sections of deleted courses are disregarded.
+
+// meetingsColumn selects the meetings of section s as a JSON array, in the
+// order they were given.
+const meetingsColumn = `COALESCE((
+	SELECT json_agg(json_build_object(
+		'days', m.days,
+		'start_time', to_char(m.start_time, 'HH24:MI'),
+		'end_time', to_char(m.end_time, 'HH24:MI'),
+		'room_id', m.room_id) ORDER BY m.id)
+	FROM section_meeting m
+	WHERE m.section_id = s.id), '[]')`
+
+// personSections matches the sections o that the person with id $4 is
+// enrolled in or the instructor of, for findClash.
+const personSections = "(o.id IN (SELECT section_id FROM person_section WHERE person_id = $4) OR o.instructor_id = $4)"
+
+// scanMeetings decodes the meetingsColumn of a section.
+func scanMeetings(data []byte) ([]models.Meeting, error) {
+	meetings := []models.Meeting{}
+	if err := json.Unmarshal(data, &meetings); err != nil {
+		return nil, fmt.Errorf("failed to decode meetings: %w", err)
+	}
+	return meetings, nil
+}
+
+// Schedule returns the week of the person with personID in the term with
+// termID, or in the current term if termID is 0: the meetings of the
+// sections they are enrolled in or the instructor of. It returns an error
+// wrapping sql.ErrNoRows if there is no such person or term.
+func (s *SectionService) Schedule(ctx context.Context, personID, termID int) (models.Schedule, error) {
+	var schedule models.Schedule
+	err := s.DB.QueryRowContext(ctx, "SELECT id, first_name, last_name FROM person WHERE id = $1 AND deleted_at IS NULL", personID).
+		Scan(&schedule.PersonID, &schedule.FirstName, &schedule.LastName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Schedule{}, fmt.Errorf("[in services.Schedule] person with id %d not found: %w", personID, err)
+		}
+		return models.Schedule{}, fmt.Errorf("[in services.Schedule] failed to get person with id %d: %w", personID, err)
+	}
+	err = s.DB.QueryRowContext(ctx, "SELECT id, name FROM term WHERE id = COALESCE(NULLIF($1, 0), "+currentTerm("CURRENT_DATE")+")", termID).
+		Scan(&schedule.TermID, &schedule.TermName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Schedule{}, fmt.Errorf("[in services.Schedule] term with id %d not found: %w", termID, err)
+		}
+		return models.Schedule{}, fmt.Errorf("[in services.Schedule] failed to get term with id %d: %w", termID, err)
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT m.days, to_char(m.start_time, 'HH24:MI'), to_char(m.end_time, 'HH24:MI'),
+		       s.id, s.number, c.id, c.name, COALESCE(ps.role, $3), m.room_id, COALESCE(r.name, '')
+		FROM section s
+		JOIN course c ON c.id = s.course_id AND c.deleted_at IS NULL
+		JOIN section_meeting m ON m.section_id = s.id
+		LEFT JOIN room r ON r.id = m.room_id
+		LEFT JOIN person_section ps ON ps.section_id = s.id AND ps.person_id = $1
+		WHERE s.term_id = $2 AND (ps.person_id IS NOT NULL OR s.instructor_id = $1)
+		ORDER BY m.start_time, m.end_time, s.course_id, s.number`, personID, schedule.TermID, models.RoleInstructor)
+	if err != nil {
+		return models.Schedule{}, fmt.Errorf("[in services.Schedule] failed to get the meetings of person with id %d: %w", personID, err)
+	}
+	defer rows.Close()
+
+	byDay := make(map[string][]models.ScheduleEntry, len(models.Weekdays))
+	for rows.Next() {
+		var (
+			days  pq.StringArray
+			entry models.ScheduleEntry
+		)
+		err := rows.Scan(&days, &entry.StartTime, &entry.EndTime, &entry.SectionID, &entry.SectionNumber,
+			&entry.CourseID, &entry.CourseName, &entry.Role, &entry.RoomID, &entry.RoomName)
+		if err != nil {
+			return models.Schedule{}, fmt.Errorf("[in services.Schedule] failed to scan meeting from row: %w", err)
+		}
+		for _, day := range days {
+			byDay[day] = append(byDay[day], entry)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return models.Schedule{}, fmt.Errorf("[in services.Schedule] failed to scan meetings: %w", err)
+	}
+
+	schedule.Days = make([]models.ScheduleDay, 0, len(models.Weekdays))
+	for _, day := range models.Weekdays {
+		entries := byDay[day]
+		if entries == nil {
+			entries = []models.ScheduleEntry{}
+		}
+		schedule.Days = append(schedule.Days, models.ScheduleDay{Day: day, Meetings: entries})
+	}
+	return schedule, nil
+}
+
+// setMeetings replaces the meetings of a section in the caller's
+// transaction.
+func setMeetings(ctx context.Context, tx *sql.Tx, sectionID int, meetings []models.Meeting) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM section_meeting WHERE section_id = $1", sectionID); err != nil {
+		return fmt.Errorf("failed to clear the meetings of section %d: %w", sectionID, err)
+	}
+	for _, meeting := range meetings {
+		_, err := tx.ExecContext(ctx, "INSERT INTO section_meeting (section_id, days, start_time, end_time, room_id) VALUES ($1, $2, $3, $4, $5)",
+			sectionID, pq.Array(meeting.Days), meeting.StartTime, meeting.EndTime, meeting.RoomID)
+		if err != nil {
+			return fmt.Errorf("failed to add a meeting to section %d: %w", sectionID, err)
+		}
+	}
+	return nil
+}
+
+// checkMeetings returns the problems with the meetings of a section: ones
+// that overlap each other, rooms that do not exist, do not seat the
+// section's capacity or host another section of the term at the same time,
+// and clashes with the other sections of its instructor or of the persons
+// enrolled in it. id is 0 for a new section. The rooms stay locked until the
+// transaction ends, so concurrent bookings of a room queue behind each other.
+func checkMeetings(ctx context.Context, tx *sql.Tx, id int, section models.Section) ([]validation.Problem, error) {
+	var problems []validation.Problem
+	for i, meeting := range section.Meetings {
+		name := fmt.Sprintf("meetings[%d]", i)
+		for j, other := range section.Meetings[:i] {
+			if meeting.Overlaps(other) {
+				problems = append(problems, validation.Problem{Name: name, Description: fmt.Sprintf("overlaps meetings[%d]", j)})
+			}
+		}
+
+		if meeting.RoomID != nil {
+			room, err := getRoom(ctx, tx, *meeting.RoomID, "FOR UPDATE")
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				problems = append(problems, validation.Problem{
+					Name:        name + ".room_id",
+					Description: fmt.Sprintf("room %d does not exist", *meeting.RoomID),
+				})
+			case err != nil:
+				return nil, err
+			default:
+				if section.Capacity != nil && room.Capacity < *section.Capacity {
+					problems = append(problems, validation.Problem{
+						Name:        name + ".room_id",
+						Description: fmt.Sprintf("room %s seats %d, fewer than the section's capacity of %d", room.Name, room.Capacity, *section.Capacity),
+					})
+				}
+				c, err := findClash(ctx, tx, section.TermID, id, meeting, "om.room_id = $4", room.ID)
+				if err != nil {
+					return nil, err
+				}
+				if c != nil {
+					problems = append(problems, validation.Problem{
+						Name:        name + ".room_id",
+						Description: fmt.Sprintf("room %s is taken by %s", room.Name, c),
+					})
+				}
+			}
+		}
+
+		if section.InstructorID != nil {
+			c, err := findClash(ctx, tx, section.TermID, id, meeting, personSections, *section.InstructorID)
+			if err != nil {
+				return nil, err
+			}
+			if c != nil {
+				problems = append(problems, validation.Problem{
+					Name:        "instructor_id",
+					Description: fmt.Sprintf("person %d teaches or holds %s at the same time as %s", *section.InstructorID, c, describeMeeting(meeting)),
+				})
+			}
+		}
+
+		if id != 0 {
+			c, err := findClash(ctx, tx, section.TermID, id, meeting,
+				"o.id IN (SELECT held.section_id FROM person_section held JOIN person_section enrolled ON enrolled.person_id = held.person_id WHERE enrolled.section_id = $4)", id)
+			if err != nil {
+				return nil, err
+			}
+			if c != nil {
+				problems = append(problems, validation.Problem{
+					Name:        name,
+					Description: fmt.Sprintf("a person enrolled in section %d also holds %s", id, c),
+				})
+			}
+		}
+	}
+	return problems, nil
+}
+
+// checkSchedule returns a *ValidationError naming field if the section with
+// sectionID meets at the same time as another section of its term that the
+// person with personID is enrolled in or the instructor of.
+func checkSchedule(ctx context.Context, tx *sql.Tx, personID, sectionID int, field string) error {
+	section, err := getSection(ctx, tx, sectionID, "")
+	if err != nil {
+		return err
+	}
+	for _, meeting := range section.Meetings {
+		c, err := findClash(ctx, tx, section.TermID, sectionID, meeting, personSections, personID)
+		if err != nil {
+			return err
+		}
+		if c != nil {
+			return &ValidationError{Problems: []validation.Problem{{
+				Name:        field,
+				Description: fmt.Sprintf("section %d meets %s, at the same time as %s", sectionID, describeMeeting(meeting), c),
+			}}}
+		}
+	}
+	return nil
+}
+
+// clashingSections describes, keyed by section id, how each section among
+// sectionIDs that meets at the same time as another of them, or as a section
+// the person with personID is the instructor of, clashes with it.
+func clashingSections(ctx context.Context, tx *sql.Tx, personID int, sectionIDs []int) (map[int]string, error) {
+	clashes := map[int]string{}
+	for _, id := range sectionIDs {
+		section, err := getSection(ctx, tx, id, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, meeting := range section.Meetings {
+			c, err := findClash(ctx, tx, section.TermID, id, meeting, "(o.id = ANY($4) OR o.instructor_id = $5)", pq.Array(sectionIDs), personID)
+			if err != nil {
+				return nil, err
+			}
+			if c != nil {
+				clashes[id] = fmt.Sprintf("section %d meets %s, at the same time as %s", id, describeMeeting(meeting), c)
+				break
+			}
+		}
+	}
+	return clashes, nil
+}
+
+// clash is a meeting of another section that overlaps one being checked.
+type clash struct {
+	SectionID int
+	TermID    int
+	Meeting   models.Meeting
+}
+
+func (c clash) String() string {
+	return fmt.Sprintf("section %d, which meets %s", c.SectionID, describeMeeting(c.Meeting))
+}
+
+// clashes reports whether the meetings c and other of two different
+// sections keep someone in both, or a room, busy at the same time: the
+// sections are of the same term and the meetings overlap. Meetings that only
+// touch, one ending when the other starts, do not clash.
+func (c clash) clashes(other clash) bool {
+	return c.SectionID != other.SectionID && c.TermID == other.TermID && c.Meeting.Overlaps(other.Meeting)
+}
+
+// findClash returns a meeting of a section of a course that is not deleted,
+// other than the one with sectionID, in the term with termID that overlaps
+// meeting and matches cond, or nil if there is none. cond refers to the
+// section as o and to the meeting as om; its args are numbered from $4.
+func findClash(ctx context.Context, q querier, termID, sectionID int, meeting models.Meeting, cond string, args ...any) (*clash, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT o.id, o.term_id, om.days, to_char(om.start_time, 'HH24:MI'), to_char(om.end_time, 'HH24:MI'), om.room_id
+		FROM section_meeting om
+		JOIN section o ON o.id = om.section_id
+		JOIN course oc ON oc.id = o.course_id AND oc.deleted_at IS NULL
+		WHERE o.term_id = $1 AND o.id <> $2 AND om.days && $3
+		  AND `+cond+`
+		ORDER BY o.id, om.id`, append([]any{termID, sectionID, pq.Array(meeting.Days)}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up meetings clashing with section %d: %w", sectionID, err)
+	}
+	defer rows.Close()
+
+	checked := clash{SectionID: sectionID, TermID: termID, Meeting: meeting}
+	for rows.Next() {
+		var (
+			c    clash
+			days pq.StringArray
+		)
+		if err := rows.Scan(&c.SectionID, &c.TermID, &days, &c.Meeting.StartTime, &c.Meeting.EndTime, &c.Meeting.RoomID); err != nil {
+			return nil, fmt.Errorf("failed to scan meeting: %w", err)
+		}
+		c.Meeting.Days = days
+		if checked.clashes(c) {
+			return &c, nil
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan meetings clashing with section %d: %w", sectionID, err)
+	}
+	return nil, nil
+}
+
+// describeMeeting formats a meeting as, e.g., "mon, wed 09:00-10:15".
+func describeMeeting(meeting models.Meeting) string {
+	return strings.Join(meeting.Days, ", ") + " " + meeting.StartTime + "-" + meeting.EndTime
+}
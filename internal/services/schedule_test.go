@@ -0,0 +1,41 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+)
+
+func TestClashes(t *testing.T) {
+	meeting := func(start, end string, days ...string) models.Meeting {
+		return models.Meeting{Days: days, StartTime: start, EndTime: end}
+	}
+	checked := clash{SectionID: 1, TermID: 1, Meeting: meeting("09:00", "10:15", "mon", "wed")}
+
+	tests := []struct {
+		name  string
+		other clash
+		want  bool
+	}{
+		{"same times", clash{2, 1, meeting("09:00", "10:15", "mon")}, true},
+		{"overlapping start", clash{2, 1, meeting("08:30", "09:30", "wed")}, true},
+		{"overlapping end", clash{2, 1, meeting("10:00", "11:00", "mon")}, true},
+		{"within", clash{2, 1, meeting("09:30", "10:00", "mon")}, true},
+		{"around", clash{2, 1, meeting("08:00", "12:00", "wed", "fri")}, true},
+		{"touching before", clash{2, 1, meeting("08:00", "09:00", "mon")}, false},
+		{"touching after", clash{2, 1, meeting("10:15", "11:30", "wed")}, false},
+		{"different days", clash{2, 1, meeting("09:00", "10:15", "tue", "thu")}, false},
+		{"different terms", clash{2, 2, meeting("09:00", "10:15", "mon", "wed")}, false},
+		{"same section", clash{1, 1, meeting("09:00", "10:15", "mon", "wed")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checked.clashes(tt.other); got != tt.want {
+				t.Errorf("clashes(%v) = %t, want %t", tt.other, got, tt.want)
+			}
+			if got := tt.other.clashes(checked); got != tt.want {
+				t.Errorf("clashes() from %v = %t, want %t", tt.other, got, tt.want)
+			}
+		})
+	}
+}
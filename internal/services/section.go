@@ -0,0 +1,550 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/rules"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+	"github.com/lib/pq"
+)
+
+// SectionService manages sections and their rosters. Enrollments are checked
+// against Rules.
+type SectionService struct {
+	DB    *sql.DB
+	Rules rules.Set
+}
+
+func NewSectionService(db *sql.DB, eligibility rules.Set) *SectionService {
+	return &SectionService{
+		DB:    db,
+		Rules: eligibility,
+	}
+}
+
+// sectionColumns selects a section s with its roster and meetings.
+var sectionColumns = "s.id, s.course_id, s.term_id, s.number, s.instructor_id, s.capacity, " +
+	rosterColumns("person_section", "person", "pc.section_id = s.id") + ", " + meetingsColumn
+
+func scanSection(row interface{ Scan(...any) error }) (models.Section, error) {
+	var (
+		section  models.Section
+		r        roster
+		meetings []byte
+	)
+	err := row.Scan(append(append([]any{&section.ID, &section.CourseID, &section.TermID, &section.Number, &section.InstructorID, &section.Capacity}, r.dest()...), &meetings)...)
+	if err != nil {
+		return models.Section{}, err
+	}
+	r.applyTo(&section.Instructors, &section.TeachingAssistants, &section.Students, &section.Auditors)
+	section.Meetings, err = scanMeetings(meetings)
+	return section, err
+}
+
+// ListSections returns the sections of courses that are not deleted matching
+// filter, ordered by term start, course and number.
+func (s *SectionService) ListSections(ctx context.Context, filter SectionFilter) ([]models.Section, error) {
+	where, args := filter.where()
+	limit, args := limitClause(filter.Limit, args)
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT `+sectionColumns+`
+		FROM section s
+		JOIN course c ON c.id = s.course_id AND c.deleted_at IS NULL
+		JOIN term t ON t.id = s.term_id`+where+`
+		ORDER BY t.start_date, s.term_id, s.course_id, s.number`+limit, args...)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.ListSections] failed to get sections: %w", err)
+	}
+	defer rows.Close()
+
+	sections := []models.Section{}
+	for rows.Next() {
+		section, err := scanSection(rows)
+		if err != nil {
+			return nil, fmt.Errorf("[in services.ListSections] failed to scan section from row: %w", err)
+		}
+		sections = append(sections, section)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.ListSections] failed to scan sections: %w", err)
+	}
+
+	return sections, nil
+}
+
+// GetSection returns a section of a course that is not deleted, or an error
+// wrapping sql.ErrNoRows.
+func (s *SectionService) GetSection(ctx context.Context, id int) (models.Section, error) {
+	section, err := getSection(ctx, s.DB, id, "")
+	if err != nil {
+		return models.Section{}, fmt.Errorf("[in services.GetSection] %w", err)
+	}
+	return section, nil
+}
+
+// CreateSection offers a course in a term. Number defaults to the next free
+// one and Capacity to the course's. Unknown courses, terms, instructors and
+// rooms, taken numbers and meetings that clash with the instructor's other
+// sections or with another section in the same room are rejected with a
+// *ValidationError.
+func (s *SectionService) CreateSection(ctx context.Context, section models.Section) (models.Section, error) {
+	return s.inTx(ctx, "CreateSection", func(tx *sql.Tx) (models.Section, error) {
+		return createSection(ctx, tx, section)
+	})
+}
+
+// UpdateSection renumbers a section, assigns its instructor and sets its
+// capacity and meetings. Raising or removing the capacity enrolls waitlisted
+// persons in the seats it frees up; it cannot be lowered below the number of
+// students already enrolled. Meetings are checked as by CreateSection, and
+// must not clash with the other sections of the persons enrolled. The course
+// and term of a section cannot be changed.
+func (s *SectionService) UpdateSection(ctx context.Context, id int, section models.Section) (models.Section, error) {
+	return s.inTx(ctx, "UpdateSection", func(tx *sql.Tx) (models.Section, error) {
+		return updateSection(ctx, tx, s.Rules, id, section)
+	})
+}
+
+// DeleteSection removes a section nobody is enrolled in, along with its
+// waitlist. A section with enrollments is rejected with a *ValidationError.
+func (s *SectionService) DeleteSection(ctx context.Context, id int) error {
+	_, err := s.inTx(ctx, "DeleteSection", func(tx *sql.Tx) (models.Section, error) {
+		return models.Section{}, deleteSection(ctx, tx, id)
+	})
+	return err
+}
+
+// Enroll enrolls the person with firstName in a section with role, or with
+// the default role for their type if role is empty, and returns the section.
+// Enrolling a person again only changes their role, and only if one is
+// given. A person can hold one section of a course per term and cannot hold
+// sections that meet at the same time, and a student seat in a full section
+// or without the course's prerequisites, or an enrollment that breaks an
+// eligibility rule, is rejected; all with a *ValidationError.
+func (s *SectionService) Enroll(ctx context.Context, sectionID int, firstName, role string) (models.Section, error) {
+	return s.inTx(ctx, "Enroll", func(tx *sql.Tx) (models.Section, error) {
+		return enrollInSection(ctx, tx, s.Rules, sectionID, firstName, role)
+	})
+}
+
+// Withdraw withdraws the person with firstName from a section, dropping their
+// grade in it and giving their seat to the next person on its waitlist. It
+// returns an error wrapping sql.ErrNoRows if they are not enrolled in it.
+func (s *SectionService) Withdraw(ctx context.Context, sectionID int, firstName string) (models.Section, error) {
+	return s.inTx(ctx, "Withdraw", func(tx *sql.Tx) (models.Section, error) {
+		return withdrawFromSection(ctx, tx, s.Rules, sectionID, firstName)
+	})
+}
+
+// inTx runs fn in a new transaction, committing it if fn succeeds.
+func (s *SectionService) inTx(ctx context.Context, method string, fn func(tx *sql.Tx) (models.Section, error)) (models.Section, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Section{}, fmt.Errorf("[in services.%s] failed to begin transaction: %w", method, err)
+	}
+
+	section, err := fn(tx)
+	if err != nil {
+		tx.Rollback()
+		return models.Section{}, fmt.Errorf("[in services.%s] %w", method, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Section{}, fmt.Errorf("[in services.%s] failed to commit transaction: %w", method, err)
+	}
+
+	return section, nil
+}
+
+// createSection inserts a section in the caller's transaction.
+func createSection(ctx context.Context, tx *sql.Tx, section models.Section) (models.Section, error) {
+	// Lock the course so concurrent sections of it cannot take the same number
+	var courseCapacity *int
+	err := tx.QueryRowContext(ctx, "SELECT capacity FROM course WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", section.CourseID).Scan(&courseCapacity)
+	if err == sql.ErrNoRows {
+		return models.Section{}, &ValidationError{Problems: []validation.Problem{{
+			Name:        "course_id",
+			Description: fmt.Sprintf("course %d does not exist", section.CourseID),
+		}}}
+	}
+	if err != nil {
+		return models.Section{}, fmt.Errorf("failed to get course with id %d: %w", section.CourseID, err)
+	}
+
+	if section.Number == 0 {
+		err = tx.QueryRowContext(ctx, "SELECT COALESCE(max(number), 0) + 1 FROM section WHERE course_id = $1 AND term_id = $2",
+			section.CourseID, section.TermID).Scan(&section.Number)
+		if err != nil {
+			return models.Section{}, fmt.Errorf("failed to number section of course %d: %w", section.CourseID, err)
+		}
+	}
+	if section.Capacity == nil {
+		section.Capacity = courseCapacity
+	}
+	if err = checkSection(ctx, tx, 0, section); err != nil {
+		return models.Section{}, err
+	}
+
+	var id int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO section (course_id, term_id, number, instructor_id, capacity)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		section.CourseID, section.TermID, section.Number, section.InstructorID, section.Capacity).Scan(&id)
+	if err != nil {
+		return models.Section{}, fmt.Errorf("failed to create section: %w", err)
+	}
+	if err = setMeetings(ctx, tx, id, section.Meetings); err != nil {
+		return models.Section{}, err
+	}
+	created, err := getSection(ctx, tx, id, "")
+	if err != nil {
+		return models.Section{}, err
+	}
+
+	if err = recordAudit(ctx, tx, AuditCreate, EntitySection, id, nil, created); err != nil {
+		return models.Section{}, err
+	}
+	if err = publish(ctx, tx, EventSectionCreated, id, created); err != nil {
+		return models.Section{}, err
+	}
+	return created, nil
+}
+
+// updateSection updates a section in the caller's transaction, promoting its
+// waitlist under the rules of set.
+func updateSection(ctx context.Context, tx *sql.Tx, set rules.Set, id int, section models.Section) (models.Section, error) {
+	if err := lockWaitlisted(ctx, tx, []int{id}); err != nil {
+		return models.Section{}, err
+	}
+	before, err := getSection(ctx, tx, id, "FOR UPDATE OF s")
+	if err != nil {
+		return models.Section{}, err
+	}
+
+	var problems []validation.Problem
+	if section.CourseID != before.CourseID {
+		problems = append(problems, validation.Problem{Name: "course_id", Description: "cannot be changed; create a new section instead"})
+	}
+	if section.TermID != before.TermID {
+		problems = append(problems, validation.Problem{Name: "term_id", Description: "cannot be changed; create a new section instead"})
+	}
+	if section.Capacity != nil && *section.Capacity < len(before.Students) {
+		problems = append(problems, validation.Problem{
+			Name:        "capacity",
+			Description: fmt.Sprintf("must be at least %d, the number of students enrolled", len(before.Students)),
+		})
+	}
+	if len(problems) > 0 {
+		return models.Section{}, &ValidationError{Problems: problems}
+	}
+	if section.Number == 0 {
+		section.Number = before.Number
+	}
+	if err = checkSection(ctx, tx, id, section); err != nil {
+		return models.Section{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE section SET number = $1, instructor_id = $2, capacity = $3 WHERE id = $4",
+		section.Number, section.InstructorID, section.Capacity, id)
+	if err != nil {
+		return models.Section{}, fmt.Errorf("failed to update section with id %d: %w", id, err)
+	}
+	if err = setMeetings(ctx, tx, id, section.Meetings); err != nil {
+		return models.Section{}, err
+	}
+	if err = promoteWaitlist(ctx, tx, set, id); err != nil {
+		return models.Section{}, err
+	}
+	after, err := getSection(ctx, tx, id, "")
+	if err != nil {
+		return models.Section{}, err
+	}
+
+	if err = recordAudit(ctx, tx, AuditUpdate, EntitySection, id, before, after); err != nil {
+		return models.Section{}, err
+	}
+	if err = publish(ctx, tx, EventSectionUpdated, id, after); err != nil {
+		return models.Section{}, err
+	}
+	return after, nil
+}
+
+// deleteSection removes a section in the caller's transaction. Its meetings
+// and the enrollments of deleted persons, which the roster leaves out, are
+// removed with it.
+func deleteSection(ctx context.Context, tx *sql.Tx, id int) error {
+	before, err := getSection(ctx, tx, id, "FOR UPDATE OF s")
+	if err != nil {
+		return err
+	}
+	if n := len(before.Instructors) + len(before.TeachingAssistants) + len(before.Students) + len(before.Auditors); n > 0 {
+		return &ValidationError{Problems: []validation.Problem{{
+			Name:        "id",
+			Description: fmt.Sprintf("section %d has %d enrollments; withdraw them first", id, n),
+		}}}
+	}
+
+	for _, table := range []string{"section_waitlist", "person_section", "section_meeting"} {
+		if _, err = tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE section_id = $1", id); err != nil {
+			return fmt.Errorf("failed to clear %s of section with id %d: %w", table, id, err)
+		}
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM section WHERE id = $1", id); err != nil {
+		return fmt.Errorf("failed to delete section with id %d: %w", id, err)
+	}
+
+	if err = recordAudit(ctx, tx, AuditDelete, EntitySection, id, before, nil); err != nil {
+		return err
+	}
+	return publish(ctx, tx, EventSectionDeleted, id, before)
+}
+
+// checkSection returns a *ValidationError if the term or instructor of a
+// section do not exist, the instructor is not a professor, another section
+// than the one with id has its number or its meetings have problems; see
+// checkMeetings.
+func checkSection(ctx context.Context, tx *sql.Tx, id int, section models.Section) error {
+	var problems []validation.Problem
+
+	var termExists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM term WHERE id = $1)", section.TermID).Scan(&termExists); err != nil {
+		return fmt.Errorf("failed to look up term with id %d: %w", section.TermID, err)
+	}
+	if !termExists {
+		problems = append(problems, validation.Problem{Name: "term_id", Description: fmt.Sprintf("term %d does not exist", section.TermID)})
+	} else {
+		var taken bool
+		err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM section WHERE course_id = $1 AND term_id = $2 AND number = $3 AND id <> $4)",
+			section.CourseID, section.TermID, section.Number, id).Scan(&taken)
+		if err != nil {
+			return fmt.Errorf("failed to check section number: %w", err)
+		}
+		if taken {
+			problems = append(problems, validation.Problem{
+				Name:        "number",
+				Description: fmt.Sprintf("course %d already has a section %d in term %d", section.CourseID, section.Number, section.TermID),
+			})
+		}
+
+		meetingProblems, err := checkMeetings(ctx, tx, id, section)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, meetingProblems...)
+	}
+
+	if section.InstructorID != nil {
+		var personType string
+		err := tx.QueryRowContext(ctx, "SELECT type FROM person WHERE id = $1 AND deleted_at IS NULL", *section.InstructorID).Scan(&personType)
+		switch {
+		case err == sql.ErrNoRows:
+			problems = append(problems, validation.Problem{Name: "instructor_id", Description: fmt.Sprintf("person %d does not exist", *section.InstructorID)})
+		case err != nil:
+			return fmt.Errorf("failed to look up person with id %d: %w", *section.InstructorID, err)
+		case personType != "professor":
+			problems = append(problems, validation.Problem{Name: "instructor_id", Description: fmt.Sprintf("person %d is not a professor", *section.InstructorID)})
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// enrollInSection enrolls a person in a section in the caller's transaction.
+func enrollInSection(ctx context.Context, tx *sql.Tx, set rules.Set, sectionID int, firstName, role string) (models.Section, error) {
+	return changeSectionEnrollment(ctx, tx, set, sectionID, firstName, func(person models.Person) error {
+		var other int
+		err := tx.QueryRowContext(ctx, `
+			SELECT o.id
+			FROM section s
+			JOIN section o ON o.course_id = s.course_id AND o.term_id = s.term_id AND o.id <> s.id
+			JOIN person_section ps ON ps.section_id = o.id AND ps.person_id = $2
+			WHERE s.id = $1
+			LIMIT 1`, sectionID, person.ID).Scan(&other)
+		if err == nil {
+			return &ValidationError{Problems: []validation.Problem{{
+				Name:        "first_name",
+				Description: fmt.Sprintf("%s is enrolled in section %d of the same course and term; withdraw from it first", firstName, other),
+			}}}
+		}
+		if err != sql.ErrNoRows {
+			return fmt.Errorf("failed to look up other sections of person with id %d: %w", person.ID, err)
+		}
+		return enroll(ctx, tx, set, person, sectionID, role, "id")
+	})
+}
+
+// withdrawFromSection withdraws a person from a section in the caller's
+// transaction, promoting its waitlist under the rules of set.
+func withdrawFromSection(ctx context.Context, tx *sql.Tx, set rules.Set, sectionID int, firstName string) (models.Section, error) {
+	return changeSectionEnrollment(ctx, tx, set, sectionID, firstName, func(person models.Person) error {
+		res, err := tx.ExecContext(ctx, "DELETE FROM person_section WHERE person_id = $1 AND section_id = $2", person.ID, sectionID)
+		if err != nil {
+			return fmt.Errorf("failed to withdraw person with id %d from section %d: %w", person.ID, sectionID, err)
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return fmt.Errorf("person with id %d is not enrolled in section %d: %w", person.ID, sectionID, sql.ErrNoRows)
+		}
+		return nil
+	})
+}
+
+// changeSectionEnrollment locks the person with firstName and a section,
+// applies change and, if the person's role in the section changed, records
+// the update of the section in the audit log, publishes the enrollment
+// events, drops their grade if they are no longer a student and gives a
+// student seat they gave up to the next person on the waitlist, checking them
+// against the rules of set.
+func changeSectionEnrollment(ctx context.Context, tx *sql.Tx, set rules.Set, sectionID int, firstName string, change func(person models.Person) error) (models.Section, error) {
+	person, err := getPersonForUpdate(ctx, tx, firstName)
+	if err != nil {
+		return models.Section{}, err
+	}
+	had, err := sectionRole(ctx, tx, person.ID, sectionID)
+	if err != nil {
+		return models.Section{}, err
+	}
+	if had == models.RoleStudent {
+		if err = lockWaitlisted(ctx, tx, []int{sectionID}); err != nil {
+			return models.Section{}, err
+		}
+	}
+	before, err := getSection(ctx, tx, sectionID, "FOR UPDATE OF s")
+	if err != nil {
+		return models.Section{}, err
+	}
+
+	if err = change(person); err != nil {
+		return models.Section{}, err
+	}
+
+	has, err := sectionRole(ctx, tx, person.ID, sectionID)
+	if err != nil || has == had {
+		return before, err
+	}
+	after, err := getSection(ctx, tx, sectionID, "")
+	if err != nil {
+		return models.Section{}, err
+	}
+
+	if err = recordAudit(ctx, tx, AuditUpdate, EntitySection, sectionID, before, after); err != nil {
+		return models.Section{}, err
+	}
+	enrollmentAs := func(role string) []models.Enrollment {
+		if role == "" {
+			return nil
+		}
+		return []models.Enrollment{{CourseID: before.CourseID, SectionID: sectionID, Role: role}}
+	}
+	if err = publishEnrollmentChanges(ctx, tx, person.ID, enrollmentAs(had), enrollmentAs(has)); err != nil {
+		return models.Section{}, err
+	}
+	if err = dropStaleGrades(ctx, tx, person.ID); err != nil {
+		return models.Section{}, err
+	}
+	if had == models.RoleStudent {
+		if err = promoteWaitlist(ctx, tx, set, sectionID); err != nil {
+			return models.Section{}, err
+		}
+		return getSection(ctx, tx, sectionID, "")
+	}
+	return after, nil
+}
+
+// getSection loads a section of a course that is not deleted and its roster,
+// applying lock, e.g. "FOR UPDATE OF s", if it is not empty. It returns an
+// error wrapping sql.ErrNoRows if there is no such section.
+func getSection(ctx context.Context, q querier, id int, lock string) (models.Section, error) {
+	section, err := scanSection(q.QueryRowContext(ctx, `
+		SELECT `+sectionColumns+`
+		FROM section s
+		JOIN course c ON c.id = s.course_id AND c.deleted_at IS NULL
+		WHERE s.id = $1 `+lock, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Section{}, fmt.Errorf("section with id %d not found: %w", id, err)
+		}
+		return models.Section{}, fmt.Errorf("failed to get section with id %d: %w", id, err)
+	}
+	return section, nil
+}
+
+// sectionRole returns a person's role in a section, or "" if they are not
+// enrolled in it.
+func sectionRole(ctx context.Context, q querier, personID, sectionID int) (string, error) {
+	var role string
+	err := q.QueryRowContext(ctx, "SELECT role FROM person_section WHERE person_id = $1 AND section_id = $2", personID, sectionID).Scan(&role)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to get the role of person with id %d in section %d: %w", personID, sectionID, err)
+	}
+	return role, nil
+}
+
+// courseSections returns the section of each course that is not deleted
+// among courseIDs that the person with personID is enrolled in by course,
+// keyed by course id: the section of the course they hold in the current term
+// or else its lowest numbered section in the current term, or 0 if it has
+// none. Unknown and deleted courses are left out.
+func courseSections(ctx context.Context, q querier, personID int, courseIDs []int) (map[int]int, error) {
+	sections := make(map[int]int, len(courseIDs))
+	if len(courseIDs) == 0 {
+		return sections, nil
+	}
+	rows, err := q.QueryContext(ctx, `
+		SELECT c.id, COALESCE(s.id, 0)
+		FROM course c
+		LEFT JOIN LATERAL (
+			SELECT s.id
+			FROM section s
+			LEFT JOIN person_section ps ON ps.section_id = s.id AND ps.person_id = $2
+			WHERE s.course_id = c.id AND s.term_id = `+currentTerm("CURRENT_DATE")+`
+			ORDER BY ps.person_id IS NULL, s.number
+			LIMIT 1
+		) s ON true
+		WHERE c.id = ANY($1) AND c.deleted_at IS NULL`, pq.Array(courseIDs), personID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the sections of courses: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var courseID, sectionID int
+		if err := rows.Scan(&courseID, &sectionID); err != nil {
+			return nil, fmt.Errorf("failed to scan course section: %w", err)
+		}
+		sections[courseID] = sectionID
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan course sections: %w", err)
+	}
+	return sections, nil
+}
+
+// querySectionIDs returns the ids of the sections of a course in every term.
+func querySectionIDs(ctx context.Context, q querier, courseID int) ([]int, error) {
+	rows, err := q.QueryContext(ctx, "SELECT id FROM section WHERE course_id = $1 ORDER BY id", courseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the sections of course %d: %w", courseID, err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan section id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan the sections of course %d: %w", courseID, err)
+	}
+	return slices.Clip(ids), nil
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"fmt"
+	"time"
+)
+
+// relations names what a read query selects persons, courses and enrollments
+// from. For the current state these are the tables themselves; for a point in
+// time they are the versions in the *_history tables that were valid then.
+// The history tables are kept up to date by triggers (see db_seed.sql), so
+// every write is captured whichever code path makes it.
+type relations struct {
+	person string
+	course string
+	// personCourse holds the enrollments in the sections of the term that
+	// was current at the time, with the course_id of their section.
+	personCourse string
+	// today is the date at the time, which the ages of persons are on.
+	today string
+}
+
+// relationsAt returns the relations for asOf, which is passed to the query as
+// the placeholder param. A zero asOf selects the current state and param is
+// not used.
+func relationsAt(asOf time.Time, param string) relations {
+	if asOf.IsZero() {
+		return relations{
+			person: "person",
+			course: "course",
+			personCourse: `(SELECT ps.person_id, s.course_id, ps.section_id, ps.role
+				FROM person_section ps
+				JOIN section s ON s.id = ps.section_id
+				WHERE s.term_id = ` + currentTerm("CURRENT_DATE") + `)`,
+			today: "CURRENT_DATE",
+		}
+	}
+	valid := fmt.Sprintf("valid_from <= %[1]s AND (valid_to IS NULL OR valid_to > %[1]s)", param)
+	return relations{
+		person: "(SELECT id, first_name, last_name, type, date_of_birth, email, phone, address, deleted_at FROM person_history WHERE " + valid + ")",
+		course: "(SELECT id, name, capacity, credits, department_id, catalog_number, description, deleted_at FROM course_history WHERE " + valid + ")",
+		personCourse: "(SELECT person_id, course_id, section_id, role FROM person_section_history WHERE " + valid +
+			" AND term_id = " + currentTerm(param+"::date") + ")",
+		today: param + "::date",
+	}
+}
+
+// currentTerm selects the id of the term current on date: the one that
+// started most recently on or before it, so a term stays current until the
+// next one starts. It is NULL before the first term.
+func currentTerm(date string) string {
+	return "(SELECT id FROM term WHERE start_date <= " + date + " ORDER BY start_date DESC, id DESC LIMIT 1)"
+}
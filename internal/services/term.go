@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+)
+
+type TermService struct {
+	DB *sql.DB
+}
+
+func NewTermService(db *sql.DB) *TermService {
+	return &TermService{
+		DB: db,
+	}
+}
+
+// termColumns selects a term t and whether it is the current term.
+var termColumns = "t.id, t.name, t.start_date, t.end_date, COALESCE(t.id = " + currentTerm("CURRENT_DATE") + ", false)"
+
+func scanTerm(row interface{ Scan(...any) error }) (models.Term, error) {
+	var term models.Term
+	err := row.Scan(&term.ID, &term.Name, &term.StartDate, &term.EndDate, &term.Current)
+	return term, err
+}
+
+// ListTerms returns every term in the order they start.
+func (t *TermService) ListTerms(ctx context.Context) ([]models.Term, error) {
+	rows, err := t.DB.QueryContext(ctx, "SELECT "+termColumns+" FROM term t ORDER BY t.start_date, t.id")
+	if err != nil {
+		return nil, fmt.Errorf("[in services.ListTerms] failed to get terms: %w", err)
+	}
+	defer rows.Close()
+
+	terms := []models.Term{}
+	for rows.Next() {
+		term, err := scanTerm(rows)
+		if err != nil {
+			return nil, fmt.Errorf("[in services.ListTerms] failed to scan term from row: %w", err)
+		}
+		terms = append(terms, term)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.ListTerms] failed to scan terms: %w", err)
+	}
+
+	return terms, nil
+}
+
+func (t *TermService) GetTerm(ctx context.Context, id int) (models.Term, error) {
+	term, err := scanTerm(t.DB.QueryRowContext(ctx, "SELECT "+termColumns+" FROM term t WHERE t.id = $1", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Term{}, fmt.Errorf("[in services.GetTerm] term with id %d not found: %w", id, err)
+		}
+		return models.Term{}, fmt.Errorf("[in services.GetTerm] failed to get term with id %d: %w", id, err)
+	}
+	return term, nil
+}
+
+// CreateTerm adds a term. A name already in use is rejected with a
+// *ValidationError.
+func (t *TermService) CreateTerm(ctx context.Context, term models.Term) (models.Term, error) {
+	return t.inTx(ctx, "CreateTerm", func(tx *sql.Tx) (models.Term, error) {
+		if err := checkTermName(ctx, tx, 0, term.Name); err != nil {
+			return models.Term{}, err
+		}
+
+		var id int
+		err := tx.QueryRowContext(ctx, "INSERT INTO term (name, start_date, end_date) VALUES ($1, $2, $3) RETURNING id",
+			term.Name, term.StartDate, term.EndDate).Scan(&id)
+		if err != nil {
+			return models.Term{}, fmt.Errorf("failed to create term: %w", err)
+		}
+		created, err := scanTerm(tx.QueryRowContext(ctx, "SELECT "+termColumns+" FROM term t WHERE t.id = $1", id))
+		if err != nil {
+			return models.Term{}, fmt.Errorf("failed to get created term with id %d: %w", id, err)
+		}
+
+		if err = recordAudit(ctx, tx, AuditCreate, EntityTerm, id, nil, created); err != nil {
+			return models.Term{}, err
+		}
+		if err = publish(ctx, tx, EventTermCreated, id, created); err != nil {
+			return models.Term{}, err
+		}
+		return created, nil
+	})
+}
+
+// UpdateTerm renames a term and moves its dates, which may change the
+// current term.
+func (t *TermService) UpdateTerm(ctx context.Context, id int, term models.Term) (models.Term, error) {
+	return t.inTx(ctx, "UpdateTerm", func(tx *sql.Tx) (models.Term, error) {
+		before, err := scanTerm(tx.QueryRowContext(ctx, "SELECT "+termColumns+" FROM term t WHERE t.id = $1 FOR UPDATE", id))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return models.Term{}, fmt.Errorf("term with id %d not found: %w", id, err)
+			}
+			return models.Term{}, fmt.Errorf("failed to get term with id %d: %w", id, err)
+		}
+		if err = checkTermName(ctx, tx, id, term.Name); err != nil {
+			return models.Term{}, err
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE term SET name = $1, start_date = $2, end_date = $3 WHERE id = $4",
+			term.Name, term.StartDate, term.EndDate, id)
+		if err != nil {
+			return models.Term{}, fmt.Errorf("failed to update term with id %d: %w", id, err)
+		}
+		after, err := scanTerm(tx.QueryRowContext(ctx, "SELECT "+termColumns+" FROM term t WHERE t.id = $1", id))
+		if err != nil {
+			return models.Term{}, fmt.Errorf("failed to get updated term with id %d: %w", id, err)
+		}
+
+		if err = recordAudit(ctx, tx, AuditUpdate, EntityTerm, id, before, after); err != nil {
+			return models.Term{}, err
+		}
+		if err = publish(ctx, tx, EventTermUpdated, id, after); err != nil {
+			return models.Term{}, err
+		}
+		return after, nil
+	})
+}
+
+// DeleteTerm removes a term that has no sections; one that has is rejected
+// with a *ValidationError.
+func (t *TermService) DeleteTerm(ctx context.Context, id int) error {
+	_, err := t.inTx(ctx, "DeleteTerm", func(tx *sql.Tx) (models.Term, error) {
+		before, err := scanTerm(tx.QueryRowContext(ctx, "SELECT "+termColumns+" FROM term t WHERE t.id = $1 FOR UPDATE", id))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return models.Term{}, fmt.Errorf("term with id %d not found: %w", id, err)
+			}
+			return models.Term{}, fmt.Errorf("failed to get term with id %d: %w", id, err)
+		}
+
+		var sections int
+		if err = tx.QueryRowContext(ctx, "SELECT count(*) FROM section WHERE term_id = $1", id).Scan(&sections); err != nil {
+			return models.Term{}, fmt.Errorf("failed to count the sections of term %d: %w", id, err)
+		}
+		if sections > 0 {
+			return models.Term{}, &ValidationError{Problems: []validation.Problem{{
+				Name:        "id",
+				Description: fmt.Sprintf("term %d has %d sections; delete them first", id, sections),
+			}}}
+		}
+
+		if _, err = tx.ExecContext(ctx, "DELETE FROM term WHERE id = $1", id); err != nil {
+			return models.Term{}, fmt.Errorf("failed to delete term with id %d: %w", id, err)
+		}
+		if err = recordAudit(ctx, tx, AuditDelete, EntityTerm, id, before, nil); err != nil {
+			return models.Term{}, err
+		}
+		return models.Term{}, publish(ctx, tx, EventTermDeleted, id, before)
+	})
+	return err
+}
+
+// checkTermName returns a *ValidationError if a term other than the one with
+// id is named name.
+func checkTermName(ctx context.Context, tx *sql.Tx, id int, name string) error {
+	var taken bool
+	err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM term WHERE name = $1 AND id <> $2)", name, id).Scan(&taken)
+	if err != nil {
+		return fmt.Errorf("failed to check term name %s: %w", name, err)
+	}
+	if taken {
+		return &ValidationError{Problems: []validation.Problem{{
+			Name:        "name",
+			Description: fmt.Sprintf("term %s already exists", name),
+		}}}
+	}
+	return nil
+}
+
+// inTx runs fn in a new transaction, committing it if fn succeeds.
+func (t *TermService) inTx(ctx context.Context, method string, fn func(tx *sql.Tx) (models.Term, error)) (models.Term, error) {
+	tx, err := t.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Term{}, fmt.Errorf("[in services.%s] failed to begin transaction: %w", method, err)
+	}
+
+	term, err := fn(tx)
+	if err != nil {
+		tx.Rollback()
+		return models.Term{}, fmt.Errorf("[in services.%s] %w", method, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Term{}, fmt.Errorf("[in services.%s] failed to commit transaction: %w", method, err)
+	}
+
+	return term, nil
+}
@@ -0,0 +1,480 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/rules"
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/validation"
+	"github.com/lib/pq"
+)
+
+// Seats limit the students of a section only; instructors, teaching
+// assistants and auditors do not take one, nor do persons that have been
+// deleted. Every write that can take or free a seat locks the section row FOR
+// UPDATE before counting them, so concurrent enrollments in the same section
+// queue behind each other and cannot both take its last seat.
+//
+// Locks are taken in one order so that writes cannot deadlock: first the
+// person a change is for, then the persons waiting for the seats it may free
+// (see lockWaitlisted), then sections in id order. Promotion runs with the
+// sections locked, so it only enrolls persons it can lock without waiting.
+//
+// The waitlist routes of a course act on its sections in the current term; a
+// person joins the waitlist of the section they would be enrolled in by
+// AddEnrollment.
+
+// ListWaitlist returns the persons waiting for a seat in the sections of a
+// course that is not deleted in the current term, in the order they will be
+// enrolled. It returns an error wrapping sql.ErrNoRows if the course does not
+// exist.
+func (c *CourseService) ListWaitlist(ctx context.Context, courseID int) ([]models.WaitlistEntry, error) {
+	var id int
+	err := c.DB.QueryRowContext(ctx, "SELECT id FROM course WHERE id = $1 AND deleted_at IS NULL", courseID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("[in services.ListWaitlist] course with id %d not found: %w", courseID, err)
+		}
+		return nil, fmt.Errorf("[in services.ListWaitlist] failed to get course with id %d: %w", courseID, err)
+	}
+
+	entries, err := queryWaitlist(ctx, c.DB, "course_id = $1 AND term_id = "+currentTerm("CURRENT_DATE"), courseID)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.ListWaitlist] %w", err)
+	}
+	return entries, nil
+}
+
+// JoinWaitlist puts the person with firstName at the end of the waitlist of
+// their section of a course in the current term, which must be full, and
+// returns their place in it; joining again keeps the place they have.
+// Persons who are already students of the section, lack the course's
+// prerequisites, hold a section meeting at the same time or would break an
+// eligibility rule as its students, and sections with free seats, are
+// rejected with a *ValidationError.
+func (c *CourseService) JoinWaitlist(ctx context.Context, courseID int, firstName string) (models.WaitlistEntry, error) {
+	tx, err := c.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.WaitlistEntry{}, fmt.Errorf("[in services.JoinWaitlist] failed to begin transaction: %w", err)
+	}
+
+	entry, err := joinWaitlist(ctx, tx, c.Rules, courseID, firstName)
+	if err != nil {
+		tx.Rollback()
+		return models.WaitlistEntry{}, fmt.Errorf("[in services.JoinWaitlist] %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.WaitlistEntry{}, fmt.Errorf("[in services.JoinWaitlist] failed to commit transaction: %w", err)
+	}
+
+	return entry, nil
+}
+
+func joinWaitlist(ctx context.Context, tx *sql.Tx, set rules.Set, courseID int, firstName string) (models.WaitlistEntry, error) {
+	person, err := getPersonForUpdate(ctx, tx, firstName)
+	if err != nil {
+		return models.WaitlistEntry{}, err
+	}
+	sections, err := courseSections(ctx, tx, person.ID, []int{courseID})
+	if err != nil {
+		return models.WaitlistEntry{}, err
+	}
+	sectionID, ok := sections[courseID]
+	if !ok {
+		return models.WaitlistEntry{}, fmt.Errorf("course with id %d not found: %w", courseID, sql.ErrNoRows)
+	}
+	if sectionID == 0 {
+		return models.WaitlistEntry{}, &ValidationError{Problems: []validation.Problem{{
+			Name:        "id",
+			Description: fmt.Sprintf("course %d has no section in the current term", courseID),
+		}}}
+	}
+	capacity, taken, err := lockSeats(ctx, tx, sectionID)
+	if err != nil {
+		return models.WaitlistEntry{}, err
+	}
+
+	if slices.Contains(person.Enrollments, models.Enrollment{CourseID: courseID, SectionID: sectionID, Role: models.RoleStudent}) {
+		return models.WaitlistEntry{}, &ValidationError{Problems: []validation.Problem{{
+			Name:        "first_name",
+			Description: fmt.Sprintf("%s is already a student of course %d", firstName, courseID),
+		}}}
+	}
+	if err = checkPrerequisites(ctx, tx, person.ID, sectionID, models.RoleStudent, "id"); err != nil {
+		return models.WaitlistEntry{}, err
+	}
+	if err = checkSchedule(ctx, tx, person.ID, sectionID, "id"); err != nil {
+		return models.WaitlistEntry{}, err
+	}
+	if err = checkSectionRules(ctx, tx, set, person, sectionID, models.RoleStudent, "id"); err != nil {
+		return models.WaitlistEntry{}, err
+	}
+	if capacity == nil || taken < *capacity {
+		return models.WaitlistEntry{}, &ValidationError{Problems: []validation.Problem{{
+			Name:        "id",
+			Description: fmt.Sprintf("section %d of course %d has free seats; enroll in it instead", sectionID, courseID),
+		}}}
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO section_waitlist (section_id, person_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", sectionID, person.ID)
+	if err != nil {
+		return models.WaitlistEntry{}, fmt.Errorf("failed to add person with id %d to the waitlist of section %d: %w", person.ID, sectionID, err)
+	}
+
+	entries, err := queryWaitlist(ctx, tx, "section_id = $1 AND person_id = $2", sectionID, person.ID)
+	if err != nil {
+		return models.WaitlistEntry{}, err
+	}
+	if len(entries) == 0 {
+		return models.WaitlistEntry{}, fmt.Errorf("person with id %d is missing from the waitlist of section %d", person.ID, sectionID)
+	}
+	return entries[0], nil
+}
+
+// LeaveWaitlist removes the person with firstName from the waitlist of their
+// section of a course in the current term. It returns an error wrapping
+// sql.ErrNoRows if they are not on it.
+func (c *CourseService) LeaveWaitlist(ctx context.Context, courseID int, firstName string) error {
+	res, err := c.DB.ExecContext(ctx, `
+		DELETE FROM section_waitlist w
+		USING person p, section s
+		WHERE p.id = w.person_id AND s.id = w.section_id AND s.course_id = $1 AND p.first_name = $2
+		  AND p.deleted_at IS NULL AND s.term_id = `+currentTerm("CURRENT_DATE"), courseID, firstName)
+	if err != nil {
+		return fmt.Errorf("[in services.LeaveWaitlist] failed to remove %s from the waitlist of course %d: %w", firstName, courseID, err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return fmt.Errorf("[in services.LeaveWaitlist] %s is not on the waitlist of course %d: %w", firstName, courseID, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// ListWaitlistPositions returns the places of the person with firstName in
+// the waitlists of the sections they are waiting for, in any term, in course
+// and section id order. It returns an error wrapping sql.ErrNoRows if the
+// person does not exist.
+func (p *PersonService) ListWaitlistPositions(ctx context.Context, firstName string) ([]models.WaitlistEntry, error) {
+	var personID int
+	err := p.DB.QueryRowContext(ctx, "SELECT id FROM person WHERE first_name = $1 AND deleted_at IS NULL", firstName).Scan(&personID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("[in services.ListWaitlistPositions] person with first name %s not found: %w", firstName, err)
+		}
+		return nil, fmt.Errorf("[in services.ListWaitlistPositions] failed to get person with first name %s: %w", firstName, err)
+	}
+
+	entries, err := queryWaitlist(ctx, p.DB, "person_id = $1", personID)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.ListWaitlistPositions] %w", err)
+	}
+	return entries, nil
+}
+
+// queryWaitlist returns the waitlist entries matching where, numbered within
+// the waitlist of their section. where can refer to the course_id and
+// term_id of the section. Deleted persons and courses are skipped.
+func queryWaitlist(ctx context.Context, q querier, where string, args ...any) ([]models.WaitlistEntry, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT course_id, section_id, person_id, first_name, last_name, position, added_at
+		FROM (
+			SELECT s.course_id, s.term_id, w.section_id, w.person_id, p.first_name, p.last_name, w.added_at,
+				row_number() OVER (PARTITION BY w.section_id ORDER BY w.id) AS position
+			FROM section_waitlist w
+			JOIN section s ON s.id = w.section_id
+			JOIN person p ON p.id = w.person_id AND p.deleted_at IS NULL
+			JOIN course c ON c.id = s.course_id AND c.deleted_at IS NULL
+		) w
+		WHERE `+where+`
+		ORDER BY course_id, section_id, position`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.queryWaitlist] failed to get waitlist: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.WaitlistEntry{}
+	for rows.Next() {
+		var entry models.WaitlistEntry
+		if err := rows.Scan(&entry.CourseID, &entry.SectionID, &entry.PersonID, &entry.FirstName, &entry.LastName, &entry.Position, &entry.AddedAt); err != nil {
+			return nil, fmt.Errorf("[in services.queryWaitlist] failed to scan waitlist entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.queryWaitlist] failed to scan waitlist: %w", err)
+	}
+
+	return entries, nil
+}
+
+// lockSeats locks a section of a course that is not deleted and returns its
+// capacity and the number of seats taken. The course is locked FOR SHARE so
+// it cannot be deleted meanwhile. It returns an error wrapping sql.ErrNoRows
+// if the section does not exist.
+func lockSeats(ctx context.Context, tx *sql.Tx, sectionID int) (*int, int, error) {
+	var capacity *int
+	err := tx.QueryRowContext(ctx, `
+		SELECT s.capacity
+		FROM section s
+		JOIN course c ON c.id = s.course_id AND c.deleted_at IS NULL
+		WHERE s.id = $1
+		FOR UPDATE OF s FOR SHARE OF c`, sectionID).Scan(&capacity)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, 0, fmt.Errorf("section with id %d not found: %w", sectionID, err)
+		}
+		return nil, 0, fmt.Errorf("failed to lock section with id %d: %w", sectionID, err)
+	}
+
+	var taken int
+	err = tx.QueryRowContext(ctx, `
+		SELECT count(*)
+		FROM person_section ps
+		JOIN person p ON p.id = ps.person_id AND p.deleted_at IS NULL
+		WHERE ps.section_id = $1 AND ps.role = $2`, sectionID, models.RoleStudent).Scan(&taken)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count the students of section %d: %w", sectionID, err)
+	}
+	return capacity, taken, nil
+}
+
+// lockSections locks the sections with the given ids FOR UPDATE in id order,
+// so that transactions enrolling in several sections cannot deadlock.
+func lockSections(ctx context.Context, tx *sql.Tx, sectionIDs []int) error {
+	if len(sectionIDs) == 0 {
+		return nil
+	}
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM section WHERE id = ANY($1) ORDER BY id FOR UPDATE", pq.Array(sectionIDs))
+	if err != nil {
+		return fmt.Errorf("failed to lock sections: %w", err)
+	}
+	return rows.Close()
+}
+
+// fullSections returns the sections among enrollments that the person with
+// personID would take a new student seat in but that have none left.
+func fullSections(ctx context.Context, tx *sql.Tx, personID int, enrollments []models.Enrollment) (map[int]bool, error) {
+	var ids []int
+	for _, enrollment := range enrollments {
+		if enrollment.Role == models.RoleStudent {
+			ids = append(ids, enrollment.SectionID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT s.id
+		FROM section s
+		WHERE s.id = ANY($1) AND s.capacity IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM person_section WHERE person_id = $2 AND section_id = s.id AND role = $3)
+		  AND s.capacity <= (
+			SELECT count(*)
+			FROM person_section ps
+			JOIN person p ON p.id = ps.person_id AND p.deleted_at IS NULL
+			WHERE ps.section_id = s.id AND ps.role = $3)`, pq.Array(ids), personID, models.RoleStudent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the seats of sections: %w", err)
+	}
+	defer rows.Close()
+
+	full := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan full section: %w", err)
+		}
+		full[id] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan full sections: %w", err)
+	}
+	return full, nil
+}
+
+// leaveFilledWaitlists removes a person from the waitlists of the sections
+// they are now a student of.
+func leaveFilledWaitlists(ctx context.Context, tx *sql.Tx, personID int) error {
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM section_waitlist w
+		USING person_section ps
+		WHERE ps.person_id = w.person_id AND ps.section_id = w.section_id
+		  AND w.person_id = $1 AND ps.role = $2`, personID, models.RoleStudent)
+	if err != nil {
+		return fmt.Errorf("failed to update the waitlists of person with id %d: %w", personID, err)
+	}
+	return nil
+}
+
+// lockWaitlisted locks the persons waiting for a seat in the sections with
+// the given ids FOR UPDATE in id order. Changes that may free a seat in them
+// call it before locking the sections, so that promotion finds the persons
+// next in line locked already.
+func lockWaitlisted(ctx context.Context, tx *sql.Tx, sectionIDs []int) error {
+	if len(sectionIDs) == 0 {
+		return nil
+	}
+	rows, err := tx.QueryContext(ctx, `
+		SELECT p.id
+		FROM person p
+		WHERE p.deleted_at IS NULL
+		  AND p.id IN (SELECT person_id FROM section_waitlist WHERE section_id = ANY($1))
+		ORDER BY p.id
+		FOR UPDATE`, pq.Array(sectionIDs))
+	if err != nil {
+		return fmt.Errorf("failed to lock waitlisted persons: %w", err)
+	}
+	return rows.Close()
+}
+
+// studentSections returns the sections among enrollments that are held with
+// a student seat.
+func studentSections(enrollments []models.Enrollment) []int {
+	var ids []int
+	for _, enrollment := range enrollments {
+		if enrollment.Role == models.RoleStudent {
+			ids = append(ids, enrollment.SectionID)
+		}
+	}
+	return ids
+}
+
+// promoteFreedSeats promotes the waitlists of the sections in which a person
+// held a student seat in before but not in after.
+func promoteFreedSeats(ctx context.Context, tx *sql.Tx, set rules.Set, before, after []models.Enrollment) error {
+	var freed []int
+	for _, enrollment := range before {
+		if enrollment.Role == models.RoleStudent && !slices.Contains(after, enrollment) {
+			freed = append(freed, enrollment.SectionID)
+		}
+	}
+	return promoteSections(ctx, tx, set, freed)
+}
+
+// allStudentSections returns every section, in any term, in which the person
+// with personID holds a student seat.
+func allStudentSections(ctx context.Context, tx *sql.Tx, personID int) ([]int, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT section_id FROM person_section WHERE person_id = $1 AND role = $2", personID, models.RoleStudent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the sections of person with id %d: %w", personID, err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan section id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan the sections of person with id %d: %w", personID, err)
+	}
+	return ids, nil
+}
+
+// promoteSections promotes the waitlists of the sections in id order.
+func promoteSections(ctx context.Context, tx *sql.Tx, set rules.Set, sectionIDs []int) error {
+	slices.Sort(sectionIDs)
+	for _, sectionID := range slices.Compact(sectionIDs) {
+		if err := promoteWaitlist(ctx, tx, set, sectionID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// promoteWaitlist enrolls the first persons on the waitlist of a section as
+// students while it has free seats, recording each enrollment like Enroll.
+// Persons Enroll would now reject, for missing prerequisites, a clashing
+// section or an eligibility rule of set, are passed over but keep their
+// place. So are persons another transaction holds a lock on, since the
+// section is locked already; see lockWaitlisted. The overrides of the change
+// that freed the seats do not apply. Sections of deleted courses are left
+// alone.
+func promoteWaitlist(ctx context.Context, tx *sql.Tx, set rules.Set, sectionID int) error {
+	capacity, taken, err := lockSeats(ctx, tx, sectionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	// A NULL limit promotes everyone waiting
+	if capacity != nil && taken >= *capacity {
+		return nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT p.first_name
+		FROM section_waitlist w
+		JOIN person p ON p.id = w.person_id AND p.deleted_at IS NULL
+		WHERE w.section_id = $1
+		ORDER BY w.id
+		FOR UPDATE OF p SKIP LOCKED`, sectionID)
+	if err != nil {
+		return fmt.Errorf("failed to get the waitlist of section %d: %w", sectionID, err)
+	}
+	var next []string
+	for rows.Next() {
+		var firstName string
+		if err := rows.Scan(&firstName); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan waitlisted person: %w", err)
+		}
+		next = append(next, firstName)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to scan the waitlist of section %d: %w", sectionID, err)
+	}
+
+	ctx = withoutOverrides(ctx)
+	for _, firstName := range next {
+		if capacity != nil && taken >= *capacity {
+			break
+		}
+		_, err := changeSectionEnrollment(ctx, tx, set, sectionID, firstName, func(person models.Person) error {
+			if err := checkPrerequisites(ctx, tx, person.ID, sectionID, models.RoleStudent, "id"); err != nil {
+				return err
+			}
+			if err := checkSchedule(ctx, tx, person.ID, sectionID, "id"); err != nil {
+				return err
+			}
+			if err := checkSectionRules(ctx, tx, set, person, sectionID, models.RoleStudent, "id"); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO person_section (person_id, section_id, role) VALUES ($1, $2, $3)
+				ON CONFLICT (person_id, section_id) DO UPDATE SET role = EXCLUDED.role`, person.ID, sectionID, models.RoleStudent)
+			if err != nil {
+				return fmt.Errorf("failed to enroll waitlisted person with id %d in section %d: %w", person.ID, sectionID, err)
+			}
+			return leaveFilledWaitlists(ctx, tx, person.ID)
+		})
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		taken++
+	}
+	return nil
+}
+
+// withoutOverrides returns ctx without the overrides of
+// WithPrerequisiteOverride and WithRuleOverride, for changes made on behalf of
+// persons other than the one they were given for.
+func withoutOverrides(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, prerequisiteOverrideKey{}, false)
+	return context.WithValue(ctx, ruleOverrideKey{}, false)
+}
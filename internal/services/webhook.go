@@ -0,0 +1,405 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/jaysinghcodes-captech/Go-API-Tech-Challenge/internal/models"
+	"github.com/lib/pq"
+)
+
+// Webhook delivery statuses recorded in webhook_delivery.status.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// DefaultDeliveryLimit caps ListDeliveries when no limit is given.
+const DefaultDeliveryLimit = 100
+
+type WebhookService struct {
+	DB *sql.DB
+}
+
+func NewWebhookService(db *sql.DB) *WebhookService {
+	return &WebhookService{
+		DB: db,
+	}
+}
+
+// PendingDelivery is a delivery claimed by the dispatcher, with everything
+// needed to send it.
+type PendingDelivery struct {
+	ID      int64
+	Attempt int
+	URL     string
+	Secret  string
+	Event   models.OutboxEvent
+}
+
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	rows, err := s.DB.QueryContext(ctx, "SELECT id, url, secret, events, active, created_at FROM webhook_subscription ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("[in services.ListSubscriptions] failed to get subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []models.WebhookSubscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("[in services.ListSubscriptions] failed to scan subscription from row: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.ListSubscriptions] failed to scan subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+func (s *WebhookService) GetSubscription(ctx context.Context, id int) (models.WebhookSubscription, error) {
+	row := s.DB.QueryRowContext(ctx, "SELECT id, url, secret, events, active, created_at FROM webhook_subscription WHERE id = $1", id)
+	sub, err := scanSubscription(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.WebhookSubscription{}, fmt.Errorf("[in services.GetSubscription] subscription with id %d not found: %w", id, err)
+		}
+		return models.WebhookSubscription{}, fmt.Errorf("[in services.GetSubscription] failed to get subscription with id %d: %w", id, err)
+	}
+	return sub, nil
+}
+
+// CreateSubscription registers a webhook. An empty Events list subscribes to
+// every event type. A random secret is generated if none is given; the
+// returned subscription is the only place it is reported.
+func (s *WebhookService) CreateSubscription(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	if sub.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return models.WebhookSubscription{}, fmt.Errorf("[in services.CreateSubscription] %w", err)
+		}
+		sub.Secret = secret
+	}
+	if sub.Events == nil {
+		sub.Events = []string{}
+	}
+
+	err := s.DB.QueryRowContext(ctx, `
+		INSERT INTO webhook_subscription (url, secret, events, active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		sub.URL, sub.Secret, pq.Array(sub.Events), sub.Active).Scan(&sub.ID, &sub.CreatedAt)
+	if err != nil {
+		return models.WebhookSubscription{}, fmt.Errorf("[in services.CreateSubscription] failed to create subscription: %w", err)
+	}
+
+	return sub, nil
+}
+
+// UpdateSubscription replaces the URL, event types and active flag of a
+// subscription. The secret is kept.
+func (s *WebhookService) UpdateSubscription(ctx context.Context, id int, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	if sub.Events == nil {
+		sub.Events = []string{}
+	}
+
+	row := s.DB.QueryRowContext(ctx, `
+		UPDATE webhook_subscription SET url = $1, events = $2, active = $3
+		WHERE id = $4
+		RETURNING id, url, secret, events, active, created_at`,
+		sub.URL, pq.Array(sub.Events), sub.Active, id)
+	updated, err := scanSubscription(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.WebhookSubscription{}, fmt.Errorf("[in services.UpdateSubscription] subscription with id %d not found: %w", id, err)
+		}
+		return models.WebhookSubscription{}, fmt.Errorf("[in services.UpdateSubscription] failed to update subscription with id %d: %w", id, err)
+	}
+
+	return updated, nil
+}
+
+// DeleteSubscription removes a subscription and its delivery history.
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id int) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("[in services.DeleteSubscription] failed to begin transaction: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM webhook_delivery WHERE subscription_id = $1", id)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("[in services.DeleteSubscription] failed to delete deliveries of subscription %d: %w", id, err)
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM webhook_subscription WHERE id = $1", id)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("[in services.DeleteSubscription] failed to delete subscription %d: %w", id, err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		tx.Rollback()
+		return fmt.Errorf("[in services.DeleteSubscription] subscription with id %d not found: %w", id, sql.ErrNoRows)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("[in services.DeleteSubscription] failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeliveries returns the deliveries of a subscription, newest first,
+// optionally only those with the given status.
+func (s *WebhookService) ListDeliveries(ctx context.Context, subscriptionID int, status string, limit int) ([]models.WebhookDelivery, error) {
+	if limit <= 0 {
+		limit = DefaultDeliveryLimit
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT d.id, d.subscription_id, d.event_id, e.type, d.status, d.attempts, d.next_attempt_at,
+			d.last_status_code, d.last_error, d.delivered_at, d.created_at
+		FROM webhook_delivery d
+		JOIN outbox_event e ON e.id = d.event_id
+		WHERE d.subscription_id = $1 AND ($2 = '' OR d.status = $2)
+		ORDER BY d.id DESC
+		LIMIT $3`, subscriptionID, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.ListDeliveries] failed to get deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.LastStatusCode, &d.LastError, &d.DeliveredAt, &d.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("[in services.ListDeliveries] failed to scan delivery from row: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.ListDeliveries] failed to scan deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// ReplayDelivery queues a delivery to be sent again straight away with a
+// fresh retry budget, whatever its current status.
+func (s *WebhookService) ReplayDelivery(ctx context.Context, subscriptionID int, deliveryID int64) error {
+	result, err := s.DB.ExecContext(ctx, `
+		UPDATE webhook_delivery
+		SET status = 'pending', attempts = 0, next_attempt_at = now(), last_error = ''
+		WHERE id = $1 AND subscription_id = $2`, deliveryID, subscriptionID)
+	if err != nil {
+		return fmt.Errorf("[in services.ReplayDelivery] failed to replay delivery %d: %w", deliveryID, err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("[in services.ReplayDelivery] delivery %d of subscription %d not found: %w", deliveryID, subscriptionID, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// ReplayEvents queues every event since from that the subscription's event
+// types match, including events that were already delivered, and returns how
+// many were queued.
+func (s *WebhookService) ReplayEvents(ctx context.Context, subscriptionID int, from time.Time) (int, error) {
+	if _, err := s.GetSubscription(ctx, subscriptionID); err != nil {
+		return 0, fmt.Errorf("[in services.ReplayEvents] %w", err)
+	}
+
+	result, err := s.DB.ExecContext(ctx, `
+		INSERT INTO webhook_delivery (subscription_id, event_id)
+		SELECT s.id, e.id
+		FROM outbox_event e
+		JOIN webhook_subscription s ON cardinality(s.events) = 0 OR e.type = ANY (s.events)
+		WHERE s.id = $1 AND e.occurred_at >= $2
+		ON CONFLICT (subscription_id, event_id) DO UPDATE
+		SET status = 'pending', attempts = 0, next_attempt_at = now(), last_error = ''`, subscriptionID, from)
+	if err != nil {
+		return 0, fmt.Errorf("[in services.ReplayEvents] failed to queue events: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("[in services.ReplayEvents] failed to get rows affected: %w", err)
+	}
+	return int(n), nil
+}
+
+// EnqueueDeliveries fans undispatched outbox events out into one delivery per
+// matching active subscription and marks the events dispatched. It handles at
+// most limit events and returns how many it handled. Concurrent dispatchers
+// skip each other's events.
+func (s *WebhookService) EnqueueDeliveries(ctx context.Context, limit int) (int, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("[in services.EnqueueDeliveries] failed to begin transaction: %w", err)
+	}
+
+	var ids pq.Int64Array
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(array_agg(id), '{}') FROM (
+			SELECT id FROM outbox_event
+			WHERE dispatched_at IS NULL
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		) pending`, limit).Scan(&ids)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("[in services.EnqueueDeliveries] failed to get outbox events: %w", err)
+	}
+	if len(ids) == 0 {
+		tx.Rollback()
+		return 0, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO webhook_delivery (subscription_id, event_id)
+		SELECT s.id, e.id
+		FROM outbox_event e
+		JOIN webhook_subscription s ON s.active AND (cardinality(s.events) = 0 OR e.type = ANY (s.events))
+		WHERE e.id = ANY ($1)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`, ids)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("[in services.EnqueueDeliveries] failed to create deliveries: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE outbox_event SET dispatched_at = now() WHERE id = ANY ($1)", ids)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("[in services.EnqueueDeliveries] failed to mark events dispatched: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("[in services.EnqueueDeliveries] failed to commit transaction: %w", err)
+	}
+
+	return len(ids), nil
+}
+
+// ClaimDeliveries takes up to limit due deliveries for sending, counting the
+// attempt. Each is leased for lease: if it is not completed or failed by
+// then, for example because the instance crashed, it becomes due again.
+// Deliveries to inactive subscriptions stay pending until they are active.
+func (s *WebhookService) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]PendingDelivery, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		WITH claimed AS (
+			UPDATE webhook_delivery
+			SET attempts = attempts + 1, next_attempt_at = now() + make_interval(secs => $2)
+			WHERE id IN (
+				SELECT d.id FROM webhook_delivery d
+				JOIN webhook_subscription s ON s.id = d.subscription_id
+				WHERE d.status = 'pending' AND d.next_attempt_at <= now() AND s.active
+				ORDER BY d.next_attempt_at
+				LIMIT $1
+				FOR UPDATE OF d SKIP LOCKED
+			)
+			RETURNING id, subscription_id, event_id, attempts
+		)
+		SELECT c.id, c.attempts, s.url, s.secret,
+			e.id, e.type, e.occurred_at, e.actor, e.request_id, e.entity_id, e.data
+		FROM claimed c
+		JOIN webhook_subscription s ON s.id = c.subscription_id AND s.active
+		JOIN outbox_event e ON e.id = c.event_id
+		ORDER BY e.id`, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("[in services.ClaimDeliveries] failed to claim deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var claimed []PendingDelivery
+	for rows.Next() {
+		var (
+			d    PendingDelivery
+			data []byte
+		)
+		err := rows.Scan(&d.ID, &d.Attempt, &d.URL, &d.Secret,
+			&d.Event.ID, &d.Event.Type, &d.Event.OccurredAt, &d.Event.Actor, &d.Event.RequestID, &d.Event.EntityID, &data)
+		if err != nil {
+			return nil, fmt.Errorf("[in services.ClaimDeliveries] failed to scan delivery from row: %w", err)
+		}
+		d.Event.Data = data
+		claimed = append(claimed, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.ClaimDeliveries] failed to scan deliveries: %w", err)
+	}
+
+	return claimed, nil
+}
+
+// CompleteDelivery records a successful delivery.
+func (s *WebhookService) CompleteDelivery(ctx context.Context, id int64, statusCode int) error {
+	_, err := s.DB.ExecContext(ctx, `
+		UPDATE webhook_delivery
+		SET status = 'delivered', last_status_code = $2, last_error = '', delivered_at = now()
+		WHERE id = $1`, id, statusCode)
+	if err != nil {
+		return fmt.Errorf("[in services.CompleteDelivery] failed to update delivery %d: %w", id, err)
+	}
+	return nil
+}
+
+// FailDelivery records a failed attempt. The delivery is retried at retryAt,
+// or dead-lettered if retryAt is zero. statusCode is 0 if no response was
+// received.
+func (s *WebhookService) FailDelivery(ctx context.Context, id int64, statusCode int, reason string, retryAt time.Time) error {
+	var code *int
+	if statusCode > 0 {
+		code = &statusCode
+	}
+
+	var err error
+	if retryAt.IsZero() {
+		_, err = s.DB.ExecContext(ctx, `
+			UPDATE webhook_delivery SET status = 'dead', last_status_code = $2, last_error = $3
+			WHERE id = $1`, id, code, reason)
+	} else {
+		_, err = s.DB.ExecContext(ctx, `
+			UPDATE webhook_delivery SET next_attempt_at = $4, last_status_code = $2, last_error = $3
+			WHERE id = $1`, id, code, reason, retryAt)
+	}
+	if err != nil {
+		return fmt.Errorf("[in services.FailDelivery] failed to update delivery %d: %w", id, err)
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSubscription(row rowScanner) (models.WebhookSubscription, error) {
+	var (
+		sub    models.WebhookSubscription
+		events pq.StringArray
+	)
+	if err := row.Scan(&sub.ID, &sub.URL, &sub.Secret, &events, &sub.Active, &sub.CreatedAt); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	sub.Events = []string(events)
+	return sub, nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingDriver is a database/sql driver that records each query and
+// returns no rows.
+type recordingDriver struct {
+	queries []string
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return recordingConn{d}, nil }
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return recordingStmt{c.d, query}, nil
+}
+func (recordingConn) Close() error              { return nil }
+func (recordingConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type recordingStmt struct {
+	d     *recordingDriver
+	query string
+}
+
+func (recordingStmt) Close() error  { return nil }
+func (recordingStmt) NumInput() int { return -1 }
+func (s recordingStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.d.queries = append(s.d.queries, s.query)
+	return driver.RowsAffected(0), nil
+}
+func (s recordingStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.d.queries = append(s.d.queries, s.query)
+	return noRows{}, nil
+}
+
+type noRows struct{}
+
+func (noRows) Columns() []string         { return nil }
+func (noRows) Close() error              { return nil }
+func (noRows) Next([]driver.Value) error { return io.EOF }
+
+func TestClaimDeliveriesSkipsInactiveSubscriptions(t *testing.T) {
+	d := &recordingDriver{}
+	sql.Register("recording-claim", d)
+	db, err := sql.Open("recording-claim", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := NewWebhookService(db).ClaimDeliveries(context.Background(), 10, time.Minute); err != nil {
+		t.Fatalf("ClaimDeliveries() error = %v", err)
+	}
+	if len(d.queries) != 1 {
+		t.Fatalf("ran %d queries, want 1", len(d.queries))
+	}
+
+	// The deliveries to claim are picked in the UPDATE's subquery, so that
+	// is where inactive subscriptions have to be left out: otherwise their
+	// deliveries are claimed and their attempts counted without being sent.
+	query := strings.Join(strings.Fields(d.queries[0]), " ")
+	pick := regexp.MustCompile(`WHERE id IN \((.*?)\) RETURNING`).FindStringSubmatch(query)
+	if pick == nil {
+		t.Fatalf("query has no claim subquery: %s", query)
+	}
+	if !strings.Contains(pick[1], "JOIN webhook_subscription s ON s.id = d.subscription_id") ||
+		!strings.Contains(pick[1], "AND s.active") {
+		t.Errorf("claim subquery does not require an active subscription: %s", pick[1])
+	}
+}
@@ -0,0 +1,178 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var builtin = map[string]Func{
+	"required": required,
+	"min":      bound(func(n, limit float64) bool { return n >= limit }, "at least"),
+	"max":      bound(func(n, limit float64) bool { return n <= limit }, "at most"),
+	"len":      length,
+	"oneof":    oneOf,
+	"regex":    matchRegex,
+	"gtfield":  compareField(func(c int) bool { return c > 0 }, "greater than"),
+	"gtefield": compareField(func(c int) bool { return c >= 0 }, "greater than or equal to"),
+	"ltfield":  compareField(func(c int) bool { return c < 0 }, "less than"),
+	"ltefield": compareField(func(c int) bool { return c <= 0 }, "less than or equal to"),
+	"eqfield":  compareField(func(c int) bool { return c == 0 }, "equal to"),
+	"nefield":  compareField(func(c int) bool { return c != 0 }, "different from"),
+}
+
+var (
+	regexMu sync.Mutex
+	regexes = map[string]*regexp.Regexp{}
+)
+
+func required(f Field) string {
+	if f.Value.IsValid() && !f.Value.IsZero() {
+		return ""
+	}
+	if f.Value.Kind() == reflect.String {
+		return "must not be blank"
+	}
+	return "is required"
+}
+
+// bound implements min and max. Numbers are compared by value, strings by
+// character count and slices by length.
+func bound(ok func(n, limit float64) bool, word string) Func {
+	return func(f Field) string {
+		limit, err := strconv.ParseFloat(f.Param, 64)
+		if err != nil {
+			return fmt.Sprintf("has invalid limit %q", f.Param)
+		}
+		n, unit, measurable := measure(f.Value)
+		if !measurable || ok(n, limit) {
+			return ""
+		}
+		if unit == "" {
+			return fmt.Sprintf("must be %s %s", word, f.Param)
+		}
+		return fmt.Sprintf("must be %s %s %s", word, f.Param, unit)
+	}
+}
+
+func length(f Field) string {
+	want, err := strconv.Atoi(f.Param)
+	if err != nil {
+		return fmt.Sprintf("has invalid length %q", f.Param)
+	}
+	n, unit, measurable := measure(f.Value)
+	if !measurable || unit == "" || int(n) == want {
+		return ""
+	}
+	return fmt.Sprintf("must be exactly %d %s", want, unit)
+}
+
+func oneOf(f Field) string {
+	options := strings.Fields(f.Param)
+	value := fmt.Sprint(f.Value)
+	if f.Value.IsValid() {
+		value = fmt.Sprint(f.Value.Interface())
+	}
+	for _, option := range options {
+		if option == value {
+			return ""
+		}
+	}
+	if len(options) == 2 {
+		return fmt.Sprintf("must be %s or %s", options[0], options[1])
+	}
+	return "must be one of " + strings.Join(options, ", ")
+}
+
+func matchRegex(f Field) string {
+	if f.Value.Kind() != reflect.String {
+		return ""
+	}
+	re, err := compile(f.Param)
+	if err != nil {
+		return fmt.Sprintf("has invalid pattern %q", f.Param)
+	}
+	if !re.MatchString(f.Value.String()) {
+		return "has an invalid format"
+	}
+	return ""
+}
+
+// compareField implements the cross-field rules by comparing the field with
+// a sibling field of the same kind.
+func compareField(ok func(c int) bool, word string) Func {
+	return func(f Field) string {
+		if !f.Parent.IsValid() {
+			return ""
+		}
+		sf, found := f.Parent.Type().FieldByName(f.Param)
+		if !found {
+			return fmt.Sprintf("is compared with unknown field %q", f.Param)
+		}
+		other := indirect(f.Parent.FieldByIndex(sf.Index))
+		c, comparable := compare(f.Value, other)
+		if !comparable || ok(c) {
+			return ""
+		}
+		return fmt.Sprintf("must be %s %s", word, fieldName(sf))
+	}
+}
+
+// measure returns the number a bound applies to and the unit to describe it
+// with ("" for plain numbers).
+func measure(v reflect.Value) (float64, string, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), "", true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), "characters", true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), "items", true
+	default:
+		return 0, "", false
+	}
+}
+
+func compare(a, b reflect.Value) (int, bool) {
+	if !a.IsValid() || !b.IsValid() {
+		return 0, false
+	}
+	if a.Kind() == reflect.String && b.Kind() == reflect.String {
+		return strings.Compare(a.String(), b.String()), true
+	}
+	x, ux, okA := measure(a)
+	y, uy, okB := measure(b)
+	if !okA || !okB || ux != "" || uy != "" {
+		return 0, false
+	}
+	switch {
+	case x < y:
+		return -1, true
+	case x > y:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	regexMu.Lock()
+	defer regexMu.Unlock()
+	if re, ok := regexes[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexes[pattern] = re
+	return re, nil
+}
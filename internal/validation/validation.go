@@ -0,0 +1,300 @@
+// Package validation validates structs from `validate` struct tags and
+// reports every failure as a Problem named after the field's JSON name.
+//
+// A tag is a comma separated list of rules, applied in order:
+//
+//	required        the field must not be its zero value
+//	omitempty       skip the remaining rules when the field is its zero value
+//	min=N, max=N    bounds on numbers, string length or slice length
+//	len=N           exact string or slice length
+//	oneof=a b c     the value must be one of the space separated options
+//	unique          slice elements must not repeat
+//	regex=PATTERN   strings must match PATTERN; it consumes the rest of the tag,
+//	                so it must come last. A comma in PATTERN followed by a
+//	                rule name must be escaped as \, to tell it from a rule
+//	dive            the rules after dive apply to each slice element
+//	gtfield=F, gtefield=F, ltfield=F, ltefield=F, eqfield=F, nefield=F
+//	                compare against the sibling field F (Go field name)
+//
+// Nested structs and slices of structs are validated recursively and named
+// "parent.child" and "items[0].name". Rules that are not built in are looked
+// up in the validators added with Register. Structs implementing
+// StructValidator get a final hook for rules that do not fit a single tag.
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Problem describes one invalid field.
+type Problem struct {
+	Name        string `json:"name" xml:"name"`
+	Description string `json:"description" xml:"description"`
+}
+
+// StructValidator is implemented by structs with rules spanning several
+// fields. ValidateStruct runs after the tag rules of the struct's fields.
+type StructValidator interface {
+	ValidateStruct() []Problem
+}
+
+// Field is the value under validation, passed to rule functions.
+type Field struct {
+	// Name is the problem name of the field, e.g. "courses[1]".
+	Name string
+	// Value is the field value.
+	Value reflect.Value
+	// Param is the text after "=" in the rule, if any.
+	Param string
+	// Parent is the struct containing the field, for cross-field rules. For
+	// slice elements it is the struct containing the slice.
+	Parent reflect.Value
+}
+
+// Func checks one rule against a field. It returns a description of the
+// problem, e.g. "must not be blank", or "" if the field is valid.
+type Func func(field Field) string
+
+// Rule is a parsed tag entry.
+type Rule struct {
+	Name  string
+	Param string
+}
+
+var (
+	customMu sync.RWMutex
+	custom   = map[string]Func{}
+)
+
+// Register adds a custom rule usable in validate tags. It panics if name is
+// empty or shadows a built-in rule, since that is a programming error.
+func Register(name string, fn Func) {
+	if name == "" || builtin[name] != nil || name == "required" || name == "omitempty" || name == "dive" {
+		panic(fmt.Sprintf("validation: cannot register rule %q", name))
+	}
+	customMu.Lock()
+	defer customMu.Unlock()
+	custom[name] = fn
+}
+
+// ParseTag splits a validate tag into rules. It panics if a rule follows
+// regex=, which would silently become part of the pattern, since that is a
+// programming error.
+func ParseTag(tag string) []Rule {
+	var rules []Rule
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "regex=") {
+			if name := trailingRule(tag); name != "" {
+				panic(fmt.Sprintf("validation: rule %q follows regex= in tag %q; put it before regex= or escape the comma as \\,", name, tag))
+			}
+			part, tag = tag, ""
+		} else {
+			part, tag, _ = strings.Cut(tag, ",")
+		}
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			rules = append(rules, Rule{Name: name, Param: param})
+		}
+	}
+	return rules
+}
+
+// trailingRule returns the name of the first rule following an unescaped
+// comma in a regex= rule, or "" if there is none.
+func trailingRule(rule string) string {
+	for i := 0; i < len(rule); i++ {
+		switch rule[i] {
+		case '\\':
+			i++
+		case ',':
+			next, _, _ := strings.Cut(rule[i+1:], ",")
+			name, _, _ := strings.Cut(strings.TrimSpace(next), "=")
+			if isRuleName(name) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func isRuleName(name string) bool {
+	switch name {
+	case "":
+		return false
+	case "required", "omitempty", "dive", "unique":
+		return true
+	}
+	return lookup(name) != nil
+}
+
+// Validate checks every validate tag on v, which must be a struct or a
+// pointer to one, and returns the problems found in field order.
+func Validate(v any) []Problem {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	return validateStruct(rv, "")
+}
+
+func validateStruct(rv reflect.Value, prefix string) []Problem {
+	var problems []Problem
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := fieldName(sf)
+		if name == "" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		rules := ParseTag(sf.Tag.Get("validate"))
+		problems = append(problems, applyRules(Field{Name: name, Value: rv.Field(i), Parent: rv}, rules)...)
+	}
+
+	if sv, ok := rv.Interface().(StructValidator); ok {
+		for _, p := range sv.ValidateStruct() {
+			if prefix != "" {
+				p.Name = prefix + "." + p.Name
+			}
+			problems = append(problems, p)
+		}
+	}
+
+	return problems
+}
+
+// applyRules runs rules against field until one fails, then recurses into
+// nested structs and, after dive, slice elements.
+func applyRules(field Field, rules []Rule) []Problem {
+	var problems []Problem
+	value := indirect(field.Value)
+
+	for i, rule := range rules {
+		switch rule.Name {
+		case "omitempty":
+			if !value.IsValid() || value.IsZero() {
+				return nil
+			}
+			continue
+		case "dive":
+			if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+				return problems
+			}
+			for j := 0; j < value.Len(); j++ {
+				elem := field
+				elem.Name = fmt.Sprintf("%s[%d]", field.Name, j)
+				elem.Value = value.Index(j)
+				elem.Param = ""
+				problems = append(problems, applyRules(elem, rules[i+1:])...)
+			}
+			return problems
+		case "unique":
+			problems = append(problems, unique(field.Name, value)...)
+			continue
+		}
+
+		fn := lookup(rule.Name)
+		if fn == nil {
+			problems = append(problems, Problem{Name: field.Name, Description: fmt.Sprintf("has unknown validation rule %q", rule.Name)})
+			return problems
+		}
+		f := field
+		f.Value = value
+		f.Param = rule.Param
+		if desc := fn(f); desc != "" {
+			// Later rules usually only add noise once one has failed.
+			return append(problems, Problem{Name: field.Name, Description: desc})
+		}
+	}
+
+	if len(problems) > 0 {
+		return problems
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		problems = append(problems, validateStruct(value, field.Name)...)
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Struct {
+			for j := 0; j < value.Len(); j++ {
+				problems = append(problems, validateStruct(value.Index(j), fmt.Sprintf("%s[%d]", field.Name, j))...)
+			}
+		}
+	}
+	return problems
+}
+
+// unique reports each element that repeats an earlier one.
+func unique(name string, value reflect.Value) []Problem {
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil
+	}
+	var problems []Problem
+	seen := map[any]int{}
+	for i := 0; i < value.Len(); i++ {
+		elem := value.Index(i)
+		if !elem.Type().Comparable() {
+			continue
+		}
+		key := elem.Interface()
+		if first, ok := seen[key]; ok {
+			problems = append(problems, Problem{
+				Name:        fmt.Sprintf("%s[%d]", name, i),
+				Description: fmt.Sprintf("duplicates %s[%d]", name, first),
+			})
+			continue
+		}
+		seen[key] = i
+	}
+	return problems
+}
+
+func lookup(name string) Func {
+	if fn, ok := builtin[name]; ok {
+		return fn
+	}
+	customMu.RLock()
+	defer customMu.RUnlock()
+	return custom[name]
+}
+
+// fieldName returns the JSON name of a struct field, or "" if it is not
+// serialised.
+func fieldName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return sf.Name
+	default:
+		return name
+	}
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
@@ -0,0 +1,199 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want []Rule
+	}{
+		{"", nil},
+		{"required", []Rule{{Name: "required"}}},
+		{"omitempty, min=1 ,max=5", []Rule{{Name: "omitempty"}, {Name: "min", Param: "1"}, {Name: "max", Param: "5"}}},
+		{"oneof=a b c", []Rule{{Name: "oneof", Param: "a b c"}}},
+		{"min=1,unique,dive,oneof=x y", []Rule{{Name: "min", Param: "1"}, {Name: "unique"}, {Name: "dive"}, {Name: "oneof", Param: "x y"}}},
+		{"required,regex=^[0-9]{6,14}$", []Rule{{Name: "required"}, {Name: "regex", Param: "^[0-9]{6,14}$"}}},
+		{"gtefield=Start,regex=^a,b$", []Rule{{Name: "gtefield", Param: "Start"}, {Name: "regex", Param: "^a,b$"}}},
+		{`regex=^a\,required$`, []Rule{{Name: "regex", Param: `^a\,required$`}}},
+		{"a,,b", []Rule{{Name: "a"}, {Name: "b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := ParseTag(tt.tag); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTag(%q) = %+v, want %+v", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTagRejectsRulesAfterRegex(t *testing.T) {
+	tags := []string{
+		`regex=^\d{4}-\d{2}-\d{2}$,gtefield=StartDate`,
+		"regex=^a$,required",
+		"regex=^a$, max=5",
+		"omitempty,regex=^a$,dive",
+	}
+	for _, tag := range tags {
+		t.Run(tag, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("ParseTag(%q) did not panic", tag)
+				}
+			}()
+			ParseTag(tag)
+		})
+	}
+}
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type member struct {
+	Name     string    `json:"name" validate:"required,max=5"`
+	Nickname string    `json:"nickname,omitempty" validate:"omitempty,min=3"`
+	Age      *int      `json:"age" validate:"omitempty,min=18"`
+	Tags     []string  `json:"tags" validate:"max=3,unique,dive,oneof=a b c"`
+	IDs      []int     `json:"ids" validate:"unique"`
+	Home     address   `json:"home"`
+	Previous []address `json:"previous"`
+	Start    string    `json:"start"`
+	End      string    `json:"end" validate:"gtefield=Start"`
+	Min      int       `json:"min"`
+	Max      int       `json:"max" validate:"gtfield=Min"`
+	Other    int       `json:"other" validate:"nefield=Max"`
+	Code     string    `json:"code" validate:"omitempty,regex=^[A-Z]{2,3}$"`
+	Skipped  string    `json:"-" validate:"required"`
+	internal string
+}
+
+// ValidateStruct reports members named after their nickname.
+func (m member) ValidateStruct() []Problem {
+	if m.Nickname != "" && m.Name == m.Nickname {
+		return []Problem{{Name: "nickname", Description: "must differ from name"}}
+	}
+	return nil
+}
+
+// team checks that ValidateStruct problems of nested structs are prefixed.
+type team struct {
+	Lead    member   `json:"lead"`
+	Members []member `json:"members"`
+}
+
+func validMember() member {
+	return member{
+		Name:  "Ada",
+		Tags:  []string{"a", "b"},
+		IDs:   []int{1, 2},
+		Home:  address{City: "London"},
+		Start: "2025-01-01",
+		End:   "2025-02-01",
+		Min:   1,
+		Max:   2,
+		Other: 3,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	age := func(n int) *int { return &n }
+	tests := []struct {
+		name   string
+		modify func(m *member)
+		want   []Problem
+	}{
+		{"valid", func(m *member) {}, nil},
+		{"required string", func(m *member) { m.Name = "" }, []Problem{{"name", "must not be blank"}}},
+		{"max stops later rules", func(m *member) { m.Name = "Augusta" }, []Problem{{"name", "must be at most 5 characters"}}},
+		{"omitempty skips zero", func(m *member) { m.Nickname = "" }, nil},
+		{"omitempty checks set value", func(m *member) { m.Nickname = "Al" }, []Problem{{"nickname", "must be at least 3 characters"}}},
+		{"omitempty nil pointer", func(m *member) { m.Age = nil }, nil},
+		{"pointer checked through", func(m *member) { m.Age = age(17) }, []Problem{{"age", "must be at least 18"}}},
+		{"dive", func(m *member) { m.Tags = []string{"a", "d"} }, []Problem{{"tags[1]", "must be one of a, b, c"}}},
+		{"unique before dive", func(m *member) { m.Tags = []string{"a", "b", "a"} }, []Problem{{"tags[2]", "duplicates tags[0]"}}},
+		{"unique and dive", func(m *member) { m.Tags = []string{"d", "d"} }, []Problem{
+			{"tags[1]", "duplicates tags[0]"}, {"tags[0]", "must be one of a, b, c"}, {"tags[1]", "must be one of a, b, c"},
+		}},
+		{"slice bound", func(m *member) { m.Tags = []string{"a", "b", "c", "a"} }, []Problem{{"tags", "must be at most 3 items"}}},
+		{"unique ints", func(m *member) { m.IDs = []int{1, 2, 1, 1} }, []Problem{{"ids[2]", "duplicates ids[0]"}, {"ids[3]", "duplicates ids[0]"}}},
+		{"nested struct", func(m *member) { m.Home.City = "" }, []Problem{{"home.city", "must not be blank"}}},
+		{"slice of structs", func(m *member) { m.Previous = []address{{City: "Paris"}, {}} }, []Problem{{"previous[1].city", "must not be blank"}}},
+		{"gtefield equal", func(m *member) { m.End = m.Start }, nil},
+		{"gtefield", func(m *member) { m.End = "2024-12-31" }, []Problem{{"end", "must be greater than or equal to start"}}},
+		{"gtfield", func(m *member) { m.Max = m.Min }, []Problem{{"max", "must be greater than min"}}},
+		{"nefield", func(m *member) { m.Other = m.Max }, []Problem{{"other", "must be different from max"}}},
+		{"regex with comma", func(m *member) { m.Code = "ABC" }, nil},
+		{"regex mismatch", func(m *member) { m.Code = "ABCD" }, []Problem{{"code", "has an invalid format"}}},
+		{"struct validator", func(m *member) { m.Nickname = "Ada" }, []Problem{{"nickname", "must differ from name"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := validMember()
+			tt.modify(&m)
+			if got := Validate(m); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %+v, want %+v", got, tt.want)
+			}
+			if got := Validate(&m); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate(pointer) = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateStructPrefix(t *testing.T) {
+	lead, named := validMember(), validMember()
+	lead.Nickname = "Ada"
+	named.Nickname = "Ada"
+	named.Home.City = ""
+	got := Validate(team{Lead: lead, Members: []member{validMember(), named}})
+	want := []Problem{
+		{"lead.nickname", "must differ from name"},
+		{"members[1].home.city", "must not be blank"},
+		{"members[1].nickname", "must differ from name"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %+v, want %+v", got, want)
+	}
+}
+
+func TestValidateNonStruct(t *testing.T) {
+	var nilMember *member
+	for _, v := range []any{nil, 3, "text", nilMember} {
+		if got := Validate(v); got != nil {
+			t.Errorf("Validate(%#v) = %+v, want nil", v, got)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("even", func(f Field) string {
+		if f.Value.Int()%2 != 0 {
+			return "must be even"
+		}
+		return ""
+	})
+	type numbers struct {
+		N int `json:"n" validate:"even"`
+		U int `json:"u" validate:"odd"`
+	}
+	got := Validate(numbers{N: 3})
+	want := []Problem{{"n", "must be even"}, {"u", `has unknown validation rule "odd"`}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %+v, want %+v", got, want)
+	}
+
+	for _, name := range []string{"", "min", "required", "dive"} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil || !strings.Contains(r.(string), "cannot register") {
+					t.Errorf("Register(%q) did not panic", name)
+				}
+			}()
+			Register(name, nil)
+		}()
+	}
+}
@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	secret = "whsec"
+	body   = []byte(`{"id":1}`)
+	sentAt = time.Unix(1700000000, 0)
+)
+
+func TestSign(t *testing.T) {
+	want := "t=1700000000,v1=e79220cb981f992adbc8b93ac6d46028b0217ea19327d27dc9d18bf334403bde"
+	if got := Sign(secret, sentAt, body); got != want {
+		t.Errorf("Sign() = %q, want %q", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	header := Sign(secret, sentAt, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		header    string
+		body      []byte
+		tolerance time.Duration
+		now       time.Time
+		want      error
+	}{
+		{"valid", secret, header, body, 5 * time.Minute, sentAt.Add(time.Minute), nil},
+		{"spaces and unknown parts", secret, " v0=abc, t=1700000000 , v1=e79220cb981f992adbc8b93ac6d46028b0217ea19327d27dc9d18bf334403bde", body, 0, sentAt, nil},
+		{"at tolerance", secret, header, body, 5 * time.Minute, sentAt.Add(5 * time.Minute), nil},
+		{"too old", secret, header, body, 5 * time.Minute, sentAt.Add(5*time.Minute + time.Second), ErrExpiredSignature},
+		{"too new", secret, header, body, 5 * time.Minute, sentAt.Add(-5*time.Minute - time.Second), ErrExpiredSignature},
+		{"zero tolerance skips the check", secret, header, body, 0, sentAt.Add(24 * time.Hour), nil},
+		{"wrong secret", "other", header, body, 0, sentAt, ErrInvalidSignature},
+		{"changed body", secret, header, []byte(`{"id":2}`), 0, sentAt, ErrInvalidSignature},
+		{"changed timestamp", secret, "t=1700000001,v1=e79220cb981f992adbc8b93ac6d46028b0217ea19327d27dc9d18bf334403bde", body, 0, sentAt, ErrInvalidSignature},
+		{"mismatch reported before expiry", "other", header, body, time.Minute, sentAt.Add(time.Hour), ErrInvalidSignature},
+		{"empty", secret, "", body, 0, sentAt, ErrMalformedSignature},
+		{"no timestamp", secret, "v1=e79220cb981f992adbc8b93ac6d46028b0217ea19327d27dc9d18bf334403bde", body, 0, sentAt, ErrMalformedSignature},
+		{"no signature", secret, "t=1700000000", body, 0, sentAt, ErrMalformedSignature},
+		{"timestamp not a number", secret, "t=soon,v1=e79220cb981f992adbc8b93ac6d46028b0217ea19327d27dc9d18bf334403bde", body, 0, sentAt, ErrMalformedSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(tt.secret, tt.header, tt.body, tt.tolerance, tt.now); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+-- Adds the append-only audit log of person and course mutations, for
+-- databases created from db_seed.sql before it existed. New databases get
+-- this schema from db_seed.sql directly. Run it once, e.g.
+--
+--     psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f migrations/001_audit_log.sql
+
+BEGIN;
+
+CREATE TABLE audit_event
+(
+    id          BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ                                      NOT NULL DEFAULT now(),
+    actor       TEXT                                             NOT NULL,
+    request_id  TEXT                                             NOT NULL DEFAULT '',
+    action      TEXT CHECK (action IN ('create', 'update', 'delete')) NOT NULL,
+    entity      TEXT                                             NOT NULL,
+    entity_id   INTEGER                                          NOT NULL,
+    before      JSONB,
+    after       JSONB
+);
+
+CREATE INDEX audit_event_entity_idx ON audit_event (entity, entity_id, occurred_at);
+CREATE INDEX audit_event_actor_idx ON audit_event (actor, occurred_at);
+CREATE INDEX audit_event_occurred_at_idx ON audit_event (occurred_at);
+
+CREATE OR REPLACE FUNCTION audit_event_immutable() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_event is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_event_no_update
+    BEFORE UPDATE OR DELETE OR TRUNCATE
+    ON audit_event
+    FOR EACH STATEMENT
+EXECUTE FUNCTION audit_event_immutable();
+
+COMMIT;
@@ -0,0 +1,19 @@
+-- Adds soft deletion of persons and courses, and the restore and purge
+-- actions to the audit log. New databases get this schema from db_seed.sql
+-- directly. Run it once after 001_audit_log.sql, e.g.
+--
+--     psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f migrations/002_soft_delete.sql
+
+BEGIN;
+
+ALTER TABLE person ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE course ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX person_deleted_at_idx ON person (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX course_deleted_at_idx ON course (deleted_at) WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE audit_event
+    DROP CONSTRAINT audit_event_action_check,
+    ADD CONSTRAINT audit_event_action_check CHECK (action IN ('create', 'update', 'delete', 'restore', 'purge'));
+
+COMMIT;
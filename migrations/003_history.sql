@@ -0,0 +1,124 @@
+-- Adds the validity-period history of persons, courses and enrollments that
+-- as_of reads use. History starts now: every existing row gets a version
+-- valid from the time this runs. New databases get this schema from
+-- db_seed.sql directly. Run it once after 002_soft_delete.sql, e.g.
+--
+--     psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f migrations/003_history.sql
+
+BEGIN;
+
+CREATE TABLE person_history
+(
+    id         INTEGER     NOT NULL,
+    first_name TEXT        NOT NULL,
+    last_name  TEXT        NOT NULL,
+    type       TEXT        NOT NULL,
+    age        INTEGER     NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_to   TIMESTAMPTZ
+);
+
+CREATE INDEX person_history_id_idx ON person_history (id, valid_from);
+CREATE INDEX person_history_valid_idx ON person_history (valid_from, valid_to);
+
+INSERT INTO person_history (id, first_name, last_name, type, age, deleted_at, valid_from)
+SELECT id, first_name, last_name, type, age, deleted_at, now()
+FROM person;
+
+CREATE OR REPLACE FUNCTION person_history_version() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE person_history SET valid_to = now() WHERE id = OLD.id AND valid_to IS NULL;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO person_history (id, first_name, last_name, type, age, deleted_at, valid_from)
+        VALUES (NEW.id, NEW.first_name, NEW.last_name, NEW.type, NEW.age, NEW.deleted_at, now());
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER person_history_version
+    AFTER INSERT OR UPDATE OR DELETE
+    ON person
+    FOR EACH ROW
+EXECUTE FUNCTION person_history_version();
+
+CREATE TABLE course_history
+(
+    id         INTEGER     NOT NULL,
+    name       TEXT        NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_to   TIMESTAMPTZ
+);
+
+CREATE INDEX course_history_id_idx ON course_history (id, valid_from);
+CREATE INDEX course_history_valid_idx ON course_history (valid_from, valid_to);
+
+INSERT INTO course_history (id, name, deleted_at, valid_from)
+SELECT id, name, deleted_at, now()
+FROM course;
+
+CREATE OR REPLACE FUNCTION course_history_version() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE course_history SET valid_to = now() WHERE id = OLD.id AND valid_to IS NULL;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO course_history (id, name, deleted_at, valid_from)
+        VALUES (NEW.id, NEW.name, NEW.deleted_at, now());
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER course_history_version
+    AFTER INSERT OR UPDATE OR DELETE
+    ON course
+    FOR EACH ROW
+EXECUTE FUNCTION course_history_version();
+
+CREATE TABLE person_course_history
+(
+    person_id  INTEGER     NOT NULL,
+    course_id  INTEGER     NOT NULL,
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_to   TIMESTAMPTZ
+);
+
+CREATE INDEX person_course_history_person_idx ON person_course_history (person_id, valid_from);
+CREATE INDEX person_course_history_course_idx ON person_course_history (course_id, valid_from);
+
+INSERT INTO person_course_history (person_id, course_id, valid_from)
+SELECT person_id, course_id, now()
+FROM person_course;
+
+CREATE OR REPLACE FUNCTION person_course_history_version() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE person_course_history
+        SET valid_to = now()
+        WHERE person_id = OLD.person_id
+          AND course_id = OLD.course_id
+          AND valid_to IS NULL;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO person_course_history (person_id, course_id, valid_from)
+        VALUES (NEW.person_id, NEW.course_id, now());
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER person_course_history_version
+    AFTER INSERT OR UPDATE OR DELETE
+    ON person_course
+    FOR EACH ROW
+EXECUTE FUNCTION person_course_history_version();
+
+COMMIT;
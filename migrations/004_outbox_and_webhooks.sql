@@ -0,0 +1,51 @@
+-- Adds the outbox of domain events and the webhook subscriptions and
+-- deliveries it feeds. New databases get this schema from db_seed.sql
+-- directly. Run it once after 003_history.sql, e.g.
+--
+--     psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f migrations/004_outbox_and_webhooks.sql
+
+BEGIN;
+
+CREATE TABLE outbox_event
+(
+    id            BIGSERIAL PRIMARY KEY,
+    type          TEXT        NOT NULL,
+    occurred_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    actor         TEXT        NOT NULL,
+    request_id    TEXT        NOT NULL DEFAULT '',
+    entity_id     INTEGER     NOT NULL,
+    data          JSONB       NOT NULL,
+    dispatched_at TIMESTAMPTZ
+);
+
+CREATE INDEX outbox_event_undispatched_idx ON outbox_event (id) WHERE dispatched_at IS NULL;
+CREATE INDEX outbox_event_occurred_at_idx ON outbox_event (occurred_at);
+
+CREATE TABLE webhook_subscription
+(
+    id         SERIAL PRIMARY KEY,
+    url        TEXT        NOT NULL,
+    secret     TEXT        NOT NULL,
+    events     TEXT[]      NOT NULL DEFAULT '{}',
+    active     BOOLEAN     NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE webhook_delivery
+(
+    id               BIGSERIAL PRIMARY KEY,
+    subscription_id  INTEGER     NOT NULL REFERENCES webhook_subscription (id),
+    event_id         BIGINT      NOT NULL REFERENCES outbox_event (id),
+    status           TEXT CHECK (status IN ('pending', 'delivered', 'dead')) NOT NULL DEFAULT 'pending',
+    attempts         INTEGER     NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_status_code INTEGER,
+    last_error       TEXT        NOT NULL DEFAULT '',
+    delivered_at     TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX webhook_delivery_due_idx ON webhook_delivery (next_attempt_at) WHERE status = 'pending';
+
+COMMIT;
@@ -0,0 +1,23 @@
+-- Notifies the event hubs of the API instances when a transaction writes
+-- outbox events. New databases get this schema from db_seed.sql directly.
+-- Run it once after 004_outbox_and_webhooks.sql, e.g.
+--
+--     psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f migrations/005_outbox_notify.sql
+
+BEGIN;
+
+CREATE OR REPLACE FUNCTION outbox_event_notify() RETURNS trigger AS
+$$
+BEGIN
+    PERFORM pg_notify('outbox_event', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER outbox_event_notify
+    AFTER INSERT
+    ON outbox_event
+    FOR EACH STATEMENT
+EXECUTE FUNCTION outbox_event_notify();
+
+COMMIT;
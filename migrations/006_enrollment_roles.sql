@@ -0,0 +1,87 @@
+-- Adds roles to enrollments. Professors already enrolled in a course become
+-- its instructors and everyone else becomes a student, in the history as
+-- well. New databases get this schema from db_seed.sql directly. Run it once
+-- after 005_outbox_notify.sql, e.g.
+--
+--     psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f migrations/006_enrollment_roles.sql
+
+BEGIN;
+
+ALTER TABLE person_course
+    ADD COLUMN role TEXT CHECK (role IN ('instructor', 'teaching_assistant', 'student', 'auditor')) NOT NULL DEFAULT 'student';
+
+ALTER TABLE person_course_history
+    ADD COLUMN role TEXT NOT NULL DEFAULT 'student';
+
+-- The backfill is not a change to the enrollments, so it makes no new
+-- versions.
+ALTER TABLE person_course DISABLE TRIGGER person_course_history_version;
+UPDATE person_course pc
+SET role = 'instructor'
+FROM person p
+WHERE p.id = pc.person_id
+  AND p.type = 'professor';
+ALTER TABLE person_course ENABLE TRIGGER person_course_history_version;
+
+UPDATE person_course_history h
+SET role = 'instructor'
+FROM person p
+WHERE p.id = h.person_id
+  AND p.type = 'professor';
+
+ALTER TABLE person_course_history
+    ALTER COLUMN role DROP DEFAULT;
+
+CREATE OR REPLACE FUNCTION person_course_history_version() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE person_course_history
+        SET valid_to = now()
+        WHERE person_id = OLD.person_id
+          AND course_id = OLD.course_id
+          AND valid_to IS NULL;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO person_course_history (person_id, course_id, role, valid_from)
+        VALUES (NEW.person_id, NEW.course_id, NEW.role, now());
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION person_course_check_role() RETURNS trigger AS
+$$
+BEGIN
+    IF NEW.role = 'instructor' AND NOT EXISTS (SELECT 1 FROM person WHERE id = NEW.person_id AND type = 'professor') THEN
+        RAISE EXCEPTION 'person % is not a professor and cannot be an instructor', NEW.person_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER person_course_check_role
+    BEFORE INSERT OR UPDATE
+    ON person_course
+    FOR EACH ROW
+EXECUTE FUNCTION person_course_check_role();
+
+CREATE OR REPLACE FUNCTION person_check_instructor_type() RETURNS trigger AS
+$$
+BEGIN
+    IF NEW.type <> 'professor' AND EXISTS (SELECT 1 FROM person_course WHERE person_id = NEW.id AND role = 'instructor') THEN
+        RAISE EXCEPTION 'person % is an instructor and must remain a professor', NEW.id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER person_check_instructor_type
+    BEFORE UPDATE OF type
+    ON person
+    FOR EACH ROW
+EXECUTE FUNCTION person_check_instructor_type();
+
+COMMIT;
@@ -0,0 +1,37 @@
+-- Adds course capacities and waitlists. Existing courses are left
+-- unlimited. New databases get this schema from db_seed.sql directly. Run it
+-- once after 006_enrollment_roles.sql, e.g.
+--
+--     psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f migrations/007_capacity_and_waitlists.sql
+
+BEGIN;
+
+ALTER TABLE course ADD COLUMN capacity INTEGER CHECK (capacity > 0);
+ALTER TABLE course_history ADD COLUMN capacity INTEGER;
+
+CREATE OR REPLACE FUNCTION course_history_version() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE course_history SET valid_to = now() WHERE id = OLD.id AND valid_to IS NULL;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO course_history (id, name, capacity, deleted_at, valid_from)
+        VALUES (NEW.id, NEW.name, NEW.capacity, NEW.deleted_at, now());
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TABLE course_waitlist
+(
+    id        BIGSERIAL PRIMARY KEY,
+    course_id INTEGER     NOT NULL REFERENCES course (id),
+    person_id INTEGER     NOT NULL REFERENCES person (id),
+    added_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (course_id, person_id)
+);
+
+CREATE INDEX course_waitlist_person_idx ON course_waitlist (person_id);
+
+COMMIT;
@@ -0,0 +1,191 @@
+-- Moves enrollments from courses to sections of terms, for databases created
+-- from db_seed.sql before terms existed. New databases get this schema from
+-- db_seed.sql directly. Run it once after 007_capacity_and_waitlists.sql, e.g.
+--
+--     psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f migrations/008_terms_and_sections.sql
+--
+-- Every course gets section 1 in a term named 'Default', which becomes the
+-- current term, and every enrollment, history row and waitlist place moves
+-- to that section. Rename the term and correct its dates once they are known.
+
+BEGIN;
+
+CREATE TABLE term
+(
+    id         SERIAL PRIMARY KEY,
+    name       TEXT NOT NULL UNIQUE,
+    start_date DATE NOT NULL,
+    end_date   DATE NOT NULL,
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX term_start_date_idx ON term (start_date);
+
+CREATE TABLE section
+(
+    id            SERIAL PRIMARY KEY,
+    course_id     INTEGER NOT NULL REFERENCES course (id),
+    term_id       INTEGER NOT NULL REFERENCES term (id),
+    number        INTEGER NOT NULL CHECK (number > 0),
+    instructor_id INTEGER REFERENCES person (id),
+    capacity      INTEGER CHECK (capacity > 0),
+    UNIQUE (course_id, term_id, number)
+);
+
+CREATE INDEX section_term_idx ON section (term_id, course_id);
+
+-- the default term starts with the oldest enrollment so that as_of reads of
+-- earlier enrollments still find it
+INSERT INTO term (name, start_date, end_date)
+SELECT 'Default', d, GREATEST(d, CURRENT_DATE) + 120
+FROM (SELECT LEAST(COALESCE(min(valid_from)::date, CURRENT_DATE), CURRENT_DATE) AS d FROM person_course_history) start;
+
+-- soft deleted courses get a section too, so their enrollments come back if
+-- they are restored
+INSERT INTO section (course_id, term_id, number, instructor_id, capacity)
+SELECT c.id,
+       (SELECT id FROM term WHERE name = 'Default'),
+       1,
+       (SELECT min(pc.person_id)
+        FROM person_course pc
+        JOIN person p ON p.id = pc.person_id AND p.type = 'professor'
+        WHERE pc.course_id = c.id AND pc.role = 'instructor'),
+       c.capacity
+FROM course c;
+
+CREATE TABLE person_section
+(
+    person_id  INTEGER NOT NULL,
+    section_id INTEGER NOT NULL,
+    role       TEXT CHECK (role IN ('instructor', 'teaching_assistant', 'student', 'auditor')) NOT NULL DEFAULT 'student',
+    PRIMARY KEY (person_id, section_id),
+    FOREIGN KEY (person_id) REFERENCES person (id),
+    FOREIGN KEY (section_id) REFERENCES section (id)
+);
+
+CREATE INDEX person_section_section_idx ON person_section (section_id);
+
+CREATE TABLE person_section_history
+(
+    person_id  INTEGER     NOT NULL,
+    section_id INTEGER     NOT NULL,
+    course_id  INTEGER     NOT NULL,
+    term_id    INTEGER     NOT NULL,
+    role       TEXT        NOT NULL,
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_to   TIMESTAMPTZ
+);
+
+CREATE INDEX person_section_history_person_idx ON person_section_history (person_id, valid_from);
+CREATE INDEX person_section_history_course_idx ON person_section_history (course_id, valid_from);
+
+-- copy the history before the enrollments, whose insert trigger is created
+-- afterwards so the moved enrollments keep their original periods
+INSERT INTO person_section_history (person_id, section_id, course_id, term_id, role, valid_from, valid_to)
+SELECT h.person_id, s.id, s.course_id, s.term_id, h.role, h.valid_from, h.valid_to
+FROM person_course_history h
+JOIN section s ON s.course_id = h.course_id;
+
+INSERT INTO person_section (person_id, section_id, role)
+SELECT pc.person_id, s.id, pc.role
+FROM person_course pc
+JOIN section s ON s.course_id = pc.course_id;
+
+CREATE OR REPLACE FUNCTION person_section_history_version() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE person_section_history
+        SET valid_to = now()
+        WHERE person_id = OLD.person_id
+          AND section_id = OLD.section_id
+          AND valid_to IS NULL;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO person_section_history (person_id, section_id, course_id, term_id, role, valid_from)
+        SELECT NEW.person_id, NEW.section_id, s.course_id, s.term_id, NEW.role, now()
+        FROM section s
+        WHERE s.id = NEW.section_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER person_section_history_version
+    AFTER INSERT OR UPDATE OR DELETE
+    ON person_section
+    FOR EACH ROW
+EXECUTE FUNCTION person_section_history_version();
+
+CREATE OR REPLACE FUNCTION person_section_check_role() RETURNS trigger AS
+$$
+BEGIN
+    IF NEW.role = 'instructor' AND NOT EXISTS (SELECT 1 FROM person WHERE id = NEW.person_id AND type = 'professor') THEN
+        RAISE EXCEPTION 'person % is not a professor and cannot be an instructor', NEW.person_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER person_section_check_role
+    BEFORE INSERT OR UPDATE
+    ON person_section
+    FOR EACH ROW
+EXECUTE FUNCTION person_section_check_role();
+
+CREATE OR REPLACE FUNCTION section_check_instructor() RETURNS trigger AS
+$$
+BEGIN
+    IF NEW.instructor_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM person WHERE id = NEW.instructor_id AND type = 'professor') THEN
+        RAISE EXCEPTION 'person % is not a professor and cannot be an instructor', NEW.instructor_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER section_check_instructor
+    BEFORE INSERT OR UPDATE OF instructor_id
+    ON section
+    FOR EACH ROW
+EXECUTE FUNCTION section_check_instructor();
+
+CREATE OR REPLACE FUNCTION person_check_instructor_type() RETURNS trigger AS
+$$
+BEGIN
+    IF NEW.type <> 'professor' AND (
+        EXISTS (SELECT 1 FROM person_section WHERE person_id = NEW.id AND role = 'instructor') OR
+        EXISTS (SELECT 1 FROM section WHERE instructor_id = NEW.id)) THEN
+        RAISE EXCEPTION 'person % is an instructor and must remain a professor', NEW.id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TABLE section_waitlist
+(
+    id         BIGSERIAL PRIMARY KEY,
+    section_id INTEGER     NOT NULL REFERENCES section (id),
+    person_id  INTEGER     NOT NULL REFERENCES person (id),
+    added_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (section_id, person_id)
+);
+
+CREATE INDEX section_waitlist_person_idx ON section_waitlist (person_id);
+
+-- keep the order of the waitlists
+INSERT INTO section_waitlist (section_id, person_id, added_at)
+SELECT s.id, w.person_id, w.added_at
+FROM course_waitlist w
+JOIN section s ON s.course_id = w.course_id
+ORDER BY w.id;
+
+DROP TABLE course_waitlist;
+DROP TABLE person_course_history;
+DROP TABLE person_course;
+DROP FUNCTION person_course_history_version();
+DROP FUNCTION person_course_check_role();
+
+COMMIT;
@@ -0,0 +1,18 @@
+-- Adds prerequisites between courses. New databases get this schema from
+-- db_seed.sql directly. Run it once after 008_terms_and_sections.sql, e.g.
+--
+--     psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f migrations/009_course_prerequisites.sql
+
+BEGIN;
+
+CREATE TABLE course_prerequisite
+(
+    course_id       INTEGER NOT NULL REFERENCES course (id),
+    prerequisite_id INTEGER NOT NULL REFERENCES course (id),
+    PRIMARY KEY (course_id, prerequisite_id),
+    CHECK (course_id <> prerequisite_id)
+);
+
+CREATE INDEX course_prerequisite_prerequisite_idx ON course_prerequisite (prerequisite_id);
+
+COMMIT;
@@ -0,0 +1,25 @@
+-- Adds course credits and grades. New databases get this schema from
+-- db_seed.sql directly. Run it once after 009_course_prerequisites.sql, e.g.
+--
+--     psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f migrations/010_grades.sql
+
+BEGIN;
+
+ALTER TABLE course
+    ADD COLUMN credits INTEGER NOT NULL DEFAULT 3 CHECK (credits > 0);
+
+CREATE TABLE grade
+(
+    person_id  INTEGER     NOT NULL REFERENCES person (id),
+    section_id INTEGER     NOT NULL REFERENCES section (id),
+    grade      TEXT        NOT NULL,
+    points     NUMERIC(3, 2),
+    passed     BOOLEAN     NOT NULL,
+    graded_by  INTEGER     REFERENCES person (id) ON DELETE SET NULL,
+    graded_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (person_id, section_id)
+);
+
+CREATE INDEX grade_section_idx ON grade (section_id);
+
+COMMIT;
@@ -0,0 +1,30 @@
+-- Adds rooms and the weekly meeting patterns of sections. New databases get
+-- this schema from db_seed.sql directly. Run it once after 010_grades.sql,
+-- e.g.
+--
+--     psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f migrations/011_schedules.sql
+
+BEGIN;
+
+CREATE TABLE room
+(
+    id       SERIAL PRIMARY KEY,
+    name     TEXT    NOT NULL UNIQUE,
+    capacity INTEGER NOT NULL CHECK (capacity > 0)
+);
+
+CREATE TABLE section_meeting
+(
+    id         SERIAL PRIMARY KEY,
+    section_id INTEGER NOT NULL REFERENCES section (id),
+    days       TEXT[]  NOT NULL CHECK (cardinality(days) > 0 AND days <@ ARRAY ['mon', 'tue', 'wed', 'thu', 'fri', 'sat', 'sun']),
+    start_time TIME    NOT NULL,
+    end_time   TIME    NOT NULL,
+    room_id    INTEGER REFERENCES room (id),
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX section_meeting_section_idx ON section_meeting (section_id);
+CREATE INDEX section_meeting_room_idx ON section_meeting (room_id);
+
+COMMIT;
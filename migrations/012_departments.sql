@@ -0,0 +1,89 @@
+-- Adds departments, and catalog codes and descriptions to courses. Existing
+-- courses are left without a department or description. New databases get
+-- this schema from db_seed.sql directly. Run it once after
+-- 011_schedules.sql, e.g.
+--
+--     psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f migrations/012_departments.sql
+
+BEGIN;
+
+CREATE TABLE department
+(
+    id       SERIAL PRIMARY KEY,
+    code     TEXT NOT NULL UNIQUE CHECK (code ~ '^[A-Z]{2,6}$'),
+    name     TEXT NOT NULL UNIQUE,
+    chair_id INTEGER REFERENCES person (id)
+);
+
+CREATE OR REPLACE FUNCTION department_check_chair() RETURNS trigger AS
+$$
+BEGIN
+    IF NEW.chair_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM person WHERE id = NEW.chair_id AND type = 'professor') THEN
+        RAISE EXCEPTION 'person % is not a professor and cannot chair a department', NEW.chair_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER department_check_chair
+    BEFORE INSERT OR UPDATE OF chair_id
+    ON department
+    FOR EACH ROW
+EXECUTE FUNCTION department_check_chair();
+
+CREATE OR REPLACE FUNCTION person_check_instructor_type() RETURNS trigger AS
+$$
+BEGIN
+    IF NEW.type <> 'professor' AND (
+        EXISTS (SELECT 1 FROM person_section WHERE person_id = NEW.id AND role = 'instructor') OR
+        EXISTS (SELECT 1 FROM section WHERE instructor_id = NEW.id)) THEN
+        RAISE EXCEPTION 'person % is an instructor and must remain a professor', NEW.id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    IF NEW.type <> 'professor' AND EXISTS (SELECT 1 FROM department WHERE chair_id = NEW.id) THEN
+        RAISE EXCEPTION 'person % chairs a department and must remain a professor', NEW.id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE course
+    ADD COLUMN department_id  INTEGER REFERENCES department (id),
+    ADD COLUMN catalog_number TEXT CHECK (catalog_number IS NULL OR department_id IS NOT NULL),
+    ADD COLUMN description    TEXT NOT NULL DEFAULT '',
+    ADD UNIQUE (department_id, catalog_number);
+
+CREATE INDEX course_credits_idx ON course (credits);
+
+-- Past versions get the course's current credits, the best guess there is,
+-- or the default for purged courses
+ALTER TABLE course_history
+    ADD COLUMN credits        INTEGER,
+    ADD COLUMN department_id  INTEGER,
+    ADD COLUMN catalog_number TEXT,
+    ADD COLUMN description    TEXT NOT NULL DEFAULT '';
+
+UPDATE course_history h
+SET credits = COALESCE((SELECT c.credits FROM course c WHERE c.id = h.id), 3);
+
+ALTER TABLE course_history
+    ALTER COLUMN credits SET NOT NULL,
+    ALTER COLUMN description DROP DEFAULT;
+
+CREATE OR REPLACE FUNCTION course_history_version() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE course_history SET valid_to = now() WHERE id = OLD.id AND valid_to IS NULL;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO course_history (id, name, capacity, credits, department_id, catalog_number, description, deleted_at, valid_from)
+        VALUES (NEW.id, NEW.name, NEW.capacity, NEW.credits, NEW.department_id, NEW.catalog_number, NEW.description, NEW.deleted_at, now());
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
@@ -0,0 +1,60 @@
+-- Replaces the age of persons with their date of birth, and adds an optional
+-- email, phone number and address. Ages are converted to approximate dates
+-- of birth, half a year before the birthday they imply: as of today for
+-- persons, and as of when it became valid for each version in their history.
+-- New databases get this schema from db_seed.sql directly. Run it once after
+-- 012_departments.sql, e.g.
+--
+--     psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f migrations/013_person_profiles.sql
+
+BEGIN;
+
+ALTER TABLE person_history
+    ADD COLUMN date_of_birth DATE,
+    ADD COLUMN email         TEXT,
+    ADD COLUMN phone         TEXT NOT NULL DEFAULT '',
+    ADD COLUMN address       TEXT NOT NULL DEFAULT '';
+
+UPDATE person_history
+SET date_of_birth = (valid_from - make_interval(years => age, months => 6))::date;
+
+ALTER TABLE person_history
+    ALTER COLUMN date_of_birth SET NOT NULL,
+    ALTER COLUMN phone DROP DEFAULT,
+    ALTER COLUMN address DROP DEFAULT,
+    DROP COLUMN age;
+
+ALTER TABLE person
+    ADD COLUMN date_of_birth DATE CHECK (date_of_birth > '1900-01-01'),
+    ADD COLUMN email         TEXT CHECK (email ~ '^[^@\s]+@[^@\s]+\.[^@\s]+$'),
+    ADD COLUMN phone         TEXT NOT NULL DEFAULT '' CHECK (phone = '' OR phone ~ '^\+[1-9][0-9]{6,14}$'),
+    ADD COLUMN address       TEXT NOT NULL DEFAULT '';
+
+-- The backfill is not a change to the persons, so it makes no new versions.
+ALTER TABLE person DISABLE TRIGGER person_history_version;
+UPDATE person
+SET date_of_birth = (CURRENT_DATE - make_interval(years => age, months => 6))::date;
+ALTER TABLE person ENABLE TRIGGER person_history_version;
+
+ALTER TABLE person
+    ALTER COLUMN date_of_birth SET NOT NULL,
+    DROP COLUMN age;
+
+CREATE UNIQUE INDEX person_email_idx ON person (lower(email));
+
+CREATE OR REPLACE FUNCTION person_history_version() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE person_history SET valid_to = now() WHERE id = OLD.id AND valid_to IS NULL;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO person_history (id, first_name, last_name, type, date_of_birth, email, phone, address, deleted_at, valid_from)
+        VALUES (NEW.id, NEW.first_name, NEW.last_name, NEW.type, NEW.date_of_birth, NEW.email, NEW.phone, NEW.address,
+                NEW.deleted_at, now());
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
@@ -0,0 +1,33 @@
+-- Numbers outbox events in commit order, so that the event hub and event
+-- streams, which follow the outbox by position, never skip an event whose
+-- transaction commits after that of a later id. Existing events keep their
+-- id as position. New databases get this schema from db_seed.sql directly.
+-- Run it once after 013_person_profiles.sql, e.g.
+--
+--     psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f migrations/014_outbox_positions.sql
+
+BEGIN;
+
+ALTER TABLE outbox_event ADD COLUMN position BIGINT UNIQUE;
+UPDATE outbox_event SET position = id;
+
+CREATE SEQUENCE outbox_event_position_seq OWNED BY outbox_event.position;
+SELECT setval('outbox_event_position_seq', COALESCE(max(id), 0) + 1, false) FROM outbox_event;
+
+CREATE OR REPLACE FUNCTION outbox_event_position() RETURNS trigger AS
+$$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('outbox_event_position'));
+    UPDATE outbox_event SET position = nextval('outbox_event_position_seq') WHERE id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER outbox_event_position
+    AFTER INSERT
+    ON outbox_event
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW
+EXECUTE FUNCTION outbox_event_position();
+
+COMMIT;